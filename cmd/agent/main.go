@@ -0,0 +1,254 @@
+// kubepolaris-agent 集群 Agent
+//
+// 部署在 KubePolaris 无法直连的集群（NAT/私有网络）中，主动与 KubePolaris 建立 WebSocket 反向隧道，
+// 并将隧道内的请求以自身 ServiceAccount 身份转发给集群内的 API Server。
+//
+// 用法（通常由 KubePolaris 生成的部署清单以环境变量配置）：
+//
+//	KUBEPOLARIS_SERVER=https://kubepolaris.example.com \
+//	KUBEPOLARIS_AGENT_TOKEN=kpat_xxx \
+//	kubepolaris-agent [-kubeconfig ~/.kube/config] [-insecure]
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/clay-wangzhi/KubePolaris/pkg/logger"
+	"github.com/clay-wangzhi/KubePolaris/pkg/tunnel"
+
+	"github.com/gorilla/websocket"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+// agentVersion Agent 版本，构建时可通过 -ldflags "-X main.agentVersion=..." 覆盖
+var agentVersion = "dev"
+
+const (
+	connectPath       = "/ws/agent/connect"
+	heartbeatInterval = 20 * time.Second
+	minBackoff        = time.Second
+	maxBackoff        = 60 * time.Second
+)
+
+func main() {
+	server := flag.String("server", os.Getenv("KUBEPOLARIS_SERVER"), "KubePolaris 访问地址（环境变量 KUBEPOLARIS_SERVER）")
+	token := flag.String("token", os.Getenv("KUBEPOLARIS_AGENT_TOKEN"), "Agent 接入令牌（环境变量 KUBEPOLARIS_AGENT_TOKEN）")
+	kubeconfig := flag.String("kubeconfig", os.Getenv("KUBECONFIG"), "kubeconfig 路径，为空时使用 in-cluster 配置")
+	insecure := flag.Bool("insecure", os.Getenv("KUBEPOLARIS_INSECURE") == "true", "跳过 KubePolaris 服务端证书校验")
+	logLevel := flag.String("log-level", "info", "日志级别")
+	flag.Parse()
+
+	logger.Init(*logLevel)
+
+	if *server == "" || *token == "" {
+		fmt.Fprintln(os.Stderr, "必须提供 -server 与 -token（或环境变量 KUBEPOLARIS_SERVER / KUBEPOLARIS_AGENT_TOKEN）")
+		os.Exit(1)
+	}
+	connectURL, err := buildConnectURL(*server)
+	if err != nil {
+		logger.Fatal("KubePolaris 地址格式错误: %v", err)
+	}
+
+	restConfig, err := loadRESTConfig(*kubeconfig)
+	if err != nil {
+		logger.Fatal("加载集群配置失败: %v", err)
+	}
+	proxy, err := newAPIProxy(restConfig)
+	if err != nil {
+		logger.Fatal("创建 API Server 代理失败: %v", err)
+	}
+	k8sVersion := detectK8sVersion(restConfig)
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	dialer := &websocket.Dialer{
+		Proxy:            http.ProxyFromEnvironment,
+		HandshakeTimeout: 15 * time.Second,
+		ReadBufferSize:   32 * 1024,
+		WriteBufferSize:  32 * 1024,
+		TLSClientConfig:  &tls.Config{InsecureSkipVerify: *insecure}, //nolint:gosec // 由 -insecure 显式开启
+	}
+	header := http.Header{"Authorization": []string{"Bearer " + *token}}
+
+	logger.Info("KubePolaris Agent %s 启动，服务端: %s", agentVersion, connectURL)
+	backoff := minBackoff
+	for ctx.Err() == nil {
+		connectedAt := time.Now()
+		err := runSession(ctx, dialer, connectURL, header, proxy, k8sVersion)
+		if ctx.Err() != nil {
+			break
+		}
+		var authErr *authError
+		if errors.As(err, &authErr) {
+			logger.Error("Agent 认证失败，请检查令牌是否被吊销: %v", err)
+		} else {
+			logger.Warn("隧道断开: %v", err)
+		}
+
+		// 连接稳定运行过一段时间后重置退避
+		if time.Since(connectedAt) > maxBackoff {
+			backoff = minBackoff
+		}
+		wait := backoff/2 + time.Duration(rand.Int63n(int64(backoff)))
+		logger.Info("%s 后重新连接", wait.Round(time.Millisecond))
+		select {
+		case <-ctx.Done():
+		case <-time.After(wait):
+		}
+		if backoff *= 2; backoff > maxBackoff {
+			backoff = maxBackoff
+		}
+	}
+	logger.Info("Agent 已退出")
+}
+
+// authError 服务端拒绝了令牌
+type authError struct {
+	status int
+}
+
+func (e *authError) Error() string {
+	return fmt.Sprintf("服务端返回 %d", e.status)
+}
+
+// runSession 建立一次隧道并在其上提供 API Server 代理，直到隧道断开
+func runSession(ctx context.Context, dialer *websocket.Dialer, connectURL string, header http.Header, proxy http.Handler, k8sVersion string) error {
+	conn, resp, err := dialer.DialContext(ctx, connectURL, header)
+	if err != nil {
+		if resp != nil && (resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden) {
+			return &authError{status: resp.StatusCode}
+		}
+		return err
+	}
+	session := tunnel.NewSession(conn, tunnel.Config{})
+	defer session.Close()
+	logger.Info("隧道已建立")
+
+	go heartbeatLoop(session, k8sVersion)
+	go func() {
+		select {
+		case <-ctx.Done():
+			_ = session.Close()
+		case <-session.Done():
+		}
+	}()
+
+	srv := &http.Server{
+		Handler:           proxy,
+		ReadHeaderTimeout: 30 * time.Second,
+	}
+	_ = srv.Serve(session)
+	_ = srv.Close()
+	return session.Err()
+}
+
+// heartbeatLoop 定期上报 Agent 与集群版本
+func heartbeatLoop(session *tunnel.Session, k8sVersion string) {
+	payload, _ := json.Marshal(map[string]string{
+		"agentVersion": agentVersion,
+		"k8sVersion":   k8sVersion,
+	})
+	ticker := time.NewTicker(heartbeatInterval)
+	defer ticker.Stop()
+	for {
+		if err := session.SendHeartbeat(payload); err != nil {
+			return
+		}
+		select {
+		case <-session.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// buildConnectURL 将 KubePolaris 地址转换为隧道 WebSocket 地址
+func buildConnectURL(server string) (string, error) {
+	u, err := url.Parse(strings.TrimRight(server, "/"))
+	if err != nil {
+		return "", err
+	}
+	switch u.Scheme {
+	case "https", "wss":
+		u.Scheme = "wss"
+	case "http", "ws":
+		u.Scheme = "ws"
+	default:
+		return "", fmt.Errorf("不支持的协议: %q", u.Scheme)
+	}
+	u.Path += connectPath
+	return u.String(), nil
+}
+
+// loadRESTConfig 加载访问本集群的配置
+func loadRESTConfig(kubeconfig string) (*rest.Config, error) {
+	if kubeconfig != "" {
+		return clientcmd.BuildConfigFromFlags("", kubeconfig)
+	}
+	return rest.InClusterConfig()
+}
+
+// newAPIProxy 创建到 API Server 的反向代理
+// 服务端请求不携带凭据，由代理统一使用 Agent 的身份认证；exec/attach 等升级请求同样适用。
+func newAPIProxy(config *rest.Config) (http.Handler, error) {
+	target, err := url.Parse(config.Host)
+	if err != nil {
+		return nil, err
+	}
+	if target.Scheme == "" {
+		target.Scheme = "https"
+	}
+
+	// 反向代理需要支持 SPDY/WebSocket 升级，强制使用 HTTP/1.1
+	cfg := rest.CopyConfig(config)
+	cfg.NextProtos = []string{"http/1.1"}
+	transport, err := rest.TransportFor(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return &httputil.ReverseProxy{
+		Rewrite: func(r *httputil.ProxyRequest) {
+			r.SetURL(target)
+			r.Out.Host = target.Host
+			r.Out.Header.Del("Authorization")
+		},
+		Transport:     transport,
+		FlushInterval: -1, // watch/日志流需要立即刷新
+		ErrorHandler: func(w http.ResponseWriter, r *http.Request, err error) {
+			logger.Error("代理请求失败 %s %s: %v", r.Method, r.URL.Path, err)
+			w.WriteHeader(http.StatusBadGateway)
+		},
+	}, nil
+}
+
+// detectK8sVersion 获取集群版本（用于心跳上报，失败时返回空）
+func detectK8sVersion(config *rest.Config) string {
+	client, err := discovery.NewDiscoveryClientForConfig(config)
+	if err != nil {
+		return ""
+	}
+	info, err := client.ServerVersion()
+	if err != nil {
+		logger.Warn("获取集群版本失败: %v", err)
+		return ""
+	}
+	return info.GitVersion
+}
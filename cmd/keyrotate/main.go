@@ -0,0 +1,69 @@
+// keyrotate 离线凭据密钥轮换工具
+//
+// 使用当前主密钥重新加密数据库中的所有凭据（集群 kubeconfig/Token/CA、ArgoCD、AI、SSH 配置及 Secret 修订快照），
+// 历史明文数据也会在此过程中被加密。执行前请停止 KubePolaris 服务并备份数据库。
+//
+// 用法：
+//
+//	# 生成新的主密钥
+//	keyrotate -generate
+//
+//	# 使用新主密钥重新加密，旧密钥通过 ENCRYPTION_PREVIOUS_KEYS 提供用于解密
+//	ENCRYPTION_MASTER_KEY=<new> ENCRYPTION_PREVIOUS_KEYS=<old> keyrotate [-dry-run]
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/clay-wangzhi/KubePolaris/internal/config"
+	"github.com/clay-wangzhi/KubePolaris/internal/database"
+	"github.com/clay-wangzhi/KubePolaris/internal/services"
+	"github.com/clay-wangzhi/KubePolaris/pkg/crypto"
+	"github.com/clay-wangzhi/KubePolaris/pkg/logger"
+)
+
+func main() {
+	generate := flag.Bool("generate", false, "生成一个新的 base64 主密钥并输出到标准输出")
+	dryRun := flag.Bool("dry-run", false, "只统计需要重新加密的行数，不写入数据库")
+	flag.Parse()
+
+	if *generate {
+		key, err := crypto.GenerateKey()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "生成密钥失败: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Println(crypto.EncodeKey(key))
+		return
+	}
+
+	cfg := config.Load()
+	logger.Init(cfg.Log.Level)
+
+	// 轮换场景下不自动生成主密钥，避免用一个随机密钥覆盖已有数据
+	opts := cfg.Security.EncryptionOptions()
+	opts.AutoGenerate = false
+	if err := crypto.Init(opts); err != nil {
+		logger.Fatal("加载主密钥失败: %v", err)
+	}
+
+	db, err := database.Init(cfg.Database)
+	if err != nil {
+		logger.Fatal("数据库初始化失败: %v", err)
+	}
+
+	result, err := services.RotateCredentialEncryption(db, *dryRun)
+	if err != nil {
+		logger.Fatal("凭据重新加密失败: %v", err)
+	}
+
+	mode := "已重新加密"
+	if *dryRun {
+		mode = "待重新加密（dry-run）"
+	}
+	fmt.Printf("%s: clusters=%d argocd_configs=%d ai_configs=%d ssh_configs=%d resource_revisions=%d\n",
+		mode, result.Clusters, result.ArgoCDConfigs, result.AIConfigs, result.SSHConfigs, result.Revisions)
+	fmt.Printf("当前主密钥指纹: %s\n", crypto.Default().PrimaryKeyID())
+}
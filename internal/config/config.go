@@ -1,6 +1,9 @@
 package config
 
 import (
+	"strings"
+
+	"github.com/clay-wangzhi/KubePolaris/pkg/crypto"
 	"github.com/clay-wangzhi/KubePolaris/pkg/logger"
 	"github.com/joho/godotenv"
 	"github.com/spf13/viper"
@@ -12,13 +15,15 @@ type Config struct {
 	Database DatabaseConfig `mapstructure:"database"`
 	JWT      JWTConfig      `mapstructure:"jwt"`
 	Log      LogConfig      `mapstructure:"log"`
-	K8s K8sConfig `mapstructure:"k8s"`
+	K8s      K8sConfig      `mapstructure:"k8s"`
+	Security SecurityConfig `mapstructure:"security"`
 }
 
 // ServerConfig 服务器配置
 type ServerConfig struct {
-	Port int    `mapstructure:"port"`
-	Mode string `mapstructure:"mode"`
+	Port        int    `mapstructure:"port"`
+	Mode        string `mapstructure:"mode"`
+	ExternalURL string `mapstructure:"external_url"` // 外部访问地址（集群 Agent 回连使用），为空时按请求地址推断
 }
 
 // DatabaseConfig 数据库配置
@@ -46,7 +51,41 @@ type LogConfig struct {
 
 // K8sConfig Kubernetes配置
 type K8sConfig struct {
-	DefaultNamespace string `mapstructure:"default_namespace"`
+	DefaultNamespace    string `mapstructure:"default_namespace"`
+	HealthCheckInterval int    `mapstructure:"health_check_interval"` // 集群健康探测间隔（秒），0 表示关闭
+	AgentImage          string `mapstructure:"agent_image"`           // 集群 Agent 镜像
+	DynamicIdleTimeout  int    `mapstructure:"dynamic_idle_timeout"`  // 动态 informer 空闲回收时间（分钟）
+	DebugImage          string `mapstructure:"debug_image"`           // 临时调试容器默认镜像
+}
+
+// SecurityConfig 凭据加密配置
+type SecurityConfig struct {
+	MasterKey        string `mapstructure:"master_key"`         // base64 编码的主密钥
+	MasterKeyFile    string `mapstructure:"master_key_file"`    // 主密钥文件路径
+	PreviousKeys     string `mapstructure:"previous_keys"`      // 轮换前的旧密钥（逗号分隔，仅用于解密）
+	PreviousKeyFiles string `mapstructure:"previous_key_files"` // 轮换前的旧密钥文件（逗号分隔）
+}
+
+// EncryptionOptions 转换为加密模块的密钥加载选项
+func (s SecurityConfig) EncryptionOptions() crypto.Options {
+	return crypto.Options{
+		MasterKey:        s.MasterKey,
+		MasterKeyFile:    s.MasterKeyFile,
+		PreviousKeys:     splitList(s.PreviousKeys),
+		PreviousKeyFiles: splitList(s.PreviousKeyFiles),
+		AutoGenerate:     s.MasterKey == "",
+	}
+}
+
+// splitList 解析逗号分隔的配置项
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
 }
 
 // Load 加载配置（纯环境变量模式）
@@ -65,6 +104,7 @@ func Load() *Config {
 	// 绑定服务器环境变量
 	_ = viper.BindEnv("server.port", "SERVER_PORT")
 	_ = viper.BindEnv("server.mode", "SERVER_MODE")
+	_ = viper.BindEnv("server.external_url", "SERVER_EXTERNAL_URL")
 
 	// 绑定数据库环境变量
 	_ = viper.BindEnv("database.driver", "DB_DRIVER")
@@ -85,12 +125,22 @@ func Load() *Config {
 
 	// 绑定 K8s 环境变量
 	_ = viper.BindEnv("k8s.default_namespace", "K8S_DEFAULT_NAMESPACE")
+	_ = viper.BindEnv("k8s.health_check_interval", "K8S_HEALTH_CHECK_INTERVAL")
+	_ = viper.BindEnv("k8s.agent_image", "K8S_AGENT_IMAGE")
+	_ = viper.BindEnv("k8s.dynamic_idle_timeout", "K8S_DYNAMIC_IDLE_TIMEOUT")
+	_ = viper.BindEnv("k8s.debug_image", "K8S_DEBUG_IMAGE")
+
+	// 绑定凭据加密环境变量
+	_ = viper.BindEnv("security.master_key", "ENCRYPTION_MASTER_KEY")
+	_ = viper.BindEnv("security.master_key_file", "ENCRYPTION_MASTER_KEY_FILE")
+	_ = viper.BindEnv("security.previous_keys", "ENCRYPTION_PREVIOUS_KEYS")
+	_ = viper.BindEnv("security.previous_key_files", "ENCRYPTION_PREVIOUS_KEY_FILES")
 
 	var config Config
 	if err := viper.Unmarshal(&config); err != nil {
 		logger.Fatal("配置解析失败: %v", err)
 	}
-	logger.Info("配置: %+v", config)
+	logger.Info("配置: %+v", redacted(config))
 
 	return &config
 }
@@ -120,4 +170,22 @@ func setDefaults() {
 
 	// K8s默认配置
 	viper.SetDefault("k8s.default_namespace", "default")
+	viper.SetDefault("k8s.health_check_interval", 60) // 60秒
+	viper.SetDefault("k8s.agent_image", "registry.cn-hangzhou.aliyuncs.com/clay-wangzhi/kubepolaris:latest")
+	viper.SetDefault("k8s.dynamic_idle_timeout", 10) // 10分钟
+	viper.SetDefault("k8s.debug_image", "busybox:1.36")
+
+	// 凭据加密默认配置（未设置主密钥时使用数据目录下的密钥文件）
+	viper.SetDefault("security.master_key_file", "./data/master.key")
+}
+
+// redacted 返回隐藏敏感字段后的配置副本，用于日志输出
+func redacted(cfg Config) Config {
+	if cfg.Security.MasterKey != "" {
+		cfg.Security.MasterKey = "******"
+	}
+	if cfg.Security.PreviousKeys != "" {
+		cfg.Security.PreviousKeys = "******"
+	}
+	return cfg
 }
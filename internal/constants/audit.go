@@ -16,6 +16,7 @@ const (
 	ModuleMonitoring = "monitoring" // 监控：Prometheus、Grafana配置
 	ModuleAlert      = "alert"      // 告警：AlertManager、静默规则
 	ModuleArgoCD     = "argocd"     // GitOps：ArgoCD应用
+	ModuleResource   = "resource"   // 通用资源：任意 GVR（含 CRD）
 	ModuleUnknown    = "unknown"    // 未知模块
 )
 
@@ -37,6 +38,14 @@ const (
 	ActionScale    = "scale"
 	ActionRollback = "rollback"
 	ActionRestart  = "restart"
+	ActionPause    = "pause"
+	ActionResume   = "resume"
+	ActionSetImage = "set_image"
+	ActionPromote  = "promote"
+	ActionAbort    = "abort"
+	ActionRetry    = "retry"
+	ActionTrigger  = "trigger"
+	ActionSuspend  = "suspend"
 
 	// 节点操作
 	ActionCordon   = "cordon"
@@ -51,6 +60,9 @@ const (
 
 	// 导入操作
 	ActionImport = "import"
+
+	// 后台任务操作
+	ActionCancel = "cancel"
 )
 
 // ModuleNames 模块中文名称映射
@@ -69,6 +81,7 @@ var ModuleNames = map[string]string{
 	ModuleMonitoring: "监控配置",
 	ModuleAlert:      "告警管理",
 	ModuleArgoCD:     "GitOps",
+	ModuleResource:   "通用资源",
 	ModuleUnknown:    "未知",
 }
 
@@ -85,10 +98,19 @@ var ActionNames = map[string]string{
 	ActionScale:          "扩缩容",
 	ActionRollback:       "回滚",
 	ActionRestart:        "重启",
+	ActionPause:          "暂停",
+	ActionResume:         "恢复",
+	ActionSetImage:       "更新镜像",
+	ActionPromote:        "推进发布",
+	ActionAbort:          "中止发布",
+	ActionRetry:          "重试发布",
+	ActionTrigger:        "立即执行",
+	ActionSuspend:        "挂起调度",
 	ActionCordon:         "禁止调度",
 	ActionUncordon:       "允许调度",
 	ActionDrain:          "驱逐节点",
 	ActionSync:           "同步",
 	ActionTest:           "测试",
 	ActionImport:         "导入",
+	ActionCancel:         "取消任务",
 }
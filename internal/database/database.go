@@ -172,13 +172,21 @@ func autoMigrate(db *gorm.DB) error {
 		&models.TerminalSession{},
 		&models.TerminalCommand{},
 		&models.AuditLog{},
-		&models.OperationLog{},      // 操作审计日志表（新增）
-		&models.SystemSetting{},     // 系统设置表
-		&models.ArgoCDConfig{},      // ArgoCD 配置表
-		&models.UserGroup{},         // 用户组表
-		&models.UserGroupMember{},   // 用户组成员关联表
-		&models.ClusterPermission{}, // 集群权限表
-		&models.AIConfig{},          // AI 配置表
+		&models.OperationLog{},             // 操作审计日志表（新增）
+		&models.SystemSetting{},            // 系统设置表
+		&models.ArgoCDConfig{},             // ArgoCD 配置表
+		&models.UserGroup{},                // 用户组表
+		&models.UserGroupMember{},          // 用户组成员关联表
+		&models.ClusterPermission{},        // 集群权限表
+		&models.AIConfig{},                 // AI 配置表
+		&models.ClusterStatusHistory{},     // 集群状态变更历史表
+		&models.ClusterAgentToken{},        // 集群 Agent 接入令牌表
+		&models.ResourceRevision{},         // 资源修订历史表
+		&models.Task{},                     // 后台任务表
+		&models.TaskStep{},                 // 后台任务步骤表
+		&models.TaskLog{},                  // 后台任务日志表
+		&models.NamespaceTemplate{},        // 命名空间模板表
+		&models.NamespaceTemplateBinding{}, // 命名空间模板绑定表
 	)
 
 	// 根据数据库驱动类型重新启用外键约束检查
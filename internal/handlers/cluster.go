@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"strconv"
@@ -44,8 +45,12 @@ func NewClusterHandler(db *gorm.DB, cfg *config.Config, mgr *k8s.ClusterInformer
 
 // GetClusters 获取集群列表
 func (h *ClusterHandler) GetClusters(c *gin.Context) {
+	selector, ok := parseClusterSelector(c)
+	if !ok {
+		return
+	}
 
-	clusters, err := h.clusterService.GetAllClusters()
+	clusters, err := h.clusterService.GetClustersBySelector(selector)
 	if err != nil {
 		logger.Error("获取集群列表失败", "error", err)
 		c.JSON(http.StatusInternalServerError, gin.H{
@@ -65,13 +70,18 @@ func (h *ClusterHandler) GetClusters(c *gin.Context) {
 			"apiServer": cluster.APIServer,
 			"version":   cluster.Version,
 			"status":    cluster.Status,
+			"labels":    services.ParseClusterLabels(cluster.Labels),
 			"createdAt": cluster.CreatedAt.Format("2006-01-02T15:04:05Z"),
 			"updatedAt": cluster.UpdatedAt.Format("2006-01-02T15:04:05Z"),
 		}
+		clusterData["connectionMode"] = cluster.ConnectionMode
 
 		if cluster.LastHeartbeat != nil {
 			clusterData["lastHeartbeat"] = cluster.LastHeartbeat.Format("2006-01-02T15:04:05Z")
 		}
+		if cluster.CertExpireAt != nil {
+			clusterData["certExpireAt"] = cluster.CertExpireAt.Format("2006-01-02T15:04:05Z")
+		}
 
 		// 获取实时节点信息和指标
 		if h.k8sMgr != nil {
@@ -109,12 +119,13 @@ func (h *ClusterHandler) ImportCluster(c *gin.Context) {
 
 	// 获取请求参数
 	var req struct {
-		Name        string `json:"name" binding:"required"`
-		Description string `json:"description"`
-		ApiServer   string `json:"apiServer"`
-		Kubeconfig  string `json:"kubeconfig"`
-		Token       string `json:"token"`
-		CaCert      string `json:"caCert"`
+		Name        string            `json:"name" binding:"required"`
+		Description string            `json:"description"`
+		ApiServer   string            `json:"apiServer"`
+		Kubeconfig  string            `json:"kubeconfig"`
+		Token       string            `json:"token"`
+		CaCert      string            `json:"caCert"`
+		Labels      map[string]string `json:"labels"`
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -128,6 +139,20 @@ func (h *ClusterHandler) ImportCluster(c *gin.Context) {
 
 	logger.Info("导入集群: %s, API Server: %s", req.Name, req.ApiServer)
 
+	if err := services.ValidateClusterLabels(req.Labels); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": err.Error(),
+			"data":    nil,
+		})
+		return
+	}
+	labelsJSON := "{}"
+	if len(req.Labels) > 0 {
+		data, _ := json.Marshal(req.Labels)
+		labelsJSON = string(data)
+	}
+
 	// 验证参数
 	if req.Kubeconfig == "" && (req.ApiServer == "" || req.Token == "") {
 		c.JSON(http.StatusBadRequest, gin.H{
@@ -178,6 +203,12 @@ func (h *ClusterHandler) ImportCluster(c *gin.Context) {
 		return
 	}
 
+	// 记录集群唯一标识，后续更新凭据时用于校验是否为同一集群
+	clusterUID, err := k8sClient.GetClusterUID()
+	if err != nil {
+		logger.Warn("获取集群标识失败", "error", err)
+	}
+
 	// 获取 API Server 地址：如果使用 kubeconfig，从配置中解析
 	apiServer := req.ApiServer
 	if apiServer == "" && req.Kubeconfig != "" {
@@ -193,12 +224,13 @@ func (h *ClusterHandler) ImportCluster(c *gin.Context) {
 	cluster := &models.Cluster{
 		Name:               req.Name,
 		APIServer:          apiServer,
-		KubeconfigEnc:      req.Kubeconfig, // TODO: 需要加密存储
-		SATokenEnc:         req.Token,      // TODO: 需要加密存储
-		CAEnc:              req.CaCert,     // TODO: 需要加密存储
+		KubeconfigEnc:      req.Kubeconfig, // 由 ClusterService 加密存储
+		SATokenEnc:         req.Token,
+		CAEnc:              req.CaCert,
+		ClusterUID:         clusterUID,
 		Version:            clusterInfo.Version,
 		Status:             clusterInfo.Status,
-		Labels:             "{}",
+		Labels:             labelsJSON,
 		MonitoringConfig:   "{}", // 初始化为空 JSON 对象，避免 MySQL JSON 字段报错
 		AlertManagerConfig: "{}", // 初始化为空 JSON 对象，避免 MySQL JSON 字段报错
 		CreatedBy:          1,    // 临时设置为1，后续需要从JWT中获取用户ID
@@ -262,13 +294,18 @@ func (h *ClusterHandler) GetCluster(c *gin.Context) {
 		"apiServer": cluster.APIServer,
 		"version":   cluster.Version,
 		"status":    cluster.Status,
+		"labels":    services.ParseClusterLabels(cluster.Labels),
 		"createdAt": cluster.CreatedAt.Format("2006-01-02T15:04:05Z"),
 		"updatedAt": cluster.UpdatedAt.Format("2006-01-02T15:04:05Z"),
 	}
+	clusterData["connectionMode"] = cluster.ConnectionMode
 
 	if cluster.LastHeartbeat != nil {
 		clusterData["lastHeartbeat"] = cluster.LastHeartbeat.Format("2006-01-02T15:04:05Z")
 	}
+	if cluster.CertExpireAt != nil {
+		clusterData["certExpireAt"] = cluster.CertExpireAt.Format("2006-01-02T15:04:05Z")
+	}
 
 	c.JSON(http.StatusOK, gin.H{
 		"code":    200,
@@ -277,6 +314,202 @@ func (h *ClusterHandler) GetCluster(c *gin.Context) {
 	})
 }
 
+// UpdateCluster 更新集群凭据（kubeconfig 或 API Server + Token）
+// 新凭据必须能连通且指向同一个集群（kube-system 命名空间 UID 一致），
+// 更新后重建该集群的 informer 缓存，集群权限、监控、告警与 ArgoCD 配置均保留。
+func (h *ClusterHandler) UpdateCluster(c *gin.Context) {
+	// 替换凭据可将集群指向任意 API Server，仅集群管理员可操作
+	if !requireClusterAdmin(c, "只有集群管理员才能更新集群凭据") {
+		return
+	}
+
+	idStr := c.Param("clusterID")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "无效的集群ID",
+			"data":    nil,
+		})
+		return
+	}
+
+	var req struct {
+		ApiServer  string `json:"apiServer"`
+		Kubeconfig string `json:"kubeconfig"`
+		Token      string `json:"token"`
+		CaCert     string `json:"caCert"`
+		// Force 原集群标识未知（历史数据且旧凭据已失效）时跳过同集群校验
+		Force bool `json:"force"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "请求参数错误: " + err.Error(),
+			"data":    nil,
+		})
+		return
+	}
+
+	if req.Kubeconfig == "" && (req.ApiServer == "" || req.Token == "") {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "请提供kubeconfig或者API Server地址和访问令牌",
+			"data":    nil,
+		})
+		return
+	}
+
+	cluster, err := h.clusterService.GetCluster(uint(id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"code":    404,
+			"message": err.Error(),
+			"data":    nil,
+		})
+		return
+	}
+
+	if cluster.IsAgentMode() {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "Agent 接入的集群没有可更新的凭据，请通过轮换 Agent 令牌重新接入",
+			"data":    nil,
+		})
+		return
+	}
+
+	logger.Info("更新集群凭据", "cluster", cluster.Name)
+
+	var k8sClient *services.K8sClient
+	if req.Kubeconfig != "" {
+		// 与导入相同的校验：不允许引用服务端本地文件或执行凭据插件
+		req.Kubeconfig, err = services.MinifyCurrentContext(req.Kubeconfig)
+		if err == nil {
+			k8sClient, err = services.NewK8sClientFromKubeconfig(req.Kubeconfig)
+		}
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"code":    400,
+				"message": fmt.Sprintf("kubeconfig格式错误: %v", err),
+				"data":    nil,
+			})
+			return
+		}
+	} else {
+		k8sClient, err = services.NewK8sClientFromToken(req.ApiServer, req.Token, req.CaCert)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"code":    400,
+				"message": fmt.Sprintf("连接配置错误: %v", err),
+				"data":    nil,
+			})
+			return
+		}
+	}
+
+	clusterInfo, err := k8sClient.TestConnection()
+	if err != nil {
+		logger.Error("连接测试失败", "cluster", cluster.Name, "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": fmt.Sprintf("连接测试失败: %v", err),
+			"data":    nil,
+		})
+		return
+	}
+
+	newUID, err := k8sClient.GetClusterUID()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": fmt.Sprintf("无法识别集群: %v", err),
+			"data":    nil,
+		})
+		return
+	}
+
+	// 校验新凭据指向同一个集群：优先使用已记录的标识，否则尝试用旧凭据获取
+	oldUID := cluster.ClusterUID
+	if oldUID == "" {
+		if oldClient, err := services.NewK8sClientForCluster(cluster); err == nil {
+			oldUID, _ = oldClient.GetClusterUID()
+		}
+	}
+	if oldUID == "" && !req.Force {
+		c.JSON(http.StatusConflict, gin.H{
+			"code":    409,
+			"message": "无法确认原集群标识（旧凭据已失效），如确认是同一集群请设置 force 后重试",
+			"data":    nil,
+		})
+		return
+	}
+	if oldUID != "" && oldUID != newUID {
+		c.JSON(http.StatusConflict, gin.H{
+			"code":    409,
+			"message": "新凭据指向的不是同一个集群，请使用导入功能添加新集群",
+			"data":    nil,
+		})
+		return
+	}
+
+	apiServer := req.ApiServer
+	if apiServer == "" && req.Kubeconfig != "" {
+		if restConfig := k8sClient.GetRestConfig(); restConfig != nil {
+			apiServer = restConfig.Host
+		}
+	}
+
+	certExpireAt, err := k8sClient.CertExpireAt()
+	if err != nil {
+		logger.Warn("解析集群证书过期时间失败", "cluster", cluster.Name, "error", err)
+	}
+
+	cred := &models.Cluster{
+		APIServer:     apiServer,
+		KubeconfigEnc: req.Kubeconfig,
+		SATokenEnc:    req.Token,
+		CAEnc:         req.CaCert,
+		ClusterUID:    newUID,
+		Version:       clusterInfo.Version,
+		Status:        clusterInfo.Status,
+		CertExpireAt:  certExpireAt,
+	}
+	if err := h.clusterService.UpdateClusterCredentials(cluster.ID, cred); err != nil {
+		logger.Error("更新集群凭据失败", "cluster", cluster.Name, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    500,
+			"message": "更新集群凭据失败: " + err.Error(),
+			"data":    nil,
+		})
+		return
+	}
+
+	// 丢弃旧凭据创建的客户端与 informer，使用新凭据重建缓存
+	if h.k8sMgr != nil {
+		h.k8sMgr.StopForCluster(cluster.ID)
+		updated, err := h.clusterService.GetCluster(cluster.ID)
+		if err == nil {
+			_, err = h.k8sMgr.EnsureForCluster(updated)
+		}
+		if err != nil {
+			logger.Error("重建集群 informer 失败", "cluster", cluster.Name, "error", err)
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "集群凭据更新成功",
+		"data": gin.H{
+			"id":        cluster.ID,
+			"name":      cluster.Name,
+			"apiServer": apiServer,
+			"version":   clusterInfo.Version,
+			"status":    clusterInfo.Status,
+		},
+	})
+}
+
 // DeleteCluster 删除集群
 func (h *ClusterHandler) DeleteCluster(c *gin.Context) {
 	idStr := c.Param("clusterID")
@@ -323,6 +556,85 @@ func (h *ClusterHandler) DeleteCluster(c *gin.Context) {
 	})
 }
 
+// UpdateClusterLabels 更新集群标签（整体替换）
+func (h *ClusterHandler) UpdateClusterLabels(c *gin.Context) {
+	idStr := c.Param("clusterID")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "无效的集群ID",
+			"data":    nil,
+		})
+		return
+	}
+
+	var req struct {
+		Labels map[string]string `json:"labels"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "请求参数错误: " + err.Error(),
+			"data":    nil,
+		})
+		return
+	}
+	if err := services.ValidateClusterLabels(req.Labels); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": err.Error(),
+			"data":    nil,
+		})
+		return
+	}
+
+	if err := h.clusterService.UpdateClusterLabels(uint(id), req.Labels); err != nil {
+		if strings.Contains(err.Error(), "集群不存在") {
+			c.JSON(http.StatusNotFound, gin.H{
+				"code":    404,
+				"message": err.Error(),
+				"data":    nil,
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    500,
+			"message": err.Error(),
+			"data":    nil,
+		})
+		return
+	}
+
+	if req.Labels == nil {
+		req.Labels = map[string]string{}
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "更新成功",
+		"data":    gin.H{"labels": req.Labels},
+	})
+}
+
+// GetClusterLabels 获取所有集群使用的标签键及取值（用于按标签分组与筛选）
+func (h *ClusterHandler) GetClusterLabels(c *gin.Context) {
+	values, err := h.clusterService.GetClusterLabelValues()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    500,
+			"message": err.Error(),
+			"data":    nil,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "获取成功",
+		"data":    values,
+	})
+}
+
 // GetClusterStats 获取集群统计
 func (h *ClusterHandler) GetClusterStats(c *gin.Context) {
 	logger.Info("获取集群统计")
@@ -387,6 +699,45 @@ func (h *ClusterHandler) GetClusterStatus(c *gin.Context) {
 	})
 }
 
+// GetClusterStatusHistory 获取集群状态变更历史（由后台健康探测记录）
+func (h *ClusterHandler) GetClusterStatusHistory(c *gin.Context) {
+	idStr := c.Param("clusterID")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "无效的集群ID",
+			"data":    nil,
+		})
+		return
+	}
+
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if limit <= 0 || limit > 500 {
+		limit = 50
+	}
+
+	histories, err := h.clusterService.GetStatusHistory(uint(id), limit)
+	if err != nil {
+		logger.Error("获取集群状态历史失败", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    500,
+			"message": "获取集群状态历史失败: " + err.Error(),
+			"data":    nil,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "获取成功",
+		"data": gin.H{
+			"items": histories,
+			"total": len(histories),
+		},
+	})
+}
+
 // GetClusterOverview 获取集群概览信息
 func (h *ClusterHandler) GetClusterOverview(c *gin.Context) {
 	clusterID := c.Param("clusterID")
@@ -0,0 +1,419 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/clay-wangzhi/KubePolaris/internal/config"
+	"github.com/clay-wangzhi/KubePolaris/internal/k8s"
+	"github.com/clay-wangzhi/KubePolaris/internal/models"
+	"github.com/clay-wangzhi/KubePolaris/internal/services"
+	"github.com/clay-wangzhi/KubePolaris/pkg/logger"
+	"github.com/clay-wangzhi/KubePolaris/pkg/tunnel"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	"gorm.io/gorm"
+)
+
+// ClusterAgentHandler 集群 Agent 接入处理器
+// 适用于 KubePolaris 无法直连 API Server 的集群（NAT/私有网络）：
+// 集群内的 Agent 主动建立 WebSocket 反向隧道，服务端经隧道访问集群。
+type ClusterAgentHandler struct {
+	cfg            *config.Config
+	clusterService *services.ClusterService
+	agentService   *services.ClusterAgentService
+	k8sMgr         *k8s.ClusterInformerManager
+	upgrader       websocket.Upgrader
+}
+
+// NewClusterAgentHandler 创建集群 Agent 接入处理器
+func NewClusterAgentHandler(db *gorm.DB, cfg *config.Config, clusterSvc *services.ClusterService, mgr *k8s.ClusterInformerManager) *ClusterAgentHandler {
+	return &ClusterAgentHandler{
+		cfg:            cfg,
+		clusterService: clusterSvc,
+		agentService:   services.NewClusterAgentService(db),
+		k8sMgr:         mgr,
+		upgrader: websocket.Upgrader{
+			// Agent 不是浏览器客户端，身份由令牌校验
+			CheckOrigin:     func(r *http.Request) bool { return true },
+			ReadBufferSize:  32 * 1024,
+			WriteBufferSize: 32 * 1024,
+		},
+	}
+}
+
+// agentHeartbeat Agent 心跳负载
+type agentHeartbeat struct {
+	AgentVersion string `json:"agentVersion"`
+	K8sVersion   string `json:"k8sVersion"`
+}
+
+// CreateAgentCluster 创建 Agent 接入模式的集群，并返回接入令牌与部署清单
+func (h *ClusterAgentHandler) CreateAgentCluster(c *gin.Context) {
+	var req struct {
+		Name        string            `json:"name" binding:"required"`
+		Description string            `json:"description"`
+		Labels      map[string]string `json:"labels"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "请求参数错误: " + err.Error(),
+			"data":    nil,
+		})
+		return
+	}
+	if err := services.ValidateClusterLabels(req.Labels); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": err.Error(),
+			"data":    nil,
+		})
+		return
+	}
+
+	createdBy := c.GetUint("user_id")
+	if createdBy == 0 {
+		createdBy = 1
+	}
+	labelsJSON := "{}"
+	if len(req.Labels) > 0 {
+		data, _ := json.Marshal(req.Labels)
+		labelsJSON = string(data)
+	}
+	cluster := &models.Cluster{
+		Name:               req.Name,
+		APIServer:          "agent://" + req.Name,
+		ConnectionMode:     models.ClusterConnectionAgent,
+		Status:             "unknown",
+		Labels:             labelsJSON,
+		MonitoringConfig:   "{}",
+		AlertManagerConfig: "{}",
+		CreatedBy:          createdBy,
+	}
+	if err := h.clusterService.CreateCluster(cluster); err != nil {
+		logger.Error("创建 Agent 集群失败", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    500,
+			"message": "创建集群失败: " + err.Error(),
+			"data":    nil,
+		})
+		return
+	}
+
+	token, record, err := h.agentService.CreateToken(cluster.ID, "初始令牌", nil, createdBy)
+	if err != nil {
+		logger.Error("签发 Agent 令牌失败", "cluster", cluster.Name, "error", err)
+		if delErr := h.clusterService.DeleteCluster(cluster.ID); delErr != nil {
+			logger.Error("回滚 Agent 集群失败", "cluster", cluster.Name, "error", delErr)
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    500,
+			"message": "签发 Agent 令牌失败: " + err.Error(),
+			"data":    nil,
+		})
+		return
+	}
+
+	manifest, err := h.renderManifest(c, token)
+	if err != nil {
+		logger.Error("生成 Agent 部署清单失败", "error", err)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "集群创建成功，请在目标集群中部署 Agent",
+		"data": gin.H{
+			"cluster": gin.H{
+				"id":             cluster.ID,
+				"name":           cluster.Name,
+				"connectionMode": cluster.ConnectionMode,
+				"status":         cluster.Status,
+				"createdAt":      cluster.CreatedAt.Format("2006-01-02T15:04:05Z"),
+			},
+			"token":     token,
+			"tokenInfo": record,
+			"manifest":  manifest,
+		},
+	})
+}
+
+// CreateAgentToken 为 Agent 集群签发新令牌（用于轮换），明文令牌只返回一次
+func (h *ClusterAgentHandler) CreateAgentToken(c *gin.Context) {
+	// 持有令牌即可接管该集群的全部 API 流量，仅集群管理员可签发
+	if !requireClusterAdmin(c, "只有集群管理员才能签发 Agent 令牌") {
+		return
+	}
+	cluster, ok := h.getAgentCluster(c)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		Description   string `json:"description"`
+		ExpiresInDays int    `json:"expiresInDays"` // 0 表示永不过期
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "请求参数错误: " + err.Error(),
+			"data":    nil,
+		})
+		return
+	}
+	var expiresAt *time.Time
+	if req.ExpiresInDays > 0 {
+		t := time.Now().AddDate(0, 0, req.ExpiresInDays)
+		expiresAt = &t
+	}
+
+	token, record, err := h.agentService.CreateToken(cluster.ID, req.Description, expiresAt, c.GetUint("user_id"))
+	if err != nil {
+		logger.Error("签发 Agent 令牌失败", "cluster", cluster.Name, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    500,
+			"message": "签发 Agent 令牌失败: " + err.Error(),
+			"data":    nil,
+		})
+		return
+	}
+
+	manifest, err := h.renderManifest(c, token)
+	if err != nil {
+		logger.Error("生成 Agent 部署清单失败", "error", err)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "令牌签发成功，请妥善保存，关闭后将无法再次查看",
+		"data": gin.H{
+			"token":     token,
+			"tokenInfo": record,
+			"manifest":  manifest,
+		},
+	})
+}
+
+// ListAgentTokens 获取 Agent 集群的令牌列表（不含明文）
+func (h *ClusterAgentHandler) ListAgentTokens(c *gin.Context) {
+	cluster, ok := h.getAgentCluster(c)
+	if !ok {
+		return
+	}
+
+	tokens, err := h.agentService.ListTokens(cluster.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    500,
+			"message": err.Error(),
+			"data":    nil,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "获取成功",
+		"data": gin.H{
+			"items": tokens,
+			"total": len(tokens),
+		},
+	})
+}
+
+// DeleteAgentToken 吊销 Agent 令牌，使用该令牌建立的隧道随即断开
+func (h *ClusterAgentHandler) DeleteAgentToken(c *gin.Context) {
+	if !requireClusterAdmin(c, "只有集群管理员才能吊销 Agent 令牌") {
+		return
+	}
+	cluster, ok := h.getAgentCluster(c)
+	if !ok {
+		return
+	}
+	tokenID, err := strconv.ParseUint(c.Param("tokenID"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "无效的令牌ID",
+			"data":    nil,
+		})
+		return
+	}
+
+	if err := h.agentService.DeleteToken(cluster.ID, uint(tokenID)); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"code":    404,
+			"message": err.Error(),
+			"data":    nil,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "令牌已吊销",
+		"data":    nil,
+	})
+}
+
+// GetAgentStatus 获取 Agent 连接状态
+func (h *ClusterAgentHandler) GetAgentStatus(c *gin.Context) {
+	cluster, ok := h.getAgentCluster(c)
+	if !ok {
+		return
+	}
+
+	data := gin.H{
+		"connected":     false,
+		"lastHeartbeat": cluster.LastHeartbeat,
+	}
+	if conn, online := services.GetAgentTunnelRegistry().Get(cluster.ID); online {
+		data = gin.H{
+			"connected":     true,
+			"remoteAddr":    conn.RemoteAddr,
+			"agentVersion":  conn.AgentVersion,
+			"k8sVersion":    conn.K8sVersion,
+			"connectedAt":   conn.ConnectedAt,
+			"lastHeartbeat": conn.LastHeartbeat,
+			"streams":       conn.Session.NumStreams(),
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "获取成功",
+		"data":    data,
+	})
+}
+
+// AgentConnect Agent 建立反向隧道（GET /ws/agent/connect，使用 Agent 令牌认证）
+func (h *ClusterAgentHandler) AgentConnect(c *gin.Context) {
+	token := strings.TrimSpace(strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer "))
+	cluster, agentToken, err := h.agentService.Authenticate(token)
+	if err != nil {
+		status := http.StatusForbidden
+		if errors.Is(err, services.ErrInvalidAgentToken) {
+			status = http.StatusUnauthorized
+		}
+		logger.Warn("Agent 认证失败", "remote", c.ClientIP(), "error", err)
+		c.JSON(status, gin.H{
+			"code":    status,
+			"message": err.Error(),
+			"data":    nil,
+		})
+		return
+	}
+
+	ws, err := h.upgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		logger.Error("Agent WebSocket 升级失败", "cluster", cluster.Name, "error", err)
+		return
+	}
+
+	registry := services.GetAgentTunnelRegistry()
+	session := tunnel.NewSession(ws, tunnel.Config{
+		OnHeartbeat: func(session *tunnel.Session, payload []byte) {
+			var hb agentHeartbeat
+			_ = json.Unmarshal(payload, &hb)
+			registry.UpdateHeartbeat(cluster.ID, session, hb.AgentVersion, hb.K8sVersion)
+			go func() {
+				if err := h.agentService.RecordHeartbeat(cluster.ID); err != nil {
+					logger.Error("记录 Agent 心跳失败", "cluster", cluster.Name, "error", err)
+				}
+			}()
+		},
+	})
+
+	now := time.Now()
+	old := registry.Register(&services.AgentConnection{
+		ClusterID:     cluster.ID,
+		TokenID:       agentToken.ID,
+		Session:       session,
+		RemoteAddr:    c.ClientIP(),
+		ConnectedAt:   now,
+		LastHeartbeat: now,
+	})
+	if old != nil {
+		logger.Info("Agent 重新连接，关闭旧隧道", "cluster", cluster.Name, "old", old.RemoteAddr)
+		_ = old.Session.Close()
+	}
+	logger.Info("Agent 已连接", "cluster", cluster.Name, "remote", c.ClientIP())
+
+	// 后台预热 informer（隧道建立后客户端即可正常访问）
+	if h.k8sMgr != nil {
+		go func() {
+			if _, err := h.k8sMgr.EnsureForCluster(cluster); err != nil {
+				logger.Error("初始化集群 informer 失败", "cluster", cluster.Name, "error", err)
+			}
+		}()
+	}
+
+	<-session.Done()
+	if registry.Unregister(cluster.ID, session) {
+		logger.Warn("Agent 已断开", "cluster", cluster.Name, "error", session.Err())
+		if h.k8sMgr != nil {
+			h.k8sMgr.StopForCluster(cluster.ID)
+		}
+	}
+}
+
+// getAgentCluster 解析路径中的集群 ID 并校验集群为 Agent 接入模式
+func (h *ClusterAgentHandler) getAgentCluster(c *gin.Context) (*models.Cluster, bool) {
+	id := parseClusterID(c.Param("clusterID"))
+	if id == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "无效的集群ID",
+			"data":    nil,
+		})
+		return nil, false
+	}
+	cluster, err := h.clusterService.GetCluster(id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"code":    404,
+			"message": err.Error(),
+			"data":    nil,
+		})
+		return nil, false
+	}
+	if !cluster.IsAgentMode() {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": fmt.Sprintf("集群 %s 不是 Agent 接入模式", cluster.Name),
+			"data":    nil,
+		})
+		return nil, false
+	}
+	return cluster, true
+}
+
+// renderManifest 生成 Agent 部署清单；未配置外部地址时按当前请求推断
+func (h *ClusterAgentHandler) renderManifest(c *gin.Context, token string) (string, error) {
+	serverURL := strings.TrimRight(h.cfg.Server.ExternalURL, "/")
+	if serverURL == "" {
+		scheme := "http"
+		if c.Request.TLS != nil {
+			scheme = "https"
+		}
+		if proto := c.GetHeader("X-Forwarded-Proto"); proto != "" {
+			scheme = proto
+		}
+		host := c.Request.Host
+		if fwdHost := c.GetHeader("X-Forwarded-Host"); fwdHost != "" {
+			host = fwdHost
+		}
+		serverURL = scheme + "://" + host
+	}
+	return services.RenderAgentManifest(services.AgentManifestOptions{
+		ServerURL: serverURL,
+		Token:     token,
+		Image:     h.cfg.K8s.AgentImage,
+	})
+}
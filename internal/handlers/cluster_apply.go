@@ -0,0 +1,196 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/clay-wangzhi/KubePolaris/internal/k8s"
+	"github.com/clay-wangzhi/KubePolaris/internal/models"
+	"github.com/clay-wangzhi/KubePolaris/internal/services"
+	"github.com/clay-wangzhi/KubePolaris/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// ClusterApplyHandler 集群级多资源 YAML 应用处理器
+type ClusterApplyHandler struct {
+	db             *gorm.DB
+	clusterService *services.ClusterService
+	k8sMgr         *k8s.ClusterInformerManager
+}
+
+// NewClusterApplyHandler 创建集群级 YAML 应用处理器
+func NewClusterApplyHandler(db *gorm.DB, clusterService *services.ClusterService, k8sMgr *k8s.ClusterInformerManager) *ClusterApplyHandler {
+	return &ClusterApplyHandler{
+		db:             db,
+		clusterService: clusterService,
+		k8sMgr:         k8sMgr,
+	}
+}
+
+// ClusterApplyRequest 多文档 YAML 应用请求
+type ClusterApplyRequest struct {
+	YAML            string `json:"yaml" binding:"required"`
+	DryRun          bool   `json:"dryRun"`
+	Force           bool   `json:"force"`           // 字段所有权冲突时强制接管
+	ContinueOnError bool   `json:"continueOnError"` // 单个资源失败后继续应用其余资源
+}
+
+// Apply 应用多文档、多类型 YAML
+// 资源按 CRD → 命名空间 → RBAC → 配置 → 工作负载 → 路由 的顺序逐个应用，返回每个资源的结果；
+// 整批请求只记录一条操作审计，存在失败资源时返回 422。
+func (h *ClusterApplyHandler) Apply(c *gin.Context) {
+	var req ClusterApplyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "请求参数错误: " + err.Error(),
+			"data":    nil,
+		})
+		return
+	}
+
+	permission, ok := getClusterPermission(c)
+	if !ok {
+		return
+	}
+
+	cluster, err := h.clusterService.GetCluster(parseClusterID(c.Param("clusterID")))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"code":    404,
+			"message": "集群不存在",
+			"data":    nil,
+		})
+		return
+	}
+	c.Set("cluster_name", cluster.Name)
+
+	objs, err := services.ParseMultiDocYAML(req.YAML)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": err.Error(),
+			"data":    nil,
+		})
+		return
+	}
+
+	k8sClient, err := h.k8sMgr.GetK8sClient(cluster)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    500,
+			"message": "获取K8s客户端失败: " + err.Error(),
+			"data":    nil,
+		})
+		return
+	}
+	engine, err := services.NewApplyEngine(k8sClient)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    500,
+			"message": err.Error(),
+			"data":    nil,
+		})
+		return
+	}
+
+	// 每个资源预留 30 秒（含等待 CRD 就绪）
+	ctx, cancel := context.WithTimeout(c.Request.Context(), time.Duration(len(objs))*30*time.Second)
+	defer cancel()
+
+	// 配额预检仅针对有权访问的命名空间，避免泄露其他命名空间的配额信息
+	quotaObjs := make([]*unstructured.Unstructured, 0, len(objs))
+	for _, obj := range objs {
+		if ns := obj.GetNamespace(); ns == "" || permission.HasNamespaceAccess(ns) {
+			quotaObjs = append(quotaObjs, obj)
+		}
+	}
+	quotaWarnings := engine.CheckQuota(ctx, quotaObjs)
+
+	result := engine.ApplyAll(ctx, objs, services.BatchApplyOptions{
+		ApplyOptions:    services.ApplyOptions{DryRun: req.DryRun, Force: req.Force},
+		ContinueOnError: req.ContinueOnError,
+		Authorize:       authorizeApply(permission),
+	})
+	result.QuotaWarnings = quotaWarnings
+	revisions := services.NewResourceRevisionService(h.db)
+	for _, item := range result.Items {
+		if item.Result != nil {
+			recordApplyRevision(c, revisions, services.RevisionActionApply, item.Result)
+		}
+	}
+
+	logger.Info("批量应用YAML", "cluster", cluster.Name, "total", result.Total,
+		"succeeded", result.Succeeded, "failed", result.Failed, "skipped", result.Skipped, "dryRun", req.DryRun)
+
+	if result.Failed > 0 {
+		c.Set("error_message", batchApplyErrorSummary(result))
+		c.JSON(http.StatusUnprocessableEntity, gin.H{
+			"code":    422,
+			"message": fmt.Sprintf("%d 个资源应用失败", result.Failed),
+			"data":    result,
+		})
+		return
+	}
+
+	message := "YAML应用成功"
+	if req.DryRun {
+		message = "预检通过"
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": message,
+		"data":    result,
+	})
+}
+
+// authorizeApply 校验应用资源所需的平台权限
+// 服务端应用可能创建也可能更新资源，因此同时要求 create 与 update 权限；集群级资源要求全部命名空间权限。
+func authorizeApply(permission *models.ClusterPermission) func(info *services.APIResourceInfo, obj *unstructured.Unstructured) error {
+	return func(info *services.APIResourceInfo, obj *unstructured.Unstructured) error {
+		nsAllowed := permission.HasAllNamespaceAccess()
+		if info.Namespaced {
+			nsAllowed = permission.HasNamespaceAccess(obj.GetNamespace())
+		}
+		if !nsAllowed {
+			return fmt.Errorf("无权限访问命名空间 %s", obj.GetNamespace())
+		}
+		for _, verb := range []string{"create", "update"} {
+			if action := info.PermissionAction(verb); !permission.CanPerformAction(action) {
+				return fmt.Errorf("权限不足，无法执行 %s", action)
+			}
+		}
+		return nil
+	}
+}
+
+// batchApplyErrorSummary 汇总失败资源，写入操作审计的错误信息
+func batchApplyErrorSummary(result *services.BatchApplyResult) string {
+	var failed []string
+	for _, item := range result.Items {
+		if item.Status != services.BatchApplyStatusFailed {
+			continue
+		}
+		key := item.Kind + "/" + item.Name
+		if item.Namespace != "" {
+			key = item.Kind + "/" + item.Namespace + "/" + item.Name
+		}
+		failed = append(failed, key+": "+item.Error)
+	}
+	return truncateAuditError(strings.Join(failed, "; "))
+}
+
+// truncateAuditError 截断写入操作审计的错误信息，审计表 error_message 字段最长 1000 字符
+func truncateAuditError(message string) string {
+	summary := []rune(message)
+	if len(summary) > 1000 {
+		return string(summary[:997]) + "..."
+	}
+	return message
+}
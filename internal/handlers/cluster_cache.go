@@ -0,0 +1,100 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/clay-wangzhi/KubePolaris/internal/k8s"
+	"github.com/clay-wangzhi/KubePolaris/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ClusterCacheHandler 集群 informer 缓存处理器
+type ClusterCacheHandler struct {
+	clusterService *services.ClusterService
+	k8sMgr         *k8s.ClusterInformerManager
+}
+
+// NewClusterCacheHandler 创建集群缓存处理器
+func NewClusterCacheHandler(clusterService *services.ClusterService, k8sMgr *k8s.ClusterInformerManager) *ClusterCacheHandler {
+	return &ClusterCacheHandler{
+		clusterService: clusterService,
+		k8sMgr:         k8sMgr,
+	}
+}
+
+// GetCacheStats 获取集群缓存统计（各资源对象数、内存估算、同步时间及动态 informer）
+func (h *ClusterCacheHandler) GetCacheStats(c *gin.Context) {
+	cluster, err := h.clusterService.GetCluster(parseClusterID(c.Param("clusterID")))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"code":    404,
+			"message": "集群不存在",
+			"data":    nil,
+		})
+		return
+	}
+
+	if _, err := h.k8sMgr.EnsureForCluster(cluster); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    500,
+			"message": "初始化集群缓存失败: " + err.Error(),
+			"data":    nil,
+		})
+		return
+	}
+
+	stats, err := h.k8sMgr.GetCacheStats(cluster.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    500,
+			"message": "获取缓存统计失败: " + err.Error(),
+			"data":    nil,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "获取成功",
+		"data":    stats,
+	})
+}
+
+// GetCacheStatus 获取集群缓存健康状态（各资源 watch 错误、最近成功事件时间、自动重建记录）
+func (h *ClusterCacheHandler) GetCacheStatus(c *gin.Context) {
+	cluster, err := h.clusterService.GetCluster(parseClusterID(c.Param("clusterID")))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"code":    404,
+			"message": "集群不存在",
+			"data":    nil,
+		})
+		return
+	}
+
+	if _, err := h.k8sMgr.EnsureForCluster(cluster); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    500,
+			"message": "初始化集群缓存失败: " + err.Error(),
+			"data":    nil,
+		})
+		return
+	}
+
+	status, err := h.k8sMgr.GetCacheStatus(cluster.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    500,
+			"message": "获取缓存状态失败: " + err.Error(),
+			"data":    nil,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "获取成功",
+		"data":    status,
+	})
+}
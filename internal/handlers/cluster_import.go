@@ -0,0 +1,266 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/clay-wangzhi/KubePolaris/internal/models"
+	"github.com/clay-wangzhi/KubePolaris/internal/services"
+	"github.com/clay-wangzhi/KubePolaris/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PreviewImportContexts 解析多上下文 kubeconfig 并并发测试每个上下文的连接情况
+func (h *ClusterHandler) PreviewImportContexts(c *gin.Context) {
+	var req struct {
+		Kubeconfig string `json:"kubeconfig" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "请求参数错误: " + err.Error(),
+			"data":    nil,
+		})
+		return
+	}
+
+	previews, err := services.PreviewKubeconfigContexts(req.Kubeconfig, nil)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": err.Error(),
+			"data":    nil,
+		})
+		return
+	}
+
+	if err := h.markImportedContexts(previews); err != nil {
+		logger.Error("获取已导入集群失败", "error", err)
+	}
+
+	reachable := 0
+	for _, p := range previews {
+		if p.Reachable {
+			reachable++
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "解析成功",
+		"data": gin.H{
+			"items":     previews,
+			"total":     len(previews),
+			"reachable": reachable,
+		},
+	})
+}
+
+// BatchImportClusters 从多上下文 kubeconfig 中批量导入选中的上下文
+// 每个集群只保存对应上下文精简后的 kubeconfig；所有集群在同一事务中创建，任一失败则全部不导入。
+func (h *ClusterHandler) BatchImportClusters(c *gin.Context) {
+	var req struct {
+		Kubeconfig string `json:"kubeconfig" binding:"required"`
+		Items      []struct {
+			Context string            `json:"context" binding:"required"`
+			Name    string            `json:"name"` // 为空时使用上下文名称
+			Labels  map[string]string `json:"labels"`
+		} `json:"items" binding:"required,min=1,dive"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "请求参数错误: " + err.Error(),
+			"data":    nil,
+		})
+		return
+	}
+
+	// 1. 校验集群名称：请求内不能重复，也不能与已有集群重名
+	existing, err := h.clusterService.GetAllClusters()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    500,
+			"message": "获取集群列表失败: " + err.Error(),
+			"data":    nil,
+		})
+		return
+	}
+	existingNames := make(map[string]bool, len(existing))
+	for _, cl := range existing {
+		existingNames[cl.Name] = true
+	}
+
+	names := make(map[string]string, len(req.Items))      // context -> name
+	labelsJSON := make(map[string]string, len(req.Items)) // context -> labels JSON
+	seen := make(map[string]bool, len(req.Items))
+	contextNames := make([]string, 0, len(req.Items))
+	for _, item := range req.Items {
+		name := strings.TrimSpace(item.Name)
+		if name == "" {
+			name = item.Context
+		}
+		if seen[name] || existingNames[name] {
+			c.JSON(http.StatusConflict, gin.H{
+				"code":    409,
+				"message": fmt.Sprintf("集群名称已存在: %s", name),
+				"data":    nil,
+			})
+			return
+		}
+		if _, dup := names[item.Context]; dup {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"code":    400,
+				"message": fmt.Sprintf("上下文重复: %s", item.Context),
+				"data":    nil,
+			})
+			return
+		}
+		if err := services.ValidateClusterLabels(item.Labels); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"code":    400,
+				"message": fmt.Sprintf("上下文 %s: %v", item.Context, err),
+				"data":    nil,
+			})
+			return
+		}
+		seen[name] = true
+		names[item.Context] = name
+		labelsJSON[item.Context] = "{}"
+		if len(item.Labels) > 0 {
+			data, _ := json.Marshal(item.Labels)
+			labelsJSON[item.Context] = string(data)
+		}
+		contextNames = append(contextNames, item.Context)
+	}
+
+	// 2. 重新测试选中上下文的连接，全部可达才导入
+	previews, err := services.PreviewKubeconfigContexts(req.Kubeconfig, contextNames)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": err.Error(),
+			"data":    nil,
+		})
+		return
+	}
+	if err := h.markImportedContexts(previews); err != nil {
+		logger.Error("获取已导入集群失败", "error", err)
+	}
+	batchUIDs := make(map[string]string, len(previews))
+	for _, p := range previews {
+		if !p.Reachable {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"code":    400,
+				"message": fmt.Sprintf("上下文 %s 连接测试失败: %s", p.Name, p.Error),
+				"data":    gin.H{"items": previews},
+			})
+			return
+		}
+		if p.ImportedAs != "" {
+			c.JSON(http.StatusConflict, gin.H{
+				"code":    409,
+				"message": fmt.Sprintf("上下文 %s 对应的集群已导入为 %s", p.Name, p.ImportedAs),
+				"data":    gin.H{"items": previews},
+			})
+			return
+		}
+		if p.ClusterUID != "" {
+			if other, dup := batchUIDs[p.ClusterUID]; dup {
+				c.JSON(http.StatusConflict, gin.H{
+					"code":    409,
+					"message": fmt.Sprintf("上下文 %s 与 %s 指向同一个集群", p.Name, other),
+					"data":    gin.H{"items": previews},
+				})
+				return
+			}
+			batchUIDs[p.ClusterUID] = p.Name
+		}
+	}
+
+	// 3. 事务内批量创建
+	createdBy := c.GetUint("user_id")
+	if createdBy == 0 {
+		createdBy = 1
+	}
+	clusters := make([]*models.Cluster, 0, len(previews))
+	for _, p := range previews {
+		clusters = append(clusters, &models.Cluster{
+			Name:               names[p.Name],
+			APIServer:          p.APIServer,
+			KubeconfigEnc:      p.Kubeconfig, // 由 ClusterService 加密存储
+			ClusterUID:         p.ClusterUID,
+			Version:            p.Version,
+			Status:             p.Status,
+			CertExpireAt:       p.CertExpireAt,
+			Labels:             labelsJSON[p.Name],
+			MonitoringConfig:   "{}",
+			AlertManagerConfig: "{}",
+			CreatedBy:          createdBy,
+		})
+	}
+	if err := h.clusterService.CreateClusters(clusters); err != nil {
+		logger.Error("批量导入集群失败", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    500,
+			"message": "批量导入集群失败: " + err.Error(),
+			"data":    nil,
+		})
+		return
+	}
+
+	// 4. 后台预热新集群的 informer
+	if h.k8sMgr != nil {
+		go func() {
+			for _, cl := range clusters {
+				if _, err := h.k8sMgr.EnsureForCluster(cl); err != nil {
+					logger.Error("初始化集群 informer 失败", "cluster", cl.Name, "error", err)
+				}
+			}
+		}()
+	}
+
+	items := make([]gin.H, 0, len(clusters))
+	for _, cl := range clusters {
+		items = append(items, gin.H{
+			"id":        cl.ID,
+			"name":      cl.Name,
+			"apiServer": cl.APIServer,
+			"version":   cl.Version,
+			"status":    cl.Status,
+			"createdAt": cl.CreatedAt.Format("2006-01-02T15:04:05Z"),
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": fmt.Sprintf("成功导入 %d 个集群", len(items)),
+		"data": gin.H{
+			"items": items,
+			"total": len(items),
+		},
+	})
+}
+
+// markImportedContexts 标记已导入过的上下文（按 kube-system 命名空间 UID 匹配）
+func (h *ClusterHandler) markImportedContexts(previews []*services.KubeconfigContextPreview) error {
+	clusters, err := h.clusterService.GetAllClusters()
+	if err != nil {
+		return err
+	}
+	byUID := make(map[string]string, len(clusters))
+	for _, cl := range clusters {
+		if cl.ClusterUID != "" {
+			byUID[cl.ClusterUID] = cl.Name
+		}
+	}
+	for _, p := range previews {
+		if p.ClusterUID != "" {
+			p.ImportedAs = byUID[p.ClusterUID]
+		}
+	}
+	return nil
+}
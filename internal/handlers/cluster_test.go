@@ -5,6 +5,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"regexp"
+	"strings"
 	"testing"
 	"time"
 
@@ -17,6 +18,7 @@ import (
 	"gorm.io/gorm/logger"
 
 	"github.com/clay-wangzhi/KubePolaris/internal/config"
+	"github.com/clay-wangzhi/KubePolaris/internal/models"
 )
 
 // ClusterHandlerTestSuite 定义集群处理器测试套件
@@ -237,6 +239,14 @@ func (s *ClusterHandlerTestSuite) TestDeleteCluster_Success() {
 	s.mock.ExpectExec(`DELETE FROM.*cluster_metrics.*WHERE.*cluster_id`).
 		WithArgs(1).
 		WillReturnResult(sqlmock.NewResult(0, 0))
+	// 删除集群状态历史
+	s.mock.ExpectExec(`DELETE FROM.*cluster_status_histories.*WHERE.*cluster_id`).
+		WithArgs(1).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	// 删除 Agent 接入令牌
+	s.mock.ExpectExec(`DELETE FROM.*cluster_agent_tokens.*WHERE.*cluster_id`).
+		WithArgs(1).
+		WillReturnResult(sqlmock.NewResult(0, 0))
 	// 删除集群
 	s.mock.ExpectExec(`DELETE FROM.*clusters.*WHERE.*id`).
 		WithArgs(1).
@@ -269,3 +279,44 @@ func (s *ClusterHandlerTestSuite) TestDeleteCluster_NotFound() {
 func TestClusterHandlerSuite(t *testing.T) {
 	suite.Run(t, new(ClusterHandlerTestSuite))
 }
+
+func TestUpdateClusterRequiresClusterAdmin(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	h := &ClusterHandler{}
+
+	for _, permissionType := range []string{models.PermissionTypeOps, models.PermissionTypeDev, models.PermissionTypeCustom} {
+		router := gin.New()
+		router.PUT("/clusters/:clusterID", func(c *gin.Context) {
+			c.Set("cluster_permission", &models.ClusterPermission{PermissionType: permissionType})
+			h.UpdateCluster(c)
+		})
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPut, "/clusters/1", strings.NewReader(`{"apiServer":"https://evil.example.com","token":"t"}`))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusForbidden, w.Code, permissionType)
+	}
+}
+
+func TestAgentTokenEndpointsRequireClusterAdmin(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	h := &ClusterAgentHandler{}
+
+	for _, permissionType := range []string{models.PermissionTypeOps, models.PermissionTypeDev} {
+		router := gin.New()
+		setPermission := func(c *gin.Context) {
+			c.Set("cluster_permission", &models.ClusterPermission{PermissionType: permissionType})
+		}
+		router.POST("/clusters/:clusterID/agent/tokens", setPermission, h.CreateAgentToken)
+		router.DELETE("/clusters/:clusterID/agent/tokens/:tokenID", setPermission, h.DeleteAgentToken)
+
+		for _, req := range []*http.Request{
+			httptest.NewRequest(http.MethodPost, "/clusters/1/agent/tokens", strings.NewReader(`{}`)),
+			httptest.NewRequest(http.MethodDelete, "/clusters/1/agent/tokens/1", nil),
+		} {
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			assert.Equal(t, http.StatusForbidden, w.Code, "%s %s", permissionType, req.Method)
+		}
+	}
+}
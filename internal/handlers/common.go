@@ -1,6 +1,14 @@
 package handlers
 
-import "strconv"
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/clay-wangzhi/KubePolaris/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"k8s.io/apimachinery/pkg/labels"
+)
 
 // ScaleRequest 扩缩容请求
 type ScaleRequest struct {
@@ -11,6 +19,7 @@ type ScaleRequest struct {
 type YAMLApplyRequest struct {
 	YAML   string `json:"yaml" binding:"required"`
 	DryRun bool   `json:"dryRun"`
+	Force  bool   `json:"force"` // 字段所有权冲突时强制接管
 }
 
 // parseClusterID 解析集群ID字符串为uint
@@ -20,3 +29,17 @@ func parseClusterID(clusterIDStr string) uint {
 	}
 	return 0
 }
+
+// parseClusterSelector 解析查询参数 clusterSelector（集群标签选择器），格式错误时直接返回 400
+func parseClusterSelector(c *gin.Context) (labels.Selector, bool) {
+	selector, err := services.ParseClusterSelector(c.Query("clusterSelector"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": err.Error(),
+			"data":    nil,
+		})
+		return nil, false
+	}
+	return selector, true
+}
@@ -466,6 +466,8 @@ func (h *ConfigMapHandler) UpdateConfigMap(c *gin.Context) {
 		return
 	}
 
+	previous := configMap.DeepCopy()
+
 	// 更新ConfigMap
 	configMap.Labels = req.Labels
 	configMap.Annotations = req.Annotations
@@ -477,6 +479,7 @@ func (h *ConfigMapHandler) UpdateConfigMap(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("更新ConfigMap失败: %v", err)})
 		return
 	}
+	recordTypedRevision(c, h.db, previous, corev1.SchemeGroupVersion.WithKind("ConfigMap"), services.RevisionActionUpdate)
 
 	c.JSON(http.StatusOK, gin.H{
 		"code":    200,
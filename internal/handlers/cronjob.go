@@ -12,16 +12,16 @@ import (
 	"github.com/clay-wangzhi/KubePolaris/internal/config"
 	"github.com/clay-wangzhi/KubePolaris/internal/k8s"
 	"github.com/clay-wangzhi/KubePolaris/internal/middleware"
+	"github.com/clay-wangzhi/KubePolaris/internal/models"
 	"github.com/clay-wangzhi/KubePolaris/internal/services"
 	"github.com/clay-wangzhi/KubePolaris/pkg/logger"
 
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
 	batchv1 "k8s.io/api/batch/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/apimachinery/pkg/runtime/serializer"
-	"k8s.io/apimachinery/pkg/util/yaml"
-	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/apimachinery/pkg/labels"
 	sigsyaml "sigs.k8s.io/yaml"
 )
 
@@ -67,10 +67,11 @@ func (h *CronJobHandler) ListCronJobs(c *gin.Context) {
 		return
 	}
 
-	// 获取缓存的 K8s 客户端
-	k8sClient, err := h.k8sMgr.GetK8sClient(cluster)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "获取K8s客户端失败: " + err.Error()})
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	if _, err := h.k8sMgr.EnsureAndWait(ctx, cluster, 5*time.Second); err != nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"code": 503, "message": "informer 未就绪: " + err.Error()})
 		return
 	}
 
@@ -84,15 +85,17 @@ func (h *CronJobHandler) ListCronJobs(c *gin.Context) {
 		return
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-	defer cancel()
-
-	clientset := k8sClient.GetClientset()
-	var cronJobList *batchv1.CronJobList
-	if namespace != "" {
-		cronJobList, err = clientset.BatchV1().CronJobs(namespace).List(ctx, metav1.ListOptions{})
+	var items []*batchv1.CronJob
+	if lister := h.k8sMgr.CronJobsLister(cluster.ID); lister != nil {
+		// 读取 informer 缓存（batch/v1beta1 集群已统一转换为 batch/v1）
+		if namespace != "" {
+			items, err = lister.CronJobs(namespace).List(labels.Everything())
+		} else {
+			items, err = lister.List(labels.Everything())
+		}
 	} else {
-		cronJobList, err = clientset.BatchV1().CronJobs("").List(ctx, metav1.ListOptions{})
+		// 版本探测失败时回退到直接请求 API Server
+		items, err = h.listCronJobsFromAPI(ctx, cluster, namespace)
 	}
 
 	if err != nil {
@@ -101,8 +104,8 @@ func (h *CronJobHandler) ListCronJobs(c *gin.Context) {
 	}
 
 	var cronJobs []CronJobInfo
-	for _, cj := range cronJobList.Items {
-		cronJobs = append(cronJobs, h.convertToCronJobInfo(&cj))
+	for _, cj := range items {
+		cronJobs = append(cronJobs, h.convertToCronJobInfo(cj))
 	}
 
 	// 根据命名空间权限过滤
@@ -269,7 +272,7 @@ func (h *CronJobHandler) ApplyYAML(c *gin.Context) {
 		return
 	}
 
-	logger.Info("应用CronJob YAML: cluster=%s, dryRun=%v", clusterId, req.DryRun)
+	logger.Info("应用CronJob YAML: cluster=%s, dryRun=%v, force=%v", clusterId, req.DryRun, req.Force)
 
 	clusterID := parseClusterID(clusterId)
 	cluster, err := h.clusterService.GetCluster(clusterID)
@@ -284,77 +287,164 @@ func (h *CronJobHandler) ApplyYAML(c *gin.Context) {
 		return
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-	defer cancel()
+	applyYAMLWithEngine(c, h.db, k8sClient, req.YAML, "CronJob", req.DryRun, req.Force)
+}
 
-	var objMap map[string]interface{}
-	if err := yaml.Unmarshal([]byte(req.YAML), &objMap); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "YAML格式错误: " + err.Error()})
+func (h *CronJobHandler) DeleteCronJob(c *gin.Context) {
+	clusterId := c.Param("clusterID")
+	namespace := c.Param("namespace")
+	name := c.Param("name")
+
+	logger.Info("删除CronJob: %s/%s/%s", clusterId, namespace, name)
+
+	clusterID := parseClusterID(clusterId)
+	cluster, err := h.clusterService.GetCluster(clusterID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"code": 404, "message": "集群不存在"})
 		return
 	}
 
-	if objMap["apiVersion"] == nil || objMap["kind"] == nil {
-		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "YAML缺少必要字段: apiVersion 或 kind"})
+	k8sClient, err := h.k8sMgr.GetK8sClient(cluster)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "获取K8s客户端失败: " + err.Error()})
 		return
 	}
 
-	kind := objMap["kind"].(string)
-	if kind != "CronJob" {
-		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "YAML类型错误，期望CronJob，实际为: " + kind})
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	clientset := k8sClient.GetClientset()
+	err = clientset.BatchV1().CronJobs(namespace).Delete(ctx, name, metav1.DeleteOptions{})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "删除失败: " + err.Error()})
 		return
 	}
 
-	metadata, ok := objMap["metadata"].(map[string]interface{})
-	if !ok {
-		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "YAML缺少 metadata 字段"})
+	c.JSON(http.StatusOK, gin.H{"code": 200, "message": "删除成功"})
+}
+
+// CronJobTriggerRequest 手动触发 CronJob 请求
+type CronJobTriggerRequest struct {
+	JobName string `json:"jobName"` // 可选，为空时自动生成
+}
+
+// TriggerCronJob 立即执行CronJob（等同于 kubectl create job --from=cronjob/<name>）
+func (h *CronJobHandler) TriggerCronJob(c *gin.Context) {
+	var req CronJobTriggerRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "参数错误: " + err.Error()})
+			return
+		}
+	}
+	if !checkWorkloadPermission(c, "CronJob", "trigger") {
 		return
 	}
 
-	namespace, _ := metadata["namespace"].(string)
-	if namespace == "" {
-		namespace = "default"
+	clusterId := c.Param("clusterID")
+	namespace := c.Param("namespace")
+	name := c.Param("name")
+	logger.Info("手动触发CronJob: %s/%s/%s", clusterId, namespace, name)
+
+	clientset, ok := workloadClientset(c, h.clusterService, h.k8sMgr)
+	if !ok {
+		return
 	}
 
-	result, err := h.applyYAML(ctx, k8sClient, req.YAML, namespace, req.DryRun)
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	job, err := services.TriggerCronJob(ctx, clientset, h.k8sMgr.IsCronJobV1beta1(parseClusterID(clusterId)), namespace, name, req.JobName)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "YAML应用失败: " + err.Error()})
+		respondWorkloadActionError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"code": 200, "message": "YAML应用成功", "data": result})
+	c.JSON(http.StatusOK, gin.H{"code": 200, "message": "已创建Job " + job.Name, "data": gin.H{"jobName": job.Name}})
 }
 
-func (h *CronJobHandler) DeleteCronJob(c *gin.Context) {
+// SuspendCronJob 挂起CronJob调度
+func (h *CronJobHandler) SuspendCronJob(c *gin.Context) {
+	h.setCronJobSuspend(c, true)
+}
+
+// ResumeCronJob 恢复CronJob调度
+func (h *CronJobHandler) ResumeCronJob(c *gin.Context) {
+	h.setCronJobSuspend(c, false)
+}
+
+func (h *CronJobHandler) setCronJobSuspend(c *gin.Context, suspend bool) {
+	if !checkWorkloadPermission(c, "CronJob", "update") {
+		return
+	}
+
 	clusterId := c.Param("clusterID")
 	namespace := c.Param("namespace")
 	name := c.Param("name")
+	logger.Info("设置CronJob挂起状态: %s/%s/%s suspend=%v", clusterId, namespace, name, suspend)
 
-	logger.Info("删除CronJob: %s/%s/%s", clusterId, namespace, name)
+	clientset, ok := workloadClientset(c, h.clusterService, h.k8sMgr)
+	if !ok {
+		return
+	}
 
-	clusterID := parseClusterID(clusterId)
-	cluster, err := h.clusterService.GetCluster(clusterID)
-	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"code": 404, "message": "集群不存在"})
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	if err := services.SetCronJobSuspend(ctx, clientset, h.k8sMgr.IsCronJobV1beta1(parseClusterID(clusterId)), namespace, name, suspend); err != nil {
+		respondWorkloadActionError(c, err)
 		return
 	}
 
-	k8sClient, err := h.k8sMgr.GetK8sClient(cluster)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "获取K8s客户端失败: " + err.Error()})
+	message := "已恢复调度"
+	if suspend {
+		message = "已挂起调度"
+	}
+	c.JSON(http.StatusOK, gin.H{"code": 200, "message": message, "data": gin.H{"suspend": suspend}})
+}
+
+// GetCronJobHistory 获取CronJob运行历史及后续调度时间
+func (h *CronJobHandler) GetCronJobHistory(c *gin.Context) {
+	clusterId := c.Param("clusterID")
+	namespace := c.Param("namespace")
+	name := c.Param("name")
+
+	clientset, ok := workloadClientset(c, h.clusterService, h.k8sMgr)
+	if !ok {
 		return
 	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 
-	clientset := k8sClient.GetClientset()
-	err = clientset.BatchV1().CronJobs(namespace).Delete(ctx, name, metav1.DeleteOptions{})
+	history, err := services.GetCronJobHistory(ctx, clientset, h.k8sMgr.IsCronJobV1beta1(parseClusterID(clusterId)), namespace, name, time.Now())
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "删除失败: " + err.Error()})
+		if apierrors.IsNotFound(err) {
+			c.JSON(http.StatusNotFound, gin.H{"code": 404, "message": "CronJob不存在: " + err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "获取运行历史失败: " + err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"code": 200, "message": "删除成功"})
+	c.JSON(http.StatusOK, gin.H{"code": 200, "message": "success", "data": history})
+}
+
+// listCronJobsFromAPI 直接从 API Server 获取 CronJob 列表
+func (h *CronJobHandler) listCronJobsFromAPI(ctx context.Context, cluster *models.Cluster, namespace string) ([]*batchv1.CronJob, error) {
+	k8sClient, err := h.k8sMgr.GetK8sClient(cluster)
+	if err != nil {
+		return nil, fmt.Errorf("获取K8s客户端失败: %w", err)
+	}
+	cronJobList, err := k8sClient.GetClientset().BatchV1().CronJobs(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	items := make([]*batchv1.CronJob, 0, len(cronJobList.Items))
+	for i := range cronJobList.Items {
+		items = append(items, &cronJobList.Items[i])
+	}
+	return items, nil
 }
 
 func (h *CronJobHandler) convertToCronJobInfo(cj *batchv1.CronJob) CronJobInfo {
@@ -389,38 +479,3 @@ func (h *CronJobHandler) convertToCronJobInfo(cj *batchv1.CronJob) CronJobInfo {
 		CreatedAt:        cj.CreationTimestamp.Time,
 	}
 }
-
-func (h *CronJobHandler) applyYAML(ctx context.Context, k8sClient *services.K8sClient, yamlContent string, namespace string, dryRun bool) (interface{}, error) {
-	decode := serializer.NewCodecFactory(scheme.Scheme).UniversalDeserializer().Decode
-	obj, _, err := decode([]byte(yamlContent), nil, nil)
-	if err != nil {
-		return nil, fmt.Errorf("解析YAML失败: %w", err)
-	}
-
-	cronJob, ok := obj.(*batchv1.CronJob)
-	if !ok {
-		return nil, fmt.Errorf("无法转换为CronJob类型")
-	}
-
-	clientset := k8sClient.GetClientset()
-	var dryRunOpt []string
-	if dryRun {
-		dryRunOpt = []string{metav1.DryRunAll}
-	}
-
-	existing, err := clientset.BatchV1().CronJobs(cronJob.Namespace).Get(ctx, cronJob.Name, metav1.GetOptions{})
-	if err == nil {
-		cronJob.ResourceVersion = existing.ResourceVersion
-		result, err := clientset.BatchV1().CronJobs(cronJob.Namespace).Update(ctx, cronJob, metav1.UpdateOptions{DryRun: dryRunOpt})
-		if err != nil {
-			return nil, err
-		}
-		return result, nil
-	}
-
-	result, err := clientset.BatchV1().CronJobs(cronJob.Namespace).Create(ctx, cronJob, metav1.CreateOptions{DryRun: dryRunOpt})
-	if err != nil {
-		return nil, err
-	}
-	return result, nil
-}
@@ -20,9 +20,7 @@ import (
 	appsv1 "k8s.io/api/apps/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
-	"k8s.io/apimachinery/pkg/runtime/serializer"
-	"k8s.io/apimachinery/pkg/util/yaml"
-	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/kubernetes"
 	sigsyaml "sigs.k8s.io/yaml"
 )
 
@@ -323,7 +321,7 @@ func (h *DaemonSetHandler) ApplyYAML(c *gin.Context) {
 		return
 	}
 
-	logger.Info("应用DaemonSet YAML: cluster=%s, dryRun=%v", clusterId, req.DryRun)
+	logger.Info("应用DaemonSet YAML: cluster=%s, dryRun=%v, force=%v", clusterId, req.DryRun, req.Force)
 
 	clusterID := parseClusterID(clusterId)
 	cluster, err := h.clusterService.GetCluster(clusterID)
@@ -344,63 +342,7 @@ func (h *DaemonSetHandler) ApplyYAML(c *gin.Context) {
 		return
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-	defer cancel()
-
-	var objMap map[string]interface{}
-	if err := yaml.Unmarshal([]byte(req.YAML), &objMap); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"code":    400,
-			"message": "YAML格式错误: " + err.Error(),
-		})
-		return
-	}
-
-	if objMap["apiVersion"] == nil || objMap["kind"] == nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"code":    400,
-			"message": "YAML缺少必要字段: apiVersion 或 kind",
-		})
-		return
-	}
-
-	kind := objMap["kind"].(string)
-	if kind != "DaemonSet" {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"code":    400,
-			"message": "YAML类型错误，期望DaemonSet，实际为: " + kind,
-		})
-		return
-	}
-
-	metadata, ok := objMap["metadata"].(map[string]interface{})
-	if !ok {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"code":    400,
-			"message": "YAML缺少 metadata 字段",
-		})
-		return
-	}
-
-	namespace, _ := metadata["namespace"].(string)
-	if namespace == "" {
-		namespace = "default"
-	}
-
-	result, err := h.applyYAML(ctx, k8sClient, req.YAML, namespace, req.DryRun)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"code":    500,
-			"message": "YAML应用失败: " + err.Error(),
-		})
-		return
-	}
-
-	c.JSON(http.StatusOK, gin.H{
-		"code":    200,
-		"message": "YAML应用成功",
-		"data":    result,
-	})
+	applyYAMLWithEngine(c, h.db, k8sClient, req.YAML, "DaemonSet", req.DryRun, req.Force)
 }
 
 // DeleteDaemonSet 删除DaemonSet
@@ -481,37 +423,31 @@ func (h *DaemonSetHandler) convertToDaemonSetInfo(ds *appsv1.DaemonSet) DaemonSe
 	}
 }
 
-func (h *DaemonSetHandler) applyYAML(ctx context.Context, k8sClient *services.K8sClient, yamlContent string, namespace string, dryRun bool) (interface{}, error) {
-	decode := serializer.NewCodecFactory(scheme.Scheme).UniversalDeserializer().Decode
-	obj, _, err := decode([]byte(yamlContent), nil, nil)
-	if err != nil {
-		return nil, fmt.Errorf("解析YAML失败: %w", err)
-	}
-
-	daemonSet, ok := obj.(*appsv1.DaemonSet)
-	if !ok {
-		return nil, fmt.Errorf("无法转换为DaemonSet类型")
-	}
-
-	clientset := k8sClient.GetClientset()
-	var dryRunOpt []string
-	if dryRun {
-		dryRunOpt = []string{metav1.DryRunAll}
-	}
+// RestartDaemonSet 滚动重启DaemonSet
+func (h *DaemonSetHandler) RestartDaemonSet(c *gin.Context) {
+	runWorkloadAction(c, h.clusterService, h.k8sMgr, services.WorkloadKindDaemonSet, "restart", "已触发滚动重启",
+		func(ctx context.Context, clientset kubernetes.Interface, namespace, name string) (*services.WorkloadRolloutStatus, error) {
+			return services.RestartWorkload(ctx, clientset, services.WorkloadKindDaemonSet, namespace, name)
+		})
+}
 
-	existing, err := clientset.AppsV1().DaemonSets(daemonSet.Namespace).Get(ctx, daemonSet.Name, metav1.GetOptions{})
-	if err == nil {
-		daemonSet.ResourceVersion = existing.ResourceVersion
-		result, err := clientset.AppsV1().DaemonSets(daemonSet.Namespace).Update(ctx, daemonSet, metav1.UpdateOptions{DryRun: dryRunOpt})
-		if err != nil {
-			return nil, err
-		}
-		return result, nil
+// SetDaemonSetImage 更新DaemonSet容器镜像
+func (h *DaemonSetHandler) SetDaemonSetImage(c *gin.Context) {
+	var req WorkloadImageRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "参数错误: " + err.Error(),
+		})
+		return
 	}
+	runWorkloadAction(c, h.clusterService, h.k8sMgr, services.WorkloadKindDaemonSet, "update", "镜像已更新",
+		func(ctx context.Context, clientset kubernetes.Interface, namespace, name string) (*services.WorkloadRolloutStatus, error) {
+			return services.SetWorkloadImages(ctx, clientset, services.WorkloadKindDaemonSet, namespace, name, req.Images)
+		})
+}
 
-	result, err := clientset.AppsV1().DaemonSets(daemonSet.Namespace).Create(ctx, daemonSet, metav1.CreateOptions{DryRun: dryRunOpt})
-	if err != nil {
-		return nil, err
-	}
-	return result, nil
+// GetDaemonSetRolloutStatus 获取DaemonSet发布进度
+func (h *DaemonSetHandler) GetDaemonSetRolloutStatus(c *gin.Context) {
+	getWorkloadRolloutStatus(c, h.clusterService, h.k8sMgr, services.WorkloadKindDaemonSet)
 }
@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
 	"sort"
@@ -18,11 +19,10 @@ import (
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
 	appsv1 "k8s.io/api/apps/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
-	"k8s.io/apimachinery/pkg/runtime/serializer"
-	"k8s.io/apimachinery/pkg/util/yaml"
-	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/kubernetes"
 	sigsyaml "sigs.k8s.io/yaml"
 )
 
@@ -407,7 +407,7 @@ func (h *DeploymentHandler) ApplyYAML(c *gin.Context) {
 		return
 	}
 
-	logger.Info("应用Deployment YAML: cluster=%s, dryRun=%v", clusterId, req.DryRun)
+	logger.Info("应用Deployment YAML: cluster=%s, dryRun=%v, force=%v", clusterId, req.DryRun, req.Force)
 
 	clusterID := parseClusterID(clusterId)
 	cluster, err := h.clusterService.GetCluster(clusterID)
@@ -429,66 +429,125 @@ func (h *DeploymentHandler) ApplyYAML(c *gin.Context) {
 		return
 	}
 
+	applyYAMLWithEngine(c, h.db, k8sClient, req.YAML, "Deployment", req.DryRun, req.Force)
+}
+
+// GetDeploymentHistory 获取Deployment发布历史（修订号、变更原因、镜像及相对上一修订的模板差异）
+func (h *DeploymentHandler) GetDeploymentHistory(c *gin.Context) {
+	namespace := c.Param("namespace")
+	name := c.Param("name")
+
+	if !checkNamespaceAccess(c, namespace) {
+		return
+	}
+
+	clientset, ok := workloadClientset(c, h.clusterService, h.k8sMgr)
+	if !ok {
+		return
+	}
+
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 
-	// 解析YAML
-	var objMap map[string]interface{}
-	if err := yaml.Unmarshal([]byte(req.YAML), &objMap); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"code":    400,
-			"message": "YAML格式错误: " + err.Error(),
+	deployment, err := clientset.AppsV1().Deployments(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"code":    404,
+			"message": "Deployment不存在",
 		})
 		return
 	}
 
-	// 验证必要字段
-	if objMap["apiVersion"] == nil || objMap["kind"] == nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"code":    400,
-			"message": "YAML缺少必要字段: apiVersion 或 kind",
+	revisions, err := services.ListDeploymentRevisions(ctx, clientset, deployment)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    500,
+			"message": "获取发布历史失败: " + err.Error(),
 		})
 		return
 	}
 
-	kind := objMap["kind"].(string)
-	if kind != "Deployment" {
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "success",
+		"data": gin.H{
+			"items": revisions,
+			"total": len(revisions),
+		},
+	})
+}
+
+// DeploymentRollbackRequest Deployment回滚请求
+type DeploymentRollbackRequest struct {
+	Revision int64 `json:"revision" binding:"min=0"` // 目标修订号，0 表示上一个修订
+}
+
+// RollbackDeployment 回滚Deployment到指定修订（等同于 kubectl rollout undo --to-revision）
+func (h *DeploymentHandler) RollbackDeployment(c *gin.Context) {
+	clusterId := c.Param("clusterID")
+	namespace := c.Param("namespace")
+	name := c.Param("name")
+
+	if !checkWorkloadPermission(c, "Deployment", "rollback") {
+		return
+	}
+
+	var req DeploymentRollbackRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{
 			"code":    400,
-			"message": "YAML类型错误，期望Deployment，实际为: " + kind,
+			"message": "参数错误: " + err.Error(),
 		})
 		return
 	}
 
-	// 获取metadata
-	metadata, ok := objMap["metadata"].(map[string]interface{})
+	logger.Info("回滚Deployment: %s/%s/%s to revision %d", clusterId, namespace, name, req.Revision)
+
+	clientset, ok := workloadClientset(c, h.clusterService, h.k8sMgr)
 	if !ok {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"code":    400,
-			"message": "YAML缺少 metadata 字段",
-		})
 		return
 	}
 
-	namespace, _ := metadata["namespace"].(string)
-	if namespace == "" {
-		namespace = "default"
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	deployment, err := clientset.AppsV1().Deployments(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"code":    404,
+			"message": "Deployment不存在",
+		})
+		return
 	}
 
-	// 应用YAML
-	result, err := h.applyYAML(ctx, k8sClient, req.YAML, namespace, req.DryRun)
+	revision, skipped, err := services.RollbackDeployment(ctx, clientset, deployment, req.Revision)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"code":    500,
-			"message": "YAML应用失败: " + err.Error(),
+		status := http.StatusInternalServerError
+		if errors.Is(err, services.ErrRevisionNotFound) || deployment.Spec.Paused {
+			status = http.StatusBadRequest
+		} else if apierrors.IsInvalid(err) || apierrors.IsConflict(err) {
+			status = http.StatusConflict
+		}
+		c.JSON(status, gin.H{
+			"code":    status,
+			"message": "回滚失败: " + err.Error(),
 		})
 		return
 	}
 
+	message := fmt.Sprintf("已回滚到修订 %d", revision)
+	if skipped {
+		message = fmt.Sprintf("当前模板已与修订 %d 一致，无需回滚", revision)
+	} else {
+		recordTypedRevision(c, h.db, deployment, appsv1.SchemeGroupVersion.WithKind("Deployment"), services.RevisionActionRollback)
+	}
 	c.JSON(http.StatusOK, gin.H{
 		"code":    200,
-		"message": "YAML应用成功",
-		"data":    result,
+		"message": message,
+		"data": gin.H{
+			"revision": revision,
+			"skipped":  skipped,
+		},
 	})
 }
 
@@ -624,46 +683,6 @@ func (h *DeploymentHandler) convertToDeploymentInfo(d *appsv1.Deployment) Deploy
 	}
 }
 
-// 辅助方法：应用YAML
-func (h *DeploymentHandler) applyYAML(ctx context.Context, k8sClient *services.K8sClient, yamlContent string, namespace string, dryRun bool) (interface{}, error) {
-	// 创建解码器
-	decode := serializer.NewCodecFactory(scheme.Scheme).UniversalDeserializer().Decode
-	obj, _, err := decode([]byte(yamlContent), nil, nil)
-	if err != nil {
-		return nil, fmt.Errorf("解析YAML失败: %w", err)
-	}
-
-	deployment, ok := obj.(*appsv1.Deployment)
-	if !ok {
-		return nil, fmt.Errorf("无法转换为Deployment类型")
-	}
-
-	clientset := k8sClient.GetClientset()
-	var dryRunOpt []string
-	if dryRun {
-		dryRunOpt = []string{metav1.DryRunAll}
-	}
-
-	// 尝试获取现有资源
-	existing, err := clientset.AppsV1().Deployments(deployment.Namespace).Get(ctx, deployment.Name, metav1.GetOptions{})
-	if err == nil {
-		// 资源存在，执行更新
-		deployment.ResourceVersion = existing.ResourceVersion
-		result, err := clientset.AppsV1().Deployments(deployment.Namespace).Update(ctx, deployment, metav1.UpdateOptions{DryRun: dryRunOpt})
-		if err != nil {
-			return nil, err
-		}
-		return result, nil
-	}
-
-	// 资源不存在，执行创建
-	result, err := clientset.AppsV1().Deployments(deployment.Namespace).Create(ctx, deployment, metav1.CreateOptions{DryRun: dryRunOpt})
-	if err != nil {
-		return nil, err
-	}
-	return result, nil
-}
-
 // GetDeploymentPods 获取Deployment关联的Pods
 func (h *DeploymentHandler) GetDeploymentPods(c *gin.Context) {
 	clusterId := c.Param("clusterID")
@@ -1240,3 +1259,48 @@ func (h *DeploymentHandler) GetDeploymentEvents(c *gin.Context) {
 		},
 	})
 }
+
+// RestartDeployment 滚动重启Deployment
+func (h *DeploymentHandler) RestartDeployment(c *gin.Context) {
+	runWorkloadAction(c, h.clusterService, h.k8sMgr, services.WorkloadKindDeployment, "restart", "已触发滚动重启",
+		func(ctx context.Context, clientset kubernetes.Interface, namespace, name string) (*services.WorkloadRolloutStatus, error) {
+			return services.RestartWorkload(ctx, clientset, services.WorkloadKindDeployment, namespace, name)
+		})
+}
+
+// PauseDeployment 暂停Deployment发布
+func (h *DeploymentHandler) PauseDeployment(c *gin.Context) {
+	runWorkloadAction(c, h.clusterService, h.k8sMgr, services.WorkloadKindDeployment, "pause", "已暂停发布",
+		func(ctx context.Context, clientset kubernetes.Interface, namespace, name string) (*services.WorkloadRolloutStatus, error) {
+			return services.SetDeploymentPaused(ctx, clientset, namespace, name, true)
+		})
+}
+
+// ResumeDeployment 恢复Deployment发布
+func (h *DeploymentHandler) ResumeDeployment(c *gin.Context) {
+	runWorkloadAction(c, h.clusterService, h.k8sMgr, services.WorkloadKindDeployment, "resume", "已恢复发布",
+		func(ctx context.Context, clientset kubernetes.Interface, namespace, name string) (*services.WorkloadRolloutStatus, error) {
+			return services.SetDeploymentPaused(ctx, clientset, namespace, name, false)
+		})
+}
+
+// SetDeploymentImage 更新Deployment容器镜像
+func (h *DeploymentHandler) SetDeploymentImage(c *gin.Context) {
+	var req WorkloadImageRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "参数错误: " + err.Error(),
+		})
+		return
+	}
+	runWorkloadAction(c, h.clusterService, h.k8sMgr, services.WorkloadKindDeployment, "update", "镜像已更新",
+		func(ctx context.Context, clientset kubernetes.Interface, namespace, name string) (*services.WorkloadRolloutStatus, error) {
+			return services.SetWorkloadImages(ctx, clientset, services.WorkloadKindDeployment, namespace, name, req.Images)
+		})
+}
+
+// GetDeploymentRolloutStatus 获取Deployment发布进度
+func (h *DeploymentHandler) GetDeploymentRolloutStatus(c *gin.Context) {
+	getWorkloadRolloutStatus(c, h.clusterService, h.k8sMgr, services.WorkloadKindDeployment)
+}
@@ -0,0 +1,38 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/clay-wangzhi/KubePolaris/internal/models"
+)
+
+func TestRollbackDeploymentRequiresPermission(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	h := &DeploymentHandler{}
+
+	cases := []struct {
+		permission *models.ClusterPermission
+		namespace  string
+	}{
+		{&models.ClusterPermission{PermissionType: models.PermissionTypeReadonly}, "default"},
+		{&models.ClusterPermission{PermissionType: models.PermissionTypeDev, Namespaces: `["team-a"]`}, "default"},
+	}
+	for _, tc := range cases {
+		router := gin.New()
+		router.POST("/clusters/:clusterID/deployments/:namespace/:name/rollback", func(c *gin.Context) {
+			c.Set("cluster_permission", tc.permission)
+			h.RollbackDeployment(c)
+		})
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/clusters/1/deployments/"+tc.namespace+"/web/rollback", strings.NewReader(`{"revision":1}`))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusForbidden, w.Code, tc.permission.PermissionType)
+	}
+}
@@ -0,0 +1,533 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/clay-wangzhi/KubePolaris/internal/k8s"
+	"github.com/clay-wangzhi/KubePolaris/internal/models"
+	"github.com/clay-wangzhi/KubePolaris/internal/services"
+	"github.com/clay-wangzhi/KubePolaris/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	sigsyaml "sigs.k8s.io/yaml"
+)
+
+// clusterScopeNamespace 集群级资源在路径中使用的命名空间占位符
+// 如 /resources/rbac.authorization.k8s.io/v1/clusterroles/_/admin
+const clusterScopeNamespace = "_"
+
+// DynamicResourceHandler 通用资源处理器
+// 基于发现接口和动态客户端支持任意 GVR（包括 CRD），路径中核心组使用 core 表示。
+type DynamicResourceHandler struct {
+	db             *gorm.DB
+	clusterService *services.ClusterService
+	k8sMgr         *k8s.ClusterInformerManager
+}
+
+// NewDynamicResourceHandler 创建通用资源处理器
+func NewDynamicResourceHandler(db *gorm.DB, clusterService *services.ClusterService, k8sMgr *k8s.ClusterInformerManager) *DynamicResourceHandler {
+	return &DynamicResourceHandler{
+		db:             db,
+		clusterService: clusterService,
+		k8sMgr:         k8sMgr,
+	}
+}
+
+// dynamicRequest 一次通用资源请求解析后的上下文
+type dynamicRequest struct {
+	cluster    *models.Cluster
+	client     dynamic.Interface
+	info       *services.APIResourceInfo
+	permission *models.ClusterPermission
+	namespace  string
+	name       string
+}
+
+// GetAPIResources 获取集群可用的 API 资源列表
+func (h *DynamicResourceHandler) GetAPIResources(c *gin.Context) {
+	_, k8sClient, ok := h.getK8sClient(c)
+	if !ok {
+		return
+	}
+
+	disc := k8sClient.CachedDiscovery()
+	if c.Query("refresh") == "true" {
+		disc.Invalidate()
+	}
+	resources, err := services.ListAPIResources(disc)
+	if err != nil {
+		logger.Error("获取 API 资源失败", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    500,
+			"message": err.Error(),
+			"data":    nil,
+		})
+		return
+	}
+
+	// 默认只返回每个组的首选版本
+	if c.Query("allVersions") != "true" {
+		preferred := make([]*services.APIResourceInfo, 0, len(resources))
+		for _, r := range resources {
+			if r.Preferred {
+				preferred = append(preferred, r)
+			}
+		}
+		resources = preferred
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "获取成功",
+		"data": gin.H{
+			"items": resources,
+			"total": len(resources),
+		},
+	})
+}
+
+// ListResources 列出资源
+// 支持 namespace、labelSelector、fieldSelector、limit、continue 查询参数。
+func (h *DynamicResourceHandler) ListResources(c *gin.Context) {
+	req, ok := h.parseRequest(c, "list")
+	if !ok {
+		return
+	}
+	req.namespace = c.Query("namespace")
+	if req.namespace != "" && !req.permission.HasNamespaceAccess(req.namespace) {
+		c.JSON(http.StatusForbidden, gin.H{
+			"code":    403,
+			"message": "无权限访问该命名空间",
+			"data":    nil,
+		})
+		return
+	}
+
+	opts := metav1.ListOptions{
+		LabelSelector: c.Query("labelSelector"),
+		FieldSelector: c.Query("fieldSelector"),
+		Continue:      c.Query("continue"),
+	}
+	if limit, err := strconv.ParseInt(c.Query("limit"), 10, 64); err == nil && limit > 0 {
+		opts.Limit = limit
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 30*time.Second)
+	defer cancel()
+
+	// 无分页/字段选择器时优先读取 informer 缓存，避免大集群频繁全量 List
+	var (
+		listed    []*unstructured.Unstructured
+		continued string
+		source    = "cache"
+	)
+	if c.Query("cache") != "false" && opts.FieldSelector == "" && opts.Continue == "" && opts.Limit == 0 &&
+		req.info.SupportsVerb("list") && req.info.SupportsVerb("watch") {
+		cached, err := h.listFromCache(ctx, req, opts.LabelSelector)
+		if err != nil {
+			logger.Warn("读取动态资源缓存失败，回退到 API Server", "gvr", req.info.GroupVersionResource().String(), "error", err)
+		} else {
+			listed = cached
+		}
+	}
+	if listed == nil {
+		source = "api"
+		list, err := services.ResourceInterface(req.client, req.info, req.namespace).List(ctx, opts)
+		if err != nil {
+			h.respondK8sError(c, "获取资源列表失败", err)
+			return
+		}
+		listed = make([]*unstructured.Unstructured, 0, len(list.Items))
+		for i := range list.Items {
+			listed = append(listed, &list.Items[i])
+		}
+		continued = list.GetContinue()
+	}
+
+	// 未指定命名空间时按用户的命名空间权限过滤
+	restricted := req.info.Namespaced && req.namespace == "" && !req.permission.HasAllNamespaceAccess()
+	items := make([]*unstructured.Unstructured, 0, len(listed))
+	for _, obj := range listed {
+		if restricted && !req.permission.HasNamespaceAccess(obj.GetNamespace()) {
+			continue
+		}
+		items = append(items, services.CleanDynamicObject(obj))
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "获取成功",
+		"data": gin.H{
+			"kind":       req.info.Kind,
+			"apiVersion": req.info.APIVersion(),
+			"namespaced": req.info.Namespaced,
+			"items":      items,
+			"total":      len(items),
+			"continue":   continued,
+			"source":     source,
+		},
+	})
+}
+
+// listFromCache 从动态 informer 缓存读取资源列表（首次访问时启动 informer）
+func (h *DynamicResourceHandler) listFromCache(ctx context.Context, req *dynamicRequest, labelSelector string) ([]*unstructured.Unstructured, error) {
+	selector, err := labels.Parse(labelSelector)
+	if err != nil {
+		return nil, err
+	}
+	lister, err := h.k8sMgr.DynamicLister(ctx, req.cluster, req.info.GroupVersionResource(), 10*time.Second)
+	if err != nil {
+		return nil, err
+	}
+
+	var objs []runtime.Object
+	if req.info.Namespaced && req.namespace != "" {
+		objs, err = lister.ByNamespace(req.namespace).List(selector)
+	} else {
+		objs, err = lister.List(selector)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*unstructured.Unstructured, 0, len(objs))
+	for _, obj := range objs {
+		if u, ok := obj.(*unstructured.Unstructured); ok {
+			result = append(result, u)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].GetNamespace() != result[j].GetNamespace() {
+			return result[i].GetNamespace() < result[j].GetNamespace()
+		}
+		return result[i].GetName() < result[j].GetName()
+	})
+	return result, nil
+}
+
+// GetResource 获取单个资源
+func (h *DynamicResourceHandler) GetResource(c *gin.Context) {
+	obj, ok := h.getObject(c)
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "获取成功",
+		"data":    services.CleanDynamicObject(obj),
+	})
+}
+
+// GetResourceYAML 获取单个资源的 YAML
+func (h *DynamicResourceHandler) GetResourceYAML(c *gin.Context) {
+	obj, ok := h.getObject(c)
+	if !ok {
+		return
+	}
+	yamlBytes, err := sigsyaml.Marshal(services.CleanDynamicObject(obj).Object)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    500,
+			"message": "转换YAML失败: " + err.Error(),
+			"data":    nil,
+		})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "获取成功",
+		"data": gin.H{
+			"yaml": string(yamlBytes),
+		},
+	})
+}
+
+// ApplyResource 应用资源 YAML（存在则更新，不存在则创建）
+func (h *DynamicResourceHandler) ApplyResource(c *gin.Context) {
+	var body ResourceYAMLApplyRequest
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "请求参数错误: " + err.Error(),
+			"data":    nil,
+		})
+		return
+	}
+
+	req, ok := h.parseRequest(c, "")
+	if !ok {
+		return
+	}
+	obj, err := services.DecodeResourceYAML(body.YAML, req.info)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": err.Error(),
+			"data":    nil,
+		})
+		return
+	}
+	req.namespace = obj.GetNamespace()
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 30*time.Second)
+	defer cancel()
+
+	// 先判断创建还是更新，再按对应动作校验权限
+	verb := "update"
+	if _, err := services.ResourceInterface(req.client, req.info, req.namespace).Get(ctx, obj.GetName(), metav1.GetOptions{}); apierrors.IsNotFound(err) {
+		verb = "create"
+	}
+	if !h.checkWrite(c, req, verb) {
+		return
+	}
+
+	result, err := services.NewApplyEngineWithClients(req.client, nil).ApplyResource(ctx, req.info, obj,
+		services.ApplyOptions{DryRun: body.DryRun, Force: body.Force})
+	if err != nil {
+		respondApplyError(c, err)
+		return
+	}
+	recordApplyRevision(c, services.NewResourceRevisionService(h.db), services.RevisionActionApply, result)
+
+	logger.Info("应用资源", "cluster", req.cluster.Name, "resource", req.info.Resource,
+		"namespace", result.Namespace, "name", result.Name, "operation", result.Operation, "dryRun", body.DryRun)
+	message := "YAML应用成功"
+	if body.DryRun {
+		message = "预检通过"
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": message,
+		"data":    result,
+	})
+}
+
+// DeleteResource 删除资源，支持 propagationPolicy 查询参数（Foreground/Background/Orphan）
+func (h *DynamicResourceHandler) DeleteResource(c *gin.Context) {
+	req, ok := h.parseRequest(c, "delete")
+	if !ok {
+		return
+	}
+	if !h.parseObjectKey(c, req) || !h.checkWrite(c, req, "delete") {
+		return
+	}
+
+	opts := metav1.DeleteOptions{}
+	if policy := c.Query("propagationPolicy"); policy != "" {
+		p := metav1.DeletionPropagation(policy)
+		opts.PropagationPolicy = &p
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 30*time.Second)
+	defer cancel()
+
+	if err := services.ResourceInterface(req.client, req.info, req.namespace).Delete(ctx, req.name, opts); err != nil {
+		h.respondK8sError(c, "删除资源失败", err)
+		return
+	}
+
+	logger.Info("删除资源", "cluster", req.cluster.Name, "resource", req.info.Resource,
+		"namespace", req.namespace, "name", req.name)
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "删除成功",
+		"data":    nil,
+	})
+}
+
+// getObject 解析路径并获取单个资源（GET 类接口共用）
+func (h *DynamicResourceHandler) getObject(c *gin.Context) (*unstructured.Unstructured, bool) {
+	req, ok := h.parseRequest(c, "get")
+	if !ok || !h.parseObjectKey(c, req) {
+		return nil, false
+	}
+	if req.namespace != "" && !req.permission.HasNamespaceAccess(req.namespace) {
+		c.JSON(http.StatusForbidden, gin.H{
+			"code":    403,
+			"message": "无权限访问该命名空间",
+			"data":    nil,
+		})
+		return nil, false
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 30*time.Second)
+	defer cancel()
+
+	obj, err := services.ResourceInterface(req.client, req.info, req.namespace).Get(ctx, req.name, metav1.GetOptions{})
+	if err != nil {
+		h.respondK8sError(c, "获取资源失败", err)
+		return nil, false
+	}
+	return obj, true
+}
+
+// parseRequest 解析集群与 GVR，verb 非空时校验资源是否支持该动词
+func (h *DynamicResourceHandler) parseRequest(c *gin.Context, verb string) (*dynamicRequest, bool) {
+	permission, ok := getClusterPermission(c)
+	if !ok {
+		return nil, false
+	}
+	cluster, k8sClient, ok := h.getK8sClient(c)
+	if !ok {
+		return nil, false
+	}
+
+	gvr := schema.GroupVersionResource{
+		Group:    services.ParseGroupPath(c.Param("group")),
+		Version:  c.Param("version"),
+		Resource: c.Param("resource"),
+	}
+	info, err := services.ResolveAPIResource(k8sClient.CachedDiscovery(), gvr)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"code":    404,
+			"message": err.Error(),
+			"data":    nil,
+		})
+		return nil, false
+	}
+	if verb != "" && !info.SupportsVerb(verb) {
+		c.JSON(http.StatusMethodNotAllowed, gin.H{
+			"code":    405,
+			"message": fmt.Sprintf("资源 %s 不支持 %s 操作", info.Resource, verb),
+			"data":    nil,
+		})
+		return nil, false
+	}
+
+	dyn, err := k8sClient.DynamicClient()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    500,
+			"message": "创建动态客户端失败: " + err.Error(),
+			"data":    nil,
+		})
+		return nil, false
+	}
+
+	return &dynamicRequest{
+		cluster:    cluster,
+		client:     dyn,
+		info:       info,
+		permission: permission,
+	}, true
+}
+
+// parseObjectKey 解析路径中的命名空间与名称
+func (h *DynamicResourceHandler) parseObjectKey(c *gin.Context, req *dynamicRequest) bool {
+	req.name = c.Param("name")
+	namespace := c.Param("namespace")
+	if namespace == clusterScopeNamespace {
+		namespace = ""
+	}
+	if req.info.Namespaced && namespace == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": fmt.Sprintf("%s 是命名空间级资源，必须指定命名空间", req.info.Resource),
+			"data":    nil,
+		})
+		return false
+	}
+	if !req.info.Namespaced {
+		namespace = ""
+	}
+	req.namespace = namespace
+	return true
+}
+
+// checkWrite 校验写操作权限：命名空间级资源检查命名空间，集群级资源要求全部命名空间权限
+func (h *DynamicResourceHandler) checkWrite(c *gin.Context, req *dynamicRequest, verb string) bool {
+	if !req.info.SupportsVerb(verb) {
+		c.JSON(http.StatusMethodNotAllowed, gin.H{
+			"code":    405,
+			"message": fmt.Sprintf("资源 %s 不支持 %s 操作", req.info.Resource, verb),
+			"data":    nil,
+		})
+		return false
+	}
+
+	nsAllowed := req.permission.HasAllNamespaceAccess()
+	if req.info.Namespaced {
+		nsAllowed = req.permission.HasNamespaceAccess(req.namespace)
+	}
+	action := req.info.PermissionAction(verb)
+	if !nsAllowed || !req.permission.CanPerformAction(action) {
+		c.JSON(http.StatusForbidden, gin.H{
+			"code":    403,
+			"message": "权限不足，无法执行此操作",
+			"data": gin.H{
+				"required_action": action,
+				"permission_type": req.permission.PermissionType,
+			},
+		})
+		return false
+	}
+	return true
+}
+
+// getK8sClient 获取集群的缓存客户端（复用发现缓存）
+func (h *DynamicResourceHandler) getK8sClient(c *gin.Context) (*models.Cluster, *services.K8sClient, bool) {
+	cluster, err := h.clusterService.GetCluster(parseClusterID(c.Param("clusterID")))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"code":    404,
+			"message": "集群不存在",
+			"data":    nil,
+		})
+		return nil, nil, false
+	}
+
+	k8sClient, err := h.k8sMgr.GetK8sClient(cluster)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    500,
+			"message": "获取K8s客户端失败: " + err.Error(),
+			"data":    nil,
+		})
+		return nil, nil, false
+	}
+	return cluster, k8sClient, true
+}
+
+// respondK8sError 将 Kubernetes API 错误转换为对应的 HTTP 状态码
+func (h *DynamicResourceHandler) respondK8sError(c *gin.Context, message string, err error) {
+	status := http.StatusInternalServerError
+	if statusErr, ok := err.(apierrors.APIStatus); ok {
+		if code := int(statusErr.Status().Code); code >= 400 && code < 600 {
+			status = code
+		}
+	}
+	c.JSON(status, gin.H{
+		"code":    status,
+		"message": message + ": " + err.Error(),
+		"data":    nil,
+	})
+}
+
+// getClusterPermission 获取 ClusterAccessRequired 中间件写入的集群权限
+func getClusterPermission(c *gin.Context) (*models.ClusterPermission, bool) {
+	value, exists := c.Get("cluster_permission")
+	permission, ok := value.(*models.ClusterPermission)
+	if !exists || !ok {
+		c.JSON(http.StatusForbidden, gin.H{
+			"code":    403,
+			"message": "无集群访问权限",
+			"data":    nil,
+		})
+		return nil, false
+	}
+	return permission, true
+}
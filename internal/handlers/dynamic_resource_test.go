@@ -0,0 +1,50 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/clay-wangzhi/KubePolaris/internal/models"
+	"github.com/clay-wangzhi/KubePolaris/internal/services"
+)
+
+func TestDynamicCheckWriteMapsBuiltinActions(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	h := &DynamicResourceHandler{}
+	pv := &services.APIResourceInfo{
+		Version:      "v1",
+		Resource:     "persistentvolumes",
+		SingularName: "persistentvolume",
+		Kind:         "PersistentVolume",
+		Verbs:        []string{"get", "list", "create", "update", "delete"},
+	}
+
+	cases := []struct {
+		permissionType string
+		verb           string
+		wantCode       int
+	}{
+		{models.PermissionTypeOps, "delete", http.StatusForbidden},
+		{models.PermissionTypeOps, "create", http.StatusForbidden},
+		{models.PermissionTypeOps, "update", http.StatusOK},
+		{models.PermissionTypeAdmin, "delete", http.StatusOK},
+	}
+	for _, tc := range cases {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		req := &dynamicRequest{info: pv, permission: &models.ClusterPermission{PermissionType: tc.permissionType}, name: "pv-data"}
+
+		allowed := h.checkWrite(c, req, tc.verb)
+		if tc.wantCode == http.StatusOK {
+			assert.True(t, allowed, "%s %s", tc.permissionType, tc.verb)
+			continue
+		}
+		assert.False(t, allowed, "%s %s", tc.permissionType, tc.verb)
+		assert.Equal(t, tc.wantCode, w.Code)
+		assert.Contains(t, w.Body.String(), `"required_action":"pv:`+tc.verb+`"`)
+	}
+}
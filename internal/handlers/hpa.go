@@ -0,0 +1,303 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/clay-wangzhi/KubePolaris/internal/k8s"
+	"github.com/clay-wangzhi/KubePolaris/internal/middleware"
+	"github.com/clay-wangzhi/KubePolaris/internal/models"
+	"github.com/clay-wangzhi/KubePolaris/internal/services"
+	"github.com/clay-wangzhi/KubePolaris/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// HPAHandler HorizontalPodAutoscaler 处理器
+type HPAHandler struct {
+	db               *gorm.DB
+	clusterService   *services.ClusterService
+	k8sMgr           *k8s.ClusterInformerManager
+	promService      *services.PrometheusService
+	monitoringCfgSvc *services.MonitoringConfigService
+}
+
+// NewHPAHandler 创建 HPA 处理器
+func NewHPAHandler(db *gorm.DB, clusterService *services.ClusterService, k8sMgr *k8s.ClusterInformerManager, promService *services.PrometheusService, monitoringCfgSvc *services.MonitoringConfigService) *HPAHandler {
+	return &HPAHandler{
+		db:               db,
+		clusterService:   clusterService,
+		k8sMgr:           k8sMgr,
+		promService:      promService,
+		monitoringCfgSvc: monitoringCfgSvc,
+	}
+}
+
+// ListHPAs 获取HPA列表，支持 namespace、targetKind 过滤
+func (h *HPAHandler) ListHPAs(c *gin.Context) {
+	namespace := c.Query("namespace")
+	targetKind := c.Query("targetKind")
+
+	nsInfo, hasAccess := middleware.CheckNamespacePermission(c, namespace)
+	if !hasAccess {
+		c.JSON(http.StatusForbidden, gin.H{
+			"code":    403,
+			"message": fmt.Sprintf("无权访问命名空间: %s", namespace),
+		})
+		return
+	}
+
+	clientset, ok := workloadClientset(c, h.clusterService, h.k8sMgr)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	hpas, err := services.ListHPAs(ctx, clientset, namespace, targetKind)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    500,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	// 根据命名空间权限过滤
+	if !nsInfo.HasAllAccess && namespace == "" {
+		hpas = middleware.FilterResourcesByNamespace(c, hpas, func(hpa services.HPAInfo) string {
+			return hpa.Namespace
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "success",
+		"data": gin.H{
+			"items": hpas,
+			"total": len(hpas),
+		},
+	})
+}
+
+// GetHPA 获取HPA详情
+func (h *HPAHandler) GetHPA(c *gin.Context) {
+	if !checkNamespaceAccess(c, c.Param("namespace")) {
+		return
+	}
+	clientset, ok := workloadClientset(c, h.clusterService, h.k8sMgr)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	hpa, err := clientset.AutoscalingV2().HorizontalPodAutoscalers(c.Param("namespace")).Get(ctx, c.Param("name"), metav1.GetOptions{})
+	if err != nil {
+		code := http.StatusInternalServerError
+		if apierrors.IsNotFound(err) {
+			code = http.StatusNotFound
+		}
+		c.JSON(code, gin.H{
+			"code":    code,
+			"message": "获取HPA失败: " + err.Error(),
+			"data":    nil,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "success",
+		"data":    services.ToHPAInfo(hpa),
+	})
+}
+
+// CreateHPA 创建HPA
+func (h *HPAHandler) CreateHPA(c *gin.Context) {
+	var req services.HPARequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "参数错误: " + err.Error(),
+		})
+		return
+	}
+
+	// 命名空间来自请求体，单独校验
+	permission, ok := getClusterPermission(c)
+	if !ok {
+		return
+	}
+	if !permission.HasNamespaceAccess(req.Namespace) || !permission.CanPerformAction("hpa:create") {
+		c.JSON(http.StatusForbidden, gin.H{
+			"code":    403,
+			"message": "无权限执行该操作",
+			"data":    nil,
+		})
+		return
+	}
+
+	logger.Info("创建HPA: cluster=%s %s/%s target=%s/%s", c.Param("clusterID"), req.Namespace, req.Name, req.TargetKind, req.TargetName)
+
+	clientset, ok := workloadClientset(c, h.clusterService, h.k8sMgr)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	hpa, err := services.CreateHPA(ctx, clientset, &req)
+	if err != nil {
+		respondWorkloadActionError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "HPA创建成功",
+		"data":    services.ToHPAInfo(hpa),
+	})
+}
+
+// UpdateHPA 更新HPA的扩缩容目标、副本范围、指标与行为
+func (h *HPAHandler) UpdateHPA(c *gin.Context) {
+	var req services.HPARequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "参数错误: " + err.Error(),
+		})
+		return
+	}
+	namespace := c.Param("namespace")
+	name := c.Param("name")
+
+	if !checkWorkloadPermission(c, "HPA", "update") {
+		return
+	}
+
+	logger.Info("更新HPA: cluster=%s %s/%s", c.Param("clusterID"), namespace, name)
+
+	clientset, ok := workloadClientset(c, h.clusterService, h.k8sMgr)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	prev, hpa, err := services.UpdateHPA(ctx, clientset, namespace, name, &req)
+	if err != nil {
+		respondWorkloadActionError(c, err)
+		return
+	}
+	recordTypedRevision(c, h.db, prev, autoscalingv2.SchemeGroupVersion.WithKind("HorizontalPodAutoscaler"), services.RevisionActionUpdate)
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "HPA更新成功",
+		"data":    services.ToHPAInfo(hpa),
+	})
+}
+
+// DeleteHPA 删除HPA
+func (h *HPAHandler) DeleteHPA(c *gin.Context) {
+	namespace := c.Param("namespace")
+	name := c.Param("name")
+
+	if !checkWorkloadPermission(c, "HPA", "delete") {
+		return
+	}
+
+	logger.Info("删除HPA: cluster=%s %s/%s", c.Param("clusterID"), namespace, name)
+
+	clientset, ok := workloadClientset(c, h.clusterService, h.k8sMgr)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	if err := services.DeleteHPA(ctx, clientset, namespace, name); err != nil {
+		respondWorkloadActionError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "HPA删除成功",
+		"data":    nil,
+	})
+}
+
+// GetHPATimeline 获取HPA扩缩容时间线：合并HPA事件与Prometheus中的副本数、CPU/内存使用率历史
+func (h *HPAHandler) GetHPATimeline(c *gin.Context) {
+	namespace := c.Param("namespace")
+	name := c.Param("name")
+
+	if !checkNamespaceAccess(c, c.Param("namespace")) {
+		return
+	}
+	clientset, ok := workloadClientset(c, h.clusterService, h.k8sMgr)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	hpa, err := clientset.AutoscalingV2().HorizontalPodAutoscalers(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		code := http.StatusInternalServerError
+		if apierrors.IsNotFound(err) {
+			code = http.StatusNotFound
+		}
+		c.JSON(code, gin.H{
+			"code":    code,
+			"message": "获取HPA失败: " + err.Error(),
+			"data":    nil,
+		})
+		return
+	}
+
+	events, err := services.ListHPAEvents(ctx, clientset, namespace, name)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    500,
+			"message": err.Error(),
+			"data":    nil,
+		})
+		return
+	}
+
+	// 监控未配置或查询失败时仅返回事件
+	var metrics *models.ClusterMetricsData
+	config, err := h.monitoringCfgSvc.GetMonitoringConfig(parseClusterID(c.Param("clusterID")))
+	if err != nil {
+		logger.Error("获取监控配置失败", "error", err)
+	} else if config.Type != "disabled" {
+		metrics, err = h.promService.QueryWorkloadMetrics(ctx, config, c.Query("clusterName"), namespace,
+			hpa.Spec.ScaleTargetRef.Name, c.DefaultQuery("range", "1h"), c.DefaultQuery("step", "1m"))
+		if err != nil {
+			logger.Error("查询工作负载监控指标失败", "error", err)
+		}
+	}
+
+	timeline := services.BuildHPATimeline(hpa, events, metrics)
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "success",
+		"data":    timeline,
+	})
+}
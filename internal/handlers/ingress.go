@@ -572,6 +572,9 @@ func (h *IngressHandler) UpdateIngress(c *gin.Context) {
 
 	clientset := k8sClient.GetClientset()
 
+	// 保存变更前的对象，用于记录修订
+	previous, previousErr := clientset.NetworkingV1().Ingresses(namespace).Get(context.Background(), name, metav1.GetOptions{})
+
 	var ingress *networkingv1.Ingress
 
 	// 根据更新方式选择处理逻辑
@@ -591,6 +594,9 @@ func (h *IngressHandler) UpdateIngress(c *gin.Context) {
 		c.JSON(500, gin.H{"code": 500, "message": fmt.Sprintf("更新Ingress失败: %v", err), "data": nil})
 		return
 	}
+	if previousErr == nil {
+		recordTypedRevision(c, h.db, previous, networkingv1.SchemeGroupVersion.WithKind("Ingress"), services.RevisionActionUpdate)
+	}
 
 	logger.Info("Ingress更新成功", "clusterId", clusterID, "namespace", ingress.Namespace, "name", ingress.Name)
 	c.JSON(200, gin.H{"code": 200, "message": "Ingress更新成功", "data": h.convertToIngressInfo(ingress)})
@@ -20,9 +20,6 @@ import (
 	batchv1 "k8s.io/api/batch/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
-	"k8s.io/apimachinery/pkg/runtime/serializer"
-	"k8s.io/apimachinery/pkg/util/yaml"
-	"k8s.io/client-go/kubernetes/scheme"
 	sigsyaml "sigs.k8s.io/yaml"
 )
 
@@ -277,7 +274,7 @@ func (h *JobHandler) ApplyYAML(c *gin.Context) {
 		return
 	}
 
-	logger.Info("应用Job YAML: cluster=%s, dryRun=%v", clusterId, req.DryRun)
+	logger.Info("应用Job YAML: cluster=%s, dryRun=%v, force=%v", clusterId, req.DryRun, req.Force)
 
 	clusterID := parseClusterID(clusterId)
 	cluster, err := h.clusterService.GetCluster(clusterID)
@@ -292,44 +289,7 @@ func (h *JobHandler) ApplyYAML(c *gin.Context) {
 		return
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-	defer cancel()
-
-	var objMap map[string]interface{}
-	if err := yaml.Unmarshal([]byte(req.YAML), &objMap); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "YAML格式错误: " + err.Error()})
-		return
-	}
-
-	if objMap["apiVersion"] == nil || objMap["kind"] == nil {
-		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "YAML缺少必要字段: apiVersion 或 kind"})
-		return
-	}
-
-	kind := objMap["kind"].(string)
-	if kind != "Job" {
-		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "YAML类型错误，期望Job，实际为: " + kind})
-		return
-	}
-
-	metadata, ok := objMap["metadata"].(map[string]interface{})
-	if !ok {
-		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "YAML缺少 metadata 字段"})
-		return
-	}
-
-	namespace, _ := metadata["namespace"].(string)
-	if namespace == "" {
-		namespace = "default"
-	}
-
-	result, err := h.applyYAML(ctx, k8sClient, req.YAML, namespace, req.DryRun)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "YAML应用失败: " + err.Error()})
-		return
-	}
-
-	c.JSON(http.StatusOK, gin.H{"code": 200, "message": "YAML应用成功", "data": result})
+	applyYAMLWithEngine(c, h.db, k8sClient, req.YAML, "Job", req.DryRun, req.Force)
 }
 
 func (h *JobHandler) DeleteJob(c *gin.Context) {
@@ -419,38 +379,3 @@ func (h *JobHandler) convertToJobInfo(j *batchv1.Job) JobInfo {
 		Images:         images,
 	}
 }
-
-func (h *JobHandler) applyYAML(ctx context.Context, k8sClient *services.K8sClient, yamlContent string, namespace string, dryRun bool) (interface{}, error) {
-	decode := serializer.NewCodecFactory(scheme.Scheme).UniversalDeserializer().Decode
-	obj, _, err := decode([]byte(yamlContent), nil, nil)
-	if err != nil {
-		return nil, fmt.Errorf("解析YAML失败: %w", err)
-	}
-
-	job, ok := obj.(*batchv1.Job)
-	if !ok {
-		return nil, fmt.Errorf("无法转换为Job类型")
-	}
-
-	clientset := k8sClient.GetClientset()
-	var dryRunOpt []string
-	if dryRun {
-		dryRunOpt = []string{metav1.DryRunAll}
-	}
-
-	existing, err := clientset.BatchV1().Jobs(job.Namespace).Get(ctx, job.Name, metav1.GetOptions{})
-	if err == nil {
-		job.ResourceVersion = existing.ResourceVersion
-		result, err := clientset.BatchV1().Jobs(job.Namespace).Update(ctx, job, metav1.UpdateOptions{DryRun: dryRunOpt})
-		if err != nil {
-			return nil, err
-		}
-		return result, nil
-	}
-
-	result, err := clientset.BatchV1().Jobs(job.Namespace).Create(ctx, job, metav1.CreateOptions{DryRun: dryRunOpt})
-	if err != nil {
-		return nil, err
-	}
-	return result, nil
-}
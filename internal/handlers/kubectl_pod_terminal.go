@@ -354,6 +354,11 @@ func (h *KubectlPodTerminalHandler) cleanupClusterIdlePods(cluster *models.Clust
 
 // createK8sConfig 创建 K8s 配置
 func (h *KubectlPodTerminalHandler) createK8sConfig(cluster *models.Cluster) (*rest.Config, error) {
+	// Agent 模式经反向隧道访问
+	if cluster.IsAgentMode() {
+		return services.NewAgentRESTConfig(cluster.ID), nil
+	}
+
 	// 优先使用 Kubeconfig 方式
 	if cluster.KubeconfigEnc != "" {
 		config, err := clientcmd.RESTConfigFromKubeConfig([]byte(cluster.KubeconfigEnc))
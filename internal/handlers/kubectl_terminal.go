@@ -600,7 +600,10 @@ func (h *KubectlTerminalHandler) createTempKubeconfig(cluster *models.Cluster) (
 
 	// 写入kubeconfig内容
 	var kubeconfigContent string
-	if cluster.KubeconfigEnc != "" {
+	if cluster.IsAgentMode() {
+		// 本地 kubectl 无法经进程内隧道拨号
+		return "", fmt.Errorf("Agent 接入的集群暂不支持 kubectl 终端，请使用 Pod 终端")
+	} else if cluster.KubeconfigEnc != "" {
 		kubeconfigContent = cluster.KubeconfigEnc
 	} else if cluster.SATokenEnc != "" {
 		// 从Token创建kubeconfig
@@ -8,22 +8,26 @@ import (
 	"github.com/clay-wangzhi/KubePolaris/internal/k8s"
 	"github.com/clay-wangzhi/KubePolaris/internal/middleware"
 	"github.com/clay-wangzhi/KubePolaris/internal/services"
+	"github.com/clay-wangzhi/KubePolaris/pkg/logger"
 
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 )
 
 type NamespaceHandler struct {
-	clusterService *services.ClusterService
-	k8sMgr         *k8s.ClusterInformerManager
+	clusterService  *services.ClusterService
+	k8sMgr          *k8s.ClusterInformerManager
+	templateService *services.NamespaceTemplateService
 }
 
-func NewNamespaceHandler(clusterService *services.ClusterService, k8sMgr *k8s.ClusterInformerManager) *NamespaceHandler {
+func NewNamespaceHandler(db *gorm.DB, clusterService *services.ClusterService, k8sMgr *k8s.ClusterInformerManager) *NamespaceHandler {
 	return &NamespaceHandler{
-		clusterService: clusterService,
-		k8sMgr:         k8sMgr,
+		clusterService:  clusterService,
+		k8sMgr:          k8sMgr,
+		templateService: services.NewNamespaceTemplateService(db),
 	}
 }
 
@@ -332,6 +336,11 @@ func (h *NamespaceHandler) DeleteNamespace(c *gin.Context) {
 		return
 	}
 
+	// 清除模板绑定，同名命名空间重建后不再沿用
+	if err := h.templateService.DeleteBinding(clusterID, namespaceName); err != nil {
+		logger.Error("删除命名空间模板绑定失败", "namespace", namespaceName, "error", err)
+	}
+
 	c.JSON(http.StatusOK, gin.H{
 		"code":    http.StatusOK,
 		"message": "命名空间删除成功",
@@ -0,0 +1,452 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/clay-wangzhi/KubePolaris/internal/k8s"
+	"github.com/clay-wangzhi/KubePolaris/internal/middleware"
+	"github.com/clay-wangzhi/KubePolaris/internal/models"
+	"github.com/clay-wangzhi/KubePolaris/internal/services"
+	"github.com/clay-wangzhi/KubePolaris/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// NamespaceTemplateHandler 命名空间模板处理器
+type NamespaceTemplateHandler struct {
+	db              *gorm.DB
+	clusterService  *services.ClusterService
+	k8sMgr          *k8s.ClusterInformerManager
+	templateService *services.NamespaceTemplateService
+}
+
+// NewNamespaceTemplateHandler 创建命名空间模板处理器
+func NewNamespaceTemplateHandler(db *gorm.DB, clusterService *services.ClusterService, k8sMgr *k8s.ClusterInformerManager) *NamespaceTemplateHandler {
+	return &NamespaceTemplateHandler{
+		db:              db,
+		clusterService:  clusterService,
+		k8sMgr:          k8sMgr,
+		templateService: services.NewNamespaceTemplateService(db),
+	}
+}
+
+// NamespaceTemplatePreviewRequest 模板预览请求
+type NamespaceTemplatePreviewRequest struct {
+	Namespace string            `json:"namespace" binding:"required"`
+	Values    map[string]string `json:"values"`
+}
+
+// CreateNamespaceFromTemplateRequest 按模板创建命名空间请求
+type CreateNamespaceFromTemplateRequest struct {
+	Name        string            `json:"name" binding:"required"`
+	TemplateID  uint              `json:"templateId" binding:"required"`
+	Values      map[string]string `json:"values"`
+	Labels      map[string]string `json:"labels"`
+	Annotations map[string]string `json:"annotations"`
+}
+
+// ApplyNamespaceTemplateRequest 重新应用模板请求，未指定 templateId 时使用命名空间当前绑定的模板
+type ApplyNamespaceTemplateRequest struct {
+	TemplateID uint              `json:"templateId"`
+	Values     map[string]string `json:"values"`
+	DryRun     bool              `json:"dryRun"`
+}
+
+// ListTemplates 获取命名空间模板列表
+func (h *NamespaceTemplateHandler) ListTemplates(c *gin.Context) {
+	items, err := h.templateService.List()
+	if err != nil {
+		respondNamespaceTemplateError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "success",
+		"data": gin.H{
+			"items": items,
+			"total": len(items),
+		},
+	})
+}
+
+// GetTemplate 获取命名空间模板详情
+func (h *NamespaceTemplateHandler) GetTemplate(c *gin.Context) {
+	tpl, ok := h.templateFromParam(c)
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "success",
+		"data":    services.ToNamespaceTemplateInfo(tpl),
+	})
+}
+
+// CreateTemplate 创建命名空间模板
+func (h *NamespaceTemplateHandler) CreateTemplate(c *gin.Context) {
+	var req services.NamespaceTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "参数错误: " + err.Error(),
+		})
+		return
+	}
+
+	tpl, err := h.templateService.Create(&req, c.GetString("username"))
+	if err != nil {
+		respondNamespaceTemplateError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "命名空间模板创建成功",
+		"data":    services.ToNamespaceTemplateInfo(tpl),
+	})
+}
+
+// UpdateTemplate 更新命名空间模板，内容变化时版本号加一
+func (h *NamespaceTemplateHandler) UpdateTemplate(c *gin.Context) {
+	id, ok := parseTemplateID(c)
+	if !ok {
+		return
+	}
+	var req services.NamespaceTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "参数错误: " + err.Error(),
+		})
+		return
+	}
+
+	tpl, err := h.templateService.Update(id, &req, c.GetString("username"))
+	if err != nil {
+		respondNamespaceTemplateError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "命名空间模板更新成功",
+		"data":    services.ToNamespaceTemplateInfo(tpl),
+	})
+}
+
+// DeleteTemplate 删除命名空间模板
+func (h *NamespaceTemplateHandler) DeleteTemplate(c *gin.Context) {
+	id, ok := parseTemplateID(c)
+	if !ok {
+		return
+	}
+	if err := h.templateService.Delete(id); err != nil {
+		respondNamespaceTemplateError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "命名空间模板删除成功",
+		"data":    nil,
+	})
+}
+
+// PreviewTemplate 使用指定的命名空间与变量渲染模板，返回渲染后的 YAML
+func (h *NamespaceTemplateHandler) PreviewTemplate(c *gin.Context) {
+	tpl, ok := h.templateFromParam(c)
+	if !ok {
+		return
+	}
+	var req NamespaceTemplatePreviewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "参数错误: " + err.Error(),
+		})
+		return
+	}
+
+	rendered, values, err := services.RenderNamespaceTemplate(tpl, req.Namespace, req.Values)
+	if err == nil {
+		_, _, err = services.ParseNamespaceTemplateObjects(rendered, req.Namespace)
+	}
+	if err != nil {
+		respondNamespaceTemplateError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "success",
+		"data": gin.H{
+			"yaml":    rendered,
+			"values":  values,
+			"version": tpl.Version,
+		},
+	})
+}
+
+// CreateNamespaceFromTemplate 按模板创建命名空间，并一并下发模板中的配额、LimitRange、RBAC、网络策略等资源
+// 任一资源失败时删除新建的命名空间。
+func (h *NamespaceTemplateHandler) CreateNamespaceFromTemplate(c *gin.Context) {
+	if !requireClusterAdmin(c, "只有管理员才能创建命名空间") {
+		return
+	}
+	var req CreateNamespaceFromTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "参数错误: " + err.Error(),
+		})
+		return
+	}
+	tpl, err := h.templateService.Get(req.TemplateID)
+	if err != nil {
+		respondNamespaceTemplateError(c, err)
+		return
+	}
+	engine, k8sClient, ok := h.applyClients(c)
+	if !ok {
+		return
+	}
+
+	logger.Info("按模板创建命名空间", "cluster", c.Param("clusterID"), "namespace", req.Name, "template", tpl.Name, "version", tpl.Version)
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Minute)
+	defer cancel()
+
+	result, err := h.templateService.CreateNamespace(ctx, engine, k8sClient.GetClientset(), parseClusterID(c.Param("clusterID")), tpl,
+		&services.NamespaceProvisionRequest{
+			Namespace:   req.Name,
+			Labels:      req.Labels,
+			Annotations: req.Annotations,
+			Values:      req.Values,
+		}, revisionOperator(c))
+	h.respondProvision(c, result, err, "命名空间创建成功")
+}
+
+// GetNamespaceTemplate 获取命名空间绑定的模板、使用的版本及是否有新版本
+func (h *NamespaceTemplateHandler) GetNamespaceTemplate(c *gin.Context) {
+	namespace := c.Param("namespace")
+	if !checkNamespaceAccess(c, namespace) {
+		return
+	}
+	binding, err := h.templateService.GetBinding(parseClusterID(c.Param("clusterID")), namespace)
+	if err != nil {
+		respondNamespaceTemplateError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "success",
+		"data":    binding,
+	})
+}
+
+// ApplyNamespaceTemplate 将模板最新版本重新应用到已存在的命名空间，或为存量命名空间绑定模板
+// 任一资源失败时撤销本次已应用的资源；支持预检。
+func (h *NamespaceTemplateHandler) ApplyNamespaceTemplate(c *gin.Context) {
+	if !requireClusterAdmin(c, "只有管理员才能应用命名空间模板") {
+		return
+	}
+	namespace := c.Param("namespace")
+	clusterID := parseClusterID(c.Param("clusterID"))
+
+	var req ApplyNamespaceTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "参数错误: " + err.Error(),
+		})
+		return
+	}
+	if req.TemplateID == 0 {
+		binding, err := h.templateService.GetBinding(clusterID, namespace)
+		if err != nil {
+			respondNamespaceTemplateError(c, err)
+			return
+		}
+		if binding == nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"code":    400,
+				"message": "命名空间未绑定模板，请指定 templateId",
+			})
+			return
+		}
+		req.TemplateID = binding.TemplateID
+	}
+	tpl, err := h.templateService.Get(req.TemplateID)
+	if err != nil {
+		respondNamespaceTemplateError(c, err)
+		return
+	}
+	engine, _, ok := h.applyClients(c)
+	if !ok {
+		return
+	}
+
+	logger.Info("应用命名空间模板", "cluster", c.Param("clusterID"), "namespace", namespace, "template", tpl.Name,
+		"version", tpl.Version, "dryRun", req.DryRun)
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Minute)
+	defer cancel()
+
+	result, err := h.templateService.ApplyToNamespace(ctx, engine, clusterID, tpl,
+		&services.NamespaceProvisionRequest{Namespace: namespace, Values: req.Values, DryRun: req.DryRun}, revisionOperator(c))
+	h.respondProvision(c, result, err, "命名空间模板应用成功")
+}
+
+// respondProvision 输出按模板下发的结果：资源失败时返回 422 及回滚情况
+func (h *NamespaceTemplateHandler) respondProvision(c *gin.Context, result *services.NamespaceProvisionResult, err error, successMessage string) {
+	if err != nil && result == nil {
+		respondNamespaceTemplateError(c, err)
+		return
+	}
+	if result.Failed() {
+		summary := batchApplyErrorSummary(result.Apply)
+		message := fmt.Sprintf("%d 个资源应用失败", result.Apply.Failed)
+		switch {
+		case result.DryRun:
+		case result.RolledBack:
+			message += "，已回滚"
+		default:
+			rollbackErrors := strings.Join(result.RollbackErrors, "; ")
+			message += "，回滚未完成: " + rollbackErrors
+			summary = truncateAuditError("回滚失败: " + rollbackErrors + "; " + summary)
+		}
+		c.Set("error_message", summary)
+		c.JSON(http.StatusUnprocessableEntity, gin.H{
+			"code":    422,
+			"message": message,
+			"data":    result,
+		})
+		return
+	}
+
+	revisions := services.NewResourceRevisionService(h.db)
+	for _, item := range result.Apply.Items {
+		if item.Result != nil {
+			recordApplyRevision(c, revisions, services.RevisionActionApply, item.Result)
+		}
+	}
+	if err != nil {
+		// 资源已下发但绑定记录保存失败
+		logger.Error("保存命名空间模板绑定失败", "namespace", result.Namespace, "error", err)
+		c.Set("error_message", err.Error())
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    500,
+			"message": err.Error(),
+			"data":    result,
+		})
+		return
+	}
+
+	if result.DryRun {
+		successMessage = "预检通过"
+	}
+	if pruneErrors := result.PruneErrors(); len(pruneErrors) > 0 {
+		// 模板中已移除的资源未能删除，下次应用时重试
+		summary := strings.Join(pruneErrors, "; ")
+		successMessage += fmt.Sprintf("，%d 个已移除的资源删除失败: %s", len(pruneErrors), summary)
+		c.Set("error_message", truncateAuditError("清理已移除资源失败: "+summary))
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": successMessage,
+		"data":    result,
+	})
+}
+
+// applyClients 获取请求集群的应用引擎与 clientset，失败时直接写入响应
+func (h *NamespaceTemplateHandler) applyClients(c *gin.Context) (*services.ApplyEngine, *services.K8sClient, bool) {
+	cluster, err := h.clusterService.GetCluster(parseClusterID(c.Param("clusterID")))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"code":    404,
+			"message": "集群不存在",
+		})
+		return nil, nil, false
+	}
+	k8sClient, err := h.k8sMgr.GetK8sClient(cluster)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    500,
+			"message": "获取K8s客户端失败: " + err.Error(),
+		})
+		return nil, nil, false
+	}
+	engine, err := services.NewApplyEngine(k8sClient)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    500,
+			"message": err.Error(),
+		})
+		return nil, nil, false
+	}
+	return engine, k8sClient, true
+}
+
+func (h *NamespaceTemplateHandler) templateFromParam(c *gin.Context) (*models.NamespaceTemplate, bool) {
+	id, ok := parseTemplateID(c)
+	if !ok {
+		return nil, false
+	}
+	tpl, err := h.templateService.Get(id)
+	if err != nil {
+		respondNamespaceTemplateError(c, err)
+		return nil, false
+	}
+	return tpl, true
+}
+
+func parseTemplateID(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "无效的模板ID",
+		})
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// requireClusterAdmin 校验当前用户在请求集群上为管理员权限，失败时直接写入 403 响应
+func requireClusterAdmin(c *gin.Context, message string) bool {
+	permission := middleware.GetClusterPermission(c)
+	if permission == nil || permission.PermissionType != models.PermissionTypeAdmin {
+		c.JSON(http.StatusForbidden, gin.H{
+			"code":    403,
+			"message": message,
+		})
+		return false
+	}
+	return true
+}
+
+// respondNamespaceTemplateError 将模板相关错误转换为对应的 HTTP 状态码
+func respondNamespaceTemplateError(c *gin.Context, err error) {
+	code := 0
+	switch {
+	case errors.Is(err, services.ErrNamespaceTemplateNotFound):
+		code = http.StatusNotFound
+	case errors.Is(err, services.ErrNamespaceTemplateExists), errors.Is(err, services.ErrNamespaceTemplateInUse):
+		code = http.StatusConflict
+	case errors.Is(err, services.ErrUnknownResourceType):
+		code = http.StatusBadRequest
+	}
+	if code == 0 {
+		respondWorkloadActionError(c, err)
+		return
+	}
+	c.Set("error_message", err.Error())
+	c.JSON(code, gin.H{
+		"code":    code,
+		"message": err.Error(),
+		"data":    nil,
+	})
+}
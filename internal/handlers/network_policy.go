@@ -0,0 +1,419 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/clay-wangzhi/KubePolaris/internal/k8s"
+	"github.com/clay-wangzhi/KubePolaris/internal/middleware"
+	"github.com/clay-wangzhi/KubePolaris/internal/models"
+	"github.com/clay-wangzhi/KubePolaris/internal/services"
+	"github.com/clay-wangzhi/KubePolaris/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// NetworkPolicyHandler NetworkPolicy 处理器
+// 查询与连通性评估基于 informer 缓存，写操作直接调用 API Server。
+type NetworkPolicyHandler struct {
+	db             *gorm.DB
+	clusterService *services.ClusterService
+	k8sMgr         *k8s.ClusterInformerManager
+}
+
+// NewNetworkPolicyHandler 创建 NetworkPolicy 处理器
+func NewNetworkPolicyHandler(db *gorm.DB, clusterService *services.ClusterService, k8sMgr *k8s.ClusterInformerManager) *NetworkPolicyHandler {
+	return &NetworkPolicyHandler{
+		db:             db,
+		clusterService: clusterService,
+		k8sMgr:         k8sMgr,
+	}
+}
+
+// NetworkPolicySimulateRequest 连通性评估请求
+// GET 请求通过查询参数评估现有策略；POST 请求可额外携带草稿策略，用于上线前验证。
+type NetworkPolicySimulateRequest struct {
+	SourceNamespace      string                       `form:"srcNamespace" json:"srcNamespace" binding:"required"`
+	SourcePod            string                       `form:"srcPod" json:"srcPod" binding:"required"`
+	DestinationNamespace string                       `form:"dstNamespace" json:"dstNamespace" binding:"required"`
+	DestinationPod       string                       `form:"dstPod" json:"dstPod" binding:"required"`
+	Port                 int32                        `form:"port" json:"port" binding:"required,min=1,max=65535"`
+	Protocol             string                       `form:"protocol" json:"protocol"`
+	DraftPolicies        []networkingv1.NetworkPolicy `form:"-" json:"draftPolicies"`
+}
+
+// ListNetworkPolicies 获取NetworkPolicy列表，支持 namespace、search 过滤
+func (h *NetworkPolicyHandler) ListNetworkPolicies(c *gin.Context) {
+	namespace := c.Query("namespace")
+	search := strings.ToLower(c.Query("search"))
+
+	nsInfo, hasAccess := middleware.CheckNamespacePermission(c, namespace)
+	if !hasAccess {
+		c.JSON(http.StatusForbidden, gin.H{
+			"code":    403,
+			"message": fmt.Sprintf("无权访问命名空间: %s", namespace),
+		})
+		return
+	}
+
+	cluster, ok := h.ensureCache(c)
+	if !ok {
+		return
+	}
+
+	listNamespace := namespace
+	if listNamespace == "_all_" {
+		listNamespace = ""
+	}
+	policies, ok := h.listPolicies(c, cluster, listNamespace)
+	if !ok {
+		return
+	}
+
+	items := make([]services.NetworkPolicyInfo, 0, len(policies))
+	for _, np := range policies {
+		if search != "" && !strings.Contains(strings.ToLower(np.Name), search) {
+			continue
+		}
+		items = append(items, services.ToNetworkPolicyInfo(np))
+	}
+
+	// 根据命名空间权限过滤
+	if !nsInfo.HasAllAccess && (namespace == "" || namespace == "_all_") {
+		items = middleware.FilterResourcesByNamespace(c, items, func(np services.NetworkPolicyInfo) string {
+			return np.Namespace
+		})
+	}
+	sort.Slice(items, func(i, j int) bool {
+		if items[i].Namespace != items[j].Namespace {
+			return items[i].Namespace < items[j].Namespace
+		}
+		return items[i].Name < items[j].Name
+	})
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "success",
+		"data": gin.H{
+			"items": items,
+			"total": len(items),
+		},
+	})
+}
+
+// GetNetworkPolicy 获取NetworkPolicy详情
+func (h *NetworkPolicyHandler) GetNetworkPolicy(c *gin.Context) {
+	namespace := c.Param("namespace")
+	if !checkNamespaceAccess(c, namespace) {
+		return
+	}
+	cluster, ok := h.ensureCache(c)
+	if !ok {
+		return
+	}
+
+	np, err := h.k8sMgr.GetNetworkPolicy(c.Request.Context(), cluster, namespace, c.Param("name"))
+	if err != nil {
+		code := http.StatusServiceUnavailable
+		if apierrors.IsNotFound(err) {
+			code = http.StatusNotFound
+		}
+		c.JSON(code, gin.H{
+			"code":    code,
+			"message": "获取NetworkPolicy失败: " + err.Error(),
+			"data":    nil,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "success",
+		"data":    np,
+	})
+}
+
+// CreateNetworkPolicy 创建NetworkPolicy
+func (h *NetworkPolicyHandler) CreateNetworkPolicy(c *gin.Context) {
+	var req services.NetworkPolicyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "参数错误: " + err.Error(),
+		})
+		return
+	}
+
+	// 命名空间来自请求体，单独校验
+	permission, ok := getClusterPermission(c)
+	if !ok {
+		return
+	}
+	if !permission.HasNamespaceAccess(req.Namespace) || !permission.CanPerformAction("networkpolicy:create") {
+		c.JSON(http.StatusForbidden, gin.H{
+			"code":    403,
+			"message": "无权限执行该操作",
+			"data":    nil,
+		})
+		return
+	}
+
+	logger.Info("创建NetworkPolicy: cluster=%s %s/%s", c.Param("clusterID"), req.Namespace, req.Name)
+
+	clientset, ok := workloadClientset(c, h.clusterService, h.k8sMgr)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	np, err := services.CreateNetworkPolicy(ctx, clientset, &req)
+	if err != nil {
+		respondWorkloadActionError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "NetworkPolicy创建成功",
+		"data":    services.ToNetworkPolicyInfo(np),
+	})
+}
+
+// UpdateNetworkPolicy 更新NetworkPolicy的标签、注解与规则
+func (h *NetworkPolicyHandler) UpdateNetworkPolicy(c *gin.Context) {
+	var req services.NetworkPolicyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "参数错误: " + err.Error(),
+		})
+		return
+	}
+	namespace := c.Param("namespace")
+	name := c.Param("name")
+
+	if !checkWorkloadPermission(c, "NetworkPolicy", "update") {
+		return
+	}
+
+	logger.Info("更新NetworkPolicy: cluster=%s %s/%s", c.Param("clusterID"), namespace, name)
+
+	clientset, ok := workloadClientset(c, h.clusterService, h.k8sMgr)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	prev, np, err := services.UpdateNetworkPolicy(ctx, clientset, namespace, name, &req)
+	if err != nil {
+		respondWorkloadActionError(c, err)
+		return
+	}
+	recordTypedRevision(c, h.db, prev, networkingv1.SchemeGroupVersion.WithKind("NetworkPolicy"), services.RevisionActionUpdate)
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "NetworkPolicy更新成功",
+		"data":    services.ToNetworkPolicyInfo(np),
+	})
+}
+
+// DeleteNetworkPolicy 删除NetworkPolicy
+func (h *NetworkPolicyHandler) DeleteNetworkPolicy(c *gin.Context) {
+	namespace := c.Param("namespace")
+	name := c.Param("name")
+
+	if !checkWorkloadPermission(c, "NetworkPolicy", "delete") {
+		return
+	}
+
+	logger.Info("删除NetworkPolicy: cluster=%s %s/%s", c.Param("clusterID"), namespace, name)
+
+	clientset, ok := workloadClientset(c, h.clusterService, h.k8sMgr)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	if err := services.DeleteNetworkPolicy(ctx, clientset, namespace, name); err != nil {
+		respondWorkloadActionError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "NetworkPolicy删除成功",
+		"data":    nil,
+	})
+}
+
+// SimulateConnectivity 评估源 Pod 能否访问目标 Pod 的指定端口
+func (h *NetworkPolicyHandler) SimulateConnectivity(c *gin.Context) {
+	var req NetworkPolicySimulateRequest
+	if err := c.ShouldBind(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "参数错误: " + err.Error(),
+		})
+		return
+	}
+	protocol := corev1.Protocol(strings.ToUpper(req.Protocol))
+	switch protocol {
+	case "":
+		protocol = corev1.ProtocolTCP
+	case corev1.ProtocolTCP, corev1.ProtocolUDP, corev1.ProtocolSCTP:
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "无效的协议: " + req.Protocol,
+		})
+		return
+	}
+
+	if !checkNamespaceAccess(c, req.SourceNamespace) || !checkNamespaceAccess(c, req.DestinationNamespace) {
+		return
+	}
+	cluster, ok := h.ensureCache(c)
+	if !ok {
+		return
+	}
+
+	podLister := h.k8sMgr.PodsLister(cluster.ID)
+	src, err := podLister.Pods(req.SourceNamespace).Get(req.SourcePod)
+	if err != nil {
+		h.respondCacheError(c, "获取源Pod失败", err)
+		return
+	}
+	dst, err := podLister.Pods(req.DestinationNamespace).Get(req.DestinationPod)
+	if err != nil {
+		h.respondCacheError(c, "获取目标Pod失败", err)
+		return
+	}
+
+	policies, ok := h.listPolicies(c, cluster, "")
+	if !ok {
+		return
+	}
+	policies, drafts, err := services.MergeDraftPolicies(policies, req.DraftPolicies)
+	if err != nil {
+		respondWorkloadActionError(c, err)
+		return
+	}
+	namespaces, err := h.k8sMgr.NamespacesLister(cluster.ID).List(labels.Everything())
+	if err != nil {
+		h.respondCacheError(c, "读取命名空间缓存失败", err)
+		return
+	}
+
+	result := services.EvaluateConnectivity(services.ConnectivityInput{
+		Source:      src,
+		Destination: dst,
+		Port:        req.Port,
+		Protocol:    protocol,
+		Policies:    policies,
+		Namespaces:  namespaces,
+		Drafts:      drafts,
+	})
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "success",
+		"data":    result,
+	})
+}
+
+// GetNamespaceIsolation 获取命名空间内各 Pod 的入站/出站隔离情况
+func (h *NetworkPolicyHandler) GetNamespaceIsolation(c *gin.Context) {
+	namespace := c.Query("namespace")
+	if namespace == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "namespace 不能为空",
+		})
+		return
+	}
+	if !checkNamespaceAccess(c, namespace) {
+		return
+	}
+	cluster, ok := h.ensureCache(c)
+	if !ok {
+		return
+	}
+
+	pods, err := h.k8sMgr.PodsLister(cluster.ID).Pods(namespace).List(labels.Everything())
+	if err != nil {
+		h.respondCacheError(c, "读取Pod缓存失败", err)
+		return
+	}
+	policies, ok := h.listPolicies(c, cluster, namespace)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "success",
+		"data":    services.BuildNamespaceIsolation(namespace, pods, policies),
+	})
+}
+
+// ensureCache 获取集群并等待 informer 缓存就绪，失败时直接写入响应
+func (h *NetworkPolicyHandler) ensureCache(c *gin.Context) (*models.Cluster, bool) {
+	cluster, err := h.clusterService.GetCluster(parseClusterID(c.Param("clusterID")))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"code":    404,
+			"message": "集群不存在",
+		})
+		return nil, false
+	}
+	if _, err := h.k8sMgr.EnsureAndWait(context.Background(), cluster, 5*time.Second); err != nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{
+			"code":    503,
+			"message": "informer 未就绪: " + err.Error(),
+		})
+		return nil, false
+	}
+	return cluster, true
+}
+
+// listPolicies 从按需启动的 NetworkPolicy 缓存读取列表，缓存未就绪（如无 list/watch 权限）时返回 503
+func (h *NetworkPolicyHandler) listPolicies(c *gin.Context, cluster *models.Cluster, namespace string) ([]*networkingv1.NetworkPolicy, bool) {
+	policies, err := h.k8sMgr.ListNetworkPolicies(c.Request.Context(), cluster, namespace)
+	if err != nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{
+			"code":    503,
+			"message": "读取NetworkPolicy缓存失败: " + err.Error(),
+			"data":    nil,
+		})
+		return nil, false
+	}
+	return policies, true
+}
+
+func (h *NetworkPolicyHandler) respondCacheError(c *gin.Context, message string, err error) {
+	code := http.StatusInternalServerError
+	if apierrors.IsNotFound(err) {
+		code = http.StatusNotFound
+	}
+	c.JSON(code, gin.H{
+		"code":    code,
+		"message": message + ": " + err.Error(),
+		"data":    nil,
+	})
+}
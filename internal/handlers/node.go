@@ -25,10 +25,11 @@ type NodeHandler struct {
 	k8sMgr           *k8s.ClusterInformerManager
 	promService      *services.PrometheusService
 	monitoringCfgSvc *services.MonitoringConfigService
+	taskSvc          *services.TaskService
 }
 
 // NewNodeHandler 创建节点处理器
-func NewNodeHandler(db *gorm.DB, cfg *config.Config, clusterService *services.ClusterService, k8sMgr *k8s.ClusterInformerManager, promService *services.PrometheusService, monitoringCfgSvc *services.MonitoringConfigService) *NodeHandler {
+func NewNodeHandler(db *gorm.DB, cfg *config.Config, clusterService *services.ClusterService, k8sMgr *k8s.ClusterInformerManager, promService *services.PrometheusService, monitoringCfgSvc *services.MonitoringConfigService, taskSvc *services.TaskService) *NodeHandler {
 	return &NodeHandler{
 		db:               db,
 		cfg:              cfg,
@@ -36,6 +37,7 @@ func NewNodeHandler(db *gorm.DB, cfg *config.Config, clusterService *services.Cl
 		k8sMgr:           k8sMgr,
 		promService:      promService,
 		monitoringCfgSvc: monitoringCfgSvc,
+		taskSvc:          taskSvc,
 	}
 }
 
@@ -504,58 +506,32 @@ func (h *NodeHandler) UncordonNode(c *gin.Context) {
 	})
 }
 
-// DrainNode 驱逐节点
+// DrainNodeRequest 驱逐节点请求
+type DrainNodeRequest struct {
+	services.DrainOptions
+	DeleteLocalData bool `json:"deleteLocalData"` // 兼容旧参数，等同于 deleteEmptyDirData
+}
+
+// DrainNode 驱逐节点（通过 Eviction API，遵守 PodDisruptionBudget）
+// 驱逐耗时较长，以后台任务执行，返回任务信息，进度通过 /ws/tasks/:id 推送。
 func (h *NodeHandler) DrainNode(c *gin.Context) {
 	clusterId := c.Param("clusterID")
 	name := c.Param("name")
 	logger.Info("驱逐节点: %s/%s", clusterId, name)
 
 	// 解析请求参数
-	var options map[string]interface{}
-	if err := c.ShouldBindJSON(&options); err != nil {
+	req := DrainNodeRequest{DrainOptions: services.DrainOptions{IgnoreDaemonSets: true}}
+	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{
 			"code":    400,
 			"message": "参数解析失败: " + err.Error(),
 		})
 		return
 	}
+	opts := req.DrainOptions
+	opts.DeleteEmptyDirData = opts.DeleteEmptyDirData || req.DeleteLocalData
 
-	// 从集群服务获取集群信息
-	clusterID := parseClusterID(clusterId)
-	cluster, err := h.clusterService.GetCluster(clusterID)
-	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{
-			"code":    404,
-			"message": "集群不存在",
-		})
-		return
-	}
-
-	// 获取缓存的 K8s 客户端
-	k8sClient, err := h.k8sMgr.GetK8sClient(cluster)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"code":    500,
-			"message": "获取K8s客户端失败: " + err.Error(),
-		})
-		return
-	}
-
-	// 驱逐节点
-	err = k8sClient.DrainNode(name, options)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"code":    500,
-			"message": "驱逐节点失败: " + err.Error(),
-		})
-		return
-	}
-
-	c.JSON(http.StatusOK, gin.H{
-		"code":    200,
-		"message": "节点驱逐成功",
-		"data":    nil,
-	})
+	h.submitTask(c, TaskTypeNodeDrain, name, NodeDrainTaskParams{Node: name, Options: opts})
 }
 
 // 获取节点内部IP
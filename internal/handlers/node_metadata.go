@@ -0,0 +1,171 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/clay-wangzhi/KubePolaris/internal/services"
+	"github.com/clay-wangzhi/KubePolaris/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// NodeMetadataRequest 修改节点标签、注解与污点请求
+type NodeMetadataRequest struct {
+	services.NodeMetadataPatch
+	DryRun bool `json:"dryRun"` // 仅预览变更结果，不提交到集群
+}
+
+// NodeBatchMetadataRequest 批量修改节点标签、注解与污点请求
+type NodeBatchMetadataRequest struct {
+	Nodes []string `json:"nodes" binding:"required,min=1"`
+	NodeMetadataRequest
+}
+
+// nodeMetadataChange 审计记录中单个节点的变更前后值
+type nodeMetadataChange struct {
+	Node   string                 `json:"node"`
+	Before *services.NodeMetadata `json:"before"`
+	After  *services.NodeMetadata `json:"after"`
+}
+
+// UpdateNodeMetadata 修改单个节点的标签、注解与污点
+func (h *NodeHandler) UpdateNodeMetadata(c *gin.Context) {
+	name := c.Param("name")
+	if !checkNodeMetadataPermission(c) {
+		return
+	}
+	var req NodeMetadataRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "参数解析失败: " + err.Error(),
+		})
+		return
+	}
+	logger.Info("修改节点元数据: cluster=%s node=%s dryRun=%t", c.Param("clusterID"), name, req.DryRun)
+
+	clientset, ok := workloadClientset(c, h.clusterService, h.k8sMgr)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 30*time.Second)
+	defer cancel()
+
+	result, err := services.PatchNodeMetadata(ctx, clientset, name, &req.NodeMetadataPatch, req.DryRun)
+	if err != nil {
+		respondWorkloadActionError(c, err)
+		return
+	}
+	if !req.DryRun && result.Changed {
+		c.Set("audit_changes", []nodeMetadataChange{{Node: result.Node, Before: result.Before, After: result.After}})
+	}
+
+	message := "节点元数据修改成功"
+	if req.DryRun {
+		message = "预检通过"
+	} else if !result.Changed {
+		message = "节点元数据无变化"
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": message,
+		"data":    result,
+	})
+}
+
+// BatchUpdateNodeMetadata 批量修改节点的标签、注解与污点
+// 逐个节点应用并返回结果，存在失败节点时返回 422。
+func (h *NodeHandler) BatchUpdateNodeMetadata(c *gin.Context) {
+	if !checkNodeMetadataPermission(c) {
+		return
+	}
+	var req NodeBatchMetadataRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "参数解析失败: " + err.Error(),
+		})
+		return
+	}
+	logger.Info("批量修改节点元数据: cluster=%s nodes=%v dryRun=%t", c.Param("clusterID"), req.Nodes, req.DryRun)
+
+	clientset, ok := workloadClientset(c, h.clusterService, h.k8sMgr)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 2*time.Minute)
+	defer cancel()
+
+	result, err := services.PatchNodesMetadata(ctx, clientset, req.Nodes, &req.NodeMetadataPatch, req.DryRun)
+	if err != nil {
+		respondWorkloadActionError(c, err)
+		return
+	}
+
+	if !req.DryRun {
+		changes := []nodeMetadataChange{}
+		for _, r := range result.Results {
+			if r.Changed {
+				changes = append(changes, nodeMetadataChange{Node: r.Node, Before: r.Before, After: r.After})
+			}
+		}
+		if len(changes) > 0 {
+			c.Set("audit_changes", changes)
+		}
+	}
+
+	if result.Failed > 0 {
+		c.Set("error_message", nodeMetadataErrorSummary(result))
+		c.JSON(http.StatusUnprocessableEntity, gin.H{
+			"code":    422,
+			"message": fmt.Sprintf("%d 个节点修改失败", result.Failed),
+			"data":    result,
+		})
+		return
+	}
+
+	message := "节点元数据批量修改成功"
+	if req.DryRun {
+		message = "预检通过"
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": message,
+		"data":    result,
+	})
+}
+
+// checkNodeMetadataPermission 节点是集群级资源，修改标签、注解与污点会影响所有命名空间的调度，
+// 需要全部命名空间的访问权限及 node:update 操作权限，无权限时直接写入响应
+func checkNodeMetadataPermission(c *gin.Context) bool {
+	permission, ok := getClusterPermission(c)
+	if !ok {
+		return false
+	}
+	if !permission.HasAllNamespaceAccess() || !permission.CanPerformAction("node:update") {
+		c.JSON(http.StatusForbidden, gin.H{
+			"code":    403,
+			"message": "无权限修改节点元数据",
+			"data":    nil,
+		})
+		return false
+	}
+	return true
+}
+
+// nodeMetadataErrorSummary 汇总失败节点的错误信息，用于操作审计
+func nodeMetadataErrorSummary(result *services.NodeMetadataBatchResult) string {
+	var failed []string
+	for _, r := range result.Results {
+		if !r.Success {
+			failed = append(failed, r.Node+": "+r.Error)
+		}
+	}
+	return truncateAuditError(strings.Join(failed, "; "))
+}
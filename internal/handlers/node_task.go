@@ -0,0 +1,195 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/clay-wangzhi/KubePolaris/internal/k8s"
+	"github.com/clay-wangzhi/KubePolaris/internal/models"
+	"github.com/clay-wangzhi/KubePolaris/internal/services"
+	"github.com/clay-wangzhi/KubePolaris/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+	"k8s.io/client-go/kubernetes"
+)
+
+// 节点相关的后台任务类型
+const (
+	TaskTypeNodeDrain       = "node_drain"
+	TaskTypeNodeBatchCordon = "node_batch_cordon"
+)
+
+// taskActions 各任务类型提交时所需的操作权限，非提交者取消任务时需具备同样的权限
+var taskActions = map[string]string{
+	TaskTypeNodeDrain:       "node:drain",
+	TaskTypeNodeBatchCordon: "node:cordon",
+}
+
+// NodeDrainTaskParams 节点驱逐任务参数
+type NodeDrainTaskParams struct {
+	Node    string                `json:"node"`
+	Options services.DrainOptions `json:"options"`
+}
+
+// NodeBatchCordonRequest 批量封锁/解封节点请求
+type NodeBatchCordonRequest struct {
+	Nodes []string `json:"nodes" binding:"required,min=1"`
+}
+
+// NodeBatchCordonTaskParams 批量封锁/解封任务参数
+type NodeBatchCordonTaskParams struct {
+	Nodes         []string `json:"nodes"`
+	Unschedulable bool     `json:"unschedulable"`
+}
+
+// NodeCordonResult 单个节点的封锁/解封结果
+type NodeCordonResult struct {
+	Node    string `json:"node"`
+	Success bool   `json:"success"`
+	Error   string `json:"error,omitempty"`
+}
+
+// RegisterNodeTaskRunners 注册节点驱逐、批量封锁等后台任务的执行函数
+func RegisterNodeTaskRunners(taskSvc *services.TaskService, clusterService *services.ClusterService, k8sMgr *k8s.ClusterInformerManager) {
+	taskSvc.RegisterRunner(TaskTypeNodeDrain, func(ctx context.Context, task *models.Task, reporter *services.TaskReporter) (interface{}, error) {
+		var params NodeDrainTaskParams
+		if err := services.DecodeTaskParams(task, &params); err != nil {
+			return nil, err
+		}
+		clientset, err := taskClientset(clusterService, k8sMgr, task.ClusterID)
+		if err != nil {
+			return nil, err
+		}
+
+		reporter.StartStep(fmt.Sprintf("封锁并驱逐节点 %s", params.Node))
+		opts := params.Options
+		opts.OnPodResult = func(pod services.DrainPodResult, done, total int) {
+			level := services.TaskLogInfo
+			message := fmt.Sprintf("%s/%s: %s", pod.Namespace, pod.Name, pod.Status)
+			if pod.Status == services.DrainPodBlocked || pod.Status == services.DrainPodFailed {
+				level = services.TaskLogWarn
+			}
+			if pod.Reason != "" {
+				message += "（" + pod.Reason + "）"
+			}
+			if pod.BlockingPDB != "" {
+				message += "，阻止驱逐的PDB: " + pod.BlockingPDB
+			}
+			reporter.Logf(level, "%s", message)
+			if total > 0 {
+				reporter.SetProgress(done * 100 / total)
+			}
+		}
+		result, err := services.DrainNode(ctx, clientset, params.Node, opts)
+		if result != nil {
+			reporter.Logf(services.TaskLogInfo, "已驱逐 %d，跳过 %d，被PDB阻止 %d，失败 %d",
+				result.Evicted, result.Skipped, result.Blocked, result.Failed)
+		}
+		return result, err
+	})
+
+	taskSvc.RegisterRunner(TaskTypeNodeBatchCordon, func(ctx context.Context, task *models.Task, reporter *services.TaskReporter) (interface{}, error) {
+		var params NodeBatchCordonTaskParams
+		if err := services.DecodeTaskParams(task, &params); err != nil {
+			return nil, err
+		}
+		clientset, err := taskClientset(clusterService, k8sMgr, task.ClusterID)
+		if err != nil {
+			return nil, err
+		}
+
+		action := "封锁"
+		if !params.Unschedulable {
+			action = "解封"
+		}
+		results := make([]NodeCordonResult, 0, len(params.Nodes))
+		failed := 0
+		for i, node := range params.Nodes {
+			if ctx.Err() != nil {
+				return results, ctx.Err()
+			}
+			reporter.StartStep(fmt.Sprintf("%s节点 %s", action, node))
+			err := services.SetNodeUnschedulable(ctx, clientset, node, params.Unschedulable)
+			reporter.FinishStep(err)
+			result := NodeCordonResult{Node: node, Success: err == nil}
+			if err != nil {
+				failed++
+				result.Error = err.Error()
+				reporter.Logf(services.TaskLogError, "%s节点 %s 失败: %v", action, node, err)
+			}
+			results = append(results, result)
+			reporter.SetProgress((i + 1) * 100 / len(params.Nodes))
+		}
+		if failed > 0 {
+			return results, fmt.Errorf("%d 个节点%s失败", failed, action)
+		}
+		return results, nil
+	})
+}
+
+// taskClientset 获取任务所属集群的 clientset
+func taskClientset(clusterService *services.ClusterService, k8sMgr *k8s.ClusterInformerManager, clusterID uint) (kubernetes.Interface, error) {
+	cluster, err := clusterService.GetCluster(clusterID)
+	if err != nil {
+		return nil, fmt.Errorf("集群不存在: %w", err)
+	}
+	k8sClient, err := k8sMgr.GetK8sClient(cluster)
+	if err != nil {
+		return nil, fmt.Errorf("获取K8s客户端失败: %w", err)
+	}
+	return k8sClient.GetClientset(), nil
+}
+
+// BatchCordonNodes 批量封锁节点（后台任务）
+func (h *NodeHandler) BatchCordonNodes(c *gin.Context) {
+	h.submitBatchCordon(c, true)
+}
+
+// BatchUncordonNodes 批量解封节点（后台任务）
+func (h *NodeHandler) BatchUncordonNodes(c *gin.Context) {
+	h.submitBatchCordon(c, false)
+}
+
+func (h *NodeHandler) submitBatchCordon(c *gin.Context, unschedulable bool) {
+	var req NodeBatchCordonRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "参数解析失败: " + err.Error(),
+		})
+		return
+	}
+	logger.Info("批量封锁/解封节点: cluster=%s nodes=%v unschedulable=%t", c.Param("clusterID"), req.Nodes, unschedulable)
+
+	target := req.Nodes[0]
+	if len(req.Nodes) > 1 {
+		target = fmt.Sprintf("%s 等 %d 个节点", req.Nodes[0], len(req.Nodes))
+	}
+	h.submitTask(c, TaskTypeNodeBatchCordon, target, NodeBatchCordonTaskParams{Nodes: req.Nodes, Unschedulable: unschedulable})
+}
+
+// submitTask 提交后台任务并返回任务信息
+func (h *NodeHandler) submitTask(c *gin.Context, taskType, target string, params interface{}) {
+	task, err := h.taskSvc.Submit(services.TaskSubmitRequest{
+		ClusterID: parseClusterID(c.Param("clusterID")),
+		Type:      taskType,
+		Target:    target,
+		Params:    params,
+		UserID:    c.GetUint("user_id"),
+		Username:  c.GetString("username"),
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    500,
+			"message": "提交任务失败: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "任务已提交",
+		"data":    task,
+	})
+}
@@ -5,6 +5,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"regexp"
+	"strings"
 	"testing"
 	"time"
 
@@ -17,6 +18,7 @@ import (
 	"gorm.io/gorm/logger"
 
 	"github.com/clay-wangzhi/KubePolaris/internal/config"
+	"github.com/clay-wangzhi/KubePolaris/internal/models"
 	"github.com/clay-wangzhi/KubePolaris/internal/services"
 )
 
@@ -49,7 +51,7 @@ func (s *NodeHandlerTestSuite) SetupTest() {
 
 	cfg := &config.Config{}
 	clusterService := services.NewClusterService(gormDB)
-	s.handler = NewNodeHandler(gormDB, cfg, clusterService, nil, nil, nil)
+	s.handler = NewNodeHandler(gormDB, cfg, clusterService, nil, nil, nil, nil)
 
 	s.router = gin.New()
 	s.router.GET("/api/clusters/:clusterID/nodes", s.handler.GetNodes)
@@ -123,3 +125,30 @@ func (s *NodeHandlerTestSuite) TestGetNode_ClusterExists() {
 func TestNodeHandlerSuite(t *testing.T) {
 	suite.Run(t, new(NodeHandlerTestSuite))
 }
+
+func TestNodeMetadataRequiresClusterWideNodePermission(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	h := &NodeHandler{}
+
+	for _, permission := range []*models.ClusterPermission{
+		{PermissionType: models.PermissionTypeDev, Namespaces: `["*"]`},
+		{PermissionType: models.PermissionTypeOps, Namespaces: `["*"]`},
+		{PermissionType: models.PermissionTypeCustom, Namespaces: `["app"]`},
+	} {
+		router := gin.New()
+		setPermission := func(c *gin.Context) {
+			c.Set("cluster_permission", permission)
+		}
+		router.PUT("/clusters/:clusterID/nodes/:name/metadata", setPermission, h.UpdateNodeMetadata)
+		router.POST("/clusters/:clusterID/nodes/batch/metadata", setPermission, h.BatchUpdateNodeMetadata)
+
+		for _, req := range []*http.Request{
+			httptest.NewRequest(http.MethodPut, "/clusters/1/nodes/node-1/metadata", strings.NewReader(`{"taints":[]}`)),
+			httptest.NewRequest(http.MethodPost, "/clusters/1/nodes/batch/metadata", strings.NewReader(`{"nodes":["node-1"]}`)),
+		} {
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			assert.Equal(t, http.StatusForbidden, w.Code, "%s %s", permission.PermissionType, req.Method)
+		}
+	}
+}
@@ -58,7 +58,7 @@ func (h *OMHandler) GetHealthDiagnosis(c *gin.Context) {
 	}
 
 	// 创建 K8s 客户端
-	if cluster.KubeconfigEnc == "" && cluster.SATokenEnc == "" {
+	if cluster.KubeconfigEnc == "" && cluster.SATokenEnc == "" && !cluster.IsAgentMode() {
 		c.JSON(http.StatusBadRequest, gin.H{
 			"code":    400,
 			"message": "集群未配置认证信息",
@@ -160,7 +160,7 @@ func (h *OMHandler) GetResourceTop(c *gin.Context) {
 	}
 
 	// 创建 K8s 客户端
-	if cluster.KubeconfigEnc == "" && cluster.SATokenEnc == "" {
+	if cluster.KubeconfigEnc == "" && cluster.SATokenEnc == "" && !cluster.IsAgentMode() {
 		c.JSON(http.StatusBadRequest, gin.H{
 			"code":    400,
 			"message": "集群未配置认证信息",
@@ -243,7 +243,7 @@ func (h *OMHandler) GetControlPlaneStatus(c *gin.Context) {
 	}
 
 	// 创建 K8s 客户端
-	if cluster.KubeconfigEnc == "" && cluster.SATokenEnc == "" {
+	if cluster.KubeconfigEnc == "" && cluster.SATokenEnc == "" && !cluster.IsAgentMode() {
 		c.JSON(http.StatusBadRequest, gin.H{
 			"code":    400,
 			"message": "集群未配置认证信息",
@@ -45,12 +45,18 @@ func NewOverviewHandler(
 // @Tags Overview
 // @Accept json
 // @Produce json
+// @Param clusterSelector query string false "集群标签选择器，如 env=prod"
 // @Success 200 {object} services.OverviewStatsResponse
 // @Router /api/v1/overview/stats [get]
 func (h *OverviewHandler) GetStats(c *gin.Context) {
+	selector, ok := parseClusterSelector(c)
+	if !ok {
+		return
+	}
+
 	logger.Info("获取总览统计数据")
 
-	stats, err := h.overviewService.GetOverviewStats(c.Request.Context())
+	stats, err := h.overviewService.GetOverviewStats(c.Request.Context(), selector)
 	if err != nil {
 		logger.Error("获取总览统计数据失败", "error", err)
 		c.JSON(http.StatusInternalServerError, gin.H{
@@ -74,12 +80,18 @@ func (h *OverviewHandler) GetStats(c *gin.Context) {
 // @Tags Overview
 // @Accept json
 // @Produce json
+// @Param clusterSelector query string false "集群标签选择器，如 env=prod"
 // @Success 200 {object} services.ResourceUsageResponse
 // @Router /api/v1/overview/resource-usage [get]
 func (h *OverviewHandler) GetResourceUsage(c *gin.Context) {
+	selector, ok := parseClusterSelector(c)
+	if !ok {
+		return
+	}
+
 	logger.Info("获取资源使用率")
 
-	usage, err := h.overviewService.GetResourceUsage(c.Request.Context())
+	usage, err := h.overviewService.GetResourceUsage(c.Request.Context(), selector)
 	if err != nil {
 		logger.Error("获取资源使用率失败", "error", err)
 		c.JSON(http.StatusInternalServerError, gin.H{
@@ -103,12 +115,18 @@ func (h *OverviewHandler) GetResourceUsage(c *gin.Context) {
 // @Tags Overview
 // @Accept json
 // @Produce json
+// @Param clusterSelector query string false "集群标签选择器，如 env=prod"
 // @Success 200 {object} services.ResourceDistributionResponse
 // @Router /api/v1/overview/distribution [get]
 func (h *OverviewHandler) GetDistribution(c *gin.Context) {
+	selector, ok := parseClusterSelector(c)
+	if !ok {
+		return
+	}
+
 	logger.Info("获取资源分布")
 
-	distribution, err := h.overviewService.GetResourceDistribution(c.Request.Context())
+	distribution, err := h.overviewService.GetResourceDistribution(c.Request.Context(), selector)
 	if err != nil {
 		logger.Error("获取资源分布失败", "error", err)
 		c.JSON(http.StatusInternalServerError, gin.H{
@@ -134,16 +152,22 @@ func (h *OverviewHandler) GetDistribution(c *gin.Context) {
 // @Produce json
 // @Param timeRange query string false "时间范围: 7d, 30d" default(7d)
 // @Param step query string false "步长: 1h, 6h, 1d" default(1h)
+// @Param clusterSelector query string false "集群标签选择器，如 env=prod"
 // @Success 200 {object} services.TrendResponse
 // @Router /api/v1/overview/trends [get]
 func (h *OverviewHandler) GetTrends(c *gin.Context) {
+	selector, ok := parseClusterSelector(c)
+	if !ok {
+		return
+	}
+
 	startTime := time.Now()
 	timeRange := c.DefaultQuery("timeRange", "7d")
 	step := c.DefaultQuery("step", "")
 
 	logger.Info("获取趋势数据开始", "timeRange", timeRange, "step", step)
 
-	trends, err := h.overviewService.GetTrends(c.Request.Context(), timeRange, step)
+	trends, err := h.overviewService.GetTrends(c.Request.Context(), timeRange, step, selector)
 
 	elapsed := time.Since(startTime)
 	logger.Info("获取趋势数据完成", "耗时", elapsed.String())
@@ -172,15 +196,21 @@ func (h *OverviewHandler) GetTrends(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Param limit query int false "返回数量限制" default(20)
+// @Param clusterSelector query string false "集群标签选择器，如 env=prod"
 // @Success 200 {array} services.AbnormalWorkload
 // @Router /api/v1/overview/abnormal-workloads [get]
 func (h *OverviewHandler) GetAbnormalWorkloads(c *gin.Context) {
+	selector, ok := parseClusterSelector(c)
+	if !ok {
+		return
+	}
+
 	limitStr := c.DefaultQuery("limit", "20")
 	limit, _ := strconv.Atoi(limitStr)
 
 	logger.Info("获取异常工作负载", "limit", limit)
 
-	workloads, err := h.overviewService.GetAbnormalWorkloads(c.Request.Context(), limit)
+	workloads, err := h.overviewService.GetAbnormalWorkloads(c.Request.Context(), limit, selector)
 	if err != nil {
 		logger.Error("获取异常工作负载失败", "error", err)
 		c.JSON(http.StatusInternalServerError, gin.H{
@@ -204,12 +234,18 @@ func (h *OverviewHandler) GetAbnormalWorkloads(c *gin.Context) {
 // @Tags Overview
 // @Accept json
 // @Produce json
+// @Param clusterSelector query string false "集群标签选择器，如 env=prod"
 // @Success 200 {object} services.GlobalAlertStats
 // @Router /api/v1/overview/alert-stats [get]
 func (h *OverviewHandler) GetAlertStats(c *gin.Context) {
+	selector, ok := parseClusterSelector(c)
+	if !ok {
+		return
+	}
+
 	logger.Info("获取全局告警统计")
 
-	stats, err := h.overviewService.GetGlobalAlertStats(c.Request.Context())
+	stats, err := h.overviewService.GetGlobalAlertStats(c.Request.Context(), selector)
 	if err != nil {
 		logger.Error("获取全局告警统计失败", "error", err)
 		c.JSON(http.StatusInternalServerError, gin.H{
@@ -0,0 +1,88 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/clay-wangzhi/KubePolaris/internal/services"
+	"github.com/clay-wangzhi/KubePolaris/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ListDebugContainers 获取Pod中的临时调试容器
+func (h *PodHandler) ListDebugContainers(c *gin.Context) {
+	if !checkNamespaceAccess(c, c.Param("namespace")) {
+		return
+	}
+	clientset, ok := workloadClientset(c, h.clusterService, h.k8sMgr)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	pod, err := clientset.CoreV1().Pods(c.Param("namespace")).Get(ctx, c.Param("name"), metav1.GetOptions{})
+	if err != nil {
+		respondWorkloadActionError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "获取成功",
+		"data": gin.H{
+			"items":        services.ListDebugContainers(pod),
+			"defaultImage": h.cfg.K8s.DebugImage,
+		},
+	})
+}
+
+// CreateDebugContainer 为运行中的Pod添加临时调试容器
+// 容器启动后通过 /ws/clusters/:clusterID/pods/:namespace/:name/debug/:container/terminal 接入
+func (h *PodHandler) CreateDebugContainer(c *gin.Context) {
+	if !checkWorkloadPermission(c, "Pod", "debug") {
+		return
+	}
+
+	var req services.DebugContainerRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "请求参数错误: " + err.Error(),
+			"data":    nil,
+		})
+		return
+	}
+	if req.Image == "" {
+		req.Image = h.cfg.K8s.DebugImage
+	}
+
+	clientset, ok := workloadClientset(c, h.clusterService, h.k8sMgr)
+	if !ok {
+		return
+	}
+
+	namespace := c.Param("namespace")
+	name := c.Param("name")
+	logger.Info("添加临时调试容器: %s/%s/%s, 镜像: %s, 目标容器: %s", c.Param("clusterID"), namespace, name, req.Image, req.TargetContainer)
+
+	// 需等待镜像拉取与容器启动
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Minute)
+	defer cancel()
+
+	info, err := services.AddDebugContainer(ctx, clientset, namespace, name, &req)
+	if err != nil {
+		respondWorkloadActionError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "调试容器已启动",
+		"data":    info,
+	})
+}
@@ -43,6 +43,7 @@ type PodTerminalSession struct {
 	Namespace      string
 	PodName        string
 	Container      string
+	Attach         bool // 附加到容器主进程（临时调试容器），而非 exec shell
 	Conn           *websocket.Conn
 	Context        context.Context
 	Cancel         context.CancelFunc
@@ -89,10 +90,23 @@ func NewPodTerminalHandler(clusterService *services.ClusterService, auditService
 
 // HandlePodTerminal 处理Pod终端WebSocket连接
 func (h *PodTerminalHandler) HandlePodTerminal(c *gin.Context) {
+	h.serveTerminal(c, c.DefaultQuery("container", ""), false)
+}
+
+// HandlePodDebugTerminal 处理临时调试容器终端WebSocket连接
+// 调试容器以 stdin/tty 方式运行镜像默认进程，通过 attach 接入，不依赖业务镜像中的 shell
+func (h *PodTerminalHandler) HandlePodDebugTerminal(c *gin.Context) {
+	if !checkWorkloadPermission(c, "Pod", "debug") {
+		return
+	}
+	h.serveTerminal(c, c.Param("container"), true)
+}
+
+// serveTerminal 建立终端会话；attach 为 true 时附加到容器主进程，否则在容器中执行探测到的 shell
+func (h *PodTerminalHandler) serveTerminal(c *gin.Context, container string, attach bool) {
 	clusterID := c.Param("clusterID")
 	namespace := c.Param("namespace")
 	podName := c.Param("name")
-	container := c.DefaultQuery("container", "")
 	userID := c.GetUint("user_id") // 从JWT中获取用户ID
 
 	// 获取集群信息
@@ -113,7 +127,9 @@ func (h *PodTerminalHandler) HandlePodTerminal(c *gin.Context) {
 	if h.auditService != nil {
 		// 检查是否是 kubectl 模式（由 kubectl_pod_terminal 设置）
 		terminalType := services.TerminalTypePod
-		if t, exists := c.Get("terminal_type"); exists && t == "kubectl" {
+		if attach {
+			terminalType = services.TerminalTypePodDebug
+		} else if t, exists := c.Get("terminal_type"); exists && t == "kubectl" {
 			terminalType = services.TerminalTypeKubectl
 		}
 
@@ -156,6 +172,7 @@ func (h *PodTerminalHandler) HandlePodTerminal(c *gin.Context) {
 		Namespace:      namespace,
 		PodName:        podName,
 		Container:      container,
+		Attach:         attach,
 		Conn:           conn,
 		Context:        ctx,
 		Cancel:         cancel,
@@ -193,11 +210,14 @@ func (h *PodTerminalHandler) HandlePodTerminal(c *gin.Context) {
 		return
 	}
 
-	// 查找可用的shell
-	shell, err := h.findAvailableShell(client, k8sConfig, session)
-	if err != nil {
-		h.sendMessage(conn, "error", fmt.Sprintf("未找到可用的shell: %v", err))
-		return
+	// 查找可用的shell（attach 模式直接接入容器主进程）
+	shell := ""
+	if !attach {
+		shell, err = h.findAvailableShell(client, k8sConfig, session)
+		if err != nil {
+			h.sendMessage(conn, "error", fmt.Sprintf("未找到可用的shell: %v", err))
+			return
+		}
 	}
 
 	// 启动Pod终端连接
@@ -211,7 +231,11 @@ func (h *PodTerminalHandler) HandlePodTerminal(c *gin.Context) {
 	if container != "" {
 		containerInfo = fmt.Sprintf(" (container: %s)", container)
 	}
-	h.sendMessage(conn, "connected", fmt.Sprintf("Connected to pod %s/%s%s using %s", namespace, podName, containerInfo, shell))
+	if attach {
+		h.sendMessage(conn, "connected", fmt.Sprintf("Attached to debug container of pod %s/%s%s, press enter if you don't see a command prompt", namespace, podName, containerInfo))
+	} else {
+		h.sendMessage(conn, "connected", fmt.Sprintf("Connected to pod %s/%s%s using %s", namespace, podName, containerInfo, shell))
+	}
 
 	// 处理WebSocket消息
 	for {
@@ -269,7 +293,7 @@ func (h *PodTerminalHandler) hasShellInContainer(client *kubernetes.Clientset, k
 		Stdout:    true,
 	}, scheme.ParameterCodec)
 
-	exec, err := remotecommand.NewSPDYExecutor(k8sConfig, "POST", req.URL())
+	exec, err := services.NewSPDYExecutor(k8sConfig, "POST", req.URL())
 	if err != nil {
 		return false
 	}
@@ -287,7 +311,7 @@ func (h *PodTerminalHandler) hasShellInContainer(client *kubernetes.Clientset, k
 	return strings.HasSuffix(result, shell)
 }
 
-// startPodTerminal 启动Pod终端连接
+// startPodTerminal 启动Pod终端连接，attach 会话忽略 shell 参数
 func (h *PodTerminalHandler) startPodTerminal(client *kubernetes.Clientset, k8sConfig *rest.Config, session *PodTerminalSession, shell string) error {
 	// 创建管道
 	stdinReader, stdinWriter := io.Pipe()
@@ -323,19 +347,28 @@ func (h *PodTerminalHandler) startPodTerminal(client *kubernetes.Clientset, k8sC
 		req := client.CoreV1().RESTClient().Post().
 			Resource("pods").
 			Name(session.PodName).
-			Namespace(session.Namespace).
-			SubResource("exec")
-
-		req.VersionedParams(&v1.PodExecOptions{
-			Container: session.Container,
-			Command:   []string{shell},
-			Stdin:     true,
-			Stdout:    true,
-			Stderr:    true,
-			TTY:       true,
-		}, scheme.ParameterCodec)
-
-		exec, err := remotecommand.NewSPDYExecutor(k8sConfig, "POST", req.URL())
+			Namespace(session.Namespace)
+
+		if session.Attach {
+			req.SubResource("attach").VersionedParams(&v1.PodAttachOptions{
+				Container: session.Container,
+				Stdin:     true,
+				Stdout:    true,
+				Stderr:    true,
+				TTY:       true,
+			}, scheme.ParameterCodec)
+		} else {
+			req.SubResource("exec").VersionedParams(&v1.PodExecOptions{
+				Container: session.Container,
+				Command:   []string{shell},
+				Stdin:     true,
+				Stdout:    true,
+				Stderr:    true,
+				TTY:       true,
+			}, scheme.ParameterCodec)
+		}
+
+		exec, err := services.NewSPDYExecutor(k8sConfig, "POST", req.URL())
 		if err != nil {
 			h.sendMessage(session.Conn, "error", fmt.Sprintf("创建执行器失败: %v", err))
 			return
@@ -542,6 +575,11 @@ func (h *PodTerminalHandler) closeSession(session *PodTerminalSession) {
 
 // createK8sConfig 创建Kubernetes配置
 func (h *PodTerminalHandler) createK8sConfig(cluster *models.Cluster) (*rest.Config, error) {
+	// Agent 模式经反向隧道访问
+	if cluster.IsAgentMode() {
+		return services.NewAgentRESTConfig(cluster.ID), nil
+	}
+
 	// 优先使用 Kubeconfig 方式
 	if cluster.KubeconfigEnc != "" {
 		config, err := clientcmd.RESTConfigFromKubeConfig([]byte(cluster.KubeconfigEnc))
@@ -0,0 +1,345 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/clay-wangzhi/KubePolaris/internal/k8s"
+	"github.com/clay-wangzhi/KubePolaris/internal/services"
+	"github.com/clay-wangzhi/KubePolaris/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ResourceQuotaHandler 命名空间 ResourceQuota 与 LimitRange 处理器
+type ResourceQuotaHandler struct {
+	db             *gorm.DB
+	clusterService *services.ClusterService
+	k8sMgr         *k8s.ClusterInformerManager
+}
+
+// NewResourceQuotaHandler 创建 ResourceQuota 处理器
+func NewResourceQuotaHandler(db *gorm.DB, clusterService *services.ClusterService, k8sMgr *k8s.ClusterInformerManager) *ResourceQuotaHandler {
+	return &ResourceQuotaHandler{
+		db:             db,
+		clusterService: clusterService,
+		k8sMgr:         k8sMgr,
+	}
+}
+
+// ListResourceQuotas 获取命名空间的 ResourceQuota 列表
+func (h *ResourceQuotaHandler) ListResourceQuotas(c *gin.Context) {
+	namespace := c.Param("namespace")
+	if !checkNamespaceAccess(c, namespace) {
+		return
+	}
+	clientset, ok := workloadClientset(c, h.clusterService, h.k8sMgr)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	items, err := services.ListResourceQuotas(ctx, clientset, namespace)
+	if err != nil {
+		respondWorkloadActionError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "success",
+		"data": gin.H{
+			"items": items,
+			"total": len(items),
+		},
+	})
+}
+
+// CreateResourceQuota 创建 ResourceQuota
+func (h *ResourceQuotaHandler) CreateResourceQuota(c *gin.Context) {
+	namespace := c.Param("namespace")
+
+	var req services.ResourceQuotaRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "参数错误: " + err.Error(),
+		})
+		return
+	}
+
+	if !checkWorkloadPermission(c, "Quota", "create") {
+		return
+	}
+
+	logger.Info("创建ResourceQuota: cluster=%s %s/%s", c.Param("clusterID"), namespace, req.Name)
+
+	clientset, ok := workloadClientset(c, h.clusterService, h.k8sMgr)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	quota, err := services.CreateResourceQuota(ctx, clientset, namespace, &req)
+	if err != nil {
+		respondWorkloadActionError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "ResourceQuota创建成功",
+		"data":    services.ToResourceQuotaDetail(quota),
+	})
+}
+
+// UpdateResourceQuota 更新 ResourceQuota
+func (h *ResourceQuotaHandler) UpdateResourceQuota(c *gin.Context) {
+	namespace := c.Param("namespace")
+	name := c.Param("name")
+
+	var req services.ResourceQuotaRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "参数错误: " + err.Error(),
+		})
+		return
+	}
+
+	if !checkWorkloadPermission(c, "Quota", "update") {
+		return
+	}
+
+	logger.Info("更新ResourceQuota: cluster=%s %s/%s", c.Param("clusterID"), namespace, name)
+
+	clientset, ok := workloadClientset(c, h.clusterService, h.k8sMgr)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	prev, quota, err := services.UpdateResourceQuota(ctx, clientset, namespace, name, &req)
+	if err != nil {
+		respondWorkloadActionError(c, err)
+		return
+	}
+	recordTypedRevision(c, h.db, prev, corev1.SchemeGroupVersion.WithKind("ResourceQuota"), services.RevisionActionUpdate)
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "ResourceQuota更新成功",
+		"data":    services.ToResourceQuotaDetail(quota),
+	})
+}
+
+// DeleteResourceQuota 删除 ResourceQuota
+func (h *ResourceQuotaHandler) DeleteResourceQuota(c *gin.Context) {
+	namespace := c.Param("namespace")
+	name := c.Param("name")
+
+	if !checkWorkloadPermission(c, "Quota", "delete") {
+		return
+	}
+
+	logger.Info("删除ResourceQuota: cluster=%s %s/%s", c.Param("clusterID"), namespace, name)
+
+	clientset, ok := workloadClientset(c, h.clusterService, h.k8sMgr)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	if err := services.DeleteResourceQuota(ctx, clientset, namespace, name); err != nil {
+		respondWorkloadActionError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "ResourceQuota删除成功",
+		"data":    nil,
+	})
+}
+
+// ListLimitRanges 获取命名空间的 LimitRange 列表
+func (h *ResourceQuotaHandler) ListLimitRanges(c *gin.Context) {
+	namespace := c.Param("namespace")
+	if !checkNamespaceAccess(c, namespace) {
+		return
+	}
+	clientset, ok := workloadClientset(c, h.clusterService, h.k8sMgr)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	items, err := services.ListLimitRanges(ctx, clientset, namespace)
+	if err != nil {
+		respondWorkloadActionError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "success",
+		"data": gin.H{
+			"items": items,
+			"total": len(items),
+		},
+	})
+}
+
+// CreateLimitRange 创建 LimitRange
+func (h *ResourceQuotaHandler) CreateLimitRange(c *gin.Context) {
+	namespace := c.Param("namespace")
+
+	var req services.LimitRangeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "参数错误: " + err.Error(),
+		})
+		return
+	}
+
+	if !checkWorkloadPermission(c, "LimitRange", "create") {
+		return
+	}
+
+	logger.Info("创建LimitRange: cluster=%s %s/%s", c.Param("clusterID"), namespace, req.Name)
+
+	clientset, ok := workloadClientset(c, h.clusterService, h.k8sMgr)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	lr, err := services.CreateLimitRange(ctx, clientset, namespace, &req)
+	if err != nil {
+		respondWorkloadActionError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "LimitRange创建成功",
+		"data":    services.ToLimitRangeDetail(lr),
+	})
+}
+
+// UpdateLimitRange 更新 LimitRange
+func (h *ResourceQuotaHandler) UpdateLimitRange(c *gin.Context) {
+	namespace := c.Param("namespace")
+	name := c.Param("name")
+
+	var req services.LimitRangeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "参数错误: " + err.Error(),
+		})
+		return
+	}
+
+	if !checkWorkloadPermission(c, "LimitRange", "update") {
+		return
+	}
+
+	logger.Info("更新LimitRange: cluster=%s %s/%s", c.Param("clusterID"), namespace, name)
+
+	clientset, ok := workloadClientset(c, h.clusterService, h.k8sMgr)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	prev, lr, err := services.UpdateLimitRange(ctx, clientset, namespace, name, &req)
+	if err != nil {
+		respondWorkloadActionError(c, err)
+		return
+	}
+	recordTypedRevision(c, h.db, prev, corev1.SchemeGroupVersion.WithKind("LimitRange"), services.RevisionActionUpdate)
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "LimitRange更新成功",
+		"data":    services.ToLimitRangeDetail(lr),
+	})
+}
+
+// DeleteLimitRange 删除 LimitRange
+func (h *ResourceQuotaHandler) DeleteLimitRange(c *gin.Context) {
+	namespace := c.Param("namespace")
+	name := c.Param("name")
+
+	if !checkWorkloadPermission(c, "LimitRange", "delete") {
+		return
+	}
+
+	logger.Info("删除LimitRange: cluster=%s %s/%s", c.Param("clusterID"), namespace, name)
+
+	clientset, ok := workloadClientset(c, h.clusterService, h.k8sMgr)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	if err := services.DeleteLimitRange(ctx, clientset, namespace, name); err != nil {
+		respondWorkloadActionError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "LimitRange删除成功",
+		"data":    nil,
+	})
+}
+
+// GetQuotaUsage 获取命名空间内每项配额资源的已用量与上限
+func (h *ResourceQuotaHandler) GetQuotaUsage(c *gin.Context) {
+	namespace := c.Param("namespace")
+	if !checkNamespaceAccess(c, namespace) {
+		return
+	}
+	clientset, ok := workloadClientset(c, h.clusterService, h.k8sMgr)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	quotas, err := clientset.CoreV1().ResourceQuotas(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		respondWorkloadActionError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "success",
+		"data":    services.BuildNamespaceQuotaUsage(namespace, quotas.Items),
+	})
+}
@@ -0,0 +1,332 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/clay-wangzhi/KubePolaris/internal/k8s"
+	"github.com/clay-wangzhi/KubePolaris/internal/models"
+	"github.com/clay-wangzhi/KubePolaris/internal/services"
+	"github.com/clay-wangzhi/KubePolaris/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// ResourceRevisionHandler 资源修订历史处理器
+type ResourceRevisionHandler struct {
+	clusterService  *services.ClusterService
+	k8sMgr          *k8s.ClusterInformerManager
+	revisionService *services.ResourceRevisionService
+}
+
+// NewResourceRevisionHandler 创建资源修订历史处理器
+func NewResourceRevisionHandler(db *gorm.DB, clusterService *services.ClusterService, k8sMgr *k8s.ClusterInformerManager) *ResourceRevisionHandler {
+	return &ResourceRevisionHandler{
+		clusterService:  clusterService,
+		k8sMgr:          k8sMgr,
+		revisionService: services.NewResourceRevisionService(db),
+	}
+}
+
+// ListRevisions 获取修订列表，支持 kind、namespace、name、page、pageSize 查询参数
+func (h *ResourceRevisionHandler) ListRevisions(c *gin.Context) {
+	permission, ok := getClusterPermission(c)
+	if !ok {
+		return
+	}
+	// 未指定命名空间时会包含集群级资源与全部命名空间，要求全部命名空间权限
+	namespace := c.Query("namespace")
+	nsAllowed := permission.HasAllNamespaceAccess()
+	if namespace != "" {
+		nsAllowed = permission.HasNamespaceAccess(namespace)
+	}
+	if !nsAllowed {
+		c.JSON(http.StatusForbidden, gin.H{
+			"code":    403,
+			"message": "无权限访问该命名空间",
+			"data":    nil,
+		})
+		return
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("pageSize", "20"))
+	result, err := h.revisionService.List(&services.ResourceRevisionListRequest{
+		ClusterID: parseClusterID(c.Param("clusterID")),
+		Kind:      c.Query("kind"),
+		Namespace: namespace,
+		Name:      c.Query("name"),
+		Page:      page,
+		PageSize:  pageSize,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    500,
+			"message": "获取修订列表失败: " + err.Error(),
+			"data":    nil,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "获取成功",
+		"data":    result,
+	})
+}
+
+// GetRevision 获取修订详情（Secret 数据脱敏）
+func (h *ResourceRevisionHandler) GetRevision(c *gin.Context) {
+	rev, obj, ok := h.loadRevision(c, c.Param("revisionID"))
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "获取成功",
+		"data": gin.H{
+			"revision": rev,
+			"yaml":     services.MaskedYAML(obj),
+		},
+	})
+}
+
+// DiffRevisions 比较两个修订：from 为较早的修订 ID；to 为较新的修订 ID，为空时与集群中的当前对象比较
+func (h *ResourceRevisionHandler) DiffRevisions(c *gin.Context) {
+	fromRev, from, ok := h.loadRevision(c, c.Query("from"))
+	if !ok {
+		return
+	}
+
+	var to *unstructured.Unstructured
+	if c.Query("to") != "" {
+		toRev, obj, ok := h.loadRevision(c, c.Query("to"))
+		if !ok {
+			return
+		}
+		if toRev.Kind != fromRev.Kind || toRev.Namespace != fromRev.Namespace || toRev.Name != fromRev.Name {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"code":    400,
+				"message": "只能比较同一资源的修订",
+				"data":    nil,
+			})
+			return
+		}
+		to = obj
+	} else {
+		engine, ok := h.applyEngine(c)
+		if !ok {
+			return
+		}
+		live, err := h.getLiveObject(c.Request.Context(), engine, from)
+		if apierrors.IsNotFound(err) {
+			c.JSON(http.StatusNotFound, gin.H{
+				"code":    404,
+				"message": "资源当前已不存在，无法与修订比较",
+				"data":    nil,
+			})
+			return
+		}
+		if err != nil {
+			respondApplyError(c, err)
+			return
+		}
+		unstructured.RemoveNestedField(live.Object, "status")
+		to = live
+	}
+
+	diff := services.DiffObjects(from, to)
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "获取成功",
+		"data":    diff,
+	})
+}
+
+// RevertRevision 将资源恢复为指定修订的内容
+// 以服务端应用方式重新应用快照并强制接管冲突字段；快照之后新增、由其他管理者持有的字段不会被删除。
+func (h *ResourceRevisionHandler) RevertRevision(c *gin.Context) {
+	permission, ok := getClusterPermission(c)
+	if !ok {
+		return
+	}
+	rev, obj, ok := h.loadRevision(c, c.Param("revisionID"))
+	if !ok {
+		return
+	}
+	engine, ok := h.applyEngine(c)
+	if !ok {
+		return
+	}
+
+	info, err := engine.ResolveKind(obj.GroupVersionKind())
+	if err != nil {
+		respondApplyError(c, err)
+		return
+	}
+	if err := authorizeApply(permission)(info, obj); err != nil {
+		c.JSON(http.StatusForbidden, gin.H{
+			"code":    403,
+			"message": err.Error(),
+			"data":    nil,
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 30*time.Second)
+	defer cancel()
+
+	dryRun := c.Query("dryRun") == "true"
+	result, err := engine.ApplyResource(ctx, info, obj, services.ApplyOptions{DryRun: dryRun, Force: true})
+	if err != nil {
+		respondApplyError(c, err)
+		return
+	}
+	recordApplyRevision(c, h.revisionService, services.RevisionActionRevert, result)
+
+	logger.Info("回滚资源修订", "clusterID", rev.ClusterID, "kind", rev.Kind, "namespace", rev.Namespace,
+		"name", rev.Name, "revision", rev.Revision, "dryRun", dryRun)
+	message := "回滚成功"
+	if dryRun {
+		message = "预检通过"
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": message,
+		"data":    result,
+	})
+}
+
+// loadRevision 加载修订并解析快照，同时校验命名空间权限
+func (h *ResourceRevisionHandler) loadRevision(c *gin.Context, idParam string) (*models.ResourceRevision, *unstructured.Unstructured, bool) {
+	permission, ok := getClusterPermission(c)
+	if !ok {
+		return nil, nil, false
+	}
+	id, err := strconv.ParseUint(idParam, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "无效的修订ID",
+			"data":    nil,
+		})
+		return nil, nil, false
+	}
+
+	rev, err := h.revisionService.Get(parseClusterID(c.Param("clusterID")), uint(id))
+	if err != nil {
+		status, message := http.StatusInternalServerError, "获取修订失败: "+err.Error()
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			status, message = http.StatusNotFound, "修订不存在"
+		}
+		c.JSON(status, gin.H{
+			"code":    status,
+			"message": message,
+			"data":    nil,
+		})
+		return nil, nil, false
+	}
+
+	nsAllowed := permission.HasAllNamespaceAccess()
+	if rev.Namespace != "" {
+		nsAllowed = permission.HasNamespaceAccess(rev.Namespace)
+	}
+	if !nsAllowed {
+		c.JSON(http.StatusForbidden, gin.H{
+			"code":    403,
+			"message": "无权限访问该命名空间",
+			"data":    nil,
+		})
+		return nil, nil, false
+	}
+
+	obj, err := h.revisionService.Object(rev)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    500,
+			"message": err.Error(),
+			"data":    nil,
+		})
+		return nil, nil, false
+	}
+	return rev, obj, true
+}
+
+// applyEngine 创建当前集群的应用引擎
+func (h *ResourceRevisionHandler) applyEngine(c *gin.Context) (*services.ApplyEngine, bool) {
+	cluster, err := h.clusterService.GetCluster(parseClusterID(c.Param("clusterID")))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"code":    404,
+			"message": "集群不存在",
+			"data":    nil,
+		})
+		return nil, false
+	}
+	k8sClient, err := h.k8sMgr.GetK8sClient(cluster)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    500,
+			"message": "获取K8s客户端失败: " + err.Error(),
+			"data":    nil,
+		})
+		return nil, false
+	}
+	engine, err := services.NewApplyEngine(k8sClient)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    500,
+			"message": err.Error(),
+			"data":    nil,
+		})
+		return nil, false
+	}
+	return engine, true
+}
+
+func (h *ResourceRevisionHandler) getLiveObject(ctx context.Context, engine *services.ApplyEngine, obj *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+	info, err := engine.ResolveKind(obj.GroupVersionKind())
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	return engine.Get(ctx, info, obj.GetNamespace(), obj.GetName())
+}
+
+// revisionOperator 从请求上下文获取当前操作者
+func revisionOperator(c *gin.Context) services.RevisionOperator {
+	return services.RevisionOperator{UserID: c.GetUint("user_id"), Username: c.GetString("username")}
+}
+
+// recordApplyRevision 应用成功后保存变更前的快照；新建、预检及未发生变化的应用不记录
+// 快照失败只记录日志，不影响本次操作结果。
+func recordApplyRevision(c *gin.Context, revisions *services.ResourceRevisionService, action string, result *services.ApplyResult) {
+	if result.DryRun || result.Previous == nil || result.Operation == services.ApplyOperationUnchanged {
+		return
+	}
+	if _, err := revisions.Record(parseClusterID(c.Param("clusterID")), result.Previous, action, revisionOperator(c)); err != nil {
+		logger.Error("保存资源修订失败", "kind", result.Kind, "namespace", result.Namespace, "name", result.Name, "error", err)
+	}
+}
+
+// recordTypedRevision 修改成功后保存变更前的 typed 对象快照
+func recordTypedRevision(c *gin.Context, db *gorm.DB, prev runtime.Object, gvk schema.GroupVersionKind, action string) {
+	accessor, err := meta.Accessor(prev)
+	if err != nil {
+		return
+	}
+	revisions := services.NewResourceRevisionService(db)
+	if _, err := revisions.RecordTyped(parseClusterID(c.Param("clusterID")), prev, gvk, action, revisionOperator(c)); err != nil {
+		logger.Error("保存资源修订失败", "kind", gvk.Kind, "namespace", accessor.GetNamespace(), "name", accessor.GetName(), "error", err)
+	}
+}
@@ -14,11 +14,7 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
-	corev1 "k8s.io/api/core/v1"
-	networkingv1 "k8s.io/api/networking/v1"
-	storagev1 "k8s.io/api/storage/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/apimachinery/pkg/util/yaml"
 	sigsyaml "sigs.k8s.io/yaml"
 )
 
@@ -44,15 +40,7 @@ func NewResourceYAMLHandler(db *gorm.DB, cfg *config.Config, clusterService *ser
 type ResourceYAMLApplyRequest struct {
 	YAML   string `json:"yaml" binding:"required"`
 	DryRun bool   `json:"dryRun"`
-}
-
-// ResourceYAMLResponse 资源YAML响应
-type ResourceYAMLResponse struct {
-	Name            string `json:"name"`
-	Namespace       string `json:"namespace,omitempty"`
-	Kind            string `json:"kind"`
-	ResourceVersion string `json:"resourceVersion,omitempty"`
-	IsCreated       bool   `json:"isCreated"` // true: 创建, false: 更新
+	Force  bool   `json:"force"` // 字段所有权冲突时强制接管
 }
 
 // ApplyConfigMapYAML 应用ConfigMap YAML
@@ -68,7 +56,7 @@ func (h *ResourceYAMLHandler) ApplyConfigMapYAML(c *gin.Context) {
 		return
 	}
 
-	logger.Info("应用ConfigMap YAML: cluster=%s, dryRun=%v", clusterID, req.DryRun)
+	logger.Info("应用ConfigMap YAML: cluster=%s, dryRun=%v, force=%v", clusterID, req.DryRun, req.Force)
 
 	id := parseClusterID(clusterID)
 	cluster, err := h.clusterService.GetCluster(id)
@@ -84,66 +72,7 @@ func (h *ResourceYAMLHandler) ApplyConfigMapYAML(c *gin.Context) {
 		return
 	}
 
-	// 解析YAML
-	var cm corev1.ConfigMap
-	if err := yaml.Unmarshal([]byte(req.YAML), &cm); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "YAML格式错误: " + err.Error()})
-		return
-	}
-
-	// 验证kind
-	if cm.Kind != "" && cm.Kind != "ConfigMap" {
-		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "YAML类型错误，期望ConfigMap，实际为: " + cm.Kind})
-		return
-	}
-
-	if cm.Namespace == "" {
-		cm.Namespace = "default"
-	}
-
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-	defer cancel()
-
-	clientset := k8sClient.GetClientset()
-	var dryRunOpt []string
-	if req.DryRun {
-		dryRunOpt = []string{metav1.DryRunAll}
-	}
-
-	// 尝试获取现有资源
-	existing, err := clientset.CoreV1().ConfigMaps(cm.Namespace).Get(ctx, cm.Name, metav1.GetOptions{})
-	var result *corev1.ConfigMap
-	isCreated := false
-
-	if err == nil {
-		// 资源存在，执行更新
-		cm.ResourceVersion = existing.ResourceVersion
-		result, err = clientset.CoreV1().ConfigMaps(cm.Namespace).Update(ctx, &cm, metav1.UpdateOptions{DryRun: dryRunOpt})
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "更新ConfigMap失败: " + err.Error()})
-			return
-		}
-	} else {
-		// 资源不存在，执行创建
-		isCreated = true
-		result, err = clientset.CoreV1().ConfigMaps(cm.Namespace).Create(ctx, &cm, metav1.CreateOptions{DryRun: dryRunOpt})
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "创建ConfigMap失败: " + err.Error()})
-			return
-		}
-	}
-
-	c.JSON(http.StatusOK, gin.H{
-		"code":    200,
-		"message": "YAML应用成功",
-		"data": ResourceYAMLResponse{
-			Name:            result.Name,
-			Namespace:       result.Namespace,
-			Kind:            "ConfigMap",
-			ResourceVersion: result.ResourceVersion,
-			IsCreated:       isCreated,
-		},
-	})
+	applyYAMLWithEngine(c, h.db, k8sClient, req.YAML, "ConfigMap", req.DryRun, req.Force)
 }
 
 // GetConfigMapYAML 获取ConfigMap的YAML
@@ -205,7 +134,7 @@ func (h *ResourceYAMLHandler) ApplySecretYAML(c *gin.Context) {
 		return
 	}
 
-	logger.Info("应用Secret YAML: cluster=%s, dryRun=%v", clusterID, req.DryRun)
+	logger.Info("应用Secret YAML: cluster=%s, dryRun=%v, force=%v", clusterID, req.DryRun, req.Force)
 
 	id := parseClusterID(clusterID)
 	cluster, err := h.clusterService.GetCluster(id)
@@ -220,61 +149,7 @@ func (h *ResourceYAMLHandler) ApplySecretYAML(c *gin.Context) {
 		return
 	}
 
-	var secret corev1.Secret
-	if err := yaml.Unmarshal([]byte(req.YAML), &secret); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "YAML格式错误: " + err.Error()})
-		return
-	}
-
-	if secret.Kind != "" && secret.Kind != "Secret" {
-		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "YAML类型错误，期望Secret，实际为: " + secret.Kind})
-		return
-	}
-
-	if secret.Namespace == "" {
-		secret.Namespace = "default"
-	}
-
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-	defer cancel()
-
-	clientset := k8sClient.GetClientset()
-	var dryRunOpt []string
-	if req.DryRun {
-		dryRunOpt = []string{metav1.DryRunAll}
-	}
-
-	existing, err := clientset.CoreV1().Secrets(secret.Namespace).Get(ctx, secret.Name, metav1.GetOptions{})
-	var result *corev1.Secret
-	isCreated := false
-
-	if err == nil {
-		secret.ResourceVersion = existing.ResourceVersion
-		result, err = clientset.CoreV1().Secrets(secret.Namespace).Update(ctx, &secret, metav1.UpdateOptions{DryRun: dryRunOpt})
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "更新Secret失败: " + err.Error()})
-			return
-		}
-	} else {
-		isCreated = true
-		result, err = clientset.CoreV1().Secrets(secret.Namespace).Create(ctx, &secret, metav1.CreateOptions{DryRun: dryRunOpt})
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "创建Secret失败: " + err.Error()})
-			return
-		}
-	}
-
-	c.JSON(http.StatusOK, gin.H{
-		"code":    200,
-		"message": "YAML应用成功",
-		"data": ResourceYAMLResponse{
-			Name:            result.Name,
-			Namespace:       result.Namespace,
-			Kind:            "Secret",
-			ResourceVersion: result.ResourceVersion,
-			IsCreated:       isCreated,
-		},
-	})
+	applyYAMLWithEngine(c, h.db, k8sClient, req.YAML, "Secret", req.DryRun, req.Force)
 }
 
 // GetSecretYAML 获取Secret的YAML
@@ -335,7 +210,7 @@ func (h *ResourceYAMLHandler) ApplyServiceYAML(c *gin.Context) {
 		return
 	}
 
-	logger.Info("应用Service YAML: cluster=%s, dryRun=%v", clusterID, req.DryRun)
+	logger.Info("应用Service YAML: cluster=%s, dryRun=%v, force=%v", clusterID, req.DryRun, req.Force)
 
 	id := parseClusterID(clusterID)
 	cluster, err := h.clusterService.GetCluster(id)
@@ -350,64 +225,7 @@ func (h *ResourceYAMLHandler) ApplyServiceYAML(c *gin.Context) {
 		return
 	}
 
-	var svc corev1.Service
-	if err := yaml.Unmarshal([]byte(req.YAML), &svc); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "YAML格式错误: " + err.Error()})
-		return
-	}
-
-	if svc.Kind != "" && svc.Kind != "Service" {
-		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "YAML类型错误，期望Service，实际为: " + svc.Kind})
-		return
-	}
-
-	if svc.Namespace == "" {
-		svc.Namespace = "default"
-	}
-
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-	defer cancel()
-
-	clientset := k8sClient.GetClientset()
-	var dryRunOpt []string
-	if req.DryRun {
-		dryRunOpt = []string{metav1.DryRunAll}
-	}
-
-	existing, err := clientset.CoreV1().Services(svc.Namespace).Get(ctx, svc.Name, metav1.GetOptions{})
-	var result *corev1.Service
-	isCreated := false
-
-	if err == nil {
-		// Service更新时需要保留ClusterIP
-		svc.ResourceVersion = existing.ResourceVersion
-		svc.Spec.ClusterIP = existing.Spec.ClusterIP
-		svc.Spec.ClusterIPs = existing.Spec.ClusterIPs
-		result, err = clientset.CoreV1().Services(svc.Namespace).Update(ctx, &svc, metav1.UpdateOptions{DryRun: dryRunOpt})
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "更新Service失败: " + err.Error()})
-			return
-		}
-	} else {
-		isCreated = true
-		result, err = clientset.CoreV1().Services(svc.Namespace).Create(ctx, &svc, metav1.CreateOptions{DryRun: dryRunOpt})
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "创建Service失败: " + err.Error()})
-			return
-		}
-	}
-
-	c.JSON(http.StatusOK, gin.H{
-		"code":    200,
-		"message": "YAML应用成功",
-		"data": ResourceYAMLResponse{
-			Name:            result.Name,
-			Namespace:       result.Namespace,
-			Kind:            "Service",
-			ResourceVersion: result.ResourceVersion,
-			IsCreated:       isCreated,
-		},
-	})
+	applyYAMLWithEngine(c, h.db, k8sClient, req.YAML, "Service", req.DryRun, req.Force)
 }
 
 // ApplyIngressYAML 应用Ingress YAML
@@ -420,7 +238,7 @@ func (h *ResourceYAMLHandler) ApplyIngressYAML(c *gin.Context) {
 		return
 	}
 
-	logger.Info("应用Ingress YAML: cluster=%s, dryRun=%v", clusterID, req.DryRun)
+	logger.Info("应用Ingress YAML: cluster=%s, dryRun=%v, force=%v", clusterID, req.DryRun, req.Force)
 
 	id := parseClusterID(clusterID)
 	cluster, err := h.clusterService.GetCluster(id)
@@ -435,61 +253,7 @@ func (h *ResourceYAMLHandler) ApplyIngressYAML(c *gin.Context) {
 		return
 	}
 
-	var ing networkingv1.Ingress
-	if err := yaml.Unmarshal([]byte(req.YAML), &ing); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "YAML格式错误: " + err.Error()})
-		return
-	}
-
-	if ing.Kind != "" && ing.Kind != "Ingress" {
-		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "YAML类型错误，期望Ingress，实际为: " + ing.Kind})
-		return
-	}
-
-	if ing.Namespace == "" {
-		ing.Namespace = "default"
-	}
-
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-	defer cancel()
-
-	clientset := k8sClient.GetClientset()
-	var dryRunOpt []string
-	if req.DryRun {
-		dryRunOpt = []string{metav1.DryRunAll}
-	}
-
-	existing, err := clientset.NetworkingV1().Ingresses(ing.Namespace).Get(ctx, ing.Name, metav1.GetOptions{})
-	var result *networkingv1.Ingress
-	isCreated := false
-
-	if err == nil {
-		ing.ResourceVersion = existing.ResourceVersion
-		result, err = clientset.NetworkingV1().Ingresses(ing.Namespace).Update(ctx, &ing, metav1.UpdateOptions{DryRun: dryRunOpt})
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "更新Ingress失败: " + err.Error()})
-			return
-		}
-	} else {
-		isCreated = true
-		result, err = clientset.NetworkingV1().Ingresses(ing.Namespace).Create(ctx, &ing, metav1.CreateOptions{DryRun: dryRunOpt})
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "创建Ingress失败: " + err.Error()})
-			return
-		}
-	}
-
-	c.JSON(http.StatusOK, gin.H{
-		"code":    200,
-		"message": "YAML应用成功",
-		"data": ResourceYAMLResponse{
-			Name:            result.Name,
-			Namespace:       result.Namespace,
-			Kind:            "Ingress",
-			ResourceVersion: result.ResourceVersion,
-			IsCreated:       isCreated,
-		},
-	})
+	applyYAMLWithEngine(c, h.db, k8sClient, req.YAML, "Ingress", req.DryRun, req.Force)
 }
 
 // ApplyPVCYAML 应用PVC YAML
@@ -502,7 +266,7 @@ func (h *ResourceYAMLHandler) ApplyPVCYAML(c *gin.Context) {
 		return
 	}
 
-	logger.Info("应用PVC YAML: cluster=%s, dryRun=%v", clusterID, req.DryRun)
+	logger.Info("应用PVC YAML: cluster=%s, dryRun=%v, force=%v", clusterID, req.DryRun, req.Force)
 
 	id := parseClusterID(clusterID)
 	cluster, err := h.clusterService.GetCluster(id)
@@ -517,63 +281,7 @@ func (h *ResourceYAMLHandler) ApplyPVCYAML(c *gin.Context) {
 		return
 	}
 
-	var pvc corev1.PersistentVolumeClaim
-	if err := yaml.Unmarshal([]byte(req.YAML), &pvc); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "YAML格式错误: " + err.Error()})
-		return
-	}
-
-	if pvc.Kind != "" && pvc.Kind != "PersistentVolumeClaim" {
-		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "YAML类型错误，期望PersistentVolumeClaim，实际为: " + pvc.Kind})
-		return
-	}
-
-	if pvc.Namespace == "" {
-		pvc.Namespace = "default"
-	}
-
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-	defer cancel()
-
-	clientset := k8sClient.GetClientset()
-	var dryRunOpt []string
-	if req.DryRun {
-		dryRunOpt = []string{metav1.DryRunAll}
-	}
-
-	existing, err := clientset.CoreV1().PersistentVolumeClaims(pvc.Namespace).Get(ctx, pvc.Name, metav1.GetOptions{})
-	var result *corev1.PersistentVolumeClaim
-	isCreated := false
-
-	if err == nil {
-		pvc.ResourceVersion = existing.ResourceVersion
-		// PVC的spec大部分字段是不可变的，只能更新某些字段
-		pvc.Spec.VolumeName = existing.Spec.VolumeName
-		result, err = clientset.CoreV1().PersistentVolumeClaims(pvc.Namespace).Update(ctx, &pvc, metav1.UpdateOptions{DryRun: dryRunOpt})
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "更新PVC失败: " + err.Error()})
-			return
-		}
-	} else {
-		isCreated = true
-		result, err = clientset.CoreV1().PersistentVolumeClaims(pvc.Namespace).Create(ctx, &pvc, metav1.CreateOptions{DryRun: dryRunOpt})
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "创建PVC失败: " + err.Error()})
-			return
-		}
-	}
-
-	c.JSON(http.StatusOK, gin.H{
-		"code":    200,
-		"message": "YAML应用成功",
-		"data": ResourceYAMLResponse{
-			Name:            result.Name,
-			Namespace:       result.Namespace,
-			Kind:            "PersistentVolumeClaim",
-			ResourceVersion: result.ResourceVersion,
-			IsCreated:       isCreated,
-		},
-	})
+	applyYAMLWithEngine(c, h.db, k8sClient, req.YAML, "PersistentVolumeClaim", req.DryRun, req.Force)
 }
 
 // ApplyPVYAML 应用PV YAML
@@ -586,7 +294,7 @@ func (h *ResourceYAMLHandler) ApplyPVYAML(c *gin.Context) {
 		return
 	}
 
-	logger.Info("应用PV YAML: cluster=%s, dryRun=%v", clusterID, req.DryRun)
+	logger.Info("应用PV YAML: cluster=%s, dryRun=%v, force=%v", clusterID, req.DryRun, req.Force)
 
 	id := parseClusterID(clusterID)
 	cluster, err := h.clusterService.GetCluster(id)
@@ -601,56 +309,7 @@ func (h *ResourceYAMLHandler) ApplyPVYAML(c *gin.Context) {
 		return
 	}
 
-	var pv corev1.PersistentVolume
-	if err := yaml.Unmarshal([]byte(req.YAML), &pv); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "YAML格式错误: " + err.Error()})
-		return
-	}
-
-	if pv.Kind != "" && pv.Kind != "PersistentVolume" {
-		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "YAML类型错误，期望PersistentVolume，实际为: " + pv.Kind})
-		return
-	}
-
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-	defer cancel()
-
-	clientset := k8sClient.GetClientset()
-	var dryRunOpt []string
-	if req.DryRun {
-		dryRunOpt = []string{metav1.DryRunAll}
-	}
-
-	existing, err := clientset.CoreV1().PersistentVolumes().Get(ctx, pv.Name, metav1.GetOptions{})
-	var result *corev1.PersistentVolume
-	isCreated := false
-
-	if err == nil {
-		pv.ResourceVersion = existing.ResourceVersion
-		result, err = clientset.CoreV1().PersistentVolumes().Update(ctx, &pv, metav1.UpdateOptions{DryRun: dryRunOpt})
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "更新PV失败: " + err.Error()})
-			return
-		}
-	} else {
-		isCreated = true
-		result, err = clientset.CoreV1().PersistentVolumes().Create(ctx, &pv, metav1.CreateOptions{DryRun: dryRunOpt})
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "创建PV失败: " + err.Error()})
-			return
-		}
-	}
-
-	c.JSON(http.StatusOK, gin.H{
-		"code":    200,
-		"message": "YAML应用成功",
-		"data": ResourceYAMLResponse{
-			Name:            result.Name,
-			Kind:            "PersistentVolume",
-			ResourceVersion: result.ResourceVersion,
-			IsCreated:       isCreated,
-		},
-	})
+	applyYAMLWithEngine(c, h.db, k8sClient, req.YAML, "PersistentVolume", req.DryRun, req.Force)
 }
 
 // ApplyStorageClassYAML 应用StorageClass YAML
@@ -663,7 +322,7 @@ func (h *ResourceYAMLHandler) ApplyStorageClassYAML(c *gin.Context) {
 		return
 	}
 
-	logger.Info("应用StorageClass YAML: cluster=%s, dryRun=%v", clusterID, req.DryRun)
+	logger.Info("应用StorageClass YAML: cluster=%s, dryRun=%v, force=%v", clusterID, req.DryRun, req.Force)
 
 	id := parseClusterID(clusterID)
 	cluster, err := h.clusterService.GetCluster(id)
@@ -678,56 +337,7 @@ func (h *ResourceYAMLHandler) ApplyStorageClassYAML(c *gin.Context) {
 		return
 	}
 
-	var sc storagev1.StorageClass
-	if err := yaml.Unmarshal([]byte(req.YAML), &sc); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "YAML格式错误: " + err.Error()})
-		return
-	}
-
-	if sc.Kind != "" && sc.Kind != "StorageClass" {
-		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "YAML类型错误，期望StorageClass，实际为: " + sc.Kind})
-		return
-	}
-
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-	defer cancel()
-
-	clientset := k8sClient.GetClientset()
-	var dryRunOpt []string
-	if req.DryRun {
-		dryRunOpt = []string{metav1.DryRunAll}
-	}
-
-	existing, err := clientset.StorageV1().StorageClasses().Get(ctx, sc.Name, metav1.GetOptions{})
-	var result *storagev1.StorageClass
-	isCreated := false
-
-	if err == nil {
-		sc.ResourceVersion = existing.ResourceVersion
-		result, err = clientset.StorageV1().StorageClasses().Update(ctx, &sc, metav1.UpdateOptions{DryRun: dryRunOpt})
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "更新StorageClass失败: " + err.Error()})
-			return
-		}
-	} else {
-		isCreated = true
-		result, err = clientset.StorageV1().StorageClasses().Create(ctx, &sc, metav1.CreateOptions{DryRun: dryRunOpt})
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "创建StorageClass失败: " + err.Error()})
-			return
-		}
-	}
-
-	c.JSON(http.StatusOK, gin.H{
-		"code":    200,
-		"message": "YAML应用成功",
-		"data": ResourceYAMLResponse{
-			Name:            result.Name,
-			Kind:            "StorageClass",
-			ResourceVersion: result.ResourceVersion,
-			IsCreated:       isCreated,
-		},
-	})
+	applyYAMLWithEngine(c, h.db, k8sClient, req.YAML, "StorageClass", req.DryRun, req.Force)
 }
 
 // createK8sClient 获取缓存的 K8s 客户端
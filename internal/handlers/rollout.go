@@ -20,8 +20,6 @@ import (
 	"gorm.io/gorm"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
-	"k8s.io/apimachinery/pkg/runtime/serializer"
-	"k8s.io/apimachinery/pkg/util/yaml"
 	"k8s.io/client-go/kubernetes/scheme"
 	sigsyaml "sigs.k8s.io/yaml"
 )
@@ -495,7 +493,7 @@ func (h *RolloutHandler) ApplyYAML(c *gin.Context) {
 		return
 	}
 
-	logger.Info("应用Rollout YAML: cluster=%s, dryRun=%v", clusterId, req.DryRun)
+	logger.Info("应用Rollout YAML: cluster=%s, dryRun=%v, force=%v", clusterId, req.DryRun, req.Force)
 
 	clusterID := parseClusterID(clusterId)
 	cluster, err := h.clusterService.GetCluster(clusterID)
@@ -517,67 +515,7 @@ func (h *RolloutHandler) ApplyYAML(c *gin.Context) {
 		return
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-	defer cancel()
-
-	// 解析YAML
-	var objMap map[string]interface{}
-	if err := yaml.Unmarshal([]byte(req.YAML), &objMap); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"code":    400,
-			"message": "YAML格式错误: " + err.Error(),
-		})
-		return
-	}
-
-	// 验证必要字段
-	if objMap["apiVersion"] == nil || objMap["kind"] == nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"code":    400,
-			"message": "YAML缺少必要字段: apiVersion 或 kind",
-		})
-		return
-	}
-
-	kind := objMap["kind"].(string)
-	if kind != "Rollout" {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"code":    400,
-			"message": "YAML类型错误，期望Rollout，实际为: " + kind,
-		})
-		return
-	}
-
-	// 获取metadata
-	metadata, ok := objMap["metadata"].(map[string]interface{})
-	if !ok {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"code":    400,
-			"message": "YAML缺少 metadata 字段",
-		})
-		return
-	}
-
-	namespace, _ := metadata["namespace"].(string)
-	if namespace == "" {
-		namespace = "default"
-	}
-
-	// 应用YAML
-	result, err := h.applyYAML(ctx, k8sClient, req.YAML, namespace, req.DryRun)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"code":    500,
-			"message": "YAML应用失败: " + err.Error(),
-		})
-		return
-	}
-
-	c.JSON(http.StatusOK, gin.H{
-		"code":    200,
-		"message": "YAML应用成功",
-		"data":    result,
-	})
+	applyYAMLWithEngine(c, h.db, k8sClient, req.YAML, "Rollout", req.DryRun, req.Force)
 }
 
 // DeleteRollout 删除Rollout
@@ -680,50 +618,6 @@ func (h *RolloutHandler) convertToRolloutInfo(r *rollouts.Rollout) RolloutInfo {
 	}
 }
 
-// 辅助方法：应用YAML
-func (h *RolloutHandler) applyYAML(ctx context.Context, k8sClient *services.K8sClient, yamlContent string, namespace string, dryRun bool) (interface{}, error) {
-	// 创建解码器
-	decode := serializer.NewCodecFactory(scheme.Scheme).UniversalDeserializer().Decode
-	obj, _, err := decode([]byte(yamlContent), nil, nil)
-	if err != nil {
-		return nil, fmt.Errorf("解析YAML失败: %w", err)
-	}
-
-	rollout, ok := obj.(*rollouts.Rollout)
-	if !ok {
-		return nil, fmt.Errorf("无法转换为Rollout类型")
-	}
-
-	rolloutClient, err := k8sClient.GetRolloutClient()
-	if err != nil {
-		return nil, fmt.Errorf("获取Rollout客户端失败: %w", err)
-	}
-
-	var dryRunOpt []string
-	if dryRun {
-		dryRunOpt = []string{metav1.DryRunAll}
-	}
-
-	// 尝试获取现有资源
-	existing, err := rolloutClient.ArgoprojV1alpha1().Rollouts(rollout.Namespace).Get(ctx, rollout.Name, metav1.GetOptions{})
-	if err == nil {
-		// 资源存在，执行更新
-		rollout.ResourceVersion = existing.ResourceVersion
-		result, err := rolloutClient.ArgoprojV1alpha1().Rollouts(rollout.Namespace).Update(ctx, rollout, metav1.UpdateOptions{DryRun: dryRunOpt})
-		if err != nil {
-			return nil, err
-		}
-		return result, nil
-	}
-
-	// 资源不存在，执行创建
-	result, err := rolloutClient.ArgoprojV1alpha1().Rollouts(rollout.Namespace).Create(ctx, rollout, metav1.CreateOptions{DryRun: dryRunOpt})
-	if err != nil {
-		return nil, err
-	}
-	return result, nil
-}
-
 // GetRolloutPods 获取Rollout关联的Pods
 func (h *RolloutHandler) GetRolloutPods(c *gin.Context) {
 	clusterId := c.Param("clusterID")
@@ -0,0 +1,218 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/clay-wangzhi/KubePolaris/internal/services"
+	"github.com/clay-wangzhi/KubePolaris/pkg/logger"
+
+	rolloutsclientset "github.com/argoproj/argo-rollouts/pkg/client/clientset/versioned"
+	"github.com/gin-gonic/gin"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/kubernetes"
+)
+
+// RolloutPromoteRequest 推进 Rollout 请求
+type RolloutPromoteRequest struct {
+	Full bool `json:"full"` // true 时跳过剩余步骤直接全量发布
+}
+
+// RolloutWeightRequest 设置金丝雀权重请求
+type RolloutWeightRequest struct {
+	Weight *int32 `json:"weight" binding:"required,min=0,max=100"`
+}
+
+// rolloutAction 对 Rollout 执行的控制操作，返回操作后的发布状态
+type rolloutAction func(ctx context.Context, client rolloutsclientset.Interface, clientset kubernetes.Interface, namespace, name string) (*services.RolloutControlStatus, error)
+
+// GetRolloutStatus 获取Rollout发布状态：当前步骤、金丝雀权重及关联的AnalysisRun/Experiment
+func (h *RolloutHandler) GetRolloutStatus(c *gin.Context) {
+	client, _, ok := h.rolloutClients(c)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	status, err := services.GetRolloutControlStatus(ctx, client, c.Param("namespace"), c.Param("name"))
+	if err != nil {
+		code := http.StatusInternalServerError
+		if apierrors.IsNotFound(err) {
+			code = http.StatusNotFound
+		}
+		c.JSON(code, gin.H{
+			"code":    code,
+			"message": "获取Rollout状态失败: " + err.Error(),
+			"data":    nil,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "success",
+		"data":    status,
+	})
+}
+
+// PromoteRollout 推进Rollout到下一步，full 为 true 时全量发布
+func (h *RolloutHandler) PromoteRollout(c *gin.Context) {
+	var req RolloutPromoteRequest
+	// 请求体可选，未提供时按单步推进处理
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"code":    400,
+				"message": "参数错误: " + err.Error(),
+			})
+			return
+		}
+	}
+	message := "已推进到下一步"
+	if req.Full {
+		message = "已触发全量发布"
+	}
+	h.runRolloutAction(c, "promote", message,
+		func(ctx context.Context, client rolloutsclientset.Interface, _ kubernetes.Interface, namespace, name string) (*services.RolloutControlStatus, error) {
+			return services.PromoteRollout(ctx, client, namespace, name, req.Full)
+		})
+}
+
+// AbortRollout 中止Rollout
+func (h *RolloutHandler) AbortRollout(c *gin.Context) {
+	h.runRolloutAction(c, "abort", "已中止发布",
+		func(ctx context.Context, client rolloutsclientset.Interface, _ kubernetes.Interface, namespace, name string) (*services.RolloutControlStatus, error) {
+			return services.AbortRollout(ctx, client, namespace, name)
+		})
+}
+
+// RetryRollout 重试已中止的Rollout
+func (h *RolloutHandler) RetryRollout(c *gin.Context) {
+	h.runRolloutAction(c, "retry", "已重试发布",
+		func(ctx context.Context, client rolloutsclientset.Interface, _ kubernetes.Interface, namespace, name string) (*services.RolloutControlStatus, error) {
+			return services.RetryRollout(ctx, client, namespace, name)
+		})
+}
+
+// PauseRollout 暂停Rollout
+func (h *RolloutHandler) PauseRollout(c *gin.Context) {
+	h.runRolloutAction(c, "pause", "已暂停发布",
+		func(ctx context.Context, client rolloutsclientset.Interface, _ kubernetes.Interface, namespace, name string) (*services.RolloutControlStatus, error) {
+			return services.SetRolloutPaused(ctx, client, namespace, name, true)
+		})
+}
+
+// ResumeRollout 恢复Rollout
+func (h *RolloutHandler) ResumeRollout(c *gin.Context) {
+	h.runRolloutAction(c, "resume", "已恢复发布",
+		func(ctx context.Context, client rolloutsclientset.Interface, _ kubernetes.Interface, namespace, name string) (*services.RolloutControlStatus, error) {
+			return services.SetRolloutPaused(ctx, client, namespace, name, false)
+		})
+}
+
+// RestartRollout 滚动重启Rollout
+func (h *RolloutHandler) RestartRollout(c *gin.Context) {
+	h.runRolloutAction(c, "restart", "已触发滚动重启",
+		func(ctx context.Context, client rolloutsclientset.Interface, _ kubernetes.Interface, namespace, name string) (*services.RolloutControlStatus, error) {
+			return services.RestartRollout(ctx, client, namespace, name)
+		})
+}
+
+// SetRolloutImage 更新Rollout容器镜像
+func (h *RolloutHandler) SetRolloutImage(c *gin.Context) {
+	var req WorkloadImageRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "参数错误: " + err.Error(),
+		})
+		return
+	}
+	h.runRolloutAction(c, "update", "镜像已更新",
+		func(ctx context.Context, client rolloutsclientset.Interface, clientset kubernetes.Interface, namespace, name string) (*services.RolloutControlStatus, error) {
+			return services.SetRolloutImages(ctx, client, clientset, namespace, name, req.Images)
+		})
+}
+
+// SetRolloutWeight 设置金丝雀权重（跳转到对应 setWeight 步骤）
+func (h *RolloutHandler) SetRolloutWeight(c *gin.Context) {
+	var req RolloutWeightRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "参数错误: " + err.Error(),
+		})
+		return
+	}
+	h.runRolloutAction(c, "update", "金丝雀权重已更新",
+		func(ctx context.Context, client rolloutsclientset.Interface, _ kubernetes.Interface, namespace, name string) (*services.RolloutControlStatus, error) {
+			return services.SetRolloutCanaryWeight(ctx, client, namespace, name, *req.Weight)
+		})
+}
+
+// runRolloutAction 校验权限后执行 Rollout 控制操作，并返回发布状态
+func (h *RolloutHandler) runRolloutAction(c *gin.Context, verb, successMessage string, action rolloutAction) {
+	namespace := c.Param("namespace")
+	name := c.Param("name")
+
+	if !checkWorkloadPermission(c, "Rollout", verb) {
+		return
+	}
+
+	logger.Info("Rollout操作: cluster=%s %s/%s %s", c.Param("clusterID"), namespace, name, verb)
+
+	client, clientset, ok := h.rolloutClients(c)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	status, err := action(ctx, client, clientset, namespace, name)
+	if err != nil {
+		respondWorkloadActionError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": successMessage,
+		"data":    status,
+	})
+}
+
+// rolloutClients 获取请求集群的 Argo Rollouts 客户端与 clientset，失败时直接写入响应
+func (h *RolloutHandler) rolloutClients(c *gin.Context) (rolloutsclientset.Interface, kubernetes.Interface, bool) {
+	cluster, err := h.clusterService.GetCluster(parseClusterID(c.Param("clusterID")))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"code":    404,
+			"message": "集群不存在",
+		})
+		return nil, nil, false
+	}
+
+	// 获取缓存的 K8s 客户端
+	k8sClient, err := h.k8sMgr.GetK8sClient(cluster)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    500,
+			"message": "获取K8s客户端失败: " + err.Error(),
+		})
+		return nil, nil, false
+	}
+
+	rolloutClient, err := k8sClient.GetRolloutClient()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    500,
+			"message": "获取Rollout客户端失败: " + err.Error(),
+		})
+		return nil, nil, false
+	}
+	return rolloutClient, k8sClient.GetClientset(), true
+}
@@ -61,8 +61,13 @@ func (h *SearchHandler) GlobalSearch(c *gin.Context) {
 
 	logger.Info("全局搜索: %s", query)
 
-	// 获取所有集群
-	clusters, err := h.clusterSvc.GetAllClusters()
+	selector, ok := parseClusterSelector(c)
+	if !ok {
+		return
+	}
+
+	// 获取匹配标签选择器的集群（未指定时为所有集群）
+	clusters, err := h.clusterSvc.GetClustersBySelector(selector)
 	if err != nil {
 		logger.Error("获取集群列表失败", "error", err)
 		c.JSON(http.StatusInternalServerError, gin.H{
@@ -281,6 +286,29 @@ func (h *SearchHandler) GlobalSearch(c *gin.Context) {
 				}
 			}
 		}
+
+		// CronJob
+		cronJobLister := h.k8sMgr.CronJobsLister(cluster.ID)
+		if cronJobLister != nil {
+			cronJobs, err := cronJobLister.List(labels.Everything())
+			if err == nil {
+				for _, cronJob := range cronJobs {
+					if strings.Contains(strings.ToLower(cronJob.Name), strings.ToLower(query)) {
+						results = append(results, SearchResult{
+							Type:        "workload",
+							ID:          cronJob.Name,
+							Name:        cronJob.Name,
+							Namespace:   cronJob.Namespace,
+							ClusterID:   clusterIDStr,
+							ClusterName: cluster.Name,
+							Status:      "CronJob",
+							Kind:        "CronJob",
+							Description: cronJob.Spec.Schedule,
+						})
+					}
+				}
+			}
+		}
 	}
 
 	// 计算统计信息
@@ -337,8 +365,13 @@ func (h *SearchHandler) QuickSearch(c *gin.Context) {
 
 	logger.Info("快速搜索: %s", query)
 
-	// 获取所有集群
-	clusters, err := h.clusterSvc.GetAllClusters()
+	selector, ok := parseClusterSelector(c)
+	if !ok {
+		return
+	}
+
+	// 获取匹配标签选择器的集群（未指定时为所有集群）
+	clusters, err := h.clusterSvc.GetClustersBySelector(selector)
 	if err != nil {
 		logger.Error("获取集群列表失败", "error", err)
 		c.JSON(http.StatusInternalServerError, gin.H{
@@ -558,6 +591,34 @@ func (h *SearchHandler) QuickSearch(c *gin.Context) {
 					}
 				}
 			}
+
+			// CronJob
+			if len(typeResults["workload"]) < typeLimit {
+				cronJobLister := h.k8sMgr.CronJobsLister(cluster.ID)
+				if cronJobLister != nil {
+					cronJobs, err := cronJobLister.List(labels.Everything())
+					if err == nil {
+						for _, cronJob := range cronJobs {
+							if len(typeResults["workload"]) >= typeLimit {
+								break
+							}
+							if strings.Contains(strings.ToLower(cronJob.Name), strings.ToLower(query)) {
+								typeResults["workload"] = append(typeResults["workload"], SearchResult{
+									Type:        "workload",
+									ID:          cronJob.Name,
+									Name:        cronJob.Name,
+									Namespace:   cronJob.Namespace,
+									ClusterID:   clusterIDStr,
+									ClusterName: cluster.Name,
+									Status:      "CronJob",
+									Kind:        "CronJob",
+									Description: cronJob.Spec.Schedule,
+								})
+							}
+						}
+					}
+				}
+			}
 		}
 	}
 
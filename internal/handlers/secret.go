@@ -500,6 +500,8 @@ func (h *SecretHandler) UpdateSecret(c *gin.Context) {
 		dataBytes[k] = []byte(v)
 	}
 
+	previous := secret.DeepCopy()
+
 	// 更新Secret
 	secret.Labels = req.Labels
 	secret.Annotations = req.Annotations
@@ -511,6 +513,7 @@ func (h *SecretHandler) UpdateSecret(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("更新Secret失败: %v", err)})
 		return
 	}
+	recordTypedRevision(c, h.db, previous, corev1.SchemeGroupVersion.WithKind("Secret"), services.RevisionActionUpdate)
 
 	c.JSON(http.StatusOK, gin.H{
 		"code":    200,
@@ -596,6 +596,9 @@ func (h *ServiceHandler) UpdateService(c *gin.Context) {
 
 	clientset := k8sClient.GetClientset()
 
+	// 保存变更前的对象，用于记录修订
+	previous, previousErr := clientset.CoreV1().Services(namespace).Get(context.Background(), name, metav1.GetOptions{})
+
 	var service *corev1.Service
 
 	// 根据更新方式选择处理逻辑
@@ -615,6 +618,9 @@ func (h *ServiceHandler) UpdateService(c *gin.Context) {
 		c.JSON(500, gin.H{"code": 500, "message": fmt.Sprintf("更新Service失败: %v", err), "data": nil})
 		return
 	}
+	if previousErr == nil {
+		recordTypedRevision(c, h.db, previous, corev1.SchemeGroupVersion.WithKind("Service"), services.RevisionActionUpdate)
+	}
 
 	logger.Info("Service更新成功", "clusterId", clusterID, "namespace", service.Namespace, "name", service.Name)
 	c.JSON(200, gin.H{"code": 200, "message": "Service更新成功", "data": h.convertToServiceInfo(service)})
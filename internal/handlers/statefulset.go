@@ -20,9 +20,7 @@ import (
 	appsv1 "k8s.io/api/apps/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
-	"k8s.io/apimachinery/pkg/runtime/serializer"
-	"k8s.io/apimachinery/pkg/util/yaml"
-	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/kubernetes"
 	sigsyaml "sigs.k8s.io/yaml"
 )
 
@@ -389,7 +387,7 @@ func (h *StatefulSetHandler) ApplyYAML(c *gin.Context) {
 		return
 	}
 
-	logger.Info("应用StatefulSet YAML: cluster=%s, dryRun=%v", clusterId, req.DryRun)
+	logger.Info("应用StatefulSet YAML: cluster=%s, dryRun=%v, force=%v", clusterId, req.DryRun, req.Force)
 
 	clusterID := parseClusterID(clusterId)
 	cluster, err := h.clusterService.GetCluster(clusterID)
@@ -410,63 +408,7 @@ func (h *StatefulSetHandler) ApplyYAML(c *gin.Context) {
 		return
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-	defer cancel()
-
-	var objMap map[string]interface{}
-	if err := yaml.Unmarshal([]byte(req.YAML), &objMap); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"code":    400,
-			"message": "YAML格式错误: " + err.Error(),
-		})
-		return
-	}
-
-	if objMap["apiVersion"] == nil || objMap["kind"] == nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"code":    400,
-			"message": "YAML缺少必要字段: apiVersion 或 kind",
-		})
-		return
-	}
-
-	kind := objMap["kind"].(string)
-	if kind != "StatefulSet" {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"code":    400,
-			"message": "YAML类型错误，期望StatefulSet，实际为: " + kind,
-		})
-		return
-	}
-
-	metadata, ok := objMap["metadata"].(map[string]interface{})
-	if !ok {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"code":    400,
-			"message": "YAML缺少 metadata 字段",
-		})
-		return
-	}
-
-	namespace, _ := metadata["namespace"].(string)
-	if namespace == "" {
-		namespace = "default"
-	}
-
-	result, err := h.applyYAML(ctx, k8sClient, req.YAML, namespace, req.DryRun)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"code":    500,
-			"message": "YAML应用失败: " + err.Error(),
-		})
-		return
-	}
-
-	c.JSON(http.StatusOK, gin.H{
-		"code":    200,
-		"message": "YAML应用成功",
-		"data":    result,
-	})
+	applyYAMLWithEngine(c, h.db, k8sClient, req.YAML, "StatefulSet", req.DryRun, req.Force)
 }
 
 // DeleteStatefulSet 删除StatefulSet
@@ -548,37 +490,47 @@ func (h *StatefulSetHandler) convertToStatefulSetInfo(ss *appsv1.StatefulSet) St
 	}
 }
 
-func (h *StatefulSetHandler) applyYAML(ctx context.Context, k8sClient *services.K8sClient, yamlContent string, namespace string, dryRun bool) (interface{}, error) {
-	decode := serializer.NewCodecFactory(scheme.Scheme).UniversalDeserializer().Decode
-	obj, _, err := decode([]byte(yamlContent), nil, nil)
-	if err != nil {
-		return nil, fmt.Errorf("解析YAML失败: %w", err)
-	}
-
-	statefulSet, ok := obj.(*appsv1.StatefulSet)
-	if !ok {
-		return nil, fmt.Errorf("无法转换为StatefulSet类型")
-	}
+// RestartStatefulSet 滚动重启StatefulSet
+func (h *StatefulSetHandler) RestartStatefulSet(c *gin.Context) {
+	runWorkloadAction(c, h.clusterService, h.k8sMgr, services.WorkloadKindStatefulSet, "restart", "已触发滚动重启",
+		func(ctx context.Context, clientset kubernetes.Interface, namespace, name string) (*services.WorkloadRolloutStatus, error) {
+			return services.RestartWorkload(ctx, clientset, services.WorkloadKindStatefulSet, namespace, name)
+		})
+}
 
-	clientset := k8sClient.GetClientset()
-	var dryRunOpt []string
-	if dryRun {
-		dryRunOpt = []string{metav1.DryRunAll}
+// SetStatefulSetImage 更新StatefulSet容器镜像
+func (h *StatefulSetHandler) SetStatefulSetImage(c *gin.Context) {
+	var req WorkloadImageRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "参数错误: " + err.Error(),
+		})
+		return
 	}
+	runWorkloadAction(c, h.clusterService, h.k8sMgr, services.WorkloadKindStatefulSet, "update", "镜像已更新",
+		func(ctx context.Context, clientset kubernetes.Interface, namespace, name string) (*services.WorkloadRolloutStatus, error) {
+			return services.SetWorkloadImages(ctx, clientset, services.WorkloadKindStatefulSet, namespace, name, req.Images)
+		})
+}
 
-	existing, err := clientset.AppsV1().StatefulSets(statefulSet.Namespace).Get(ctx, statefulSet.Name, metav1.GetOptions{})
-	if err == nil {
-		statefulSet.ResourceVersion = existing.ResourceVersion
-		result, err := clientset.AppsV1().StatefulSets(statefulSet.Namespace).Update(ctx, statefulSet, metav1.UpdateOptions{DryRun: dryRunOpt})
-		if err != nil {
-			return nil, err
-		}
-		return result, nil
+// SetStatefulSetPartition 设置StatefulSet滚动更新分区，用于分批（金丝雀）发布
+func (h *StatefulSetHandler) SetStatefulSetPartition(c *gin.Context) {
+	var req StatefulSetPartitionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "参数错误: " + err.Error(),
+		})
+		return
 	}
+	runWorkloadAction(c, h.clusterService, h.k8sMgr, services.WorkloadKindStatefulSet, "update", "分区已更新",
+		func(ctx context.Context, clientset kubernetes.Interface, namespace, name string) (*services.WorkloadRolloutStatus, error) {
+			return services.SetStatefulSetPartition(ctx, clientset, namespace, name, *req.Partition)
+		})
+}
 
-	result, err := clientset.AppsV1().StatefulSets(statefulSet.Namespace).Create(ctx, statefulSet, metav1.CreateOptions{DryRun: dryRunOpt})
-	if err != nil {
-		return nil, err
-	}
-	return result, nil
+// GetStatefulSetRolloutStatus 获取StatefulSet发布进度
+func (h *StatefulSetHandler) GetStatefulSetRolloutStatus(c *gin.Context) {
+	getWorkloadRolloutStatus(c, h.clusterService, h.k8sMgr, services.WorkloadKindStatefulSet)
 }
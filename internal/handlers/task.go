@@ -0,0 +1,229 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/clay-wangzhi/KubePolaris/internal/middleware"
+	"github.com/clay-wangzhi/KubePolaris/internal/models"
+	"github.com/clay-wangzhi/KubePolaris/internal/services"
+	"github.com/clay-wangzhi/KubePolaris/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+)
+
+// TaskHandler 后台任务处理器
+type TaskHandler struct {
+	taskSvc       *services.TaskService
+	permissionSvc *services.PermissionService
+	upgrader      websocket.Upgrader
+}
+
+// NewTaskHandler 创建后台任务处理器
+func NewTaskHandler(taskSvc *services.TaskService, permissionSvc *services.PermissionService) *TaskHandler {
+	return &TaskHandler{
+		taskSvc:       taskSvc,
+		permissionSvc: permissionSvc,
+		upgrader: websocket.Upgrader{
+			CheckOrigin: func(r *http.Request) bool {
+				return true
+			},
+			ReadBufferSize:  1024,
+			WriteBufferSize: 1024,
+		},
+	}
+}
+
+// ListTasks 获取集群的任务列表，支持 type、status、page、pageSize 查询参数
+func (h *TaskHandler) ListTasks(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("pageSize", "20"))
+
+	result, err := h.taskSvc.List(services.TaskListRequest{
+		ClusterID: parseClusterID(c.Param("clusterID")),
+		Type:      c.Query("type"),
+		Status:    c.Query("status"),
+		Page:      page,
+		PageSize:  pageSize,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    500,
+			"message": "获取任务列表失败: " + err.Error(),
+			"data":    nil,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "success",
+		"data":    result,
+	})
+}
+
+// GetTask 获取任务详情（含步骤与日志）
+func (h *TaskHandler) GetTask(c *gin.Context) {
+	task, ok := h.loadClusterTask(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "success",
+		"data":    task,
+	})
+}
+
+// CancelTask 取消任务，仅任务提交者或具备该任务所需操作权限的用户可以取消
+func (h *TaskHandler) CancelTask(c *gin.Context) {
+	task, ok := h.loadClusterTask(c)
+	if !ok {
+		return
+	}
+	if !canCancelTask(middleware.GetClusterPermission(c), task, c.GetUint("user_id")) {
+		c.JSON(http.StatusForbidden, gin.H{
+			"code":    403,
+			"message": "只有任务提交者或具备相应操作权限的用户才能取消该任务",
+			"data":    nil,
+		})
+		return
+	}
+
+	if err := h.taskSvc.Cancel(task.ID); err != nil {
+		code := http.StatusInternalServerError
+		if errors.Is(err, services.ErrTaskFinished) {
+			code = http.StatusConflict
+		}
+		c.Set("error_message", err.Error())
+		c.JSON(code, gin.H{
+			"code":    code,
+			"message": "取消任务失败: " + err.Error(),
+			"data":    nil,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "已请求取消任务",
+		"data":    nil,
+	})
+}
+
+// StreamTask 通过 WebSocket 推送任务进度
+// 连接建立后先发送 snapshot（任务当前状态、步骤与日志），之后推送增量事件，任务结束后关闭连接。
+func (h *TaskHandler) StreamTask(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "无效的任务ID"})
+		return
+	}
+
+	// 先订阅再读取快照，避免遗漏两者之间产生的事件
+	events, unsubscribe := h.taskSvc.Subscribe(uint(id))
+	defer unsubscribe()
+
+	task, err := h.taskSvc.Get(uint(id))
+	if err != nil {
+		if errors.Is(err, services.ErrTaskNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"code": 404, "message": "任务不存在"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "获取任务失败: " + err.Error()})
+		return
+	}
+	// 需要具有任务所属集群的访问权限
+	if _, err := h.permissionSvc.GetUserClusterPermission(c.GetUint("user_id"), task.ClusterID); err != nil {
+		c.JSON(http.StatusForbidden, gin.H{"code": 403, "message": "无权限访问该集群"})
+		return
+	}
+
+	conn, err := h.upgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		logger.Error("WebSocket升级失败", "error", err)
+		return
+	}
+	defer func() {
+		_ = conn.Close()
+	}()
+
+	if err := conn.WriteJSON(gin.H{"type": "snapshot", "task": task}); err != nil || task.IsFinished() {
+		return
+	}
+
+	// 监听客户端断开
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	for {
+		select {
+		case <-closed:
+			return
+		case event := <-events:
+			if err := conn.WriteJSON(event); err != nil {
+				return
+			}
+			if event.Type == services.TaskEventStatus && (&models.Task{Status: event.Status}).IsFinished() {
+				return
+			}
+		}
+	}
+}
+
+// canCancelTask 检查用户能否取消任务：提交者本人，或具备全部命名空间访问权限及任务所需的操作权限；
+// 未登记操作权限的任务类型仅集群管理员可以取消
+func canCancelTask(permission *models.ClusterPermission, task *models.Task, userID uint) bool {
+	if userID != 0 && task.UserID == userID {
+		return true
+	}
+	if permission == nil {
+		return false
+	}
+	action, ok := taskActions[task.Type]
+	if !ok {
+		return permission.PermissionType == models.PermissionTypeAdmin
+	}
+	return permission.HasAllNamespaceAccess() && permission.CanPerformAction(action)
+}
+
+// loadClusterTask 读取请求集群下的任务，失败时直接写入响应
+func (h *TaskHandler) loadClusterTask(c *gin.Context) (*models.Task, bool) {
+	id, err := strconv.ParseUint(c.Param("taskID"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "无效的任务ID",
+			"data":    nil,
+		})
+		return nil, false
+	}
+
+	task, err := h.taskSvc.Get(uint(id))
+	if err == nil && task.ClusterID != parseClusterID(c.Param("clusterID")) {
+		err = services.ErrTaskNotFound
+	}
+	if err != nil {
+		status, message := http.StatusInternalServerError, "获取任务失败: "+err.Error()
+		if errors.Is(err, services.ErrTaskNotFound) {
+			status, message = http.StatusNotFound, "任务不存在"
+		}
+		c.JSON(status, gin.H{
+			"code":    status,
+			"message": message,
+			"data":    nil,
+		})
+		return nil, false
+	}
+	return task, true
+}
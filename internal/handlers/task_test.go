@@ -0,0 +1,61 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/glebarez/sqlite"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"github.com/clay-wangzhi/KubePolaris/internal/models"
+	"github.com/clay-wangzhi/KubePolaris/internal/services"
+)
+
+func TestCancelTaskRequiresCreatorOrTaskPermission(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	require.NoError(t, err)
+	// 内存数据库仅在单个连接内可见
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	sqlDB.SetMaxOpenConns(1)
+	require.NoError(t, db.AutoMigrate(&models.Task{}, &models.TaskStep{}, &models.TaskLog{}))
+	h := NewTaskHandler(services.NewTaskService(db), nil)
+
+	cancel := func(userID uint, permission *models.ClusterPermission, taskID string) int {
+		router := gin.New()
+		router.POST("/clusters/:clusterID/tasks/:taskID/cancel", func(c *gin.Context) {
+			c.Set("user_id", userID)
+			c.Set("cluster_permission", permission)
+		}, h.CancelTask)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/clusters/1/tasks/"+taskID+"/cancel", nil))
+		return w.Code
+	}
+
+	for _, task := range []*models.Task{
+		{ClusterID: 1, Type: TaskTypeNodeDrain, Status: models.TaskStatusRunning, UserID: 1},
+		{ClusterID: 1, Type: TaskTypeNodeDrain, Status: models.TaskStatusRunning, UserID: 1},
+		{ClusterID: 1, Type: TaskTypeNodeDrain, Status: models.TaskStatusRunning, UserID: 2},
+	} {
+		require.NoError(t, db.Create(task).Error)
+	}
+
+	dev := &models.ClusterPermission{PermissionType: models.PermissionTypeDev, Namespaces: `["app"]`}
+	ops := &models.ClusterPermission{PermissionType: models.PermissionTypeOps, Namespaces: `["*"]`}
+	admin := &models.ClusterPermission{PermissionType: models.PermissionTypeAdmin, Namespaces: `["*"]`}
+
+	// 非提交者且无驱逐权限
+	assert.Equal(t, http.StatusForbidden, cancel(2, dev, "1"))
+	assert.Equal(t, http.StatusForbidden, cancel(2, ops, "1"))
+	// 具备驱逐权限的管理员
+	assert.Equal(t, http.StatusOK, cancel(3, admin, "1"))
+	// 提交者本人
+	assert.Equal(t, http.StatusOK, cancel(1, dev, "2"))
+	assert.Equal(t, http.StatusOK, cancel(2, dev, "3"))
+}
@@ -0,0 +1,172 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/clay-wangzhi/KubePolaris/internal/k8s"
+	"github.com/clay-wangzhi/KubePolaris/internal/middleware"
+	"github.com/clay-wangzhi/KubePolaris/internal/services"
+	"github.com/clay-wangzhi/KubePolaris/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/kubernetes"
+)
+
+// WorkloadImageRequest 更新容器镜像请求
+type WorkloadImageRequest struct {
+	Images map[string]string `json:"images" binding:"required"` // 容器名 -> 镜像
+}
+
+// StatefulSetPartitionRequest 设置 StatefulSet 滚动更新分区请求
+type StatefulSetPartitionRequest struct {
+	Partition *int32 `json:"partition" binding:"required"`
+}
+
+// workloadAction 对工作负载执行的生命周期操作，返回操作后的发布进度
+type workloadAction func(ctx context.Context, clientset kubernetes.Interface, namespace, name string) (*services.WorkloadRolloutStatus, error)
+
+// runWorkloadAction 校验命名空间与操作权限后执行工作负载生命周期操作，并返回发布进度
+// verb 为权限动作（如 restart、update），与 kind 组合为 deployment:restart 形式。
+func runWorkloadAction(c *gin.Context, clusterService *services.ClusterService, k8sMgr *k8s.ClusterInformerManager,
+	kind, verb, successMessage string, action workloadAction) {
+	namespace := c.Param("namespace")
+	name := c.Param("name")
+
+	if !checkWorkloadPermission(c, kind, verb) {
+		return
+	}
+
+	logger.Info("工作负载操作: cluster=%s %s %s/%s %s", c.Param("clusterID"), kind, namespace, name, verb)
+
+	clientset, ok := workloadClientset(c, clusterService, k8sMgr)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	status, err := action(ctx, clientset, namespace, name)
+	if err != nil {
+		respondWorkloadActionError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": successMessage,
+		"data":    status,
+	})
+}
+
+// checkWorkloadPermission 校验当前用户对请求命名空间的访问权限及 kind:verb 操作权限，无权限时直接写入响应
+func checkWorkloadPermission(c *gin.Context, kind, verb string) bool {
+	permission, ok := getClusterPermission(c)
+	if !ok {
+		return false
+	}
+	if !permission.HasNamespaceAccess(c.Param("namespace")) || !permission.CanPerformAction(strings.ToLower(kind)+":"+verb) {
+		c.JSON(http.StatusForbidden, gin.H{
+			"code":    403,
+			"message": "无权限执行该操作",
+			"data":    nil,
+		})
+		return false
+	}
+	return true
+}
+
+// checkNamespaceAccess 校验当前用户能否访问指定命名空间，失败时直接写入 403 响应
+func checkNamespaceAccess(c *gin.Context, namespace string) bool {
+	if _, hasAccess := middleware.CheckNamespacePermission(c, namespace); !hasAccess {
+		c.JSON(http.StatusForbidden, gin.H{
+			"code":    403,
+			"message": fmt.Sprintf("无权访问命名空间: %s", namespace),
+		})
+		return false
+	}
+	return true
+}
+
+// respondWorkloadActionError 将工作负载操作错误转换为对应的 HTTP 状态码，并记录到审计日志
+func respondWorkloadActionError(c *gin.Context, err error) {
+	code := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, services.ErrInvalidWorkloadOperation):
+		code = http.StatusBadRequest
+	case apierrors.IsNotFound(err):
+		code = http.StatusNotFound
+	case apierrors.IsConflict(err), apierrors.IsAlreadyExists(err):
+		code = http.StatusConflict
+	case apierrors.IsInvalid(err):
+		code = http.StatusUnprocessableEntity
+	case apierrors.IsForbidden(err):
+		code = http.StatusForbidden
+	}
+	c.Set("error_message", err.Error())
+	c.JSON(code, gin.H{
+		"code":    code,
+		"message": "操作失败: " + err.Error(),
+		"data":    nil,
+	})
+}
+
+// getWorkloadRolloutStatus 查询工作负载发布进度
+func getWorkloadRolloutStatus(c *gin.Context, clusterService *services.ClusterService, k8sMgr *k8s.ClusterInformerManager, kind string) {
+	clientset, ok := workloadClientset(c, clusterService, k8sMgr)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	status, err := services.GetWorkloadRolloutStatus(ctx, clientset, kind, c.Param("namespace"), c.Param("name"))
+	if err != nil {
+		code := http.StatusInternalServerError
+		if apierrors.IsNotFound(err) {
+			code = http.StatusNotFound
+		}
+		c.JSON(code, gin.H{
+			"code":    code,
+			"message": "获取发布进度失败: " + err.Error(),
+			"data":    nil,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "success",
+		"data":    status,
+	})
+}
+
+// workloadClientset 获取请求集群的 clientset，失败时直接写入响应
+func workloadClientset(c *gin.Context, clusterService *services.ClusterService, k8sMgr *k8s.ClusterInformerManager) (kubernetes.Interface, bool) {
+	cluster, err := clusterService.GetCluster(parseClusterID(c.Param("clusterID")))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"code":    404,
+			"message": "集群不存在",
+		})
+		return nil, false
+	}
+
+	// 获取缓存的 K8s 客户端
+	k8sClient, err := k8sMgr.GetK8sClient(cluster)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    500,
+			"message": "获取K8s客户端失败: " + err.Error(),
+		})
+		return nil, false
+	}
+	return k8sClient.GetClientset(), true
+}
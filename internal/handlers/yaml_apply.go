@@ -0,0 +1,100 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/clay-wangzhi/KubePolaris/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// applyYAMLWithEngine 解析单个资源 YAML 并通过统一应用引擎（服务端应用）创建或更新，
+// 返回应用结果与差异，并保存变更前的修订快照；expectedKind 非空时校验资源类型。
+func applyYAMLWithEngine(c *gin.Context, db *gorm.DB, k8sClient *services.K8sClient, yamlContent, expectedKind string, dryRun, force bool) {
+	obj, err := services.ParseApplyYAML(yamlContent, expectedKind)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	engine, err := services.NewApplyEngine(k8sClient)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    500,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	// 预估配额用量，超限时在结果中提示；被配额拒绝时返回超限明细，代替笼统的 exceeded quota 错误
+	quotaWarnings := engine.CheckQuota(ctx, []*unstructured.Unstructured{obj})
+
+	result, err := engine.Apply(ctx, obj, services.ApplyOptions{DryRun: dryRun, Force: force})
+	if err != nil {
+		if apierrors.IsForbidden(err) && len(quotaWarnings) > 0 {
+			c.Set("error_message", err.Error())
+			c.JSON(http.StatusForbidden, gin.H{
+				"code":    403,
+				"message": "YAML应用失败，超出资源配额: " + services.FormatQuotaWarnings(quotaWarnings),
+				"data": gin.H{
+					"quotaWarnings": quotaWarnings,
+				},
+			})
+			return
+		}
+		respondApplyError(c, err)
+		return
+	}
+	result.QuotaWarnings = quotaWarnings
+	recordApplyRevision(c, services.NewResourceRevisionService(db), services.RevisionActionApply, result)
+
+	message := "YAML应用成功"
+	if dryRun {
+		message = "预检通过"
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": message,
+		"data":    result,
+	})
+}
+
+// respondApplyError 输出应用失败响应：字段冲突返回 409 及冲突明细，其余按 Kubernetes 错误码返回
+func respondApplyError(c *gin.Context, err error) {
+	var conflictErr *services.ApplyConflictError
+	if errors.As(err, &conflictErr) {
+		c.JSON(http.StatusConflict, gin.H{
+			"code":    409,
+			"message": conflictErr.Error(),
+			"data": gin.H{
+				"conflicts": conflictErr.Conflicts,
+			},
+		})
+		return
+	}
+
+	status := http.StatusInternalServerError
+	if errors.Is(err, services.ErrUnknownResourceType) {
+		status = http.StatusBadRequest
+	} else if statusErr, ok := err.(apierrors.APIStatus); ok {
+		if code := int(statusErr.Status().Code); code >= 400 && code < 600 {
+			status = code
+		}
+	}
+	c.JSON(status, gin.H{
+		"code":    status,
+		"message": "YAML应用失败: " + err.Error(),
+	})
+}
@@ -0,0 +1,286 @@
+package k8s
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/clay-wangzhi/KubePolaris/internal/models"
+	"github.com/clay-wangzhi/KubePolaris/pkg/logger"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/tools/cache"
+)
+
+const (
+	// authFailureRebuildThreshold 连续认证失败（401/403）达到该次数后重建集群缓存
+	authFailureRebuildThreshold = 3
+
+	// minRebuildInterval 同一集群两次自动重建的最小间隔，避免凭据持续无效时反复重建
+	minRebuildInterval = 2 * time.Minute
+)
+
+// informerTracker 记录单个 informer 的同步、事件与 watch 错误（用于缓存统计与健康检查）
+type informerTracker struct {
+	syncedAt  atomic.Int64 // UnixNano，0 表示尚未同步
+	lastEvent atomic.Int64 // UnixNano，0 表示尚无事件
+
+	consecutiveErrors atomic.Int64 // 最近一次成功事件或重新 List 后的连续错误次数
+	authFailures      atomic.Int64 // 最近一次成功事件或重新 List 后的连续认证失败次数
+
+	// lastSyncRV 返回 informer 最近一次成功 List/Watch 的资源版本，用于判断出错后是否已恢复
+	lastSyncRV func() string
+
+	mu          sync.Mutex
+	errorCount  int64
+	lastError   string
+	lastErrorAt time.Time
+	rvAtError   string // 最近一次出错时的资源版本
+}
+
+// newInformerTracker 为 informer 注册事件记录与 watch 错误处理器（需在 informer 启动前调用）
+// onAuthFailure 在连续认证失败时回调，参数为当前连续失败次数。
+func newInformerTracker(resource string, informer cache.SharedIndexInformer, onAuthFailure func(failures int64)) *informerTracker {
+	t := &informerTracker{lastSyncRV: informer.LastSyncResourceVersion}
+	if _, err := informer.AddEventHandler(t.eventHandler()); err != nil {
+		logger.Warn("注册 informer 事件处理器失败", "resource", resource, "error", err)
+	}
+	if err := informer.SetWatchErrorHandler(func(_ *cache.Reflector, err error) {
+		if isBenignWatchError(err) {
+			return
+		}
+		logger.Warn("informer watch 失败", "resource", resource, "error", err)
+		if failures := t.recordError(err); failures > 0 && onAuthFailure != nil {
+			onAuthFailure(failures)
+		}
+	}); err != nil {
+		logger.Warn("注册 informer watch 错误处理器失败", "resource", resource, "error", err)
+	}
+	return t
+}
+
+func (t *informerTracker) touchEvent() {
+	t.lastEvent.Store(time.Now().UnixNano())
+	t.resetErrors()
+}
+
+func (t *informerTracker) resetErrors() {
+	t.consecutiveErrors.Store(0)
+	t.authFailures.Store(0)
+}
+
+// eventHandler 返回仅记录事件时间的处理器
+func (t *informerTracker) eventHandler() cache.ResourceEventHandler {
+	return cache.ResourceEventHandlerFuncs{
+		AddFunc:    func(interface{}) { t.touchEvent() },
+		UpdateFunc: func(interface{}, interface{}) { t.touchEvent() },
+		DeleteFunc: func(interface{}) { t.touchEvent() },
+	}
+}
+
+// recordError 记录一次 watch 错误，返回连续认证失败次数（非认证错误返回 0）
+func (t *informerTracker) recordError(err error) int64 {
+	rv := t.currentRV()
+	t.mu.Lock()
+	t.errorCount++
+	t.lastError = err.Error()
+	t.lastErrorAt = time.Now()
+	t.rvAtError = rv
+	t.mu.Unlock()
+
+	t.consecutiveErrors.Add(1)
+	if apierrors.IsUnauthorized(err) || apierrors.IsForbidden(err) {
+		return t.authFailures.Add(1)
+	}
+	return 0
+}
+
+// healthy 最近一次成功事件或重新 List 后没有发生错误
+// 空资源（如没有任何 CronJob）重新 List 成功后不会产生事件，此时通过资源版本变化判断已恢复。
+func (t *informerTracker) healthy() bool {
+	if t.consecutiveErrors.Load() == 0 {
+		return true
+	}
+	if t.relistedSinceError() {
+		t.resetErrors()
+		return true
+	}
+	return false
+}
+
+// relistedSinceError 出错后 informer 是否已成功 List/Watch（资源版本发生变化）
+func (t *informerTracker) relistedSinceError() bool {
+	rv := t.currentRV()
+	if rv == "" {
+		return false
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return rv != t.rvAtError
+}
+
+func (t *informerTracker) currentRV() string {
+	if t.lastSyncRV == nil {
+		return ""
+	}
+	return t.lastSyncRV()
+}
+
+func (t *informerTracker) status(resource string, dynamic bool) ResourceCacheStatus {
+	healthy := t.healthy()
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	rs := ResourceCacheStatus{
+		Resource:          resource,
+		Dynamic:           dynamic,
+		Healthy:           healthy,
+		ErrorCount:        t.errorCount,
+		ConsecutiveErrors: t.consecutiveErrors.Load(),
+		LastError:         t.lastError,
+		LastEventAt:       unixNanoToTime(t.lastEvent.Load()),
+	}
+	if !t.lastErrorAt.IsZero() {
+		at := t.lastErrorAt
+		rs.LastErrorAt = &at
+	}
+	return rs
+}
+
+// isBenignWatchError watch 正常断开或资源版本过期，reflector 会自动重新 List，无需记录
+func isBenignWatchError(err error) bool {
+	return errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) ||
+		apierrors.IsResourceExpired(err) || apierrors.IsGone(err)
+}
+
+// rebuildRecord 集群缓存自动重建记录
+type rebuildRecord struct {
+	count  int
+	lastAt time.Time
+	reason string
+}
+
+// SetClusterLoader 设置重建缓存时重新加载集群配置的方法（用于获取更新后的凭据）
+func (m *ClusterInformerManager) SetClusterLoader(loader func(clusterID uint) (*models.Cluster, error)) {
+	m.mu.Lock()
+	m.clusterLoader = loader
+	m.mu.Unlock()
+}
+
+// handleAuthFailure 连续认证失败达到阈值时异步重建集群缓存
+func (m *ClusterInformerManager) handleAuthFailure(rt *ClusterRuntime, resource string, failures int64) {
+	if failures < authFailureRebuildThreshold {
+		return
+	}
+	if !rt.rebuilding.CompareAndSwap(false, true) {
+		return
+	}
+	go m.rebuildCluster(rt, resource)
+}
+
+// rebuildCluster 停止旧的 informer 并使用最新的集群配置重新创建
+func (m *ClusterInformerManager) rebuildCluster(rt *ClusterRuntime, resource string) {
+	clusterID := rt.cluster.ID
+
+	m.mu.Lock()
+	if current, ok := m.clusters[clusterID]; !ok || current != rt {
+		// 集群已被停止或重建
+		m.mu.Unlock()
+		return
+	}
+	record := m.rebuilds[clusterID]
+	if record != nil && time.Since(record.lastAt) < minRebuildInterval {
+		m.mu.Unlock()
+		rt.rebuilding.Store(false)
+		return
+	}
+	if record == nil {
+		record = &rebuildRecord{}
+		m.rebuilds[clusterID] = record
+	}
+	record.count++
+	record.lastAt = time.Now()
+	record.reason = resource + " 连续认证失败"
+	loader := m.clusterLoader
+	m.mu.Unlock()
+
+	logger.Warn("集群 informer 连续认证失败，重建缓存", "clusterID", clusterID, "resource", resource)
+
+	cluster := rt.cluster
+	if loader != nil {
+		if latest, err := loader(clusterID); err != nil {
+			logger.Error("重建缓存时加载集群失败", "clusterID", clusterID, "error", err)
+		} else {
+			cluster = latest
+		}
+	}
+
+	m.StopForCluster(clusterID)
+	if _, err := m.EnsureForCluster(cluster); err != nil {
+		logger.Error("重建集群缓存失败", "clusterID", clusterID, "error", err)
+	}
+}
+
+// GetCacheStatus 返回集群缓存健康状态（各资源 watch 错误、最近事件时间与自动重建记录）
+func (m *ClusterInformerManager) GetCacheStatus(clusterID uint) (*ClusterCacheStatus, error) {
+	m.mu.RLock()
+	rt, ok := m.clusters[clusterID]
+	record := m.rebuilds[clusterID]
+	var rebuild rebuildRecord
+	if record != nil {
+		rebuild = *record
+	}
+	m.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("集群 %d 未初始化 informer", clusterID)
+	}
+
+	status := &ClusterCacheStatus{
+		ClusterID:     clusterID,
+		Synced:        true,
+		Healthy:       true,
+		RebuildCount:  rebuild.count,
+		RebuildReason: rebuild.reason,
+	}
+	if !rebuild.lastAt.IsZero() {
+		status.LastRebuildAt = &rebuild.lastAt
+	}
+
+	for _, ni := range rt.typedInformers {
+		if !ni.informer.HasSynced() {
+			status.Synced = false
+		}
+		status.add(ni.tracker.status(ni.resource, false))
+	}
+	for _, d := range rt.dynamicInformers() {
+		status.add(d.tracker.status(d.gvr.String(), true))
+	}
+	status.Stale = !status.Synced || !status.Healthy
+	return status, nil
+}
+
+// add 追加资源状态；动态资源的错误仅影响其自身，不影响集群整体健康
+func (s *ClusterCacheStatus) add(rs ResourceCacheStatus) {
+	s.Resources = append(s.Resources, rs)
+	if !rs.Healthy && !rs.Dynamic {
+		s.Healthy = false
+	}
+}
+
+// IsCacheStale 集群缓存是否可能已过期（未同步或存在 watch 错误）；未初始化的集群返回 false
+func (m *ClusterInformerManager) IsCacheStale(clusterID uint) bool {
+	m.mu.RLock()
+	rt, ok := m.clusters[clusterID]
+	m.mu.RUnlock()
+	if !ok || !rt.started {
+		return false
+	}
+	for _, ni := range rt.typedInformers {
+		if !ni.informer.HasSynced() || !ni.tracker.healthy() {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,74 @@
+package k8s
+
+import (
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func TestInformerTrackerErrors(t *testing.T) {
+	tracker := &informerTracker{}
+	assert.True(t, tracker.healthy())
+
+	assert.Zero(t, tracker.recordError(errors.New("connection refused")))
+	assert.False(t, tracker.healthy())
+
+	unauthorized := apierrors.NewUnauthorized("token expired")
+	assert.EqualValues(t, 1, tracker.recordError(unauthorized))
+	assert.EqualValues(t, 2, tracker.recordError(unauthorized))
+
+	status := tracker.status("pods", false)
+	assert.False(t, status.Healthy)
+	assert.EqualValues(t, 3, status.ErrorCount)
+	assert.EqualValues(t, 3, status.ConsecutiveErrors)
+	assert.Contains(t, status.LastError, "token expired")
+	assert.NotNil(t, status.LastErrorAt)
+	assert.Nil(t, status.LastEventAt)
+
+	// 收到事件后恢复健康，连续计数清零，累计次数保留
+	tracker.touchEvent()
+	status = tracker.status("pods", false)
+	assert.True(t, status.Healthy)
+	assert.EqualValues(t, 3, status.ErrorCount)
+	assert.Zero(t, status.ConsecutiveErrors)
+	assert.NotNil(t, status.LastEventAt)
+	assert.EqualValues(t, 1, tracker.recordError(unauthorized))
+}
+
+func TestInformerTrackerRecoversAfterRelist(t *testing.T) {
+	// 空资源重新 List 成功后没有任何事件，只有资源版本变化
+	rv := "100"
+	tracker := &informerTracker{lastSyncRV: func() string { return rv }}
+
+	assert.Zero(t, tracker.recordError(errors.New("connection reset by peer")))
+	assert.False(t, tracker.healthy())
+
+	// 资源版本未变化（如 List 仍然失败）时保持不健康
+	forbidden := apierrors.NewForbidden(schema.GroupResource{Resource: "cronjobs"}, "", errors.New("rbac"))
+	assert.EqualValues(t, 1, tracker.recordError(forbidden))
+	assert.False(t, tracker.healthy())
+
+	rv = "105"
+	status := tracker.status("cronjobs", false)
+	assert.True(t, status.Healthy)
+	assert.Zero(t, status.ConsecutiveErrors)
+	assert.EqualValues(t, 2, status.ErrorCount)
+	assert.Nil(t, status.LastEventAt)
+
+	// 认证失败计数同样清零
+	assert.EqualValues(t, 1, tracker.recordError(forbidden))
+	assert.False(t, tracker.healthy())
+}
+
+func TestIsBenignWatchError(t *testing.T) {
+	gr := schema.GroupResource{Resource: "pods"}
+	assert.True(t, isBenignWatchError(io.EOF))
+	assert.True(t, isBenignWatchError(apierrors.NewResourceExpired("too old resource version")))
+	assert.True(t, isBenignWatchError(apierrors.NewGone("gone")))
+	assert.False(t, isBenignWatchError(apierrors.NewForbidden(gr, "", errors.New("rbac"))))
+	assert.False(t, isBenignWatchError(errors.New("dial tcp: i/o timeout")))
+}
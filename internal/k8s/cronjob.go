@@ -0,0 +1,105 @@
+package k8s
+
+import (
+	batchv1 "k8s.io/api/batch/v1"
+	batchv1beta1 "k8s.io/api/batch/v1beta1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/discovery"
+	batchv1listers "k8s.io/client-go/listers/batch/v1"
+	batchv1beta1listers "k8s.io/client-go/listers/batch/v1beta1"
+)
+
+// CronJob 可用的 API 版本（batch/v1 自 1.21 起可用，1.25 起移除 batch/v1beta1）
+const (
+	CronJobVersionV1      = "batch/v1"
+	CronJobVersionV1beta1 = "batch/v1beta1"
+)
+
+// detectCronJobVersion 通过发现接口探测集群支持的 CronJob 版本，优先 batch/v1；均不支持时返回空
+func detectCronJobVersion(disc discovery.DiscoveryInterface) string {
+	for _, gv := range []string{CronJobVersionV1, CronJobVersionV1beta1} {
+		list, err := disc.ServerResourcesForGroupVersion(gv)
+		if err != nil || list == nil {
+			continue
+		}
+		for _, r := range list.APIResources {
+			if r.Name == "cronjobs" {
+				return gv
+			}
+		}
+	}
+	return ""
+}
+
+// v1beta1CronJobLister 将 batch/v1beta1 的 CronJob Lister 适配为 batch/v1 类型
+// 便于上层统一处理，无需关心集群实际版本。
+type v1beta1CronJobLister struct {
+	lister batchv1beta1listers.CronJobLister
+}
+
+func (l *v1beta1CronJobLister) List(selector labels.Selector) ([]*batchv1.CronJob, error) {
+	items, err := l.lister.List(selector)
+	if err != nil {
+		return nil, err
+	}
+	return convertV1beta1CronJobs(items), nil
+}
+
+func (l *v1beta1CronJobLister) CronJobs(namespace string) batchv1listers.CronJobNamespaceLister {
+	return &v1beta1CronJobNamespaceLister{lister: l.lister.CronJobs(namespace)}
+}
+
+type v1beta1CronJobNamespaceLister struct {
+	lister batchv1beta1listers.CronJobNamespaceLister
+}
+
+func (l *v1beta1CronJobNamespaceLister) List(selector labels.Selector) ([]*batchv1.CronJob, error) {
+	items, err := l.lister.List(selector)
+	if err != nil {
+		return nil, err
+	}
+	return convertV1beta1CronJobs(items), nil
+}
+
+func (l *v1beta1CronJobNamespaceLister) Get(name string) (*batchv1.CronJob, error) {
+	item, err := l.lister.Get(name)
+	if err != nil {
+		return nil, err
+	}
+	return convertV1beta1CronJob(item), nil
+}
+
+func convertV1beta1CronJobs(items []*batchv1beta1.CronJob) []*batchv1.CronJob {
+	result := make([]*batchv1.CronJob, 0, len(items))
+	for _, item := range items {
+		result = append(result, convertV1beta1CronJob(item))
+	}
+	return result
+}
+
+// convertV1beta1CronJob 将 batch/v1beta1 CronJob 转换为 batch/v1（两者字段一致，结果为深拷贝，不会修改缓存对象）
+func convertV1beta1CronJob(in *batchv1beta1.CronJob) *batchv1.CronJob {
+	cj := in.DeepCopy()
+	return &batchv1.CronJob{
+		TypeMeta:   cj.TypeMeta,
+		ObjectMeta: cj.ObjectMeta,
+		Spec: batchv1.CronJobSpec{
+			Schedule:                   cj.Spec.Schedule,
+			TimeZone:                   cj.Spec.TimeZone,
+			StartingDeadlineSeconds:    cj.Spec.StartingDeadlineSeconds,
+			ConcurrencyPolicy:          batchv1.ConcurrencyPolicy(cj.Spec.ConcurrencyPolicy),
+			Suspend:                    cj.Spec.Suspend,
+			SuccessfulJobsHistoryLimit: cj.Spec.SuccessfulJobsHistoryLimit,
+			FailedJobsHistoryLimit:     cj.Spec.FailedJobsHistoryLimit,
+			JobTemplate: batchv1.JobTemplateSpec{
+				ObjectMeta: cj.Spec.JobTemplate.ObjectMeta,
+				Spec:       cj.Spec.JobTemplate.Spec,
+			},
+		},
+		Status: batchv1.CronJobStatus{
+			Active:             cj.Status.Active,
+			LastScheduleTime:   cj.Status.LastScheduleTime,
+			LastSuccessfulTime: cj.Status.LastSuccessfulTime,
+		},
+	}
+}
@@ -0,0 +1,72 @@
+package k8s
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	batchv1beta1 "k8s.io/api/batch/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	batchv1beta1listers "k8s.io/client-go/listers/batch/v1beta1"
+	k8stesting "k8s.io/client-go/testing"
+	"k8s.io/client-go/tools/cache"
+)
+
+func TestDetectCronJobVersion(t *testing.T) {
+	cronJobs := []metav1.APIResource{{Name: "cronjobs", Kind: "CronJob", Namespaced: true}}
+	tests := []struct {
+		name      string
+		resources []*metav1.APIResourceList
+		want      string
+	}{
+		{"v1 优先", []*metav1.APIResourceList{
+			{GroupVersion: "batch/v1beta1", APIResources: cronJobs},
+			{GroupVersion: "batch/v1", APIResources: cronJobs},
+		}, CronJobVersionV1},
+		{"仅 v1beta1", []*metav1.APIResourceList{
+			{GroupVersion: "batch/v1", APIResources: []metav1.APIResource{{Name: "jobs", Kind: "Job"}}},
+			{GroupVersion: "batch/v1beta1", APIResources: cronJobs},
+		}, CronJobVersionV1beta1},
+		{"不支持", nil, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			disc := &fakediscovery.FakeDiscovery{Fake: &k8stesting.Fake{Resources: tt.resources}}
+			assert.Equal(t, tt.want, detectCronJobVersion(disc))
+		})
+	}
+}
+
+func TestV1beta1CronJobLister(t *testing.T) {
+	suspend := true
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	require.NoError(t, indexer.Add(&batchv1beta1.CronJob{
+		ObjectMeta: metav1.ObjectMeta{Name: "backup", Namespace: "ops"},
+		Spec: batchv1beta1.CronJobSpec{
+			Schedule:          "0 2 * * *",
+			Suspend:           &suspend,
+			ConcurrencyPolicy: batchv1beta1.ForbidConcurrent,
+		},
+	}))
+
+	lister := &v1beta1CronJobLister{lister: batchv1beta1listers.NewCronJobLister(indexer)}
+
+	items, err := lister.List(labels.Everything())
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+	assert.Equal(t, "0 2 * * *", items[0].Spec.Schedule)
+	assert.Equal(t, "Forbid", string(items[0].Spec.ConcurrencyPolicy))
+	assert.True(t, *items[0].Spec.Suspend)
+
+	cj, err := lister.CronJobs("ops").Get("backup")
+	require.NoError(t, err)
+	assert.Equal(t, "backup", cj.Name)
+
+	// 转换结果为深拷贝，修改不影响缓存对象
+	*cj.Spec.Suspend = false
+	again, err := lister.CronJobs("ops").Get("backup")
+	require.NoError(t, err)
+	assert.True(t, *again.Spec.Suspend)
+}
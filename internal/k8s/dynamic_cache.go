@@ -0,0 +1,384 @@
+package k8s
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/clay-wangzhi/KubePolaris/internal/models"
+	"github.com/clay-wangzhi/KubePolaris/pkg/logger"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	"k8s.io/client-go/tools/cache"
+)
+
+const (
+	// DefaultDynamicInformerIdleTimeout 动态 informer 默认空闲回收时间
+	DefaultDynamicInformerIdleTimeout = 10 * time.Minute
+
+	// minEvictionInterval 空闲回收扫描的最小间隔
+	minEvictionInterval = 30 * time.Second
+
+	// memorySampleSize 估算缓存内存时每类资源抽样的对象数
+	memorySampleSize = 50
+)
+
+// namedInformer 带资源名的 typed informer
+type namedInformer struct {
+	resource string
+	informer cache.SharedIndexInformer
+	tracker  *informerTracker
+}
+
+// newNamedInformer 包装 informer 并注册事件与 watch 错误记录（需在 informer 启动前调用）
+func newNamedInformer(resource string, informer cache.SharedIndexInformer, onAuthFailure func(failures int64)) *namedInformer {
+	return &namedInformer{
+		resource: resource,
+		informer: informer,
+		tracker:  newInformerTracker(resource, informer, onAuthFailure),
+	}
+}
+
+// dynamicInformer 按 GVR 懒启动的动态 informer
+type dynamicInformer struct {
+	gvr       schema.GroupVersionResource
+	informer  cache.SharedIndexInformer
+	lister    cache.GenericLister
+	tracker   *informerTracker
+	stopCh    chan struct{}
+	startedAt time.Time
+	lastUsed  atomic.Int64 // UnixNano
+}
+
+func (d *dynamicInformer) touch() {
+	d.lastUsed.Store(time.Now().UnixNano())
+}
+
+// dynamicInformerFactory 集群级动态 informer 工厂
+// 与 informers.SharedInformerFactory 不同，每个 GVR 拥有独立的停止通道，以便空闲时单独回收。
+type dynamicInformerFactory struct {
+	client dynamic.Interface
+
+	mu        sync.Mutex
+	informers map[schema.GroupVersionResource]*dynamicInformer
+}
+
+func newDynamicInformerFactory(client dynamic.Interface) *dynamicInformerFactory {
+	return &dynamicInformerFactory{
+		client:    client,
+		informers: make(map[schema.GroupVersionResource]*dynamicInformer),
+	}
+}
+
+// informerFor 获取 GVR 对应的 informer，不存在时创建并启动
+// 仅已同步的 informer 会刷新使用时间，始终无法同步的 informer 由调用方通过 remove 回收。
+func (f *dynamicInformerFactory) informerFor(gvr schema.GroupVersionResource) *dynamicInformer {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if d, ok := f.informers[gvr]; ok {
+		if d.informer.HasSynced() {
+			d.touch()
+		}
+		return d
+	}
+
+	// resync 为 0 表示关闭周期性全量 Resync；监听全部命名空间，由调用方按权限过滤
+	gi := dynamicinformer.NewFilteredDynamicInformer(f.client, gvr, "", 0,
+		cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc}, nil)
+	// 动态资源的 403 多为该资源未授权，不触发集群缓存重建，仅记录错误
+	d := &dynamicInformer{
+		gvr:       gvr,
+		informer:  gi.Informer(),
+		lister:    gi.Lister(),
+		tracker:   newInformerTracker(gvr.String(), gi.Informer(), nil),
+		stopCh:    make(chan struct{}),
+		startedAt: time.Now(),
+	}
+	d.touch()
+	go d.informer.Run(d.stopCh)
+	f.informers[gvr] = d
+	logger.Info("启动动态 informer", "gvr", gvr.String())
+	return d
+}
+
+// remove 停止并移除指定 informer（仅当其仍为该 GVR 当前的 informer 时），返回是否移除
+func (f *dynamicInformerFactory) remove(d *dynamicInformer) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if current, ok := f.informers[d.gvr]; !ok || current != d {
+		return false
+	}
+	close(d.stopCh)
+	delete(f.informers, d.gvr)
+	return true
+}
+
+// evictIdle 停止并移除超过 idle 未被使用的 informer，返回被回收的 GVR
+func (f *dynamicInformerFactory) evictIdle(idle time.Duration) []schema.GroupVersionResource {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	deadline := time.Now().Add(-idle).UnixNano()
+	var evicted []schema.GroupVersionResource
+	for gvr, d := range f.informers {
+		if d.lastUsed.Load() < deadline {
+			close(d.stopCh)
+			delete(f.informers, gvr)
+			evicted = append(evicted, gvr)
+		}
+	}
+	return evicted
+}
+
+// stopAll 停止全部动态 informer
+func (f *dynamicInformerFactory) stopAll() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for gvr, d := range f.informers {
+		close(d.stopCh)
+		delete(f.informers, gvr)
+	}
+}
+
+// snapshot 返回当前全部动态 informer
+func (f *dynamicInformerFactory) snapshot() []*dynamicInformer {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	result := make([]*dynamicInformer, 0, len(f.informers))
+	for _, d := range f.informers {
+		result = append(result, d)
+	}
+	return result
+}
+
+// dynamicFactory 获取集群的动态 informer 工厂（首次使用时创建）
+func (rt *ClusterRuntime) dynamicFactory() (*dynamicInformerFactory, error) {
+	rt.dynamicMu.Lock()
+	defer rt.dynamicMu.Unlock()
+	if rt.dynamic != nil {
+		return rt.dynamic, nil
+	}
+	client, err := rt.k8sClient.DynamicClient()
+	if err != nil {
+		return nil, fmt.Errorf("创建动态客户端失败: %w", err)
+	}
+	rt.dynamic = newDynamicInformerFactory(client)
+	return rt.dynamic, nil
+}
+
+// dynamicInformers 返回集群当前运行的动态 informer（按 GVR 排序）
+func (rt *ClusterRuntime) dynamicInformers() []*dynamicInformer {
+	rt.dynamicMu.Lock()
+	f := rt.dynamic
+	rt.dynamicMu.Unlock()
+	if f == nil {
+		return nil
+	}
+	dynamics := f.snapshot()
+	sort.Slice(dynamics, func(i, j int) bool {
+		return dynamics[i].gvr.String() < dynamics[j].gvr.String()
+	})
+	return dynamics
+}
+
+// stopDynamic 停止集群的全部动态 informer
+func (rt *ClusterRuntime) stopDynamic() {
+	rt.dynamicMu.Lock()
+	f := rt.dynamic
+	rt.dynamicMu.Unlock()
+	if f != nil {
+		f.stopAll()
+	}
+}
+
+// DynamicLister 返回任意 GVR 的缓存 Lister：首次访问时启动对应 informer 并等待同步
+// 调用方需自行确认资源支持 list/watch。
+func (m *ClusterInformerManager) DynamicLister(ctx context.Context, cluster *models.Cluster, gvr schema.GroupVersionResource, timeout time.Duration) (cache.GenericLister, error) {
+	rt, err := m.EnsureForCluster(cluster)
+	if err != nil {
+		return nil, err
+	}
+	factory, err := rt.dynamicFactory()
+	if err != nil {
+		return nil, err
+	}
+	return factory.syncedLister(ctx, gvr, timeout)
+}
+
+// syncedLister 获取 GVR 的 informer 并等待同步
+// 等待超时且 List/Watch 出错（如无权限）时停止并移除该 informer，避免 reflector 无限重试，下次访问重新创建；
+// 仅超时而无错误（资源量大、首次 List 较慢）时保留，未同步前不刷新使用时间，始终无法同步时由空闲回收兜底。
+func (f *dynamicInformerFactory) syncedLister(ctx context.Context, gvr schema.GroupVersionResource, timeout time.Duration) (cache.GenericLister, error) {
+	d := f.informerFor(gvr)
+	if !d.informer.HasSynced() {
+		wctx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+		if !cache.WaitForCacheSync(wctx.Done(), d.informer.HasSynced) {
+			if ctx.Err() == nil && !d.tracker.healthy() && f.remove(d) {
+				logger.Warn("动态 informer 同步失败，已停止", "gvr", gvr.String(), "error", d.tracker.status(gvr.String(), true).LastError)
+			}
+			return nil, fmt.Errorf("%s 缓存尚未就绪", gvr.String())
+		}
+	}
+	d.touch()
+	d.tracker.syncedAt.CompareAndSwap(0, time.Now().UnixNano())
+	return d.lister, nil
+}
+
+// SetDynamicIdleTimeout 设置动态 informer 的空闲回收时间（<=0 表示使用默认值）
+func (m *ClusterInformerManager) SetDynamicIdleTimeout(idle time.Duration) {
+	if idle <= 0 {
+		idle = DefaultDynamicInformerIdleTimeout
+	}
+	m.mu.Lock()
+	m.dynamicIdleTimeout = idle
+	m.mu.Unlock()
+}
+
+func (m *ClusterInformerManager) idleTimeout() time.Duration {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if m.dynamicIdleTimeout <= 0 {
+		return DefaultDynamicInformerIdleTimeout
+	}
+	return m.dynamicIdleTimeout
+}
+
+// StartDynamicEviction 启动后台回收循环，定期停止空闲的动态 informer（非阻塞，Stop 时退出）
+func (m *ClusterInformerManager) StartDynamicEviction() {
+	m.evictOnce.Do(func() {
+		go m.evictLoop()
+		logger.Info("动态 informer 空闲回收已启动", "idleTimeout", m.idleTimeout())
+	})
+}
+
+func (m *ClusterInformerManager) evictLoop() {
+	interval := m.idleTimeout() / 2
+	if interval < minEvictionInterval {
+		interval = minEvictionInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-m.stopCh:
+			return
+		case <-ticker.C:
+			m.EvictIdleDynamicInformers()
+		}
+	}
+}
+
+// EvictIdleDynamicInformers 立即回收所有集群中空闲的动态 informer
+func (m *ClusterInformerManager) EvictIdleDynamicInformers() {
+	idle := m.idleTimeout()
+
+	m.mu.RLock()
+	runtimes := make(map[uint]*ClusterRuntime, len(m.clusters))
+	for id, rt := range m.clusters {
+		runtimes[id] = rt
+	}
+	m.mu.RUnlock()
+
+	for id, rt := range runtimes {
+		rt.dynamicMu.Lock()
+		f := rt.dynamic
+		rt.dynamicMu.Unlock()
+		if f == nil {
+			continue
+		}
+		for _, gvr := range f.evictIdle(idle) {
+			logger.Info("回收空闲动态 informer", "clusterID", id, "gvr", gvr.String())
+		}
+	}
+}
+
+// GetCacheStats 返回集群 informer 缓存统计（对象数、内存估算、同步时间）
+func (m *ClusterInformerManager) GetCacheStats(clusterID uint) (*ClusterCacheStats, error) {
+	m.mu.RLock()
+	rt, ok := m.clusters[clusterID]
+	m.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("集群 %d 未初始化 informer", clusterID)
+	}
+
+	stats := &ClusterCacheStats{
+		ClusterID:          clusterID,
+		Synced:             rt.synced,
+		DynamicIdleTimeout: int64(m.idleTimeout().Seconds()),
+	}
+
+	for _, ni := range rt.typedInformers {
+		rs := buildResourceStats(ni.resource, ni.informer, ni.tracker)
+		stats.add(rs)
+	}
+
+	for _, d := range rt.dynamicInformers() {
+		rs := buildResourceStats(d.gvr.String(), d.informer, d.tracker)
+		rs.Dynamic = true
+		rs.StartedAt = unixNanoToTime(d.startedAt.UnixNano())
+		rs.LastUsedAt = unixNanoToTime(d.lastUsed.Load())
+		stats.add(rs)
+		stats.DynamicInformers++
+	}
+	return stats, nil
+}
+
+func (s *ClusterCacheStats) add(rs ResourceCacheStats) {
+	s.Resources = append(s.Resources, rs)
+	s.TotalObjects += rs.Objects
+	s.EstimatedBytes += rs.EstimatedBytes
+}
+
+func buildResourceStats(resource string, informer cache.SharedIndexInformer, tracker *informerTracker) ResourceCacheStats {
+	items := informer.GetStore().List()
+	return ResourceCacheStats{
+		Resource:                resource,
+		Objects:                 len(items),
+		EstimatedBytes:          estimateBytes(items),
+		Synced:                  informer.HasSynced(),
+		LastSyncResourceVersion: informer.LastSyncResourceVersion(),
+		SyncedAt:                unixNanoToTime(tracker.syncedAt.Load()),
+		LastEventAt:             unixNanoToTime(tracker.lastEvent.Load()),
+	}
+}
+
+// estimateBytes 抽样序列化部分对象，按平均大小估算整体内存占用
+// 反序列化后的对象在内存中通常比 JSON 更大，此处仅作数量级参考。
+func estimateBytes(items []interface{}) int64 {
+	if len(items) == 0 {
+		return 0
+	}
+	step := len(items) / memorySampleSize
+	if step < 1 {
+		step = 1
+	}
+	var sampled, total int64
+	for i := 0; i < len(items); i += step {
+		data, err := json.Marshal(items[i])
+		if err != nil {
+			continue
+		}
+		total += int64(len(data))
+		sampled++
+	}
+	if sampled == 0 {
+		return 0
+	}
+	return total / sampled * int64(len(items))
+}
+
+func unixNanoToTime(ns int64) *time.Time {
+	if ns == 0 {
+		return nil
+	}
+	t := time.Unix(0, ns)
+	return &t
+}
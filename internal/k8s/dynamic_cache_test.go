@@ -0,0 +1,78 @@
+package k8s
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	k8stesting "k8s.io/client-go/testing"
+	"k8s.io/client-go/tools/cache"
+)
+
+func TestDynamicInformerFactoryLifecycle(t *testing.T) {
+	gvr := schema.GroupVersionResource{Group: "example.com", Version: "v1", Resource: "widgets"}
+	widget := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "example.com/v1",
+		"kind":       "Widget",
+		"metadata":   map[string]interface{}{"name": "w1", "namespace": "default"},
+	}}
+	client := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{gvr: "WidgetList"}, widget)
+
+	f := newDynamicInformerFactory(client)
+	d := f.informerFor(gvr)
+	require.Same(t, d, f.informerFor(gvr), "同一 GVR 应复用 informer")
+
+	stopCh := make(chan struct{})
+	time.AfterFunc(5*time.Second, func() { close(stopCh) })
+	require.True(t, cache.WaitForCacheSync(stopCh, d.informer.HasSynced))
+
+	objs, err := d.lister.ByNamespace("default").List(labels.Everything())
+	require.NoError(t, err)
+	assert.Len(t, objs, 1)
+
+	stats := buildResourceStats(gvr.String(), d.informer, d.tracker)
+	assert.Equal(t, 1, stats.Objects)
+	assert.Positive(t, stats.EstimatedBytes)
+
+	// 未超过空闲时间不回收
+	assert.Empty(t, f.evictIdle(time.Hour))
+	assert.Len(t, f.snapshot(), 1)
+
+	d.lastUsed.Store(time.Now().Add(-2 * time.Hour).UnixNano())
+	assert.Equal(t, []schema.GroupVersionResource{gvr}, f.evictIdle(time.Hour))
+	assert.Empty(t, f.snapshot())
+	select {
+	case <-d.stopCh:
+	default:
+		t.Fatal("回收后 informer 应已停止")
+	}
+}
+
+func TestDynamicInformerEvictedWhenSyncFails(t *testing.T) {
+	gvr := schema.GroupVersionResource{Group: "example.com", Version: "v1", Resource: "widgets"}
+	client := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{gvr: "WidgetList"})
+	client.PrependReactor("list", "widgets", func(k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, apierrors.NewForbidden(gvr.GroupResource(), "", nil)
+	})
+
+	f := newDynamicInformerFactory(client)
+	_, err := f.syncedLister(context.Background(), gvr, 500*time.Millisecond)
+	require.Error(t, err)
+	assert.Empty(t, f.snapshot(), "同步失败的 informer 应被停止并移除")
+
+	// 未同步的 informer 再次获取时不刷新使用时间，可被空闲回收
+	d := f.informerFor(gvr)
+	d.lastUsed.Store(time.Now().Add(-2 * time.Hour).UnixNano())
+	f.informerFor(gvr)
+	assert.Equal(t, []schema.GroupVersionResource{gvr}, f.evictIdle(time.Hour))
+}
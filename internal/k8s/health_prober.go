@@ -0,0 +1,221 @@
+package k8s
+
+import (
+	"runtime/debug"
+	"sync"
+	"time"
+
+	"github.com/clay-wangzhi/KubePolaris/internal/models"
+	"github.com/clay-wangzhi/KubePolaris/internal/services"
+	"github.com/clay-wangzhi/KubePolaris/pkg/logger"
+)
+
+const (
+	// probeConcurrency 同时探测的集群数量上限
+	probeConcurrency = 5
+	// proberRestartBackoff 探测循环异常退出后的重启等待时间
+	proberRestartBackoff = 10 * time.Second
+)
+
+// ClusterHealthProber 后台集群健康探测器
+// 周期性对所有集群执行 TestConnection，维护 Status/Version/LastHeartbeat/CertExpireAt，
+// 记录状态变更历史，并在集群失联/恢复时停止/重建该集群的 informer。
+type ClusterHealthProber struct {
+	clusterSvc *services.ClusterService
+	mgr        *ClusterInformerManager
+	interval   time.Duration
+
+	stopCh   chan struct{}
+	stopOnce sync.Once
+	wg       sync.WaitGroup
+}
+
+// NewClusterHealthProber 创建集群健康探测器
+func NewClusterHealthProber(clusterSvc *services.ClusterService, mgr *ClusterInformerManager, interval time.Duration) *ClusterHealthProber {
+	return &ClusterHealthProber{
+		clusterSvc: clusterSvc,
+		mgr:        mgr,
+		interval:   interval,
+		stopCh:     make(chan struct{}),
+	}
+}
+
+// Start 启动后台探测（非阻塞）
+func (p *ClusterHealthProber) Start() {
+	p.wg.Add(1)
+	go p.supervise()
+	logger.Info("集群健康探测已启动", "interval", p.interval)
+}
+
+// Stop 停止后台探测并等待当前轮次结束
+func (p *ClusterHealthProber) Stop() {
+	p.stopOnce.Do(func() {
+		close(p.stopCh)
+	})
+	p.wg.Wait()
+}
+
+// supervise 守护探测循环：循环因 panic 退出时等待一段时间后重启
+func (p *ClusterHealthProber) supervise() {
+	defer p.wg.Done()
+	for {
+		if p.runSafely() {
+			return
+		}
+		logger.Warn("集群健康探测循环异常退出，稍后重启", "backoff", proberRestartBackoff)
+		select {
+		case <-p.stopCh:
+			return
+		case <-time.After(proberRestartBackoff):
+		}
+	}
+}
+
+// runSafely 运行探测循环，返回 true 表示正常停止，false 表示发生 panic
+func (p *ClusterHealthProber) runSafely() (stopped bool) {
+	defer func() {
+		if r := recover(); r != nil {
+			logger.Error("集群健康探测发生 panic", "panic", r, "stack", string(debug.Stack()))
+			stopped = false
+		}
+	}()
+	p.run()
+	return true
+}
+
+func (p *ClusterHealthProber) run() {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	p.probeAll()
+	for {
+		select {
+		case <-p.stopCh:
+			return
+		case <-ticker.C:
+			p.probeAll()
+		}
+	}
+}
+
+// probeAll 并发探测所有集群（并发数受 probeConcurrency 限制）
+func (p *ClusterHealthProber) probeAll() {
+	clusters, err := p.clusterSvc.GetAllClusters()
+	if err != nil {
+		logger.Error("健康探测获取集群列表失败", "error", err)
+		return
+	}
+
+	sem := make(chan struct{}, probeConcurrency)
+	var wg sync.WaitGroup
+	for _, cl := range clusters {
+		select {
+		case <-p.stopCh:
+			wg.Wait()
+			return
+		case sem <- struct{}{}:
+		}
+		wg.Add(1)
+		go func(cluster *models.Cluster) {
+			defer func() {
+				if r := recover(); r != nil {
+					logger.Error("探测集群时发生 panic", "cluster", cluster.Name, "panic", r)
+				}
+				<-sem
+				wg.Done()
+			}()
+			p.probeCluster(cluster)
+		}(cl)
+	}
+	wg.Wait()
+}
+
+// probeCluster 探测单个集群并落库结果
+func (p *ClusterHealthProber) probeCluster(cluster *models.Cluster) {
+	kc, err := services.NewK8sClientForCluster(cluster)
+	var info *services.ClusterInfo
+	if err == nil {
+		info, err = kc.TestConnection()
+	}
+
+	if err != nil {
+		p.handleProbeFailure(cluster, err)
+		return
+	}
+
+	if err := p.clusterSvc.UpdateClusterStatus(cluster.ID, info.Status, info.Version); err != nil {
+		logger.Error("更新集群状态失败", "cluster", cluster.Name, "error", err)
+		return
+	}
+	p.recordTransition(cluster, info.Status, info.Version, "")
+
+	// API Server 可达：确保 informer 处于运行状态（失联期间已被停止的会在此重建）
+	if _, err := p.mgr.EnsureForCluster(cluster); err != nil {
+		logger.Error("重建集群 informer 失败", "cluster", cluster.Name, "error", err)
+	}
+
+	p.updateCertExpireAt(cluster, kc)
+
+	// 补齐历史集群的唯一标识（凭据更新时用于校验是否为同一集群）
+	if cluster.ClusterUID == "" {
+		if uid, err := kc.GetClusterUID(); err == nil {
+			if err := p.clusterSvc.SetClusterUID(cluster.ID, uid); err != nil {
+				logger.Error("记录集群标识失败", "cluster", cluster.Name, "error", err)
+			}
+		}
+	}
+}
+
+// handleProbeFailure 探测失败：标记为 unhealthy 并停止该集群的 informer，避免 watch 持续重试
+func (p *ClusterHealthProber) handleProbeFailure(cluster *models.Cluster, probeErr error) {
+	logger.Warn("集群健康探测失败", "cluster", cluster.Name, "error", probeErr)
+
+	if cluster.Status != "unhealthy" {
+		if err := p.clusterSvc.SetClusterStatus(cluster.ID, "unhealthy"); err != nil {
+			logger.Error("更新集群状态失败", "cluster", cluster.Name, "error", err)
+			return
+		}
+	}
+	p.recordTransition(cluster, "unhealthy", cluster.Version, probeErr.Error())
+	p.mgr.StopForCluster(cluster.ID)
+}
+
+// recordTransition 状态发生变化时写入历史记录
+func (p *ClusterHealthProber) recordTransition(cluster *models.Cluster, toStatus, version, message string) {
+	if cluster.Status == toStatus {
+		return
+	}
+	logger.Info("集群状态变更", "cluster", cluster.Name, "from", cluster.Status, "to", toStatus)
+	if err := p.clusterSvc.RecordStatusTransition(&models.ClusterStatusHistory{
+		ClusterID:  cluster.ID,
+		FromStatus: cluster.Status,
+		ToStatus:   toStatus,
+		Version:    version,
+		Message:    message,
+	}); err != nil {
+		logger.Error("记录集群状态变更失败", "cluster", cluster.Name, "error", err)
+	}
+}
+
+// updateCertExpireAt 解析证书过期时间，发生变化时更新
+func (p *ClusterHealthProber) updateCertExpireAt(cluster *models.Cluster, kc *services.K8sClient) {
+	expireAt, err := kc.CertExpireAt()
+	if err != nil {
+		logger.Warn("解析集群证书过期时间失败", "cluster", cluster.Name, "error", err)
+		return
+	}
+	if sameTime(cluster.CertExpireAt, expireAt) {
+		return
+	}
+	if err := p.clusterSvc.UpdateClusterCertExpireAt(cluster.ID, expireAt); err != nil {
+		logger.Error("更新集群证书过期时间失败", "cluster", cluster.Name, "error", err)
+	}
+}
+
+func sameTime(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	// 数据库可能截断到秒，按秒比较
+	return a.Unix() == b.Unix()
+}
@@ -0,0 +1,95 @@
+package k8s
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/glebarez/sqlite"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"github.com/clay-wangzhi/KubePolaris/internal/models"
+	"github.com/clay-wangzhi/KubePolaris/internal/services"
+)
+
+func newTestProber(t *testing.T) (*ClusterHealthProber, *gorm.DB) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	require.NoError(t, err)
+	// 内存数据库仅在单个连接内可见
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	sqlDB.SetMaxOpenConns(1)
+	require.NoError(t, db.AutoMigrate(&models.Cluster{}, &models.ClusterStatusHistory{}))
+	return NewClusterHealthProber(services.NewClusterService(db), NewClusterInformerManager(), time.Minute), db
+}
+
+func TestProberStatusTransitions(t *testing.T) {
+	cases := []struct {
+		name        string
+		from        string
+		probeErr    error  // 非 nil 时模拟探测失败
+		to          string // 探测成功时的新状态
+		wantStatus  string
+		wantHistory bool
+	}{
+		{name: "健康保持健康", from: "healthy", to: "healthy", wantStatus: "healthy"},
+		{name: "健康变为失联", from: "healthy", probeErr: errors.New("connection refused"), wantStatus: "unhealthy", wantHistory: true},
+		{name: "失联保持失联", from: "unhealthy", probeErr: errors.New("connection refused"), wantStatus: "unhealthy"},
+		{name: "失联恢复健康", from: "unhealthy", to: "healthy", wantStatus: "healthy", wantHistory: true},
+		{name: "未知变为失联", from: "unknown", probeErr: errors.New("timeout"), wantStatus: "unhealthy", wantHistory: true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			p, db := newTestProber(t)
+			cluster := &models.Cluster{Name: "c1", APIServer: "https://127.0.0.1:6443", Status: tc.from, Version: "v1.29.3"}
+			require.NoError(t, db.Create(cluster).Error)
+
+			if tc.probeErr != nil {
+				p.handleProbeFailure(cluster, tc.probeErr)
+			} else {
+				require.NoError(t, p.clusterSvc.UpdateClusterStatus(cluster.ID, tc.to, cluster.Version))
+				p.recordTransition(cluster, tc.to, cluster.Version, "")
+			}
+
+			var stored models.Cluster
+			require.NoError(t, db.First(&stored, cluster.ID).Error)
+			assert.Equal(t, tc.wantStatus, stored.Status)
+
+			histories, err := p.clusterSvc.GetStatusHistory(cluster.ID, 10)
+			require.NoError(t, err)
+			if !tc.wantHistory {
+				assert.Empty(t, histories)
+				return
+			}
+			require.Len(t, histories, 1)
+			assert.Equal(t, tc.from, histories[0].FromStatus)
+			assert.Equal(t, tc.wantStatus, histories[0].ToStatus)
+			if tc.probeErr != nil {
+				assert.Equal(t, tc.probeErr.Error(), histories[0].Message)
+			}
+		})
+	}
+}
+
+func TestSameTime(t *testing.T) {
+	now := time.Now()
+	truncated := now.Truncate(time.Second)
+	later := now.Add(2 * time.Second)
+
+	assert.True(t, sameTime(nil, nil))
+	assert.False(t, sameTime(&now, nil))
+	assert.False(t, sameTime(nil, &now))
+	assert.True(t, sameTime(&now, &truncated))
+	assert.False(t, sameTime(&now, &later))
+}
+
+func TestProberStopIsIdempotent(t *testing.T) {
+	p, _ := newTestProber(t)
+	p.Start()
+	p.Stop()
+	p.Stop()
+}
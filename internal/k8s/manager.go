@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/clay-wangzhi/KubePolaris/internal/models"
@@ -26,6 +27,7 @@ import (
 )
 
 type ClusterRuntime struct {
+	cluster   *models.Cluster     // 创建 informer 时使用的集群配置
 	k8sClient *services.K8sClient // 缓存的 K8sClient（包含 clientset 和 rest.Config）
 	clientset *kubernetes.Clientset
 	factory   informers.SharedInformerFactory
@@ -44,17 +46,43 @@ type ClusterRuntime struct {
 	rolloutInformer     cache.SharedIndexInformer
 	rolloutLister       rolloutslisters.RolloutLister
 	rolloutGroupVersion schema.GroupVersion
+
+	// CronJob informer（按集群支持的 batch/v1 或 batch/v1beta1 创建）
+	cronJobVersion  string
+	cronJobInformer cache.SharedIndexInformer
+	cronJobLister   batchv1listers.CronJobLister
+
+	// 已注册的 typed informer（用于缓存统计与健康检查）
+	typedInformers []*namedInformer
+
+	// watch 连续认证失败时的回调（触发缓存重建）
+	onAuthFailure func(resource string, failures int64)
+	rebuilding    atomic.Bool
+
+	// 按 GVR 懒启动的动态 informer（首次使用时创建）
+	dynamicMu sync.Mutex
+	dynamic   *dynamicInformerFactory
 }
 
 // ClusterInformerManager 统一管理各集群的 Informer 生命周期与缓存访问
 type ClusterInformerManager struct {
 	mu       sync.RWMutex
 	clusters map[uint]*ClusterRuntime
+
+	dynamicIdleTimeout time.Duration // 动态 informer 空闲回收时间
+	clusterLoader      func(clusterID uint) (*models.Cluster, error)
+	rebuilds           map[uint]*rebuildRecord // 各集群缓存自动重建记录
+	evictOnce          sync.Once
+	stopCh             chan struct{}
+	stopOnce           sync.Once
 }
 
 func NewClusterInformerManager() *ClusterInformerManager {
 	return &ClusterInformerManager{
-		clusters: make(map[uint]*ClusterRuntime),
+		clusters:           make(map[uint]*ClusterRuntime),
+		dynamicIdleTimeout: DefaultDynamicInformerIdleTimeout,
+		rebuilds:           make(map[uint]*rebuildRecord),
+		stopCh:             make(chan struct{}),
 	}
 }
 
@@ -78,24 +106,53 @@ func (m *ClusterInformerManager) EnsureForCluster(cluster *models.Cluster) (*Clu
 	factory := informers.NewSharedInformerFactory(clientset, 0)
 
 	rt := &ClusterRuntime{
+		cluster:   cluster,
 		k8sClient: kc,
 		clientset: clientset,
 		factory:   factory,
 		stopCh:    make(chan struct{}),
 	}
 
-	// 预创建需要的 informer（pods/nodes/ns/services/deployments）
-	_ = factory.Core().V1().Pods().Informer()
-	_ = factory.Core().V1().Nodes().Informer()
-	_ = factory.Core().V1().Namespaces().Informer()
-	_ = factory.Core().V1().Services().Informer()
-	_ = factory.Core().V1().ConfigMaps().Informer()
-	_ = factory.Core().V1().Secrets().Informer()
-	_ = factory.Apps().V1().Deployments().Informer()
-	_ = factory.Apps().V1().StatefulSets().Informer()
-	_ = factory.Apps().V1().DaemonSets().Informer()
-	_ = factory.Batch().V1().Jobs().Informer()
-	// _ = factory.Batch().V1beta1().CronJobs().Informer()
+	rt.onAuthFailure = func(resource string, failures int64) {
+		m.handleAuthFailure(rt, resource, failures)
+	}
+	track := func(resource string, informer cache.SharedIndexInformer) *namedInformer {
+		return newNamedInformer(resource, informer, func(failures int64) {
+			rt.onAuthFailure(resource, failures)
+		})
+	}
+
+	// 预创建需要的 informer（pods/nodes/ns/services/deployments），并记录 watch 错误
+	rt.typedInformers = []*namedInformer{
+		track("pods", factory.Core().V1().Pods().Informer()),
+		track("nodes", factory.Core().V1().Nodes().Informer()),
+		track("namespaces", factory.Core().V1().Namespaces().Informer()),
+		track("services", factory.Core().V1().Services().Informer()),
+		track("configmaps", factory.Core().V1().ConfigMaps().Informer()),
+		track("secrets", factory.Core().V1().Secrets().Informer()),
+		track("deployments", factory.Apps().V1().Deployments().Informer()),
+		track("statefulsets", factory.Apps().V1().StatefulSets().Informer()),
+		track("daemonsets", factory.Apps().V1().DaemonSets().Informer()),
+		track("jobs", factory.Batch().V1().Jobs().Informer()),
+	}
+
+	// CronJob：按集群支持的版本创建 informer，batch/v1beta1 统一转换为 batch/v1 类型
+	rt.cronJobVersion = detectCronJobVersion(clientset.Discovery())
+	switch rt.cronJobVersion {
+	case CronJobVersionV1:
+		informer := factory.Batch().V1().CronJobs()
+		rt.cronJobInformer = informer.Informer()
+		rt.cronJobLister = informer.Lister()
+	case CronJobVersionV1beta1:
+		informer := factory.Batch().V1beta1().CronJobs()
+		rt.cronJobInformer = informer.Informer()
+		rt.cronJobLister = &v1beta1CronJobLister{lister: informer.Lister()}
+	default:
+		logger.Warn("集群不支持 CronJob 资源或探测失败", "cluster", cluster.Name)
+	}
+	if rt.cronJobInformer != nil {
+		rt.typedInformers = append(rt.typedInformers, track("cronjobs", rt.cronJobInformer))
+	}
 
 	// Detect and setup Argo Rollouts typed informer if CRD exists
 	if gv, found := hasArgoRollouts(clientset); found {
@@ -111,6 +168,7 @@ func (m *ClusterInformerManager) EnsureForCluster(cluster *models.Cluster) (*Clu
 				rt.rolloutLister = informer.Lister()
 				rt.rolloutGroupVersion = gv
 				rt.rolloutEnabled = true
+				rt.typedInformers = append(rt.typedInformers, track("rollouts", rt.rolloutInformer))
 			}
 		}
 	}
@@ -147,7 +205,9 @@ func (m *ClusterInformerManager) waitForSync(ctx context.Context, rt *ClusterRun
 			rt.factory.Apps().V1().StatefulSets().Informer().HasSynced,
 			rt.factory.Apps().V1().DaemonSets().Informer().HasSynced,
 			rt.factory.Batch().V1().Jobs().Informer().HasSynced,
-			// rt.factory.Batch().V1beta1().CronJobs().Informer().HasSynced,  todo 后续兼容不同版本
+		}
+		if rt.cronJobInformer != nil {
+			syncedFuncs = append(syncedFuncs, rt.cronJobInformer.HasSynced)
 		}
 		if rt.rolloutEnabled && rt.rolloutInformer != nil {
 			syncedFuncs = append(syncedFuncs, rt.rolloutInformer.HasSynced)
@@ -155,6 +215,10 @@ func (m *ClusterInformerManager) waitForSync(ctx context.Context, rt *ClusterRun
 		ok := cache.WaitForCacheSync(rt.stopCh, syncedFuncs...)
 		if ok {
 			rt.synced = true
+			now := time.Now().UnixNano()
+			for _, ni := range rt.typedInformers {
+				ni.tracker.syncedAt.CompareAndSwap(0, now)
+			}
 		}
 		close(syncCh)
 	}()
@@ -237,6 +301,16 @@ func (m *ClusterInformerManager) GetOverviewSnapshot(ctx context.Context, cluste
 		snap.Jobs = len(jobs)
 	}
 
+	// CronJobs
+	if rt.cronJobLister != nil {
+		cronJobs, err := rt.cronJobLister.List(labels.Everything())
+		if err != nil {
+			logger.Error("读取缓存 cronjobs 失败", "error", err)
+		} else {
+			snap.CronJobs = len(cronJobs)
+		}
+	}
+
 	// Rollouts
 	if rt.rolloutEnabled && rt.rolloutLister != nil {
 		rollouts, err := rt.rolloutLister.List(labels.Everything())
@@ -364,15 +438,30 @@ func (m *ClusterInformerManager) JobsLister(clusterID uint) batchv1listers.JobLi
 	return nil
 }
 
-// CronJobsLister 返回 CronJobs 的 Lister
-// func (m *ClusterInformerManager) CronJobsLister(clusterID uint) batchv1beta1listers.CronJobLister {
-// 	m.mu.RLock()
-// 	defer m.mu.RUnlock()
-// 	if rt, ok := m.clusters[clusterID]; ok {
-// 		return rt.factory.Batch().V1beta1().CronJobs().Lister()
-// 	}
-// 	return nil
-// }
+// CronJobsLister 返回 CronJobs 的 Lister（batch/v1beta1 集群同样返回 batch/v1 类型；集群不支持 CronJob 时返回 nil）
+func (m *ClusterInformerManager) CronJobsLister(clusterID uint) batchv1listers.CronJobLister {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if rt, ok := m.clusters[clusterID]; ok {
+		return rt.cronJobLister
+	}
+	return nil
+}
+
+// CronJobVersion 返回集群使用的 CronJob API 版本（未初始化或不支持时返回空）
+func (m *ClusterInformerManager) CronJobVersion(clusterID uint) string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if rt, ok := m.clusters[clusterID]; ok {
+		return rt.cronJobVersion
+	}
+	return ""
+}
+
+// IsCronJobV1beta1 集群是否仅提供 batch/v1beta1 版本的 CronJob
+func (m *ClusterInformerManager) IsCronJobV1beta1(clusterID uint) bool {
+	return m.CronJobVersion(clusterID) == CronJobVersionV1beta1
+}
 
 // hasArgoRollouts 探测是否存在 argoproj.io 的 rollouts 资源，返回其 GroupVersion
 func hasArgoRollouts(cs *kubernetes.Clientset) (schema.GroupVersion, bool) {
@@ -441,16 +530,21 @@ func (m *ClusterInformerManager) StopForCluster(clusterID uint) {
 		rt.stopOnce.Do(func() {
 			close(rt.stopCh)
 		})
+		rt.stopDynamic()
 		logger.Info("集群 informer 已停止", "clusterID", clusterID)
 	}
 }
 
 // Stop 关闭所有集群的 informer（应用退出时调用）
 func (m *ClusterInformerManager) Stop() {
+	m.stopOnce.Do(func() {
+		close(m.stopCh)
+	})
 	m.mu.Lock()
 	defer m.mu.Unlock()
 	for id, rt := range m.clusters {
 		close(rt.stopCh)
+		rt.stopDynamic()
 		delete(m.clusters, id)
 	}
 }
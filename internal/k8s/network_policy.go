@@ -0,0 +1,73 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/clay-wangzhi/KubePolaris/internal/models"
+
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// networkPolicySyncTimeout 首次访问 NetworkPolicy 时等待动态 informer 同步的最长时间
+const networkPolicySyncTimeout = 10 * time.Second
+
+// NetworkPoliciesGVR NetworkPolicy 不在集群必需的 typed informer 中，按需使用动态 informer 缓存，
+// 凭据无 networkpolicies list/watch 权限时不影响其他资源缓存的同步
+var NetworkPoliciesGVR = schema.GroupVersionResource{Group: "networking.k8s.io", Version: "v1", Resource: "networkpolicies"}
+
+// ListNetworkPolicies 从缓存读取 NetworkPolicy 列表（namespace 为空表示全部命名空间）
+func (m *ClusterInformerManager) ListNetworkPolicies(ctx context.Context, cluster *models.Cluster, namespace string) ([]*networkingv1.NetworkPolicy, error) {
+	lister, err := m.DynamicLister(ctx, cluster, NetworkPoliciesGVR, networkPolicySyncTimeout)
+	if err != nil {
+		return nil, err
+	}
+	var objs []runtime.Object
+	if namespace != "" {
+		objs, err = lister.ByNamespace(namespace).List(labels.Everything())
+	} else {
+		objs, err = lister.List(labels.Everything())
+	}
+	if err != nil {
+		return nil, err
+	}
+	policies := make([]*networkingv1.NetworkPolicy, 0, len(objs))
+	for _, obj := range objs {
+		np, err := toNetworkPolicy(obj)
+		if err != nil {
+			return nil, err
+		}
+		policies = append(policies, np)
+	}
+	return policies, nil
+}
+
+// GetNetworkPolicy 从缓存读取单个 NetworkPolicy，不存在时返回 NotFound 错误
+func (m *ClusterInformerManager) GetNetworkPolicy(ctx context.Context, cluster *models.Cluster, namespace, name string) (*networkingv1.NetworkPolicy, error) {
+	lister, err := m.DynamicLister(ctx, cluster, NetworkPoliciesGVR, networkPolicySyncTimeout)
+	if err != nil {
+		return nil, err
+	}
+	obj, err := lister.ByNamespace(namespace).Get(name)
+	if err != nil {
+		return nil, err
+	}
+	return toNetworkPolicy(obj)
+}
+
+func toNetworkPolicy(obj runtime.Object) (*networkingv1.NetworkPolicy, error) {
+	u, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		return nil, fmt.Errorf("非预期的缓存对象类型 %T", obj)
+	}
+	np := &networkingv1.NetworkPolicy{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, np); err != nil {
+		return nil, fmt.Errorf("转换NetworkPolicy失败: %w", err)
+	}
+	return np, nil
+}
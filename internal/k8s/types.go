@@ -1,5 +1,7 @@
 package k8s
 
+import "time"
+
 // OverviewSnapshot 统一的集群概览快照（由本地 Informer 缓存即时汇总）
 type OverviewSnapshot struct {
 	ClusterID uint `json:"clusterID"`
@@ -14,6 +16,7 @@ type OverviewSnapshot struct {
 	StatefulSets int `json:"statefulsets"`
 	DaemonSets   int `json:"daemonsets"`
 	Jobs         int `json:"jobs"`
+	CronJobs     int `json:"cronjobs"`
 	Rollouts     int `json:"rollouts"`
 
 	// 容器子网IP信息
@@ -26,3 +29,52 @@ type ContainerSubnetIPs struct {
 	UsedIPs      int `json:"used_ips"`
 	AvailableIPs int `json:"available_ips"`
 }
+
+// ClusterCacheStats 集群 informer 缓存统计
+type ClusterCacheStats struct {
+	ClusterID          uint                 `json:"clusterID"`
+	Synced             bool                 `json:"synced"`             // 内置资源缓存是否已同步
+	TotalObjects       int                  `json:"totalObjects"`       // 缓存对象总数
+	EstimatedBytes     int64                `json:"estimatedBytes"`     // 内存占用估算（字节）
+	DynamicInformers   int                  `json:"dynamicInformers"`   // 当前运行的动态 informer 数量
+	DynamicIdleTimeout int64                `json:"dynamicIdleTimeout"` // 动态 informer 空闲回收时间（秒）
+	Resources          []ResourceCacheStats `json:"resources"`
+}
+
+// ResourceCacheStats 单类资源的缓存统计
+type ResourceCacheStats struct {
+	Resource                string     `json:"resource"` // 内置资源为复数名，动态资源为 GVR
+	Dynamic                 bool       `json:"dynamic"`
+	Objects                 int        `json:"objects"`
+	EstimatedBytes          int64      `json:"estimatedBytes"`
+	Synced                  bool       `json:"synced"`
+	LastSyncResourceVersion string     `json:"lastSyncResourceVersion"`
+	SyncedAt                *time.Time `json:"syncedAt,omitempty"`    // 首次同步完成时间
+	LastEventAt             *time.Time `json:"lastEventAt,omitempty"` // 最近一次收到事件的时间
+	StartedAt               *time.Time `json:"startedAt,omitempty"`   // 动态 informer 启动时间
+	LastUsedAt              *time.Time `json:"lastUsedAt,omitempty"`  // 动态 informer 最近使用时间
+}
+
+// ClusterCacheStatus 集群 informer 缓存健康状态
+type ClusterCacheStatus struct {
+	ClusterID     uint                  `json:"clusterID"`
+	Synced        bool                  `json:"synced"`  // 内置资源缓存是否均已同步
+	Healthy       bool                  `json:"healthy"` // 内置资源 watch 是否均无错误
+	Stale         bool                  `json:"stale"`   // 缓存数据可能已过期（未同步或不健康）
+	RebuildCount  int                   `json:"rebuildCount"`
+	LastRebuildAt *time.Time            `json:"lastRebuildAt,omitempty"`
+	RebuildReason string                `json:"rebuildReason,omitempty"`
+	Resources     []ResourceCacheStatus `json:"resources"`
+}
+
+// ResourceCacheStatus 单类资源的 watch 健康状态
+type ResourceCacheStatus struct {
+	Resource          string     `json:"resource"`
+	Dynamic           bool       `json:"dynamic"`
+	Healthy           bool       `json:"healthy"`
+	ErrorCount        int64      `json:"errorCount"`        // 累计 watch 错误次数
+	ConsecutiveErrors int64      `json:"consecutiveErrors"` // 最近一次成功事件后的连续错误次数
+	LastError         string     `json:"lastError,omitempty"`
+	LastErrorAt       *time.Time `json:"lastErrorAt,omitempty"`
+	LastEventAt       *time.Time `json:"lastEventAt,omitempty"` // 最近一次成功收到事件的时间
+}
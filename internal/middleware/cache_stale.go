@@ -0,0 +1,24 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CacheStaleHeader 响应头：值为 true 表示数据可能来自已过期的 informer 缓存
+const CacheStaleHeader = "X-Cache-Stale"
+
+// CacheStaleMarker 集群缓存不健康（未同步或 watch 持续失败）时为读请求添加过期标记，
+// 前端据此提示用户数据可能不是最新的。
+func CacheStaleMarker(isStale func(clusterID uint) bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.Method == http.MethodGet {
+			if id, err := strconv.ParseUint(c.Param("clusterID"), 10, 32); err == nil && isStale(uint(id)) {
+				c.Header(CacheStaleHeader, "true")
+			}
+		}
+		c.Next()
+	}
+}
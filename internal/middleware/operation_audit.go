@@ -45,34 +45,66 @@ func init() {
 
 		// 集群模块
 		{`^/api/v1/clusters/import$`, constants.ModuleCluster, constants.ActionImport, "cluster", -1},
+		{`^/api/v1/clusters/import/preview$`, constants.ModuleCluster, constants.ActionTest, "cluster", -1},
+		{`^/api/v1/clusters/import/batch$`, constants.ModuleCluster, constants.ActionImport, "cluster", -1},
 		{`^/api/v1/clusters/test-connection$`, constants.ModuleCluster, constants.ActionTest, "cluster", -1},
+		{`^/api/v1/clusters/(\d+)/labels$`, constants.ModuleCluster, constants.ActionUpdate, "cluster", 1},
+		{`^/api/v1/clusters/agent$`, constants.ModuleCluster, constants.ActionImport, "cluster", -1},
+		{`^/api/v1/clusters/(\d+)/agent/tokens$`, constants.ModuleCluster, constants.ActionCreate, "agent_token", 1},
+		{`^/api/v1/clusters/(\d+)/agent/tokens/\d+$`, constants.ModuleCluster, constants.ActionDelete, "agent_token", 1},
 		{`^/api/v1/clusters/(\d+)$`, constants.ModuleCluster, "", "cluster", 1},
 
 		// 节点模块
+		{`^/api/v1/clusters/\d+/nodes/batch/cordon$`, constants.ModuleNode, constants.ActionCordon, "node", -1},
+		{`^/api/v1/clusters/\d+/nodes/batch/uncordon$`, constants.ModuleNode, constants.ActionUncordon, "node", -1},
+		{`^/api/v1/clusters/\d+/nodes/batch/metadata$`, constants.ModuleNode, constants.ActionUpdate, "node", -1},
+		{`^/api/v1/clusters/\d+/nodes/([^/]+)/metadata$`, constants.ModuleNode, constants.ActionUpdate, "node", 1},
 		{`^/api/v1/clusters/\d+/nodes/([^/]+)/cordon$`, constants.ModuleNode, constants.ActionCordon, "node", 1},
 		{`^/api/v1/clusters/\d+/nodes/([^/]+)/uncordon$`, constants.ModuleNode, constants.ActionUncordon, "node", 1},
 		{`^/api/v1/clusters/\d+/nodes/([^/]+)/drain$`, constants.ModuleNode, constants.ActionDrain, "node", 1},
 
+		// 后台任务
+		{`^/api/v1/clusters/\d+/tasks/([^/]+)/cancel$`, constants.ModuleCluster, constants.ActionCancel, "task", 1},
+
 		// Pod 模块
+		{`^/api/v1/clusters/\d+/pods/([^/]+)/([^/]+)/debug$`, constants.ModulePod, constants.ActionCreate, "debug_container", 2},
 		{`^/api/v1/clusters/\d+/pods/([^/]+)/([^/]+)$`, constants.ModulePod, "", "pod", 2},
 
 		// Deployment 模块
 		{`^/api/v1/clusters/\d+/deployments/yaml/apply$`, constants.ModuleWorkload, constants.ActionApply, "deployment", -1},
 		{`^/api/v1/clusters/\d+/deployments/([^/]+)/([^/]+)/scale$`, constants.ModuleWorkload, constants.ActionScale, "deployment", 2},
+		{`^/api/v1/clusters/\d+/deployments/([^/]+)/([^/]+)/rollback$`, constants.ModuleWorkload, constants.ActionRollback, "deployment", 2},
+		{`^/api/v1/clusters/\d+/deployments/([^/]+)/([^/]+)/restart$`, constants.ModuleWorkload, constants.ActionRestart, "deployment", 2},
+		{`^/api/v1/clusters/\d+/deployments/([^/]+)/([^/]+)/pause$`, constants.ModuleWorkload, constants.ActionPause, "deployment", 2},
+		{`^/api/v1/clusters/\d+/deployments/([^/]+)/([^/]+)/resume$`, constants.ModuleWorkload, constants.ActionResume, "deployment", 2},
+		{`^/api/v1/clusters/\d+/deployments/([^/]+)/([^/]+)/image$`, constants.ModuleWorkload, constants.ActionSetImage, "deployment", 2},
 		{`^/api/v1/clusters/\d+/deployments/([^/]+)/([^/]+)$`, constants.ModuleWorkload, "", "deployment", 2},
 
 		// Rollout 模块
 		{`^/api/v1/clusters/\d+/rollouts/yaml/apply$`, constants.ModuleWorkload, constants.ActionApply, "rollout", -1},
 		{`^/api/v1/clusters/\d+/rollouts/([^/]+)/([^/]+)/scale$`, constants.ModuleWorkload, constants.ActionScale, "rollout", 2},
+		{`^/api/v1/clusters/\d+/rollouts/([^/]+)/([^/]+)/promote$`, constants.ModuleWorkload, constants.ActionPromote, "rollout", 2},
+		{`^/api/v1/clusters/\d+/rollouts/([^/]+)/([^/]+)/abort$`, constants.ModuleWorkload, constants.ActionAbort, "rollout", 2},
+		{`^/api/v1/clusters/\d+/rollouts/([^/]+)/([^/]+)/retry$`, constants.ModuleWorkload, constants.ActionRetry, "rollout", 2},
+		{`^/api/v1/clusters/\d+/rollouts/([^/]+)/([^/]+)/pause$`, constants.ModuleWorkload, constants.ActionPause, "rollout", 2},
+		{`^/api/v1/clusters/\d+/rollouts/([^/]+)/([^/]+)/resume$`, constants.ModuleWorkload, constants.ActionResume, "rollout", 2},
+		{`^/api/v1/clusters/\d+/rollouts/([^/]+)/([^/]+)/restart$`, constants.ModuleWorkload, constants.ActionRestart, "rollout", 2},
+		{`^/api/v1/clusters/\d+/rollouts/([^/]+)/([^/]+)/image$`, constants.ModuleWorkload, constants.ActionSetImage, "rollout", 2},
+		{`^/api/v1/clusters/\d+/rollouts/([^/]+)/([^/]+)/weight$`, constants.ModuleWorkload, constants.ActionUpdate, "rollout", 2},
 		{`^/api/v1/clusters/\d+/rollouts/([^/]+)/([^/]+)$`, constants.ModuleWorkload, "", "rollout", 2},
 
 		// StatefulSet 模块
 		{`^/api/v1/clusters/\d+/statefulsets/yaml/apply$`, constants.ModuleWorkload, constants.ActionApply, "statefulset", -1},
 		{`^/api/v1/clusters/\d+/statefulsets/([^/]+)/([^/]+)/scale$`, constants.ModuleWorkload, constants.ActionScale, "statefulset", 2},
+		{`^/api/v1/clusters/\d+/statefulsets/([^/]+)/([^/]+)/restart$`, constants.ModuleWorkload, constants.ActionRestart, "statefulset", 2},
+		{`^/api/v1/clusters/\d+/statefulsets/([^/]+)/([^/]+)/image$`, constants.ModuleWorkload, constants.ActionSetImage, "statefulset", 2},
+		{`^/api/v1/clusters/\d+/statefulsets/([^/]+)/([^/]+)/partition$`, constants.ModuleWorkload, constants.ActionUpdate, "statefulset", 2},
 		{`^/api/v1/clusters/\d+/statefulsets/([^/]+)/([^/]+)$`, constants.ModuleWorkload, "", "statefulset", 2},
 
 		// DaemonSet 模块
 		{`^/api/v1/clusters/\d+/daemonsets/yaml/apply$`, constants.ModuleWorkload, constants.ActionApply, "daemonset", -1},
+		{`^/api/v1/clusters/\d+/daemonsets/([^/]+)/([^/]+)/restart$`, constants.ModuleWorkload, constants.ActionRestart, "daemonset", 2},
+		{`^/api/v1/clusters/\d+/daemonsets/([^/]+)/([^/]+)/image$`, constants.ModuleWorkload, constants.ActionSetImage, "daemonset", 2},
 		{`^/api/v1/clusters/\d+/daemonsets/([^/]+)/([^/]+)$`, constants.ModuleWorkload, "", "daemonset", 2},
 
 		// Job 模块
@@ -81,8 +113,15 @@ func init() {
 
 		// CronJob 模块
 		{`^/api/v1/clusters/\d+/cronjobs/yaml/apply$`, constants.ModuleWorkload, constants.ActionApply, "cronjob", -1},
+		{`^/api/v1/clusters/\d+/cronjobs/([^/]+)/([^/]+)/trigger$`, constants.ModuleWorkload, constants.ActionTrigger, "cronjob", 2},
+		{`^/api/v1/clusters/\d+/cronjobs/([^/]+)/([^/]+)/suspend$`, constants.ModuleWorkload, constants.ActionSuspend, "cronjob", 2},
+		{`^/api/v1/clusters/\d+/cronjobs/([^/]+)/([^/]+)/resume$`, constants.ModuleWorkload, constants.ActionResume, "cronjob", 2},
 		{`^/api/v1/clusters/\d+/cronjobs/([^/]+)/([^/]+)$`, constants.ModuleWorkload, "", "cronjob", 2},
 
+		// HPA 模块
+		{`^/api/v1/clusters/\d+/hpas$`, constants.ModuleWorkload, constants.ActionCreate, "hpa", -1},
+		{`^/api/v1/clusters/\d+/hpas/([^/]+)/([^/]+)$`, constants.ModuleWorkload, "", "hpa", 2},
+
 		// ConfigMap 模块
 		{`^/api/v1/clusters/\d+/configmaps$`, constants.ModuleConfig, constants.ActionCreate, "configmap", -1},
 		{`^/api/v1/clusters/\d+/configmaps/([^/]+)/([^/]+)$`, constants.ModuleConfig, "", "configmap", 2},
@@ -98,8 +137,17 @@ func init() {
 		// Ingress 模块
 		{`^/api/v1/clusters/\d+/ingresses$`, constants.ModuleNetwork, constants.ActionCreate, "ingress", -1},
 		{`^/api/v1/clusters/\d+/ingresses/([^/]+)/([^/]+)$`, constants.ModuleNetwork, "", "ingress", 2},
+		{`^/api/v1/clusters/\d+/networkpolicies/simulate$`, constants.ModuleNetwork, constants.ActionTest, "networkpolicy", -1},
+		{`^/api/v1/clusters/\d+/networkpolicies$`, constants.ModuleNetwork, constants.ActionCreate, "networkpolicy", -1},
+		{`^/api/v1/clusters/\d+/networkpolicies/([^/]+)/([^/]+)$`, constants.ModuleNetwork, "", "networkpolicy", 2},
 
 		// Namespace 模块
+		{`^/api/v1/clusters/\d+/namespaces/from-template$`, constants.ModuleNamespace, constants.ActionCreate, "namespace", -1},
+		{`^/api/v1/clusters/\d+/namespaces/([^/]+)/template$`, constants.ModuleNamespace, constants.ActionApply, "namespace_template", 1},
+		{`^/api/v1/clusters/\d+/namespaces/[^/]+/quotas$`, constants.ModuleNamespace, constants.ActionCreate, "resourcequota", -1},
+		{`^/api/v1/clusters/\d+/namespaces/[^/]+/quotas/([^/]+)$`, constants.ModuleNamespace, "", "resourcequota", 1},
+		{`^/api/v1/clusters/\d+/namespaces/[^/]+/limitranges$`, constants.ModuleNamespace, constants.ActionCreate, "limitrange", -1},
+		{`^/api/v1/clusters/\d+/namespaces/[^/]+/limitranges/([^/]+)$`, constants.ModuleNamespace, "", "limitrange", 1},
 		{`^/api/v1/clusters/\d+/namespaces$`, constants.ModuleNamespace, constants.ActionCreate, "namespace", -1},
 		{`^/api/v1/clusters/\d+/namespaces/([^/]+)$`, constants.ModuleNamespace, "", "namespace", 1},
 
@@ -126,7 +174,20 @@ func init() {
 		{`^/api/v1/clusters/\d+/argocd/applications/([^/]+)/sync$`, constants.ModuleArgoCD, constants.ActionSync, "application", 1},
 		{`^/api/v1/clusters/\d+/argocd/applications/([^/]+)/rollback$`, constants.ModuleArgoCD, constants.ActionRollback, "application", 1},
 
+		// 资源修订回滚
+		{`^/api/v1/clusters/\d+/revisions/(\d+)/revert$`, constants.ModuleResource, constants.ActionRollback, "resource_revision", 1},
+
+		// 多资源 YAML 批量应用
+		{`^/api/v1/clusters/\d+/apply$`, constants.ModuleResource, constants.ActionApply, "yaml_bundle", -1},
+
+		// 通用资源模块（/resources/:group/:version/:resource[/:namespace/:name]）
+		{`^/api/v1/clusters/\d+/resources/[^/]+/[^/]+/([^/]+)$`, constants.ModuleResource, constants.ActionApply, "resource", 1},
+		{`^/api/v1/clusters/\d+/resources/[^/]+/[^/]+/[^/]+/([^/]+)/([^/]+)$`, constants.ModuleResource, "", "resource", 2},
+
 		// 权限模块
+		{`^/api/v1/namespace-templates$`, constants.ModuleNamespace, constants.ActionCreate, "namespace_template", -1},
+		{`^/api/v1/namespace-templates/(\d+)/preview$`, constants.ModuleNamespace, constants.ActionTest, "namespace_template", 1},
+		{`^/api/v1/namespace-templates/(\d+)$`, constants.ModuleNamespace, "", "namespace_template", 1},
 		{`^/api/v1/permissions/user-groups$`, constants.ModulePermission, constants.ActionCreate, "user_group", -1},
 		{`^/api/v1/permissions/user-groups/(\d+)$`, constants.ModulePermission, "", "user_group", 1},
 		{`^/api/v1/permissions/user-groups/(\d+)/users$`, constants.ModulePermission, constants.ActionUpdate, "user_group_member", 1},
@@ -230,6 +291,9 @@ func OperationAudit(logSvc *services.OperationLogService) gin.HandlerFunc {
 			}
 		}
 
+		// 获取变更前后的值（handler 通过 audit_changes 提供）
+		changes, _ := c.Get("audit_changes")
+
 		// 从 namespace 参数获取命名空间
 		namespace := c.Param("namespace")
 		if namespace == "" {
@@ -260,6 +324,7 @@ func OperationAudit(logSvc *services.OperationLogService) gin.HandlerFunc {
 			ResourceType: resourceType,
 			ResourceName: resourceName,
 			RequestBody:  requestBody,
+			Changes:      changes,
 			StatusCode:   c.Writer.Status(),
 			Success:      c.Writer.Status() < 400,
 			ErrorMessage: errorMessage,
@@ -11,7 +11,7 @@ type TerminalSession struct {
 	ID         uint           `json:"id" gorm:"primaryKey"`
 	UserID     uint           `json:"user_id" gorm:"not null"`
 	ClusterID  uint           `json:"cluster_id" gorm:"not null"`
-	TargetType string         `json:"target_type" gorm:"not null;size:20"` // pod, node, cluster
+	TargetType string         `json:"target_type" gorm:"not null;size:20"` // kubectl, pod, node, pod_debug
 	TargetRef  string         `json:"target_ref" gorm:"type:json"`         // JSON格式存储目标引用信息
 	Namespace  string         `json:"namespace" gorm:"size:100"`
 	Pod        string         `json:"pod" gorm:"size:100"`
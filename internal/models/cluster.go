@@ -11,9 +11,10 @@ type Cluster struct {
 	ID            uint           `json:"id" gorm:"primaryKey"`
 	Name          string         `json:"name" gorm:"uniqueIndex;not null;size:100"`
 	APIServer     string         `json:"api_server" gorm:"not null;size:255"`
-	KubeconfigEnc string         `json:"-" gorm:"type:text"` // 加密存储的 kubeconfig
-	CAEnc         string         `json:"-" gorm:"type:text"` // 加密存储的 CA 证书
-	SATokenEnc    string         `json:"-" gorm:"type:text"` // 加密存储的 SA Token
+	KubeconfigEnc string         `json:"-" gorm:"type:text"`         // 加密存储的 kubeconfig
+	CAEnc         string         `json:"-" gorm:"type:text"`         // 加密存储的 CA 证书
+	SATokenEnc    string         `json:"-" gorm:"type:text"`         // 加密存储的 SA Token
+	ClusterUID    string         `json:"cluster_uid" gorm:"size:64"` // kube-system 命名空间 UID，用于识别同一集群
 	Version       string         `json:"version" gorm:"size:50"`
 	Status        string         `json:"status" gorm:"default:unknown;size:20"` // healthy, unhealthy, unknown
 	Labels        string         `json:"labels" gorm:"type:json"`               // JSON 格式存储标签
@@ -24,6 +25,9 @@ type Cluster struct {
 	UpdatedAt     time.Time      `json:"updated_at"`
 	DeletedAt     gorm.DeletedAt `json:"-" gorm:"index"`
 
+	// 连接方式：direct 直连 API Server；agent 经集群内 Agent 反向隧道访问
+	ConnectionMode string `json:"connection_mode" gorm:"default:direct;size:20"`
+
 	// 监控配置
 	MonitoringConfig string `json:"monitoring_config" gorm:"type:json"` // JSON 格式存储监控配置
 
@@ -35,6 +39,41 @@ type Cluster struct {
 	TerminalSession []TerminalSession `json:"terminal_sessions" gorm:"foreignKey:ClusterID"`
 }
 
+// 集群连接方式
+const (
+	ClusterConnectionDirect = "direct"
+	ClusterConnectionAgent  = "agent"
+)
+
+// IsAgentMode 集群是否通过 Agent 反向隧道接入
+func (c *Cluster) IsAgentMode() bool {
+	return c.ConnectionMode == ClusterConnectionAgent
+}
+
+// ClusterAgentToken 集群 Agent 接入令牌（只保存 SHA-256 摘要，明文仅在创建时返回一次）
+type ClusterAgentToken struct {
+	ID          uint       `json:"id" gorm:"primaryKey"`
+	ClusterID   uint       `json:"cluster_id" gorm:"index;not null"`
+	TokenHash   string     `json:"-" gorm:"uniqueIndex;not null;size:64"`
+	TokenPrefix string     `json:"token_prefix" gorm:"size:16"` // 令牌前若干位，便于识别
+	Description string     `json:"description" gorm:"size:255"`
+	ExpiresAt   *time.Time `json:"expires_at"`
+	LastUsedAt  *time.Time `json:"last_used_at"`
+	CreatedBy   uint       `json:"created_by"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+// ClusterStatusHistory 集群状态变更历史（由后台健康探测写入）
+type ClusterStatusHistory struct {
+	ID         uint      `json:"id" gorm:"primaryKey"`
+	ClusterID  uint      `json:"cluster_id" gorm:"index;not null"`
+	FromStatus string    `json:"from_status" gorm:"size:20"`
+	ToStatus   string    `json:"to_status" gorm:"size:20"`
+	Version    string    `json:"version" gorm:"size:50"`
+	Message    string    `json:"message" gorm:"type:text"` // 探测失败时的错误信息
+	CreatedAt  time.Time `json:"created_at" gorm:"index"`
+}
+
 // ClusterStats 集群统计信息
 type ClusterStats struct {
 	TotalClusters     int `json:"total_clusters"`
@@ -135,6 +174,7 @@ type ClusterMetricsData struct {
 	CPUUsageAbsolute  *MetricSeries   `json:"cpu_usage_absolute,omitempty"`  // CPU 实际使用量（cores）
 	MemoryUsageBytes  *MetricSeries   `json:"memory_usage_bytes,omitempty"`  // 内存实际使用量（bytes）
 	OOMKills          *MetricSeries   `json:"oom_kills,omitempty"`           // OOM Kill 次数
+	Replicas          *MetricSeries   `json:"replicas,omitempty"`            // 运行中的副本数
 
 	// 集群级别监控指标
 	ClusterOverview *ClusterOverview `json:"cluster_overview,omitempty"` // 集群概览
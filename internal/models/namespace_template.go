@@ -0,0 +1,127 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// NamespaceTemplateVariable 命名空间模板变量，在模板内容中以 ${name} 引用
+type NamespaceTemplateVariable struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Required    bool   `json:"required"`
+	Default     string `json:"default"`
+}
+
+// NamespaceTemplate 命名空间模板：参数化的多文档 YAML，创建命名空间时一并下发配额、LimitRange、RBAC、网络策略等
+type NamespaceTemplate struct {
+	ID          uint      `json:"id" gorm:"primaryKey"`
+	Name        string    `json:"name" gorm:"uniqueIndex;not null;size:100"`
+	Description string    `json:"description" gorm:"size:255"`
+	Content     string    `json:"content" gorm:"type:longtext"` // 多文档 YAML，支持 ${变量} 占位符
+	Variables   string    `json:"-" gorm:"type:text"`           // 变量定义，JSON 格式
+	Version     int       `json:"version"`                      // 内容或变量变化时递增
+	CreatedBy   string    `json:"created_by" gorm:"size:100"`
+	UpdatedBy   string    `json:"updated_by" gorm:"size:100"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// TableName 指定表名
+func (NamespaceTemplate) TableName() string {
+	return "namespace_templates"
+}
+
+// GetVariables 获取变量定义
+func (t *NamespaceTemplate) GetVariables() []NamespaceTemplateVariable {
+	var vars []NamespaceTemplateVariable
+	if t.Variables == "" {
+		return vars
+	}
+	if err := json.Unmarshal([]byte(t.Variables), &vars); err != nil {
+		return nil
+	}
+	return vars
+}
+
+// SetVariables 设置变量定义
+func (t *NamespaceTemplate) SetVariables(vars []NamespaceTemplateVariable) error {
+	data, err := json.Marshal(vars)
+	if err != nil {
+		return err
+	}
+	t.Variables = string(data)
+	return nil
+}
+
+// NamespaceTemplateObject 模板下发到命名空间中的资源标识
+type NamespaceTemplateObject struct {
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	Name       string `json:"name"`
+}
+
+// NamespaceTemplateBinding 命名空间与模板的绑定：记录命名空间最近一次应用的模板版本与变量值，用于模板更新后重新应用
+type NamespaceTemplateBinding struct {
+	ID              uint      `json:"id" gorm:"primaryKey"`
+	ClusterID       uint      `json:"cluster_id" gorm:"uniqueIndex:idx_namespace_template_binding,priority:1"`
+	Namespace       string    `json:"namespace" gorm:"size:253;uniqueIndex:idx_namespace_template_binding,priority:2"`
+	TemplateID      uint      `json:"template_id" gorm:"index"`
+	TemplateName    string    `json:"template_name" gorm:"size:100"`
+	TemplateVersion int       `json:"template_version"`
+	Values          string    `json:"-" gorm:"type:text"` // 变量值，JSON 格式
+	Objects         string    `json:"-" gorm:"type:text"` // 最近一次应用的资源列表，JSON 格式，用于清理模板中已移除的资源
+	UserID          uint      `json:"user_id"`
+	Username        string    `json:"username" gorm:"size:100"`
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"` // 最近一次应用时间
+}
+
+// TableName 指定表名
+func (NamespaceTemplateBinding) TableName() string {
+	return "namespace_template_bindings"
+}
+
+// GetValues 获取变量值
+func (b *NamespaceTemplateBinding) GetValues() map[string]string {
+	values := map[string]string{}
+	if b.Values == "" {
+		return values
+	}
+	if err := json.Unmarshal([]byte(b.Values), &values); err != nil {
+		return map[string]string{}
+	}
+	return values
+}
+
+// SetValues 设置变量值
+func (b *NamespaceTemplateBinding) SetValues(values map[string]string) error {
+	data, err := json.Marshal(values)
+	if err != nil {
+		return err
+	}
+	b.Values = string(data)
+	return nil
+}
+
+// GetObjects 获取最近一次应用的资源列表（未记录时为空）
+func (b *NamespaceTemplateBinding) GetObjects() []NamespaceTemplateObject {
+	var objects []NamespaceTemplateObject
+	if b.Objects == "" {
+		return objects
+	}
+	if err := json.Unmarshal([]byte(b.Objects), &objects); err != nil {
+		return nil
+	}
+	return objects
+}
+
+// SetObjects 设置最近一次应用的资源列表
+func (b *NamespaceTemplateBinding) SetObjects(objects []NamespaceTemplateObject) error {
+	data, err := json.Marshal(objects)
+	if err != nil {
+		return err
+	}
+	b.Objects = string(data)
+	return nil
+}
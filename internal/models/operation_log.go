@@ -30,6 +30,7 @@ type OperationLog struct {
 	// 请求/响应
 	RequestBody string `json:"request_body" gorm:"type:text"` // 敏感信息脱敏后的请求体
 	StatusCode  int    `json:"status_code"`                   // HTTP 状态码
+	Changes     string `json:"changes" gorm:"type:text"`      // 变更前后的值（JSON），由 handler 提供
 
 	// 结果
 	Success      bool   `json:"success" gorm:"index"`           // 是否成功
@@ -116,11 +116,12 @@ func (cp *ClusterPermission) CanPerformAction(action string) bool {
 	case PermissionTypeAdmin:
 		return true // 管理员可以执行所有操作
 	case PermissionTypeOps:
-		// 运维权限：排除节点 cordon/drain、存储管理、配额管理的写操作
+		// 运维权限：排除节点 cordon/drain/元数据修改、存储管理、配额管理的写操作
 		restrictedActions := map[string]bool{
 			"node:cordon":         true,
 			"node:uncordon":       true,
 			"node:drain":          true,
+			"node:update":         true,
 			"pv:create":           true,
 			"pv:delete":           true,
 			"storageclass:create": true,
@@ -128,6 +129,9 @@ func (cp *ClusterPermission) CanPerformAction(action string) bool {
 			"quota:create":        true,
 			"quota:update":        true,
 			"quota:delete":        true,
+			"limitrange:create":   true,
+			"limitrange:update":   true,
+			"limitrange:delete":   true,
 		}
 		return !restrictedActions[action]
 	case PermissionTypeDev:
@@ -135,7 +139,7 @@ func (cp *ClusterPermission) CanPerformAction(action string) bool {
 		allowedPrefixes := []string{
 			"pod:", "deployment:", "statefulset:", "daemonset:",
 			"job:", "cronjob:", "service:", "ingress:",
-			"configmap:", "secret:", "rollout:",
+			"configmap:", "secret:", "rollout:", "hpa:", "networkpolicy:",
 		}
 		for _, prefix := range allowedPrefixes {
 			if len(action) >= len(prefix) && action[:len(prefix)] == prefix {
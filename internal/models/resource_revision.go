@@ -0,0 +1,32 @@
+package models
+
+import "time"
+
+// ResourceRevision 资源修订记录：通过平台修改资源前保存的对象快照，用于查看历史、比较与回滚
+type ResourceRevision struct {
+	ID uint `json:"id" gorm:"primaryKey"`
+
+	// 资源标识
+	ClusterID  uint   `json:"cluster_id" gorm:"index:idx_resource_revision_object,priority:1"`
+	APIVersion string `json:"api_version" gorm:"size:100"`
+	Kind       string `json:"kind" gorm:"size:100;index:idx_resource_revision_object,priority:2"`
+	Namespace  string `json:"namespace" gorm:"size:253;index:idx_resource_revision_object,priority:3"`
+	Name       string `json:"name" gorm:"size:253;index:idx_resource_revision_object,priority:4"`
+
+	Revision        int    `json:"revision"`                        // 同一资源内递增的修订号
+	ResourceVersion string `json:"resource_version" gorm:"size:64"` // 快照对象的 resourceVersion
+	Action          string `json:"action" gorm:"size:50"`           // 触发快照的操作：apply/update/revert/rollback
+	Content         string `json:"-" gorm:"type:longtext"`          // 快照 YAML（Secret 加密存储）
+	Encrypted       bool   `json:"encrypted"`
+
+	// 操作者信息
+	UserID   *uint  `json:"user_id"`
+	Username string `json:"username" gorm:"size:100"`
+
+	CreatedAt time.Time `json:"created_at" gorm:"index"`
+}
+
+// TableName 指定表名
+func (ResourceRevision) TableName() string {
+	return "resource_revisions"
+}
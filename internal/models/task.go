@@ -0,0 +1,83 @@
+package models
+
+import "time"
+
+// 任务状态
+const (
+	TaskStatusPending     = "pending"
+	TaskStatusRunning     = "running"
+	TaskStatusSucceeded   = "succeeded"
+	TaskStatusFailed      = "failed"
+	TaskStatusCancelled   = "cancelled"
+	TaskStatusInterrupted = "interrupted" // 服务重启时未完成且无法恢复执行
+)
+
+// Task 后台长时间运行的任务（节点驱逐、批量封锁等），状态、步骤与日志持久化，服务重启后可查询
+type Task struct {
+	ID        uint   `json:"id" gorm:"primaryKey"`
+	ClusterID uint   `json:"cluster_id" gorm:"index"`
+	Type      string `json:"type" gorm:"size:50;index"` // 任务类型，如 node_drain
+	Target    string `json:"target" gorm:"size:255"`    // 操作对象描述，如节点名称
+	Status    string `json:"status" gorm:"size:20;index"`
+	Progress  int    `json:"progress"`                // 0-100
+	Params    string `json:"params" gorm:"type:text"` // 任务参数（JSON），用于重启后恢复执行
+	Result    string `json:"result" gorm:"type:text"` // 任务结果（JSON）
+	Error     string `json:"error" gorm:"type:text"`  // 失败原因
+	Attempts  int    `json:"attempts"`                // 执行次数（含重启后恢复执行）
+
+	// 提交者信息
+	UserID   uint   `json:"user_id" gorm:"index"`
+	Username string `json:"username" gorm:"size:100"`
+
+	StartedAt  *time.Time `json:"started_at"`
+	FinishedAt *time.Time `json:"finished_at"`
+	CreatedAt  time.Time  `json:"created_at" gorm:"index"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+
+	Steps []TaskStep `json:"steps,omitempty" gorm:"foreignKey:TaskID"`
+	Logs  []TaskLog  `json:"logs,omitempty" gorm:"foreignKey:TaskID"`
+}
+
+// TableName 指定表名
+func (Task) TableName() string {
+	return "tasks"
+}
+
+// IsFinished 任务是否已结束
+func (t *Task) IsFinished() bool {
+	switch t.Status {
+	case TaskStatusSucceeded, TaskStatusFailed, TaskStatusCancelled, TaskStatusInterrupted:
+		return true
+	}
+	return false
+}
+
+// TaskStep 任务步骤
+type TaskStep struct {
+	ID         uint       `json:"id" gorm:"primaryKey"`
+	TaskID     uint       `json:"task_id" gorm:"index"`
+	Name       string     `json:"name" gorm:"size:255"`
+	Status     string     `json:"status" gorm:"size:20"` // running/succeeded/failed/cancelled
+	Message    string     `json:"message" gorm:"type:text"`
+	StartedAt  time.Time  `json:"started_at"`
+	FinishedAt *time.Time `json:"finished_at"`
+}
+
+// TableName 指定表名
+func (TaskStep) TableName() string {
+	return "task_steps"
+}
+
+// TaskLog 任务日志
+type TaskLog struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	TaskID    uint      `json:"task_id" gorm:"index"`
+	Level     string    `json:"level" gorm:"size:10"` // info/warn/error
+	Message   string    `json:"message" gorm:"type:text"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// TableName 指定表名
+func (TaskLog) TableName() string {
+	return "task_logs"
+}
@@ -5,6 +5,7 @@ import (
 	"io/fs"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/gin-contrib/gzip"
 	"github.com/gin-gonic/gin"
@@ -21,6 +22,17 @@ import (
 // staticFS 保存嵌入的前端静态文件系统，由 Setup 注入
 var staticFS embed.FS
 
+// shutdownHooks 保存 Setup 中启动的后台组件的停止函数，由 Shutdown 在服务退出时调用
+var shutdownHooks []func()
+
+// Shutdown 按启动的逆序停止 Setup 中启动的后台组件，应在 HTTP 服务关闭后调用
+func Shutdown() {
+	for i := len(shutdownHooks) - 1; i >= 0; i-- {
+		shutdownHooks[i]()
+	}
+	shutdownHooks = nil
+}
+
 func Setup(db *gorm.DB, cfg *config.Config, frontendFS embed.FS) *gin.Engine {
 	staticFS = frontendFS
 	r := gin.New()
@@ -80,6 +92,11 @@ func Setup(db *gorm.DB, cfg *config.Config, frontendFS embed.FS) *gin.Engine {
 	monitoringConfigSvc := services.NewMonitoringConfigServiceWithGrafana(db, grafanaSvc)
 	// K8s Informer 管理器
 	k8sMgr := k8s.NewClusterInformerManager()
+	// 动态 informer（CRD 等任意 GVR）按需启动，空闲超时后回收
+	k8sMgr.SetDynamicIdleTimeout(time.Duration(cfg.K8s.DynamicIdleTimeout) * time.Minute)
+	k8sMgr.StartDynamicEviction()
+	// 缓存因认证失败重建时重新加载集群（获取更新后的凭据）
+	k8sMgr.SetClusterLoader(clusterSvc.GetCluster)
 	// 预热所有已存在集群的 Informer（后台执行，不阻塞启动）
 	go func() {
 		clusters, err := clusterSvc.GetAllClusters()
@@ -93,6 +110,22 @@ func Setup(db *gorm.DB, cfg *config.Config, frontendFS embed.FS) *gin.Engine {
 			}
 		}
 	}()
+	// 后台集群健康探测：维护集群状态/心跳/证书过期时间，并按可达性启停 informer
+	if cfg.K8s.HealthCheckInterval > 0 {
+		prober := k8s.NewClusterHealthProber(clusterSvc, k8sMgr, time.Duration(cfg.K8s.HealthCheckInterval)*time.Second)
+		prober.Start()
+		shutdownHooks = append(shutdownHooks, prober.Stop)
+	}
+
+	// 后台任务（节点驱逐、批量封锁等）：注册执行函数后恢复服务重启前未完成的任务
+	taskSvc := services.NewTaskService(db)
+	handlers.RegisterNodeTaskRunners(taskSvc, clusterSvc, k8sMgr)
+	go taskSvc.Recover()
+	taskHandler := handlers.NewTaskHandler(taskSvc, permissionSvc)
+
+	// 集群 Agent 反向隧道：使用 Agent 令牌认证，不走 JWT
+	clusterAgentHandler := handlers.NewClusterAgentHandler(db, cfg, clusterSvc, k8sMgr)
+	r.GET("/ws/agent/connect", clusterAgentHandler.AgentConnect)
 
 	// /api/v1
 	api := r.Group("/api/v1")
@@ -138,30 +171,102 @@ func Setup(db *gorm.DB, cfg *config.Config, frontendFS embed.FS) *gin.Engine {
 
 			// 静态路由优先（不需要集群权限检查）
 			clusters.GET("/stats", clusterHandler.GetClusterStats)
+			clusters.GET("/labels", clusterHandler.GetClusterLabels)
 			clusters.POST("/import", clusterHandler.ImportCluster)
+			clusters.POST("/import/preview", clusterHandler.PreviewImportContexts)
+			clusters.POST("/import/batch", clusterHandler.BatchImportClusters)
 			clusters.POST("/test-connection", clusterHandler.TestConnection)
+			clusters.POST("/agent", clusterAgentHandler.CreateAgentCluster)
 			clusters.GET("", clusterHandler.GetClusters)
 
 			// 动态 cluster 子分组（需要集群权限检查）
 			cluster := clusters.Group("/:clusterID")
 			cluster.Use(permMiddleware.ClusterAccessRequired()) // 启用集群权限检查
 			cluster.Use(permMiddleware.AutoWriteCheck())        // 自动检查写权限（POST/PUT/DELETE需要非只读权限）
+			// 缓存不健康时为读请求标记数据可能过期（X-Cache-Stale）
+			cluster.Use(middleware.CacheStaleMarker(k8sMgr.IsCacheStale))
 			{
 				cluster.GET("", clusterHandler.GetCluster)
 				cluster.GET("/status", clusterHandler.GetClusterStatus)
+				cluster.GET("/status/history", clusterHandler.GetClusterStatusHistory)
 				cluster.GET("/overview", clusterHandler.GetClusterOverview)
 				cluster.GET("/metrics", clusterHandler.GetClusterMetrics)
 				cluster.GET("/events", clusterHandler.GetClusterEvents)
+				cluster.PUT("", clusterHandler.UpdateCluster)
+				cluster.PUT("/labels", clusterHandler.UpdateClusterLabels)
 				cluster.DELETE("", clusterHandler.DeleteCluster)
 
+				// Agent 接入（反向隧道）
+				cluster.GET("/agent", clusterAgentHandler.GetAgentStatus)
+				cluster.GET("/agent/tokens", clusterAgentHandler.ListAgentTokens)
+				cluster.POST("/agent/tokens", clusterAgentHandler.CreateAgentToken)
+				cluster.DELETE("/agent/tokens/:tokenID", clusterAgentHandler.DeleteAgentToken)
+
+				// informer 缓存统计与健康状态
+				clusterCacheHandler := handlers.NewClusterCacheHandler(clusterSvc, k8sMgr)
+				cluster.GET("/cache/stats", clusterCacheHandler.GetCacheStats)
+				cluster.GET("/cache/status", clusterCacheHandler.GetCacheStatus)
+
+				// 多文档 YAML 按依赖顺序批量应用
+				clusterApplyHandler := handlers.NewClusterApplyHandler(db, clusterSvc, k8sMgr)
+				cluster.POST("/apply", clusterApplyHandler.Apply)
+
+				// 后台任务（进度通过 /ws/tasks/:id 推送）
+				tasks := cluster.Group("/tasks")
+				{
+					tasks.GET("", taskHandler.ListTasks)
+					tasks.GET("/:taskID", taskHandler.GetTask)
+					tasks.POST("/:taskID/cancel", taskHandler.CancelTask)
+				}
+
+				// 资源修订历史（通过平台修改资源前的快照）
+				revisionHandler := handlers.NewResourceRevisionHandler(db, clusterSvc, k8sMgr)
+				revisions := cluster.Group("/revisions")
+				{
+					revisions.GET("", revisionHandler.ListRevisions)
+					revisions.GET("/diff", revisionHandler.DiffRevisions)
+					revisions.GET("/:revisionID", revisionHandler.GetRevision)
+					revisions.POST("/:revisionID/revert", revisionHandler.RevertRevision)
+				}
+
+				// 通用资源（基于发现接口，支持任意 GVR 包括 CRD；核心组使用 core，集群级资源命名空间使用 _）
+				dynamicHandler := handlers.NewDynamicResourceHandler(db, clusterSvc, k8sMgr)
+				cluster.GET("/api-resources", dynamicHandler.GetAPIResources)
+				dynamicResources := cluster.Group("/resources/:group/:version/:resource")
+				{
+					dynamicResources.GET("", dynamicHandler.ListResources)
+					dynamicResources.POST("", dynamicHandler.ApplyResource)
+					dynamicResources.GET("/:namespace/:name", dynamicHandler.GetResource)
+					dynamicResources.GET("/:namespace/:name/yaml", dynamicHandler.GetResourceYAML)
+					dynamicResources.DELETE("/:namespace/:name", dynamicHandler.DeleteResource)
+				}
+
 				// namespaces 子分组
-				namespaceHandler := handlers.NewNamespaceHandler(clusterSvc, k8sMgr)
+				namespaceHandler := handlers.NewNamespaceHandler(db, clusterSvc, k8sMgr)
 				namespaces := cluster.Group("/namespaces")
 				{
 					namespaces.GET("", namespaceHandler.GetNamespaces)
 					namespaces.GET("/:namespace", namespaceHandler.GetNamespaceDetail)
 					namespaces.POST("", namespaceHandler.CreateNamespace)
 					namespaces.DELETE("/:namespace", namespaceHandler.DeleteNamespace)
+
+					// 命名空间模板
+					nsTemplateHandler := handlers.NewNamespaceTemplateHandler(db, clusterSvc, k8sMgr)
+					namespaces.POST("/from-template", nsTemplateHandler.CreateNamespaceFromTemplate)
+					namespaces.GET("/:namespace/template", nsTemplateHandler.GetNamespaceTemplate)
+					namespaces.POST("/:namespace/template", nsTemplateHandler.ApplyNamespaceTemplate)
+
+					// 资源配额与 LimitRange
+					quotaHandler := handlers.NewResourceQuotaHandler(db, clusterSvc, k8sMgr)
+					namespaces.GET("/:namespace/quota-usage", quotaHandler.GetQuotaUsage)
+					namespaces.GET("/:namespace/quotas", quotaHandler.ListResourceQuotas)
+					namespaces.POST("/:namespace/quotas", quotaHandler.CreateResourceQuota)
+					namespaces.PUT("/:namespace/quotas/:name", quotaHandler.UpdateResourceQuota)
+					namespaces.DELETE("/:namespace/quotas/:name", quotaHandler.DeleteResourceQuota)
+					namespaces.GET("/:namespace/limitranges", quotaHandler.ListLimitRanges)
+					namespaces.POST("/:namespace/limitranges", quotaHandler.CreateLimitRange)
+					namespaces.PUT("/:namespace/limitranges/:name", quotaHandler.UpdateLimitRange)
+					namespaces.DELETE("/:namespace/limitranges/:name", quotaHandler.DeleteLimitRange)
 				}
 
 				// monitoring 子分组
@@ -210,15 +315,19 @@ func Setup(db *gorm.DB, cfg *config.Config, frontendFS embed.FS) *gin.Engine {
 				}
 
 				// nodes 子分组
-				nodeHandler := handlers.NewNodeHandler(db, cfg, clusterSvc, k8sMgr, prometheusSvc, monitoringConfigSvc)
+				nodeHandler := handlers.NewNodeHandler(db, cfg, clusterSvc, k8sMgr, prometheusSvc, monitoringConfigSvc, taskSvc)
 				nodes := cluster.Group("/nodes")
 				{
 					nodes.GET("", nodeHandler.GetNodes)
 					nodes.GET("/overview", nodeHandler.GetNodeOverview)
+					nodes.POST("/batch/cordon", nodeHandler.BatchCordonNodes)
+					nodes.POST("/batch/uncordon", nodeHandler.BatchUncordonNodes)
+					nodes.POST("/batch/metadata", nodeHandler.BatchUpdateNodeMetadata)
 					nodes.GET("/:name", nodeHandler.GetNode)
 					nodes.POST("/:name/cordon", nodeHandler.CordonNode)
 					nodes.POST("/:name/uncordon", nodeHandler.UncordonNode)
 					nodes.POST("/:name/drain", nodeHandler.DrainNode)
+					nodes.PUT("/:name/metadata", nodeHandler.UpdateNodeMetadata)
 					nodes.GET("/:name/metrics", monitoringHandler.GetNodeMetrics)
 				}
 
@@ -233,6 +342,8 @@ func Setup(db *gorm.DB, cfg *config.Config, frontendFS embed.FS) *gin.Engine {
 					pods.DELETE("/:namespace/:name", podHandler.DeletePod)
 					pods.GET("/:namespace/:name/logs", podHandler.GetPodLogs)
 					pods.GET("/:namespace/:name/metrics", monitoringHandler.GetPodMetrics)
+					pods.GET("/:namespace/:name/debug", podHandler.ListDebugContainers)
+					pods.POST("/:namespace/:name/debug", podHandler.CreateDebugContainer)
 				}
 
 				// Deployment 子分组
@@ -245,6 +356,13 @@ func Setup(db *gorm.DB, cfg *config.Config, frontendFS embed.FS) *gin.Engine {
 					deployments.GET("/:namespace/:name/metrics", monitoringHandler.GetWorkloadMetrics)
 					deployments.POST("/yaml/apply", deploymentHandler.ApplyYAML)
 					deployments.POST("/:namespace/:name/scale", deploymentHandler.ScaleDeployment)
+					deployments.GET("/:namespace/:name/history", deploymentHandler.GetDeploymentHistory)
+					deployments.POST("/:namespace/:name/rollback", deploymentHandler.RollbackDeployment)
+					deployments.POST("/:namespace/:name/restart", deploymentHandler.RestartDeployment)
+					deployments.POST("/:namespace/:name/pause", deploymentHandler.PauseDeployment)
+					deployments.POST("/:namespace/:name/resume", deploymentHandler.ResumeDeployment)
+					deployments.PUT("/:namespace/:name/image", deploymentHandler.SetDeploymentImage)
+					deployments.GET("/:namespace/:name/rollout-status", deploymentHandler.GetDeploymentRolloutStatus)
 					deployments.DELETE("/:namespace/:name", deploymentHandler.DeleteDeployment)
 					// Deployment详情页相关接口
 					deployments.GET("/:namespace/:name/pods", deploymentHandler.GetDeploymentPods)
@@ -273,6 +391,15 @@ func Setup(db *gorm.DB, cfg *config.Config, frontendFS embed.FS) *gin.Engine {
 					rollouts.GET("/:namespace/:name/events", rolloutHandler.GetRolloutEvents)
 					rollouts.POST("/yaml/apply", rolloutHandler.ApplyYAML)
 					rollouts.POST("/:namespace/:name/scale", rolloutHandler.ScaleRollout)
+					rollouts.GET("/:namespace/:name/status", rolloutHandler.GetRolloutStatus)
+					rollouts.POST("/:namespace/:name/promote", rolloutHandler.PromoteRollout)
+					rollouts.POST("/:namespace/:name/abort", rolloutHandler.AbortRollout)
+					rollouts.POST("/:namespace/:name/retry", rolloutHandler.RetryRollout)
+					rollouts.POST("/:namespace/:name/pause", rolloutHandler.PauseRollout)
+					rollouts.POST("/:namespace/:name/resume", rolloutHandler.ResumeRollout)
+					rollouts.POST("/:namespace/:name/restart", rolloutHandler.RestartRollout)
+					rollouts.PUT("/:namespace/:name/image", rolloutHandler.SetRolloutImage)
+					rollouts.PUT("/:namespace/:name/weight", rolloutHandler.SetRolloutWeight)
 					rollouts.DELETE("/:namespace/:name", rolloutHandler.DeleteRollout)
 				}
 
@@ -286,6 +413,10 @@ func Setup(db *gorm.DB, cfg *config.Config, frontendFS embed.FS) *gin.Engine {
 					statefulSets.GET("/:namespace/:name/metrics", monitoringHandler.GetWorkloadMetrics)
 					statefulSets.POST("/yaml/apply", statefulSetHandler.ApplyYAML)
 					statefulSets.POST("/:namespace/:name/scale", statefulSetHandler.ScaleStatefulSet)
+					statefulSets.POST("/:namespace/:name/restart", statefulSetHandler.RestartStatefulSet)
+					statefulSets.PUT("/:namespace/:name/image", statefulSetHandler.SetStatefulSetImage)
+					statefulSets.PUT("/:namespace/:name/partition", statefulSetHandler.SetStatefulSetPartition)
+					statefulSets.GET("/:namespace/:name/rollout-status", statefulSetHandler.GetStatefulSetRolloutStatus)
 					statefulSets.DELETE("/:namespace/:name", statefulSetHandler.DeleteStatefulSet)
 				}
 
@@ -298,6 +429,9 @@ func Setup(db *gorm.DB, cfg *config.Config, frontendFS embed.FS) *gin.Engine {
 					daemonsets.GET("/:namespace/:name", daemonSetHandler.GetDaemonSet)
 					daemonsets.GET("/:namespace/:name/metrics", monitoringHandler.GetWorkloadMetrics)
 					daemonsets.POST("/yaml/apply", daemonSetHandler.ApplyYAML)
+					daemonsets.POST("/:namespace/:name/restart", daemonSetHandler.RestartDaemonSet)
+					daemonsets.PUT("/:namespace/:name/image", daemonSetHandler.SetDaemonSetImage)
+					daemonsets.GET("/:namespace/:name/rollout-status", daemonSetHandler.GetDaemonSetRolloutStatus)
 					daemonsets.DELETE("/:namespace/:name", daemonSetHandler.DeleteDaemonSet)
 				}
 
@@ -322,9 +456,25 @@ func Setup(db *gorm.DB, cfg *config.Config, frontendFS embed.FS) *gin.Engine {
 					cronjobs.GET("/:namespace/:name", cronJobHandler.GetCronJob)
 					cronjobs.GET("/:namespace/:name/metrics", monitoringHandler.GetWorkloadMetrics)
 					cronjobs.POST("/yaml/apply", cronJobHandler.ApplyYAML)
+					cronjobs.POST("/:namespace/:name/trigger", cronJobHandler.TriggerCronJob)
+					cronjobs.POST("/:namespace/:name/suspend", cronJobHandler.SuspendCronJob)
+					cronjobs.POST("/:namespace/:name/resume", cronJobHandler.ResumeCronJob)
+					cronjobs.GET("/:namespace/:name/history", cronJobHandler.GetCronJobHistory)
 					cronjobs.DELETE("/:namespace/:name", cronJobHandler.DeleteCronJob)
 				}
 
+				// HPA 子分组（autoscaling/v2）
+				hpaHandler := handlers.NewHPAHandler(db, clusterSvc, k8sMgr, prometheusSvc, monitoringConfigSvc)
+				hpas := cluster.Group("/hpas")
+				{
+					hpas.GET("", hpaHandler.ListHPAs)
+					hpas.POST("", hpaHandler.CreateHPA)
+					hpas.GET("/:namespace/:name", hpaHandler.GetHPA)
+					hpas.PUT("/:namespace/:name", hpaHandler.UpdateHPA)
+					hpas.DELETE("/:namespace/:name", hpaHandler.DeleteHPA)
+					hpas.GET("/:namespace/:name/timeline", hpaHandler.GetHPATimeline)
+				}
+
 				// 通用资源 YAML 处理器（用于 dry-run 和 apply）
 				resourceYAMLHandler := handlers.NewResourceYAMLHandler(db, cfg, clusterSvc, k8sMgr)
 
@@ -383,6 +533,20 @@ func Setup(db *gorm.DB, cfg *config.Config, frontendFS embed.FS) *gin.Engine {
 					ingresses.POST("/yaml/apply", resourceYAMLHandler.ApplyIngressYAML)
 				}
 
+				// networkpolicies 子分组
+				networkPolicyHandler := handlers.NewNetworkPolicyHandler(db, clusterSvc, k8sMgr)
+				networkPolicies := cluster.Group("/networkpolicies")
+				{
+					networkPolicies.GET("", networkPolicyHandler.ListNetworkPolicies)
+					networkPolicies.POST("", networkPolicyHandler.CreateNetworkPolicy)
+					networkPolicies.GET("/simulate", networkPolicyHandler.SimulateConnectivity)
+					networkPolicies.POST("/simulate", networkPolicyHandler.SimulateConnectivity)
+					networkPolicies.GET("/isolation", networkPolicyHandler.GetNamespaceIsolation)
+					networkPolicies.GET("/:namespace/:name", networkPolicyHandler.GetNetworkPolicy)
+					networkPolicies.PUT("/:namespace/:name", networkPolicyHandler.UpdateNetworkPolicy)
+					networkPolicies.DELETE("/:namespace/:name", networkPolicyHandler.DeleteNetworkPolicy)
+				}
+
 				// storage 子分组 - PVC, PV, StorageClass
 				storageHandler := handlers.NewStorageHandler(db, cfg, clusterSvc, k8sMgr)
 
@@ -516,6 +680,18 @@ func Setup(db *gorm.DB, cfg *config.Config, frontendFS embed.FS) *gin.Engine {
 			audit.GET("/actions", opLogHandler.GetActions)
 		}
 
+		// namespace templates - 命名空间模板（修改仅平台管理员）
+		nsTemplateHandler := handlers.NewNamespaceTemplateHandler(db, clusterSvc, k8sMgr)
+		nsTemplates := protected.Group("/namespace-templates")
+		{
+			nsTemplates.GET("", nsTemplateHandler.ListTemplates)
+			nsTemplates.GET("/:id", nsTemplateHandler.GetTemplate)
+			nsTemplates.POST("/:id/preview", nsTemplateHandler.PreviewTemplate)
+			nsTemplates.POST("", middleware.PlatformAdminRequired(db), nsTemplateHandler.CreateTemplate)
+			nsTemplates.PUT("/:id", middleware.PlatformAdminRequired(db), nsTemplateHandler.UpdateTemplate)
+			nsTemplates.DELETE("/:id", middleware.PlatformAdminRequired(db), nsTemplateHandler.DeleteTemplate)
+		}
+
 		// monitoring templates
 		monitoringHandler := handlers.NewMonitoringHandler(monitoringConfigSvc, prometheusSvc)
 		protected.GET("/monitoring/templates", monitoringHandler.GetMonitoringTemplates)
@@ -622,6 +798,9 @@ func Setup(db *gorm.DB, cfg *config.Config, frontendFS embed.FS) *gin.Engine {
 		// 节点 SSH 终端（不需要集群权限检查）
 		ws.GET("/ssh/terminal", ssh.SSHConnect)
 
+		// 后台任务进度（在处理器中按任务所属集群检查权限）
+		ws.GET("/tasks/:id", taskHandler.StreamTask)
+
 		// 集群相关的 WebSocket 路由（需要集群权限检查）
 		wsCluster := ws.Group("/clusters/:clusterID")
 		wsCluster.Use(permMiddleware.ClusterAccessRequired()) // 启用集群权限检查
@@ -635,6 +814,9 @@ func Setup(db *gorm.DB, cfg *config.Config, frontendFS embed.FS) *gin.Engine {
 			// Pod 终端：使用 kubectl exec 连接到 Pod
 			wsCluster.GET("/pods/:namespace/:name/terminal", podTerminal.HandlePodTerminal)
 
+			// 临时调试容器终端：attach 到通过 ephemeralcontainers 子资源添加的调试容器
+			wsCluster.GET("/pods/:namespace/:name/debug/:container/terminal", podTerminal.HandlePodDebugTerminal)
+
 			// Pod 日志流式传输
 			wsCluster.GET("/pods/:namespace/:name/logs", podHandler.StreamPodLogs)
 
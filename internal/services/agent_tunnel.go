@@ -0,0 +1,154 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/clay-wangzhi/KubePolaris/pkg/tunnel"
+
+	"k8s.io/apimachinery/pkg/util/httpstream/spdy"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/remotecommand"
+)
+
+// agentTunnelHost Agent 模式下 rest.Config 使用的占位地址
+// 实际连接由 Dial 经隧道转发到 Agent，Agent 再以自身 ServiceAccount 访问集群内 API Server，
+// 因此隧道内使用明文 HTTP（隧道本身运行在 TLS WebSocket 之上）。
+const agentTunnelHost = "http://kubepolaris-agent"
+
+// AgentConnection 一个在线 Agent 的连接信息
+type AgentConnection struct {
+	ClusterID     uint            `json:"clusterId"`
+	TokenID       uint            `json:"-"` // 建立连接时使用的 Agent 令牌
+	Session       *tunnel.Session `json:"-"`
+	RemoteAddr    string          `json:"remoteAddr"`
+	AgentVersion  string          `json:"agentVersion"`
+	K8sVersion    string          `json:"k8sVersion"`
+	ConnectedAt   time.Time       `json:"connectedAt"`
+	LastHeartbeat time.Time       `json:"lastHeartbeat"`
+}
+
+// AgentTunnelRegistry 集群 ID 到 Agent 隧道会话的映射
+// rest.Config 的 Dial 每次建连时都会查询当前会话，Agent 断线重连后无需重建客户端。
+type AgentTunnelRegistry struct {
+	mu    sync.RWMutex
+	conns map[uint]*AgentConnection
+}
+
+var agentTunnels = &AgentTunnelRegistry{conns: make(map[uint]*AgentConnection)}
+
+// GetAgentTunnelRegistry 获取全局 Agent 隧道注册表
+func GetAgentTunnelRegistry() *AgentTunnelRegistry {
+	return agentTunnels
+}
+
+// Register 注册集群的 Agent 连接，返回被替换的旧连接（同一集群同时只保留一个 Agent 连接）
+func (r *AgentTunnelRegistry) Register(conn *AgentConnection) *AgentConnection {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	old := r.conns[conn.ClusterID]
+	r.conns[conn.ClusterID] = conn
+	return old
+}
+
+// Unregister 注销连接；仅当注册表中仍是该会话时才删除，避免误删重连后的新连接
+func (r *AgentTunnelRegistry) Unregister(clusterID uint, session *tunnel.Session) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if conn, ok := r.conns[clusterID]; ok && conn.Session == session {
+		delete(r.conns, clusterID)
+		return true
+	}
+	return false
+}
+
+// Disconnect 关闭集群当前的 Agent 隧道（令牌吊销或集群删除时调用）
+// tokenID 非 0 时仅关闭使用该令牌建立的连接；连接的注销与 informer 清理由 Agent 连接处理流程完成。
+func (r *AgentTunnelRegistry) Disconnect(clusterID, tokenID uint) bool {
+	r.mu.RLock()
+	conn, ok := r.conns[clusterID]
+	r.mu.RUnlock()
+	if !ok || (tokenID != 0 && conn.TokenID != tokenID) {
+		return false
+	}
+	_ = conn.Session.Close()
+	return true
+}
+
+// Get 获取集群当前的 Agent 连接（返回副本，心跳信息可能随后更新）
+func (r *AgentTunnelRegistry) Get(clusterID uint) (*AgentConnection, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	conn, ok := r.conns[clusterID]
+	if !ok {
+		return nil, false
+	}
+	snapshot := *conn
+	return &snapshot, true
+}
+
+// UpdateHeartbeat 记录 Agent 上报的心跳信息
+func (r *AgentTunnelRegistry) UpdateHeartbeat(clusterID uint, session *tunnel.Session, agentVersion, k8sVersion string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	conn, ok := r.conns[clusterID]
+	if !ok || conn.Session != session {
+		return
+	}
+	conn.LastHeartbeat = time.Now()
+	if agentVersion != "" {
+		conn.AgentVersion = agentVersion
+	}
+	if k8sVersion != "" {
+		conn.K8sVersion = k8sVersion
+	}
+}
+
+// DialContext 通过集群当前的 Agent 会话打开一条流
+func (r *AgentTunnelRegistry) DialContext(clusterID uint) func(ctx context.Context, network, address string) (net.Conn, error) {
+	return func(ctx context.Context, network, address string) (net.Conn, error) {
+		conn, ok := r.Get(clusterID)
+		if !ok {
+			return nil, fmt.Errorf("集群 %d 的 Agent 未连接", clusterID)
+		}
+		return conn.Session.Open()
+	}
+}
+
+// NewAgentRESTConfig 构造经 Agent 隧道访问集群的 REST 配置
+func NewAgentRESTConfig(clusterID uint) *rest.Config {
+	return &rest.Config{
+		Host:    agentTunnelHost,
+		Timeout: 30 * time.Second,
+		Dial:    agentTunnels.DialContext(clusterID),
+		// 隧道地址不能走 HTTP_PROXY；设置 Proxy 同时让 client-go 不缓存该 transport
+		// （Dial 每次都是新的闭包，缓存只会不断增长）
+		Proxy: func(*http.Request) (*url.URL, error) { return nil, nil },
+	}
+}
+
+// NewSPDYExecutor 创建 exec/attach 使用的 SPDY 执行器
+// client-go 的 SPDY upgrader 不使用 rest.Config.Dial，Agent 模式下需要显式指定拨号函数。
+func NewSPDYExecutor(config *rest.Config, method string, u *url.URL) (remotecommand.Executor, error) {
+	if config.Dial == nil {
+		return remotecommand.NewSPDYExecutor(config, method, u)
+	}
+
+	upgradeRoundTripper, err := spdy.NewRoundTripperWithConfig(spdy.RoundTripperConfig{
+		UpgradeTransport: &http.Transport{DialContext: config.Dial},
+		PingPeriod:       5 * time.Second,
+	})
+	if err != nil {
+		return nil, err
+	}
+	wrapper, err := rest.HTTPWrappersForConfig(config, upgradeRoundTripper)
+	if err != nil {
+		return nil, err
+	}
+	return remotecommand.NewSPDYExecutorForTransports(wrapper, upgradeRoundTripper, method, u)
+}
@@ -28,6 +28,9 @@ func (s *AIConfigService) GetConfig() (*models.AIConfig, error) {
 		}
 		return nil, fmt.Errorf("获取 AI 配置失败: %w", err)
 	}
+	if err := decryptFields(&config.APIKey); err != nil {
+		return nil, err
+	}
 	return &config, nil
 }
 
@@ -42,10 +45,13 @@ func (s *AIConfigService) GetConfigWithAPIKey() (*models.AIConfig, error) {
 		}
 		return nil, fmt.Errorf("获取 AI 配置失败: %w", err)
 	}
+	if err := decryptFields(&config.APIKey); err != nil {
+		return nil, err
+	}
 	return &config, nil
 }
 
-// SaveConfig 保存 AI 配置（创建或更新）
+// SaveConfig 保存 AI 配置（创建或更新，API Key 加密后落库）
 func (s *AIConfigService) SaveConfig(config *models.AIConfig) error {
 	var existing models.AIConfig
 	err := s.db.Select("id, api_key").First(&existing).Error
@@ -53,22 +59,30 @@ func (s *AIConfigService) SaveConfig(config *models.AIConfig) error {
 		return fmt.Errorf("查询 AI 配置失败: %w", err)
 	}
 
+	stored := *config
 	if err == gorm.ErrRecordNotFound {
-		if err := s.db.Create(config).Error; err != nil {
+		if err := encryptFields(&stored.APIKey); err != nil {
+			return err
+		}
+		if err := s.db.Create(&stored).Error; err != nil {
 			return fmt.Errorf("创建 AI 配置失败: %w", err)
 		}
+		config.ID = stored.ID
 		logger.Info("AI 配置创建成功")
 		return nil
 	}
 
 	// 更新已有记录
 	config.ID = existing.ID
-	// 如果传入的 APIKey 为占位符，保持原有 key 不变
+	stored.ID = existing.ID
+	// 如果传入的 APIKey 为占位符，保持原有 key（已是密文）不变
 	if config.APIKey == "******" {
-		config.APIKey = existing.APIKey
+		stored.APIKey = existing.APIKey
+	} else if err := encryptFields(&stored.APIKey); err != nil {
+		return err
 	}
 
-	if err := s.db.Model(&existing).Select("provider", "endpoint", "api_key", "model", "enabled").Updates(config).Error; err != nil {
+	if err := s.db.Model(&existing).Select("provider", "endpoint", "api_key", "model", "enabled").Updates(&stored).Error; err != nil {
 		return fmt.Errorf("更新 AI 配置失败: %w", err)
 	}
 
@@ -0,0 +1,259 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/dynamic"
+)
+
+// ApplyFieldManager 服务端应用（Server-Side Apply）使用的字段管理者名称
+const ApplyFieldManager = "kubepolaris"
+
+// 资源应用结果的操作类型
+const (
+	ApplyOperationCreated    = "created"
+	ApplyOperationConfigured = "configured"
+	ApplyOperationUnchanged  = "unchanged"
+)
+
+// ApplyOptions 资源应用选项
+type ApplyOptions struct {
+	DryRun bool // 仅预检（服务端 dry-run），不实际落库
+	Force  bool // 发生字段所有权冲突时强制接管
+}
+
+// ApplyResult 单个资源的应用结果
+type ApplyResult struct {
+	Name            string                     `json:"name"`
+	Namespace       string                     `json:"namespace,omitempty"`
+	Kind            string                     `json:"kind"`
+	APIVersion      string                     `json:"apiVersion"`
+	ResourceVersion string                     `json:"resourceVersion,omitempty"`
+	IsCreated       bool                       `json:"isCreated"` // true: 创建, false: 更新
+	Operation       string                     `json:"operation"` // created / configured / unchanged
+	DryRun          bool                       `json:"dryRun"`
+	Diff            *ObjectDiff                `json:"diff"`
+	QuotaWarnings   []QuotaWarning             `json:"quotaWarnings,omitempty"` // 预计将超出的配额
+	Object          *unstructured.Unstructured `json:"-"`
+	Previous        *unstructured.Unstructured `json:"-"` // 应用前的对象（新建时为 nil）
+}
+
+// ErrUnknownResourceType 集群中不存在 YAML 声明的资源类型（如 CRD 未安装）
+var ErrUnknownResourceType = errors.New("集群中不存在该资源类型")
+
+// ApplyConflict 字段所有权冲突
+type ApplyConflict struct {
+	Field   string `json:"field"`   // 冲突字段路径，如 .spec.replicas
+	Manager string `json:"manager"` // 当前持有该字段的管理者
+	Message string `json:"message"`
+}
+
+// ApplyConflictError 服务端应用发生字段所有权冲突（可通过 Force 强制接管）
+type ApplyConflictError struct {
+	Conflicts []ApplyConflict
+	Err       error
+}
+
+func (e *ApplyConflictError) Error() string {
+	fields := make([]string, 0, len(e.Conflicts))
+	for _, c := range e.Conflicts {
+		fields = append(fields, c.Field)
+	}
+	return fmt.Sprintf("字段所有权冲突（%s），可使用强制应用接管", strings.Join(fields, ", "))
+}
+
+func (e *ApplyConflictError) Unwrap() error {
+	return e.Err
+}
+
+// ApplyEngine 统一的资源应用引擎：基于服务端应用实现创建或更新，并返回与当前对象的差异
+type ApplyEngine struct {
+	dynamic   dynamic.Interface
+	discovery discovery.CachedDiscoveryInterface
+}
+
+// NewApplyEngine 使用集群客户端创建应用引擎
+func NewApplyEngine(k8sClient *K8sClient) (*ApplyEngine, error) {
+	dyn, err := k8sClient.DynamicClient()
+	if err != nil {
+		return nil, fmt.Errorf("创建动态客户端失败: %w", err)
+	}
+	return &ApplyEngine{dynamic: dyn, discovery: k8sClient.CachedDiscovery()}, nil
+}
+
+// NewApplyEngineWithClients 使用指定的动态客户端与发现客户端创建应用引擎
+func NewApplyEngineWithClients(dyn dynamic.Interface, disc discovery.CachedDiscoveryInterface) *ApplyEngine {
+	return &ApplyEngine{dynamic: dyn, discovery: disc}
+}
+
+// ParseApplyYAML 解析单个资源的 YAML/JSON，校验 apiVersion/kind/name
+// expectedKind 非空时校验资源类型。
+func ParseApplyYAML(data string, expectedKind string) (*unstructured.Unstructured, error) {
+	obj := &unstructured.Unstructured{}
+	if err := yaml.NewYAMLOrJSONDecoder(strings.NewReader(data), 4096).Decode(&obj.Object); err != nil {
+		return nil, fmt.Errorf("YAML格式错误: %w", err)
+	}
+	if len(obj.Object) == 0 {
+		return nil, fmt.Errorf("YAML内容为空")
+	}
+	if obj.GetAPIVersion() == "" || obj.GetKind() == "" {
+		return nil, fmt.Errorf("YAML缺少必要字段: apiVersion 或 kind")
+	}
+	if expectedKind != "" && obj.GetKind() != expectedKind {
+		return nil, fmt.Errorf("YAML类型错误，期望%s，实际为: %s", expectedKind, obj.GetKind())
+	}
+	if obj.GetName() == "" {
+		return nil, fmt.Errorf("metadata.name 不能为空")
+	}
+	return obj, nil
+}
+
+// ResolveKind 通过发现接口将 apiVersion/kind 解析为 API 资源，未命中时刷新缓存重试一次
+func (e *ApplyEngine) ResolveKind(gvk schema.GroupVersionKind) (*APIResourceInfo, error) {
+	gv := gvk.GroupVersion()
+	for attempt := 0; attempt < 2; attempt++ {
+		list, err := e.discovery.ServerResourcesForGroupVersion(gv.String())
+		if err == nil {
+			for i := range list.APIResources {
+				r := &list.APIResources[i]
+				if r.Kind == gvk.Kind && !strings.Contains(r.Name, "/") {
+					return newAPIResourceInfo(gv, r), nil
+				}
+			}
+		} else if !apierrors.IsNotFound(err) {
+			return nil, fmt.Errorf("获取 %s 的 API 资源失败: %w", gv, err)
+		}
+		e.discovery.Invalidate()
+	}
+	return nil, fmt.Errorf("%w: %s/%s", ErrUnknownResourceType, gv, gvk.Kind)
+}
+
+// Apply 以服务端应用方式创建或更新资源
+// 命名空间级资源未指定命名空间时使用 default；返回结果包含应用前后的结构化差异。
+// 字段所有权冲突时返回 *ApplyConflictError。
+func (e *ApplyEngine) Apply(ctx context.Context, obj *unstructured.Unstructured, opts ApplyOptions) (*ApplyResult, error) {
+	info, err := e.ResolveKind(obj.GroupVersionKind())
+	if err != nil {
+		return nil, err
+	}
+	return e.ApplyResource(ctx, info, obj, opts)
+}
+
+// ApplyResource 与 Apply 相同，但使用已解析的 API 资源
+func (e *ApplyEngine) ApplyResource(ctx context.Context, info *APIResourceInfo, obj *unstructured.Unstructured, opts ApplyOptions) (*ApplyResult, error) {
+	obj = prepareApplyObject(obj, info)
+	ri := ResourceInterface(e.dynamic, info, obj.GetNamespace())
+
+	live, err := ri.Get(ctx, obj.GetName(), metav1.GetOptions{})
+	if err != nil {
+		if !apierrors.IsNotFound(err) {
+			return nil, err
+		}
+		live = nil
+	}
+
+	data, err := obj.MarshalJSON()
+	if err != nil {
+		return nil, fmt.Errorf("序列化资源失败: %w", err)
+	}
+	force := opts.Force
+	patchOpts := metav1.PatchOptions{FieldManager: ApplyFieldManager, Force: &force}
+	if opts.DryRun {
+		patchOpts.DryRun = []string{metav1.DryRunAll}
+	}
+
+	applied, err := ri.Patch(ctx, obj.GetName(), types.ApplyPatchType, data, patchOpts)
+	if err != nil {
+		if conflicts := ParseApplyConflicts(err); len(conflicts) > 0 {
+			return nil, &ApplyConflictError{Conflicts: conflicts, Err: err}
+		}
+		return nil, err
+	}
+
+	result := &ApplyResult{
+		Name:            applied.GetName(),
+		Namespace:       applied.GetNamespace(),
+		Kind:            info.Kind,
+		APIVersion:      info.APIVersion(),
+		ResourceVersion: applied.GetResourceVersion(),
+		IsCreated:       live == nil,
+		DryRun:          opts.DryRun,
+		Object:          applied,
+		Previous:        live,
+	}
+	result.Diff = DiffObjects(live, applied)
+	switch {
+	case live == nil:
+		result.Operation = ApplyOperationCreated
+	case len(result.Diff.Changes) == 0:
+		result.Operation = ApplyOperationUnchanged
+	default:
+		result.Operation = ApplyOperationConfigured
+	}
+	return result, nil
+}
+
+// Get 获取资源在集群中的当前对象
+func (e *ApplyEngine) Get(ctx context.Context, info *APIResourceInfo, namespace, name string) (*unstructured.Unstructured, error) {
+	if !info.Namespaced {
+		namespace = ""
+	}
+	return ResourceInterface(e.dynamic, info, namespace).Get(ctx, name, metav1.GetOptions{})
+}
+
+// prepareApplyObject 去除服务端应用不允许或无意义的字段，并规范命名空间
+// 从页面复制的 YAML 常带有 resourceVersion/managedFields 等，会导致应用失败。
+func prepareApplyObject(in *unstructured.Unstructured, info *APIResourceInfo) *unstructured.Unstructured {
+	obj := in.DeepCopy()
+	obj.SetResourceVersion("")
+	obj.SetManagedFields(nil)
+	obj.SetUID("")
+	obj.SetGeneration(0)
+	obj.SetSelfLink("")
+	unstructured.RemoveNestedField(obj.Object, "metadata", "creationTimestamp")
+	if info.Namespaced {
+		if obj.GetNamespace() == "" {
+			obj.SetNamespace("default")
+		}
+	} else {
+		obj.SetNamespace("")
+	}
+	return obj
+}
+
+var conflictManagerPattern = regexp.MustCompile(`conflict with "([^"]+)"`)
+
+// ParseApplyConflicts 从服务端应用的 409 错误中解析字段所有权冲突；非冲突错误返回 nil
+func ParseApplyConflicts(err error) []ApplyConflict {
+	var statusErr apierrors.APIStatus
+	if !errors.As(err, &statusErr) || !apierrors.IsConflict(err) {
+		return nil
+	}
+	details := statusErr.Status().Details
+	if details == nil {
+		return nil
+	}
+	var conflicts []ApplyConflict
+	for _, cause := range details.Causes {
+		if cause.Type != metav1.CauseTypeFieldManagerConflict {
+			continue
+		}
+		conflict := ApplyConflict{Field: cause.Field, Message: cause.Message}
+		if m := conflictManagerPattern.FindStringSubmatch(cause.Message); m != nil {
+			conflict.Manager = m[1]
+		}
+		conflicts = append(conflicts, conflict)
+	}
+	return conflicts
+}
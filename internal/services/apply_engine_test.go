@@ -0,0 +1,83 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func TestDiffObjects(t *testing.T) {
+	live := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "apps/v1",
+		"kind":       "Deployment",
+		"metadata": map[string]interface{}{
+			"name":            "web",
+			"resourceVersion": "1",
+			"annotations":     map[string]interface{}{"app.kubernetes.io/owner": "a"},
+		},
+		"spec": map[string]interface{}{
+			"replicas": int64(1),
+			"template": map[string]interface{}{"spec": map[string]interface{}{
+				"containers": []interface{}{map[string]interface{}{"name": "web", "image": "nginx:1.24"}},
+			}},
+		},
+	}}
+	applied := live.DeepCopy()
+	applied.SetResourceVersion("2")
+	applied.SetAnnotations(nil)
+	require.NoError(t, unstructured.SetNestedField(applied.Object, int64(3), "spec", "replicas"))
+	require.NoError(t, unstructured.SetNestedSlice(applied.Object, []interface{}{
+		map[string]interface{}{"name": "web", "image": "nginx:1.25"},
+	}, "spec", "template", "spec", "containers"))
+
+	diff := DiffObjects(live, applied)
+	assert.Equal(t, []FieldChange{
+		{Path: ".metadata.annotations", Type: FieldChangeRemoved, Old: map[string]interface{}{"app.kubernetes.io/owner": "a"}},
+		{Path: ".spec.replicas", Type: FieldChangeModified, Old: int64(1), New: int64(3)},
+		{Path: ".spec.template.spec.containers[0].image", Type: FieldChangeModified, Old: "nginx:1.24", New: "nginx:1.25"},
+	}, diff.Changes)
+	assert.NotContains(t, diff.Applied, "resourceVersion")
+
+	created := DiffObjects(nil, applied)
+	assert.Empty(t, created.Changes)
+	assert.Empty(t, created.Live)
+	assert.Contains(t, created.Applied, "nginx:1.25")
+}
+
+func TestDiffObjectsMasksSecret(t *testing.T) {
+	secret := func(value string) *unstructured.Unstructured {
+		return &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "Secret",
+			"metadata":   map[string]interface{}{"name": "db"},
+			"data":       map[string]interface{}{"password": value},
+		}}
+	}
+
+	diff := DiffObjects(secret("b2xk"), secret("bmV3"))
+	require.Len(t, diff.Changes, 1)
+	assert.Equal(t, maskedValue, diff.Changes[0].Old)
+	assert.Equal(t, maskedValue, diff.Changes[0].New)
+	assert.NotContains(t, diff.Live, "b2xk")
+	assert.NotContains(t, diff.Applied, "bmV3")
+}
+
+func TestParseApplyConflicts(t *testing.T) {
+	err := apierrors.NewApplyConflict([]metav1.StatusCause{{
+		Type:    metav1.CauseTypeFieldManagerConflict,
+		Message: `conflict with "kubectl-client-side-apply" using apps/v1`,
+		Field:   ".spec.replicas",
+	}}, "Apply failed with 1 conflict")
+
+	conflicts := ParseApplyConflicts(err)
+	require.Len(t, conflicts, 1)
+	assert.Equal(t, ".spec.replicas", conflicts[0].Field)
+	assert.Equal(t, "kubectl-client-side-apply", conflicts[0].Manager)
+
+	assert.Nil(t, ParseApplyConflicts(apierrors.NewNotFound(schema.GroupResource{Resource: "pods"}, "x")))
+}
@@ -47,20 +47,34 @@ func (s *ArgoCDService) GetConfig(ctx context.Context, clusterID uint) (*models.
 		}
 		return nil, err
 	}
+	if err := decryptFields(argoCDCredentialFields(&config)...); err != nil {
+		return nil, err
+	}
 	return &config, nil
 }
 
-// SaveConfig 保存 ArgoCD 配置
+// SaveConfig 保存 ArgoCD 配置（敏感字段加密后落库）
 func (s *ArgoCDService) SaveConfig(ctx context.Context, config *models.ArgoCDConfig) error {
+	stored := *config
+	if err := encryptFields(argoCDCredentialFields(&stored)...); err != nil {
+		return err
+	}
+
 	var existing models.ArgoCDConfig
 	if err := s.db.Where("cluster_id = ?", config.ClusterID).First(&existing).Error; err == nil {
 		// 更新
-		config.ID = existing.ID
-		config.CreatedAt = existing.CreatedAt
-		return s.db.Save(config).Error
+		stored.ID = existing.ID
+		stored.CreatedAt = existing.CreatedAt
+		if err := s.db.Save(&stored).Error; err != nil {
+			return err
+		}
+	} else if err := s.db.Create(&stored).Error; err != nil {
+		// 新建
+		return err
 	}
-	// 新建
-	return s.db.Create(config).Error
+	config.ID = stored.ID
+	config.CreatedAt = stored.CreatedAt
+	return nil
 }
 
 // TestConnection 测试 ArgoCD 连接
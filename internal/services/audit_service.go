@@ -24,9 +24,10 @@ func NewAuditService(db *gorm.DB) *AuditService {
 type TerminalType string
 
 const (
-	TerminalTypeKubectl TerminalType = "kubectl"
-	TerminalTypePod     TerminalType = "pod"
-	TerminalTypeNode    TerminalType = "node"
+	TerminalTypeKubectl  TerminalType = "kubectl"
+	TerminalTypePod      TerminalType = "pod"
+	TerminalTypeNode     TerminalType = "node"
+	TerminalTypePodDebug TerminalType = "pod_debug" // 附加到临时调试容器
 )
 
 // CreateSessionRequest 创建会话请求
@@ -416,12 +417,13 @@ func (s *AuditService) GetSessionCommands(sessionID uint, page, pageSize int) (*
 
 // GetSessionStats 获取会话统计信息
 type SessionStats struct {
-	TotalSessions   int64 `json:"total_sessions"`
-	ActiveSessions  int64 `json:"active_sessions"`
-	TotalCommands   int64 `json:"total_commands"`
-	KubectlSessions int64 `json:"kubectl_sessions"`
-	PodSessions     int64 `json:"pod_sessions"`
-	NodeSessions    int64 `json:"node_sessions"`
+	TotalSessions    int64 `json:"total_sessions"`
+	ActiveSessions   int64 `json:"active_sessions"`
+	TotalCommands    int64 `json:"total_commands"`
+	KubectlSessions  int64 `json:"kubectl_sessions"`
+	PodSessions      int64 `json:"pod_sessions"`
+	NodeSessions     int64 `json:"node_sessions"`
+	PodDebugSessions int64 `json:"pod_debug_sessions"`
 }
 
 // GetSessionStats 获取会话统计
@@ -441,6 +443,7 @@ func (s *AuditService) GetSessionStats() (*SessionStats, error) {
 	s.db.Model(&models.TerminalSession{}).Where("target_type = ?", "kubectl").Count(&stats.KubectlSessions)
 	s.db.Model(&models.TerminalSession{}).Where("target_type = ?", "pod").Count(&stats.PodSessions)
 	s.db.Model(&models.TerminalSession{}).Where("target_type = ?", "node").Count(&stats.NodeSessions)
+	s.db.Model(&models.TerminalSession{}).Where("target_type = ?", "pod_debug").Count(&stats.PodDebugSessions)
 
 	return stats, nil
 }
@@ -0,0 +1,329 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/apimachinery/pkg/util/yaml"
+)
+
+// 批量应用中单个资源的状态
+const (
+	BatchApplyStatusApplied = "applied"
+	BatchApplyStatusFailed  = "failed"
+	BatchApplyStatusSkipped = "skipped" // 前序资源失败且未设置继续执行，或依赖的 CRD/命名空间仅做了预检
+)
+
+// crdEstablishTimeout 应用 CRD 后等待其可用的最长时间
+const crdEstablishTimeout = 30 * time.Second
+
+// applyKindPriority 按依赖关系排列的资源应用顺序：
+// CRD → 命名空间 → 策略 → RBAC → 配置与存储 → 服务 → 工作负载 → 路由与扩展；未列出的类型（通常是自定义资源）最后应用。
+var applyKindPriority = func() map[string]int {
+	tiers := [][]string{
+		{"CustomResourceDefinition"},
+		{"Namespace"},
+		{"ResourceQuota", "LimitRange", "PriorityClass", "PodSecurityPolicy", "PodDisruptionBudget", "NetworkPolicy"},
+		{"ServiceAccount", "ClusterRole", "ClusterRoleBinding", "Role", "RoleBinding"},
+		{"Secret", "ConfigMap", "StorageClass", "PersistentVolume", "PersistentVolumeClaim"},
+		{"Service", "Endpoints", "EndpointSlice"},
+		{"Pod", "ReplicaSet", "Deployment", "StatefulSet", "DaemonSet", "Job", "CronJob", "Rollout", "HorizontalPodAutoscaler"},
+		{"IngressClass", "Ingress", "GatewayClass", "Gateway", "HTTPRoute", "APIService", "MutatingWebhookConfiguration", "ValidatingWebhookConfiguration"},
+	}
+	priority := make(map[string]int)
+	for i, kinds := range tiers {
+		for _, kind := range kinds {
+			priority[kind] = i
+		}
+	}
+	return priority
+}()
+
+// ApplyKindPriority 返回资源类型的应用优先级，数值越小越先应用
+func ApplyKindPriority(kind string) int {
+	if p, ok := applyKindPriority[kind]; ok {
+		return p
+	}
+	return len(applyKindPriority)
+}
+
+// BatchApplyOptions 批量应用选项
+type BatchApplyOptions struct {
+	ApplyOptions
+	ContinueOnError bool // 单个资源失败后继续应用其余资源
+	// Authorize 应用前的权限校验，返回错误时该资源记为失败
+	Authorize func(info *APIResourceInfo, obj *unstructured.Unstructured) error
+}
+
+// BatchApplyItem 批量应用中单个资源的结果
+type BatchApplyItem struct {
+	Index      int             `json:"index"` // 在原始 YAML 中的文档序号（从 0 开始）
+	Kind       string          `json:"kind"`
+	APIVersion string          `json:"apiVersion"`
+	Name       string          `json:"name"`
+	Namespace  string          `json:"namespace,omitempty"`
+	Status     string          `json:"status"` // applied / failed / skipped
+	Error      string          `json:"error,omitempty"`
+	Conflicts  []ApplyConflict `json:"conflicts,omitempty"`
+	Result     *ApplyResult    `json:"result,omitempty"`
+}
+
+// BatchApplyResult 批量应用结果
+type BatchApplyResult struct {
+	Items     []BatchApplyItem `json:"items"` // 按实际应用顺序排列
+	Total     int              `json:"total"`
+	Succeeded int              `json:"succeeded"`
+	Failed    int              `json:"failed"`
+	Skipped   int              `json:"skipped"`
+	DryRun    bool             `json:"dryRun"`
+	// QuotaWarnings 应用前预估的配额超限项
+	QuotaWarnings []QuotaWarning `json:"quotaWarnings,omitempty"`
+}
+
+// ParseMultiDocYAML 解析多文档 YAML（--- 分隔），展开 kind: List，忽略空文档
+func ParseMultiDocYAML(data string) ([]*unstructured.Unstructured, error) {
+	decoder := yaml.NewYAMLOrJSONDecoder(strings.NewReader(data), 4096)
+	var objs []*unstructured.Unstructured
+	for doc := 0; ; doc++ {
+		obj := &unstructured.Unstructured{}
+		if err := decoder.Decode(&obj.Object); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return nil, fmt.Errorf("第 %d 个文档 YAML格式错误: %w", doc+1, err)
+		}
+		if len(obj.Object) == 0 {
+			continue
+		}
+		if obj.IsList() {
+			list, err := obj.ToList()
+			if err != nil {
+				return nil, fmt.Errorf("第 %d 个文档解析 List 失败: %w", doc+1, err)
+			}
+			for i := range list.Items {
+				objs = append(objs, &list.Items[i])
+			}
+			continue
+		}
+		objs = append(objs, obj)
+	}
+	if len(objs) == 0 {
+		return nil, fmt.Errorf("YAML内容为空")
+	}
+	for i, obj := range objs {
+		if obj.GetAPIVersion() == "" || obj.GetKind() == "" {
+			return nil, fmt.Errorf("第 %d 个资源缺少必要字段: apiVersion 或 kind", i+1)
+		}
+		if obj.GetName() == "" {
+			return nil, fmt.Errorf("第 %d 个资源（%s）metadata.name 不能为空", i+1, obj.GetKind())
+		}
+	}
+	return objs, nil
+}
+
+// SortForApply 按资源类型优先级稳定排序，返回排序后对象在原切片中的下标
+func SortForApply(objs []*unstructured.Unstructured) []int {
+	order := make([]int, len(objs))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return ApplyKindPriority(objs[order[a]].GetKind()) < ApplyKindPriority(objs[order[b]].GetKind())
+	})
+	return order
+}
+
+// ApplyAll 按依赖顺序逐个应用资源
+// 新建的 CRD 会等待其 Established 后再应用后续资源；预检模式下依赖本批 CRD 的自定义资源、
+// 以及位于本批新建命名空间中的资源记为跳过（预检不会真正创建 CRD 与命名空间）。
+func (e *ApplyEngine) ApplyAll(ctx context.Context, objs []*unstructured.Unstructured, opts BatchApplyOptions) *BatchApplyResult {
+	result := &BatchApplyResult{Total: len(objs), DryRun: opts.DryRun, Items: make([]BatchApplyItem, 0, len(objs))}
+
+	// 本批次定义的 CRD（group/kind），用于识别依赖它们的自定义资源
+	batchCRDs := make(map[schema.GroupKind]bool)
+	for _, obj := range objs {
+		if gk, ok := crdGroupKind(obj); ok {
+			batchCRDs[gk] = true
+		}
+	}
+
+	// 本批次新建的命名空间（预检模式下由 Namespace 的预检结果得出）
+	batchNamespaces := make(map[string]bool)
+
+	stopped := false
+	for _, idx := range SortForApply(objs) {
+		obj := objs[idx]
+		item := BatchApplyItem{
+			Index:      idx,
+			Kind:       obj.GetKind(),
+			APIVersion: obj.GetAPIVersion(),
+			Name:       obj.GetName(),
+			Namespace:  obj.GetNamespace(),
+		}
+		if stopped {
+			item.Status = BatchApplyStatusSkipped
+			item.Error = "前序资源应用失败，已停止"
+			result.add(item)
+			continue
+		}
+
+		applied, err := e.applyBatchItem(ctx, obj, opts)
+		switch {
+		case err == nil:
+			item.Status = BatchApplyStatusApplied
+			item.Namespace = applied.Namespace
+			item.Result = applied
+			if opts.DryRun && isNamespaceObject(obj) && applied.Operation == ApplyOperationCreated {
+				batchNamespaces[applied.Name] = true
+			}
+		case opts.DryRun && errors.Is(err, ErrUnknownResourceType) && batchCRDs[obj.GroupVersionKind().GroupKind()]:
+			item.Status = BatchApplyStatusSkipped
+			item.Error = "依赖本次提交的 CRD，预检模式下无法校验"
+		case opts.DryRun && apierrors.IsNotFound(err) && batchNamespaces[obj.GetNamespace()]:
+			item.Status = BatchApplyStatusSkipped
+			item.Error = fmt.Sprintf("命名空间 %s 由本次提交创建，预检模式下无法校验", obj.GetNamespace())
+		default:
+			item.Status = BatchApplyStatusFailed
+			item.Error = err.Error()
+			var conflictErr *ApplyConflictError
+			if errors.As(err, &conflictErr) {
+				item.Conflicts = conflictErr.Conflicts
+			}
+			stopped = !opts.ContinueOnError
+		}
+		result.add(item)
+	}
+	return result
+}
+
+func (e *ApplyEngine) applyBatchItem(ctx context.Context, obj *unstructured.Unstructured, opts BatchApplyOptions) (*ApplyResult, error) {
+	info, err := e.ResolveKind(obj.GroupVersionKind())
+	if err != nil {
+		return nil, err
+	}
+	if opts.Authorize != nil {
+		if err := opts.Authorize(info, prepareApplyObject(obj, info)); err != nil {
+			return nil, err
+		}
+	}
+	applied, err := e.ApplyResource(ctx, info, obj, opts.ApplyOptions)
+	if err != nil {
+		return nil, err
+	}
+	if _, isCRD := crdGroupKind(obj); isCRD && !opts.DryRun {
+		if err := e.waitForCRDEstablished(ctx, info, applied.Name); err != nil {
+			return nil, err
+		}
+		// 新 CRD 生效后刷新发现缓存，后续自定义资源才能解析
+		e.discovery.Invalidate()
+	}
+	return applied, nil
+}
+
+// RevertApplied 按应用的逆序撤销批量应用中已成功的资源：新建的资源删除，已更新的资源恢复为应用前的对象
+// 返回撤销失败的资源及原因；预检结果无需撤销。
+func (e *ApplyEngine) RevertApplied(ctx context.Context, result *BatchApplyResult) []string {
+	if result.DryRun {
+		return nil
+	}
+	var failed []string
+	for i := len(result.Items) - 1; i >= 0; i-- {
+		item := result.Items[i]
+		if item.Status != BatchApplyStatusApplied || item.Result == nil || item.Result.Operation == ApplyOperationUnchanged {
+			continue
+		}
+		if err := e.revertItem(ctx, item.Result); err != nil {
+			failed = append(failed, fmt.Sprintf("%s/%s: %v", item.Kind, item.Name, err))
+		}
+	}
+	return failed
+}
+
+func (e *ApplyEngine) revertItem(ctx context.Context, applied *ApplyResult) error {
+	info, err := e.ResolveKind(schema.FromAPIVersionAndKind(applied.APIVersion, applied.Kind))
+	if err != nil {
+		return err
+	}
+	ri := ResourceInterface(e.dynamic, info, applied.Namespace)
+	if applied.Previous == nil {
+		err := ri.Delete(ctx, applied.Name, metav1.DeleteOptions{})
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+
+	current, err := ri.Get(ctx, applied.Name, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+	prev := applied.Previous.DeepCopy()
+	prev.SetResourceVersion(current.GetResourceVersion())
+	prev.SetManagedFields(nil)
+	_, err = ri.Update(ctx, prev, metav1.UpdateOptions{})
+	return err
+}
+
+// waitForCRDEstablished 等待 CRD 的 Established 条件为 True
+func (e *ApplyEngine) waitForCRDEstablished(ctx context.Context, info *APIResourceInfo, name string) error {
+	ri := ResourceInterface(e.dynamic, info, "")
+	err := wait.PollUntilContextTimeout(ctx, time.Second, crdEstablishTimeout, true, func(ctx context.Context) (bool, error) {
+		crd, err := ri.Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return false, nil
+		}
+		conditions, _, _ := unstructured.NestedSlice(crd.Object, "status", "conditions")
+		for _, c := range conditions {
+			cond, ok := c.(map[string]interface{})
+			if ok && cond["type"] == "Established" && cond["status"] == "True" {
+				return true, nil
+			}
+		}
+		return false, nil
+	})
+	if err != nil {
+		return fmt.Errorf("等待 CRD %s 就绪超时: %w", name, err)
+	}
+	return nil
+}
+
+// isNamespaceObject 是否为 core/v1 Namespace 对象
+func isNamespaceObject(obj *unstructured.Unstructured) bool {
+	gvk := obj.GroupVersionKind()
+	return gvk.Group == "" && gvk.Kind == "Namespace"
+}
+
+// crdGroupKind 返回 CRD 对象定义的资源组与类型
+func crdGroupKind(obj *unstructured.Unstructured) (schema.GroupKind, bool) {
+	if obj.GetKind() != "CustomResourceDefinition" || obj.GroupVersionKind().Group != "apiextensions.k8s.io" {
+		return schema.GroupKind{}, false
+	}
+	group, _, _ := unstructured.NestedString(obj.Object, "spec", "group")
+	kind, _, _ := unstructured.NestedString(obj.Object, "spec", "names", "kind")
+	if kind == "" {
+		return schema.GroupKind{}, false
+	}
+	return schema.GroupKind{Group: group, Kind: kind}, true
+}
+
+func (r *BatchApplyResult) add(item BatchApplyItem) {
+	r.Items = append(r.Items, item)
+	switch item.Status {
+	case BatchApplyStatusApplied:
+		r.Succeeded++
+	case BatchApplyStatusFailed:
+		r.Failed++
+	default:
+		r.Skipped++
+	}
+}
@@ -0,0 +1,145 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery/cached/memory"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+const bundleYAML = `
+apiVersion: networking.k8s.io/v1
+kind: Ingress
+metadata:
+  name: web
+  namespace: demo
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  namespace: demo
+---
+# 空文档会被忽略
+---
+apiVersion: v1
+kind: List
+items:
+- apiVersion: v1
+  kind: ConfigMap
+  metadata:
+    name: web-config
+    namespace: demo
+- apiVersion: example.com/v1
+  kind: Widget
+  metadata:
+    name: w1
+---
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: demo
+---
+apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: widgets.example.com
+spec:
+  group: example.com
+  names:
+    kind: Widget
+`
+
+func TestParseMultiDocYAMLAndSort(t *testing.T) {
+	objs, err := ParseMultiDocYAML(bundleYAML)
+	require.NoError(t, err)
+	require.Len(t, objs, 6)
+
+	var kinds []string
+	for _, idx := range SortForApply(objs) {
+		kinds = append(kinds, objs[idx].GetKind())
+	}
+	assert.Equal(t, []string{"CustomResourceDefinition", "Namespace", "ConfigMap", "Deployment", "Ingress", "Widget"}, kinds)
+
+	gk, ok := crdGroupKind(objs[5])
+	require.True(t, ok)
+	assert.Equal(t, "example.com", gk.Group)
+	assert.Equal(t, "Widget", gk.Kind)
+}
+
+func TestParseMultiDocYAMLErrors(t *testing.T) {
+	_, err := ParseMultiDocYAML("---\n---\n")
+	assert.Error(t, err)
+
+	_, err = ParseMultiDocYAML("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: a\n---\napiVersion: v1\nkind: Secret\n")
+	assert.ErrorContains(t, err, "第 2 个资源")
+}
+
+func TestApplyAllDryRunSkipsObjectsInBundleNamespace(t *testing.T) {
+	dyn := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+		{Version: "v1", Resource: "namespaces"}: "NamespaceList",
+		{Version: "v1", Resource: "configmaps"}: "ConfigMapList",
+	})
+	// 模拟服务端 dry-run：只有 default 命名空间真实存在
+	dyn.PrependReactor("patch", "*", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		patch := action.(k8stesting.PatchAction)
+		if patch.GetResource().Resource != "namespaces" && patch.GetNamespace() != "default" {
+			return true, nil, apierrors.NewNotFound(schema.GroupResource{Resource: "namespaces"}, patch.GetNamespace())
+		}
+		obj := &unstructured.Unstructured{}
+		return true, obj, obj.UnmarshalJSON(patch.GetPatch())
+	})
+	engine := NewApplyEngineWithClients(dyn, memory.NewMemCacheClient(newFakeDiscovery()))
+
+	objs, err := ParseMultiDocYAML(`
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: settings
+  namespace: team-a
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: shared
+  namespace: default
+---
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: team-a
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: orphan
+  namespace: missing
+`)
+	require.NoError(t, err)
+
+	result := engine.ApplyAll(context.Background(), objs, BatchApplyOptions{
+		ApplyOptions:    ApplyOptions{DryRun: true},
+		ContinueOnError: true,
+	})
+	status := make(map[string]BatchApplyItem)
+	for _, item := range result.Items {
+		status[item.Kind+"/"+item.Name] = item
+	}
+	assert.Equal(t, BatchApplyStatusApplied, status["Namespace/team-a"].Status)
+	assert.Equal(t, BatchApplyStatusApplied, status["ConfigMap/shared"].Status)
+	assert.Equal(t, BatchApplyStatusSkipped, status["ConfigMap/settings"].Status)
+	assert.Contains(t, status["ConfigMap/settings"].Error, "命名空间 team-a 由本次提交创建")
+	// 不在本次提交中创建的命名空间仍按失败处理
+	assert.Equal(t, BatchApplyStatusFailed, status["ConfigMap/orphan"].Status)
+	assert.Equal(t, 2, result.Succeeded)
+	assert.Equal(t, 1, result.Skipped)
+	assert.Equal(t, 1, result.Failed)
+}
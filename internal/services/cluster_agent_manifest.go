@@ -0,0 +1,97 @@
+package services
+
+import (
+	"bytes"
+	"text/template"
+)
+
+// AgentManifestOptions 集群 Agent 部署清单参数
+type AgentManifestOptions struct {
+	ServerURL string // KubePolaris 外部访问地址（http/https）
+	Token     string // Agent 接入令牌明文
+	Image     string // Agent 镜像
+}
+
+// agentManifestTemplate 集群 Agent 部署清单
+// Agent 以 cluster-admin 身份代理 KubePolaris 对集群的访问，权限与直连模式导入的凭据一致。
+var agentManifestTemplate = template.Must(template.New("agent").Parse(`apiVersion: v1
+kind: Namespace
+metadata:
+  name: kubepolaris-agent
+---
+apiVersion: v1
+kind: ServiceAccount
+metadata:
+  name: kubepolaris-agent
+  namespace: kubepolaris-agent
+---
+apiVersion: rbac.authorization.k8s.io/v1
+kind: ClusterRoleBinding
+metadata:
+  name: kubepolaris-agent
+roleRef:
+  apiGroup: rbac.authorization.k8s.io
+  kind: ClusterRole
+  name: cluster-admin
+subjects:
+- kind: ServiceAccount
+  name: kubepolaris-agent
+  namespace: kubepolaris-agent
+---
+apiVersion: v1
+kind: Secret
+metadata:
+  name: kubepolaris-agent
+  namespace: kubepolaris-agent
+type: Opaque
+stringData:
+  token: {{ printf "%q" .Token }}
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: kubepolaris-agent
+  namespace: kubepolaris-agent
+  labels:
+    app: kubepolaris-agent
+spec:
+  replicas: 1
+  strategy:
+    type: Recreate
+  selector:
+    matchLabels:
+      app: kubepolaris-agent
+  template:
+    metadata:
+      labels:
+        app: kubepolaris-agent
+    spec:
+      serviceAccountName: kubepolaris-agent
+      containers:
+      - name: agent
+        image: {{ .Image }}
+        command: ["/app/kubepolaris-agent"]
+        env:
+        - name: KUBEPOLARIS_SERVER
+          value: {{ printf "%q" .ServerURL }}
+        - name: KUBEPOLARIS_AGENT_TOKEN
+          valueFrom:
+            secretKeyRef:
+              name: kubepolaris-agent
+              key: token
+        resources:
+          requests:
+            cpu: 50m
+            memory: 64Mi
+          limits:
+            memory: 256Mi
+`))
+
+// RenderAgentManifest 生成集群 Agent 的部署清单（kubectl apply -f 即可部署）
+func RenderAgentManifest(opts AgentManifestOptions) (string, error) {
+	var buf bytes.Buffer
+	if err := agentManifestTemplate.Execute(&buf, opts); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
@@ -0,0 +1,122 @@
+package services
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/clay-wangzhi/KubePolaris/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// agentTokenPrefix Agent 令牌前缀，便于在日志/密钥扫描中识别
+const agentTokenPrefix = "kpat_"
+
+// ErrInvalidAgentToken Agent 令牌无效或已过期
+var ErrInvalidAgentToken = errors.New("Agent 令牌无效或已过期")
+
+// ClusterAgentService 集群 Agent 接入服务
+type ClusterAgentService struct {
+	db *gorm.DB
+}
+
+// NewClusterAgentService 创建集群 Agent 接入服务
+func NewClusterAgentService(db *gorm.DB) *ClusterAgentService {
+	return &ClusterAgentService{db: db}
+}
+
+// hashAgentToken 计算令牌摘要（数据库只保存摘要）
+func hashAgentToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// CreateToken 为集群签发 Agent 令牌，返回的明文令牌只在此时可见
+func (s *ClusterAgentService) CreateToken(clusterID uint, description string, expiresAt *time.Time, createdBy uint) (string, *models.ClusterAgentToken, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", nil, fmt.Errorf("生成 Agent 令牌失败: %w", err)
+	}
+	plain := agentTokenPrefix + hex.EncodeToString(buf)
+
+	token := &models.ClusterAgentToken{
+		ClusterID:   clusterID,
+		TokenHash:   hashAgentToken(plain),
+		TokenPrefix: plain[:len(agentTokenPrefix)+6],
+		Description: description,
+		ExpiresAt:   expiresAt,
+		CreatedBy:   createdBy,
+	}
+	if err := s.db.Create(token).Error; err != nil {
+		return "", nil, fmt.Errorf("保存 Agent 令牌失败: %w", err)
+	}
+	return plain, token, nil
+}
+
+// ListTokens 获取集群的 Agent 令牌列表
+func (s *ClusterAgentService) ListTokens(clusterID uint) ([]models.ClusterAgentToken, error) {
+	var tokens []models.ClusterAgentToken
+	if err := s.db.Where("cluster_id = ?", clusterID).Order("created_at DESC").Find(&tokens).Error; err != nil {
+		return nil, fmt.Errorf("获取 Agent 令牌失败: %w", err)
+	}
+	return tokens, nil
+}
+
+// DeleteToken 吊销 Agent 令牌，并断开使用该令牌建立的隧道
+func (s *ClusterAgentService) DeleteToken(clusterID, tokenID uint) error {
+	result := s.db.Where("id = ? AND cluster_id = ?", tokenID, clusterID).Delete(&models.ClusterAgentToken{})
+	if result.Error != nil {
+		return fmt.Errorf("删除 Agent 令牌失败: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("Agent 令牌不存在: %d", tokenID)
+	}
+	GetAgentTunnelRegistry().Disconnect(clusterID, tokenID)
+	return nil
+}
+
+// Authenticate 校验 Agent 令牌，返回其所属集群及令牌记录
+func (s *ClusterAgentService) Authenticate(plain string) (*models.Cluster, *models.ClusterAgentToken, error) {
+	if !strings.HasPrefix(plain, agentTokenPrefix) {
+		return nil, nil, ErrInvalidAgentToken
+	}
+
+	var token models.ClusterAgentToken
+	if err := s.db.Where("token_hash = ?", hashAgentToken(plain)).First(&token).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, ErrInvalidAgentToken
+		}
+		return nil, nil, fmt.Errorf("查询 Agent 令牌失败: %w", err)
+	}
+	now := time.Now()
+	if token.ExpiresAt != nil && token.ExpiresAt.Before(now) {
+		return nil, nil, ErrInvalidAgentToken
+	}
+
+	var cluster models.Cluster
+	if err := s.db.First(&cluster, token.ClusterID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, ErrInvalidAgentToken
+		}
+		return nil, nil, fmt.Errorf("查询集群失败: %w", err)
+	}
+	if !cluster.IsAgentMode() {
+		return nil, nil, fmt.Errorf("集群 %s 不是 Agent 接入模式", cluster.Name)
+	}
+
+	_ = s.db.Model(&token).Update("last_used_at", &now).Error
+	return &cluster, &token, nil
+}
+
+// RecordHeartbeat 刷新集群心跳时间（Agent 心跳到达时调用）
+func (s *ClusterAgentService) RecordHeartbeat(clusterID uint) error {
+	if err := s.db.Model(&models.Cluster{}).Where("id = ?", clusterID).Update("last_heartbeat", time.Now()).Error; err != nil {
+		return fmt.Errorf("更新集群心跳失败: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,105 @@
+package services
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/clay-wangzhi/KubePolaris/internal/models"
+	"github.com/clay-wangzhi/KubePolaris/pkg/tunnel"
+
+	"github.com/glebarez/sqlite"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// newAgentTestSession 建立一对隧道会话，返回平台端会话
+func newAgentTestSession(t *testing.T) *tunnel.Session {
+	t.Helper()
+	serverCh := make(chan *tunnel.Session, 1)
+	upgrader := websocket.Upgrader{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			t.Errorf("upgrade: %v", err)
+			return
+		}
+		serverCh <- tunnel.NewSession(conn, tunnel.Config{})
+	}))
+	t.Cleanup(srv.Close)
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(srv.URL, "http"), nil)
+	require.NoError(t, err)
+	agent := tunnel.NewSession(conn, tunnel.Config{})
+	server := <-serverCh
+	t.Cleanup(func() {
+		_ = server.Close()
+		_ = agent.Close()
+	})
+	return server
+}
+
+func assertSessionClosed(t *testing.T, session *tunnel.Session, closed bool) {
+	t.Helper()
+	select {
+	case <-session.Done():
+		assert.True(t, closed, "会话不应被关闭")
+	case <-time.After(200 * time.Millisecond):
+		assert.False(t, closed, "会话应已关闭")
+	}
+}
+
+func TestAgentTunnelClosedOnRevocation(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	require.NoError(t, err)
+	// 内存数据库仅在单个连接内可见
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	sqlDB.SetMaxOpenConns(1)
+	require.NoError(t, db.AutoMigrate(&models.Cluster{}, &models.ClusterAgentToken{}, &models.ClusterPermission{},
+		&models.TerminalSession{}, &models.TerminalCommand{}, &models.ArgoCDConfig{}, &models.OperationLog{},
+		&models.ClusterMetrics{}, &models.ClusterStatusHistory{}))
+
+	cluster := &models.Cluster{Name: "edge", APIServer: agentTunnelHost, ConnectionMode: models.ClusterConnectionAgent}
+	require.NoError(t, db.Create(cluster).Error)
+	svc := NewClusterAgentService(db)
+	plain, active, err := svc.CreateToken(cluster.ID, "active", nil, 1)
+	require.NoError(t, err)
+	_, other, err := svc.CreateToken(cluster.ID, "other", nil, 1)
+	require.NoError(t, err)
+
+	_, token, err := svc.Authenticate(plain)
+	require.NoError(t, err)
+	assert.Equal(t, active.ID, token.ID)
+
+	registry := GetAgentTunnelRegistry()
+	session := newAgentTestSession(t)
+	registry.Register(&AgentConnection{ClusterID: cluster.ID, TokenID: token.ID, Session: session})
+	first := session
+	t.Cleanup(func() { registry.Unregister(cluster.ID, first) })
+
+	// 吊销其他令牌不影响当前连接
+	require.NoError(t, svc.DeleteToken(cluster.ID, other.ID))
+	assertSessionClosed(t, session, false)
+
+	require.NoError(t, svc.DeleteToken(cluster.ID, active.ID))
+	assertSessionClosed(t, session, true)
+	_, _, err = svc.Authenticate(plain)
+	assert.ErrorIs(t, err, ErrInvalidAgentToken)
+
+	// 删除集群时断开仍在线的隧道
+	plain, _, err = svc.CreateToken(cluster.ID, "rotated", nil, 1)
+	require.NoError(t, err)
+	_, token, err = svc.Authenticate(plain)
+	require.NoError(t, err)
+	session = newAgentTestSession(t)
+	registry.Register(&AgentConnection{ClusterID: cluster.ID, TokenID: token.ID, Session: session})
+	t.Cleanup(func() { registry.Unregister(cluster.ID, session) })
+	require.NoError(t, NewClusterService(db).DeleteCluster(cluster.ID))
+	assertSessionClosed(t, session, true)
+}
@@ -0,0 +1,79 @@
+package services
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"time"
+
+	"k8s.io/client-go/rest"
+)
+
+// CertExpireAt 返回客户端证书与 CA 证书中最早的过期时间
+// Token 认证且未提供 CA 时没有可解析的证书，返回 nil。
+func (c *K8sClient) CertExpireAt() (*time.Time, error) {
+	return ParseCertExpiry(c.config)
+}
+
+// ParseCertExpiry 从 REST 配置中解析客户端证书与 CA 证书，返回最早的过期时间
+func ParseCertExpiry(config *rest.Config) (*time.Time, error) {
+	if config == nil {
+		return nil, nil
+	}
+
+	var earliest *time.Time
+	for _, src := range []struct {
+		name string
+		data []byte
+		file string
+	}{
+		{"客户端证书", config.CertData, config.CertFile},
+		{"CA 证书", config.CAData, config.CAFile},
+	} {
+		data := src.data
+		if len(data) == 0 && src.file != "" {
+			b, err := os.ReadFile(src.file)
+			if err != nil {
+				return nil, fmt.Errorf("读取%s失败: %w", src.name, err)
+			}
+			data = b
+		}
+		if len(data) == 0 {
+			continue
+		}
+
+		notAfter, err := earliestNotAfter(data)
+		if err != nil {
+			return nil, fmt.Errorf("解析%s失败: %w", src.name, err)
+		}
+		if notAfter != nil && (earliest == nil || notAfter.Before(*earliest)) {
+			earliest = notAfter
+		}
+	}
+	return earliest, nil
+}
+
+// earliestNotAfter 解析 PEM 数据中的所有证书（CA 可能是证书链），返回最早的 NotAfter
+func earliestNotAfter(data []byte) (*time.Time, error) {
+	var earliest *time.Time
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		notAfter := cert.NotAfter
+		if earliest == nil || notAfter.Before(*earliest) {
+			earliest = &notAfter
+		}
+	}
+	return earliest, nil
+}
@@ -0,0 +1,125 @@
+package services
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/client-go/rest"
+)
+
+func testCertPEM(t *testing.T, notAfter time.Time) []byte {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "kubepolaris-test"},
+		NotBefore:    notAfter.Add(-24 * time.Hour),
+		NotAfter:     notAfter,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	require.NoError(t, err)
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+}
+
+func TestParseCertExpiry(t *testing.T) {
+	// 证书时间精度为秒
+	base := time.Now().Add(30 * 24 * time.Hour).Truncate(time.Second).UTC()
+	early := base.Add(-10 * 24 * time.Hour)
+	keyBlock := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: []byte("ignored")})
+
+	cases := []struct {
+		name    string
+		config  *rest.Config
+		want    *time.Time
+		wantErr bool
+	}{
+		{name: "nil 配置", config: nil},
+		{name: "Token 认证无证书", config: &rest.Config{BearerToken: "token"}},
+		{
+			name:   "仅客户端证书",
+			config: &rest.Config{TLSClientConfig: rest.TLSClientConfig{CertData: testCertPEM(t, base)}},
+			want:   &base,
+		},
+		{
+			name: "CA 早于客户端证书",
+			config: &rest.Config{TLSClientConfig: rest.TLSClientConfig{
+				CertData: testCertPEM(t, base),
+				CAData:   testCertPEM(t, early),
+			}},
+			want: &early,
+		},
+		{
+			name: "CA 证书链取最早的证书",
+			config: &rest.Config{TLSClientConfig: rest.TLSClientConfig{
+				CAData: append(testCertPEM(t, base), testCertPEM(t, early)...),
+			}},
+			want: &early,
+		},
+		{
+			name: "跳过非证书 PEM 块",
+			config: &rest.Config{TLSClientConfig: rest.TLSClientConfig{
+				CertData: append(keyBlock, testCertPEM(t, base)...),
+			}},
+			want: &base,
+		},
+		{
+			name: "证书内容无效",
+			config: &rest.Config{TLSClientConfig: rest.TLSClientConfig{
+				CertData: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: []byte("invalid")}),
+			}},
+			wantErr: true,
+		},
+		{
+			name: "证书文件不存在",
+			config: &rest.Config{TLSClientConfig: rest.TLSClientConfig{
+				CertFile: filepath.Join(t.TempDir(), "missing.crt"),
+			}},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := ParseCertExpiry(tc.config)
+			if tc.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			if tc.want == nil {
+				assert.Nil(t, got)
+				return
+			}
+			require.NotNil(t, got)
+			assert.True(t, tc.want.Equal(*got), "want %v, got %v", tc.want, got)
+		})
+	}
+}
+
+func TestEarliestNotAfter(t *testing.T) {
+	base := time.Now().Add(24 * time.Hour).Truncate(time.Second).UTC()
+
+	got, err := earliestNotAfter(nil)
+	require.NoError(t, err)
+	assert.Nil(t, got)
+
+	got, err = earliestNotAfter([]byte("not a pem"))
+	require.NoError(t, err)
+	assert.Nil(t, got)
+
+	got, err = earliestNotAfter(append(testCertPEM(t, base.Add(time.Hour)), testCertPEM(t, base)...))
+	require.NoError(t, err)
+	require.NotNil(t, got)
+	assert.True(t, base.Equal(*got))
+}
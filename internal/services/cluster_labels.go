@@ -0,0 +1,133 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/clay-wangzhi/KubePolaris/internal/models"
+
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// ParseClusterLabels 解析集群的 Labels JSON 字段（非法或为空时返回空 map）
+func ParseClusterLabels(raw string) map[string]string {
+	result := make(map[string]string)
+	if raw == "" {
+		return result
+	}
+	_ = json.Unmarshal([]byte(raw), &result)
+	return result
+}
+
+// ParseClusterSelector 解析集群标签选择器，语法与 Kubernetes label selector 一致
+// （如 env=prod,region in (cn-north,cn-east),!deprecated）；空字符串匹配所有集群。
+func ParseClusterSelector(selector string) (labels.Selector, error) {
+	selector = strings.TrimSpace(selector)
+	if selector == "" {
+		return labels.Everything(), nil
+	}
+	sel, err := labels.Parse(selector)
+	if err != nil {
+		return nil, fmt.Errorf("集群标签选择器格式错误: %w", err)
+	}
+	return sel, nil
+}
+
+// ValidateClusterLabels 校验集群标签（键值规则与 Kubernetes 标签一致）
+func ValidateClusterLabels(lbls map[string]string) error {
+	for k, v := range lbls {
+		if errs := validation.IsQualifiedName(k); len(errs) > 0 {
+			return fmt.Errorf("标签键 %q 不合法: %s", k, strings.Join(errs, "; "))
+		}
+		if errs := validation.IsValidLabelValue(v); len(errs) > 0 {
+			return fmt.Errorf("标签 %s 的值 %q 不合法: %s", k, v, strings.Join(errs, "; "))
+		}
+	}
+	return nil
+}
+
+// MatchClusterSelector 判断集群是否匹配标签选择器（selector 为 nil 时匹配所有集群）
+func MatchClusterSelector(cluster *models.Cluster, selector labels.Selector) bool {
+	if selector == nil || selector.Empty() {
+		return true
+	}
+	return selector.Matches(labels.Set(ParseClusterLabels(cluster.Labels)))
+}
+
+// GetClustersBySelector 获取匹配标签选择器的集群（selector 为 nil 时返回所有集群）
+func (s *ClusterService) GetClustersBySelector(selector labels.Selector) ([]*models.Cluster, error) {
+	clusters, err := s.GetAllClusters()
+	if err != nil {
+		return nil, err
+	}
+	if selector == nil || selector.Empty() {
+		return clusters, nil
+	}
+
+	matched := make([]*models.Cluster, 0, len(clusters))
+	for _, cluster := range clusters {
+		if MatchClusterSelector(cluster, selector) {
+			matched = append(matched, cluster)
+		}
+	}
+	return matched, nil
+}
+
+// UpdateClusterLabels 替换集群标签
+func (s *ClusterService) UpdateClusterLabels(id uint, lbls map[string]string) error {
+	if err := ValidateClusterLabels(lbls); err != nil {
+		return err
+	}
+	if lbls == nil {
+		lbls = map[string]string{}
+	}
+	data, err := json.Marshal(lbls)
+	if err != nil {
+		return fmt.Errorf("序列化集群标签失败: %w", err)
+	}
+
+	result := s.db.Model(&models.Cluster{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"labels":     string(data),
+		"updated_at": time.Now(),
+	})
+	if result.Error != nil {
+		return fmt.Errorf("更新集群标签失败: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("集群不存在: %d", id)
+	}
+	return nil
+}
+
+// GetClusterLabelValues 汇总所有集群使用的标签键及其取值（用于按标签分组/筛选）
+func (s *ClusterService) GetClusterLabelValues() (map[string][]string, error) {
+	var clusters []models.Cluster
+	if err := s.db.Select("id", "labels").Find(&clusters).Error; err != nil {
+		return nil, fmt.Errorf("获取集群标签失败: %w", err)
+	}
+
+	sets := make(map[string]map[string]bool)
+	for _, cluster := range clusters {
+		for k, v := range ParseClusterLabels(cluster.Labels) {
+			if sets[k] == nil {
+				sets[k] = make(map[string]bool)
+			}
+			sets[k][v] = true
+		}
+	}
+
+	result := make(map[string][]string, len(sets))
+	for k, values := range sets {
+		list := make([]string, 0, len(values))
+		for v := range values {
+			list = append(list, v)
+		}
+		sort.Strings(list)
+		result[k] = list
+	}
+	return result, nil
+}
@@ -0,0 +1,44 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/clay-wangzhi/KubePolaris/internal/models"
+)
+
+func TestMatchClusterSelector(t *testing.T) {
+	prod := &models.Cluster{Name: "prod", Labels: `{"env":"prod","region":"cn-north","team":"payments"}`}
+	dev := &models.Cluster{Name: "dev", Labels: `{"env":"dev","region":"cn-east"}`}
+	legacy := &models.Cluster{Name: "legacy", Labels: ""}
+
+	tests := []struct {
+		selector string
+		want     []bool // prod, dev, legacy
+	}{
+		{"", []bool{true, true, true}},
+		{"env=prod", []bool{true, false, false}},
+		{"region in (cn-north,cn-east)", []bool{true, true, false}},
+		{"env!=prod", []bool{false, true, true}},
+		{"team", []bool{true, false, false}},
+		{"!team", []bool{false, true, true}},
+	}
+	for _, tt := range tests {
+		sel, err := ParseClusterSelector(tt.selector)
+		require.NoError(t, err, tt.selector)
+		assert.Equal(t, tt.want[0], MatchClusterSelector(prod, sel), tt.selector)
+		assert.Equal(t, tt.want[1], MatchClusterSelector(dev, sel), tt.selector)
+		assert.Equal(t, tt.want[2], MatchClusterSelector(legacy, sel), tt.selector)
+	}
+
+	_, err := ParseClusterSelector("env in prod")
+	assert.Error(t, err)
+}
+
+func TestValidateClusterLabels(t *testing.T) {
+	assert.NoError(t, ValidateClusterLabels(map[string]string{"env": "prod", "example.com/team": "payments"}))
+	assert.Error(t, ValidateClusterLabels(map[string]string{"bad key": "x"}))
+	assert.Error(t, ValidateClusterLabels(map[string]string{"env": "not valid!"}))
+}
@@ -36,6 +36,44 @@ func NewClusterService(db *gorm.DB) *ClusterService {
 
 // CreateCluster 创建集群
 func (s *ClusterService) CreateCluster(cluster *models.Cluster) error {
+	stored, err := prepareClusterForCreate(cluster)
+	if err != nil {
+		return err
+	}
+
+	// 保存到数据库
+	if err := s.db.Create(stored).Error; err != nil {
+		logger.Error("创建集群失败", "error", err)
+		return fmt.Errorf("创建集群失败: %w", err)
+	}
+	cluster.ID = stored.ID
+
+	logger.Info("集群创建成功", "id", cluster.ID, "name", cluster.Name)
+	return nil
+}
+
+// CreateClusters 在同一事务中批量创建集群，任一失败则全部回滚
+func (s *ClusterService) CreateClusters(clusters []*models.Cluster) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		for _, cluster := range clusters {
+			stored, err := prepareClusterForCreate(cluster)
+			if err != nil {
+				return err
+			}
+			if err := tx.Create(stored).Error; err != nil {
+				logger.Error("创建集群失败", "name", cluster.Name, "error", err)
+				return fmt.Errorf("创建集群 %s 失败: %w", cluster.Name, err)
+			}
+			cluster.ID = stored.ID
+		}
+		logger.Info("批量创建集群成功", "count", len(clusters))
+		return nil
+	})
+}
+
+// prepareClusterForCreate 填充创建时间、规范化 JSON 字段，并返回凭据加密后的待落库副本
+// 调用方持有的对象保持明文
+func prepareClusterForCreate(cluster *models.Cluster) (*models.Cluster, error) {
 	// 设置创建时间
 	cluster.CreatedAt = time.Now()
 	cluster.UpdatedAt = time.Now()
@@ -67,14 +105,12 @@ func (s *ClusterService) CreateCluster(cluster *models.Cluster) error {
 		}
 	}
 
-	// 保存到数据库
-	if err := s.db.Create(cluster).Error; err != nil {
-		logger.Error("创建集群失败", "error", err)
-		return fmt.Errorf("创建集群失败: %w", err)
+	// 凭据加密后落库
+	stored := *cluster
+	if err := encryptFields(clusterCredentialFields(&stored)...); err != nil {
+		return nil, err
 	}
-
-	logger.Info("集群创建成功", "id", cluster.ID, "name", cluster.Name)
-	return nil
+	return &stored, nil
 }
 
 // GetCluster 获取单个集群
@@ -86,6 +122,9 @@ func (s *ClusterService) GetCluster(id uint) (*models.Cluster, error) {
 		}
 		return nil, fmt.Errorf("获取集群失败: %w", err)
 	}
+	if err := decryptFields(clusterCredentialFields(&cluster)...); err != nil {
+		return nil, fmt.Errorf("集群 %s: %w", cluster.Name, err)
+	}
 	return &cluster, nil
 }
 
@@ -96,6 +135,11 @@ func (s *ClusterService) GetAllClusters() ([]*models.Cluster, error) {
 		logger.Error("获取集群列表失败", "error", err)
 		return nil, fmt.Errorf("获取集群列表失败: %w", err)
 	}
+	for _, cluster := range clusters {
+		if err := decryptFields(clusterCredentialFields(cluster)...); err != nil {
+			return nil, fmt.Errorf("集群 %s: %w", cluster.Name, err)
+		}
+	}
 	return clusters, nil
 }
 
@@ -120,10 +164,91 @@ func (s *ClusterService) UpdateClusterStatus(id uint, status string, version str
 	return nil
 }
 
+// SetClusterStatus 仅更新集群状态（探测失败时使用，不刷新心跳与版本）
+func (s *ClusterService) SetClusterStatus(id uint, status string) error {
+	result := s.db.Model(&models.Cluster{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"status":     status,
+		"updated_at": time.Now(),
+	})
+	if result.Error != nil {
+		return fmt.Errorf("更新集群状态失败: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("集群不存在: %d", id)
+	}
+	return nil
+}
+
+// UpdateClusterCertExpireAt 更新集群证书过期时间
+func (s *ClusterService) UpdateClusterCertExpireAt(id uint, expireAt *time.Time) error {
+	if err := s.db.Model(&models.Cluster{}).Where("id = ?", id).Update("cert_expire_at", expireAt).Error; err != nil {
+		return fmt.Errorf("更新证书过期时间失败: %w", err)
+	}
+	return nil
+}
+
+// RecordStatusTransition 记录集群状态变更
+func (s *ClusterService) RecordStatusTransition(history *models.ClusterStatusHistory) error {
+	if err := s.db.Create(history).Error; err != nil {
+		return fmt.Errorf("记录集群状态变更失败: %w", err)
+	}
+	return nil
+}
+
+// GetStatusHistory 获取集群状态变更历史（按时间倒序）
+func (s *ClusterService) GetStatusHistory(clusterID uint, limit int) ([]models.ClusterStatusHistory, error) {
+	var histories []models.ClusterStatusHistory
+	if err := s.db.Where("cluster_id = ?", clusterID).Order("created_at DESC").Limit(limit).Find(&histories).Error; err != nil {
+		return nil, fmt.Errorf("获取集群状态历史失败: %w", err)
+	}
+	return histories, nil
+}
+
+// UpdateClusterCredentials 替换集群凭据
+// 所有凭据相关字段在同一条 UPDATE 中写入，避免出现新旧凭据混用的中间状态；
+// 从 kubeconfig 切换为 Token（或反之）时，未使用的字段会被清空。
+func (s *ClusterService) UpdateClusterCredentials(id uint, cred *models.Cluster) error {
+	stored := *cred
+	if err := encryptFields(clusterCredentialFields(&stored)...); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	result := s.db.Model(&models.Cluster{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"api_server":     stored.APIServer,
+		"kubeconfig_enc": stored.KubeconfigEnc,
+		"ca_enc":         stored.CAEnc,
+		"sa_token_enc":   stored.SATokenEnc,
+		"cluster_uid":    stored.ClusterUID,
+		"version":        stored.Version,
+		"status":         stored.Status,
+		"cert_expire_at": stored.CertExpireAt,
+		"last_heartbeat": &now,
+		"updated_at":     now,
+	})
+	if result.Error != nil {
+		return fmt.Errorf("更新集群凭据失败: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("集群不存在: %d", id)
+	}
+
+	logger.Info("集群凭据已更新", "id", id)
+	return nil
+}
+
+// SetClusterUID 记录集群唯一标识（历史集群导入时未记录，由健康探测补齐）
+func (s *ClusterService) SetClusterUID(id uint, uid string) error {
+	if err := s.db.Model(&models.Cluster{}).Where("id = ?", id).Update("cluster_uid", uid).Error; err != nil {
+		return fmt.Errorf("更新集群标识失败: %w", err)
+	}
+	return nil
+}
+
 // DeleteCluster 删除集群
 func (s *ClusterService) DeleteCluster(id uint) error {
 	// 使用事务确保数据一致性
-	return s.db.Transaction(func(tx *gorm.DB) error {
+	err := s.db.Transaction(func(tx *gorm.DB) error {
 		// 1. 检查集群是否存在
 		var cluster models.Cluster
 		if err := tx.First(&cluster, id).Error; err != nil {
@@ -175,7 +300,19 @@ func (s *ClusterService) DeleteCluster(id uint) error {
 			// 监控指标删除失败不阻止删除
 		}
 
-		// 7. 硬删除集群（使用 Unscoped 绕过软删除）
+		// 7. 删除集群状态变更历史
+		if err := tx.Where("cluster_id = ?", id).Delete(&models.ClusterStatusHistory{}).Error; err != nil {
+			logger.Error("删除集群状态历史失败", "cluster_id", id, "error", err)
+			// 状态历史删除失败不阻止删除
+		}
+
+		// 8. 删除 Agent 接入令牌（令牌失效必须与集群删除一致，失败则整体回滚）
+		if err := tx.Where("cluster_id = ?", id).Delete(&models.ClusterAgentToken{}).Error; err != nil {
+			logger.Error("删除 Agent 令牌失败", "cluster_id", id, "error", err)
+			return fmt.Errorf("删除 Agent 令牌失败: %w", err)
+		}
+
+		// 9. 硬删除集群（使用 Unscoped 绕过软删除）
 		if err := tx.Unscoped().Delete(&cluster).Error; err != nil {
 			return fmt.Errorf("删除集群失败: %w", err)
 		}
@@ -183,6 +320,12 @@ func (s *ClusterService) DeleteCluster(id uint) error {
 		logger.Info("集群删除成功", "id", id, "name", cluster.Name)
 		return nil
 	})
+	if err != nil {
+		return err
+	}
+	// 令牌已随集群删除，断开仍在线的 Agent 隧道
+	GetAgentTunnelRegistry().Disconnect(id, 0)
+	return nil
 }
 
 // GetClusterStats 获取集群统计信息
@@ -209,9 +352,8 @@ func (s *ClusterService) GetClusterStats() (*models.ClusterStats, error) {
 	stats.UnhealthyClusters = int(unhealthyCount)
 
 	// 获取所有集群的实时指标统计
-	var clusters []*models.Cluster
-	if err := s.db.Find(&clusters).Error; err != nil {
-		logger.Error("获取集群列表失败", "error", err)
+	clusters, err := s.GetAllClusters()
+	if err != nil {
 		return &stats, nil // 返回基础统计，不因为指标获取失败而整体失败
 	}
 
@@ -242,7 +384,7 @@ func (s *ClusterService) GetClusterStats() (*models.ClusterStats, error) {
 // getClusterRealTimeMetrics 获取集群实时指标
 func (s *ClusterService) getClusterRealTimeMetrics(cluster *models.Cluster) *models.ClusterMetrics {
 	// 如果没有连接信息，返回空指标
-	if cluster.KubeconfigEnc == "" && cluster.SATokenEnc == "" {
+	if cluster.KubeconfigEnc == "" && cluster.SATokenEnc == "" && !cluster.IsAgentMode() {
 		return nil
 	}
 
@@ -210,6 +210,14 @@ func (s *ClusterServiceTestSuite) TestDeleteCluster_Success() {
 	s.mock.ExpectExec(`DELETE FROM.*cluster_metrics.*WHERE.*cluster_id`).
 		WithArgs(1).
 		WillReturnResult(sqlmock.NewResult(0, 0))
+	// 删除集群状态历史
+	s.mock.ExpectExec(`DELETE FROM.*cluster_status_histories.*WHERE.*cluster_id`).
+		WithArgs(1).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	// 删除 Agent 接入令牌
+	s.mock.ExpectExec(`DELETE FROM.*cluster_agent_tokens.*WHERE.*cluster_id`).
+		WithArgs(1).
+		WillReturnResult(sqlmock.NewResult(0, 0))
 	// 删除集群 - 使用 Unscoped
 	s.mock.ExpectExec(`DELETE FROM.*clusters.*WHERE.*id`).
 		WithArgs(1).
@@ -0,0 +1,225 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/clay-wangzhi/KubePolaris/internal/models"
+	"github.com/clay-wangzhi/KubePolaris/pkg/crypto"
+	"github.com/clay-wangzhi/KubePolaris/pkg/logger"
+
+	"gorm.io/gorm"
+)
+
+// encryptFields 原地加密多个字段（已加密的字段不会重复加密）
+func encryptFields(fields ...*string) error {
+	for _, f := range fields {
+		if *f == "" || crypto.IsEncrypted(*f) {
+			continue
+		}
+		enc, err := crypto.Encrypt(*f)
+		if err != nil {
+			return fmt.Errorf("加密凭据失败: %w", err)
+		}
+		*f = enc
+	}
+	return nil
+}
+
+// decryptFields 原地解密多个字段（历史明文原样保留）
+func decryptFields(fields ...*string) error {
+	for _, f := range fields {
+		plain, err := crypto.Decrypt(*f)
+		if err != nil {
+			return fmt.Errorf("解密凭据失败: %w", err)
+		}
+		*f = plain
+	}
+	return nil
+}
+
+// clusterCredentialFields 集群的敏感字段
+func clusterCredentialFields(c *models.Cluster) []*string {
+	return []*string{&c.KubeconfigEnc, &c.CAEnc, &c.SATokenEnc}
+}
+
+// argoCDCredentialFields ArgoCD 配置的敏感字段
+func argoCDCredentialFields(c *models.ArgoCDConfig) []*string {
+	return []*string{&c.Token, &c.Password, &c.GitPassword, &c.GitSSHKey}
+}
+
+// sshCredentialFields SSH 配置的敏感字段
+func sshCredentialFields(c *models.SSHConfig) []*string {
+	return []*string{&c.Password, &c.PrivateKey}
+}
+
+// KeyRotationResult 密钥轮换结果（各表重新加密的行数）
+type KeyRotationResult struct {
+	Clusters      int `json:"clusters"`
+	ArgoCDConfigs int `json:"argocd_configs"`
+	AIConfigs     int `json:"ai_configs"`
+	SSHConfigs    int `json:"ssh_configs"`
+	Revisions     int `json:"resource_revisions"`
+}
+
+// RotateCredentialEncryption 使用当前主密钥重新加密所有存储的凭据
+// 明文（历史数据）与旧密钥加密的数据都会被重新加密；需要在服务停止时离线执行。
+// dryRun 为 true 时只统计需要处理的行数，事务最终回滚。
+func RotateCredentialEncryption(db *gorm.DB, dryRun bool) (*KeyRotationResult, error) {
+	kr := crypto.Default()
+	if kr == nil {
+		return nil, crypto.ErrNotInitialized
+	}
+
+	result := &KeyRotationResult{}
+	errDryRun := errors.New("dry-run")
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		// 1. 集群凭据（包含软删除的行，避免残留明文）
+		var clusters []models.Cluster
+		if err := tx.Unscoped().Select("id", "kubeconfig_enc", "ca_enc", "sa_token_enc").Find(&clusters).Error; err != nil {
+			return fmt.Errorf("查询集群失败: %w", err)
+		}
+		for i := range clusters {
+			c := &clusters[i]
+			changed, err := reencryptFields(kr, clusterCredentialFields(c)...)
+			if err != nil {
+				return fmt.Errorf("集群 %d: %w", c.ID, err)
+			}
+			if !changed {
+				continue
+			}
+			if err := tx.Unscoped().Model(&models.Cluster{}).Where("id = ?", c.ID).UpdateColumns(map[string]interface{}{
+				"kubeconfig_enc": c.KubeconfigEnc,
+				"ca_enc":         c.CAEnc,
+				"sa_token_enc":   c.SATokenEnc,
+			}).Error; err != nil {
+				return fmt.Errorf("更新集群 %d 失败: %w", c.ID, err)
+			}
+			result.Clusters++
+		}
+
+		// 2. ArgoCD 配置
+		var argoConfigs []models.ArgoCDConfig
+		if err := tx.Unscoped().Select("id", "token", "password", "git_password", "git_ssh_key").Find(&argoConfigs).Error; err != nil {
+			return fmt.Errorf("查询 ArgoCD 配置失败: %w", err)
+		}
+		for i := range argoConfigs {
+			c := &argoConfigs[i]
+			changed, err := reencryptFields(kr, argoCDCredentialFields(c)...)
+			if err != nil {
+				return fmt.Errorf("ArgoCD 配置 %d: %w", c.ID, err)
+			}
+			if !changed {
+				continue
+			}
+			if err := tx.Unscoped().Model(&models.ArgoCDConfig{}).Where("id = ?", c.ID).UpdateColumns(map[string]interface{}{
+				"token":        c.Token,
+				"password":     c.Password,
+				"git_password": c.GitPassword,
+				"git_ssh_key":  c.GitSSHKey,
+			}).Error; err != nil {
+				return fmt.Errorf("更新 ArgoCD 配置 %d 失败: %w", c.ID, err)
+			}
+			result.ArgoCDConfigs++
+		}
+
+		// 3. AI 配置
+		var aiConfigs []models.AIConfig
+		if err := tx.Unscoped().Select("id", "api_key").Find(&aiConfigs).Error; err != nil {
+			return fmt.Errorf("查询 AI 配置失败: %w", err)
+		}
+		for i := range aiConfigs {
+			c := &aiConfigs[i]
+			changed, err := reencryptFields(kr, &c.APIKey)
+			if err != nil {
+				return fmt.Errorf("AI 配置 %d: %w", c.ID, err)
+			}
+			if !changed {
+				continue
+			}
+			if err := tx.Unscoped().Model(&models.AIConfig{}).Where("id = ?", c.ID).UpdateColumn("api_key", c.APIKey).Error; err != nil {
+				return fmt.Errorf("更新 AI 配置 %d 失败: %w", c.ID, err)
+			}
+			result.AIConfigs++
+		}
+
+		// 4. SSH 全局配置（JSON 存储在 system_settings 中）
+		var settings []models.SystemSetting
+		if err := tx.Unscoped().Where("config_key = ?", sshConfigKey).Find(&settings).Error; err != nil {
+			return fmt.Errorf("查询 SSH 配置失败: %w", err)
+		}
+		for i := range settings {
+			setting := &settings[i]
+			var sshConfig models.SSHConfig
+			if err := json.Unmarshal([]byte(setting.Value), &sshConfig); err != nil {
+				return fmt.Errorf("解析 SSH 配置失败: %w", err)
+			}
+			changed, err := reencryptFields(kr, sshCredentialFields(&sshConfig)...)
+			if err != nil {
+				return fmt.Errorf("SSH 配置: %w", err)
+			}
+			if !changed {
+				continue
+			}
+			value, err := json.Marshal(sshConfig)
+			if err != nil {
+				return fmt.Errorf("序列化 SSH 配置失败: %w", err)
+			}
+			if err := tx.Unscoped().Model(&models.SystemSetting{}).Where("id = ?", setting.ID).UpdateColumn("value", string(value)).Error; err != nil {
+				return fmt.Errorf("更新 SSH 配置失败: %w", err)
+			}
+			result.SSHConfigs++
+		}
+
+		// 5. 资源修订中加密存储的 Secret 快照（未加密的快照不含敏感数据，无需处理）
+		var revisions []models.ResourceRevision
+		if err := tx.Select("id", "content").Where("encrypted = ?", true).Find(&revisions).Error; err != nil {
+			return fmt.Errorf("查询资源修订失败: %w", err)
+		}
+		for i := range revisions {
+			rev := &revisions[i]
+			changed, err := reencryptFields(kr, &rev.Content)
+			if err != nil {
+				return fmt.Errorf("资源修订 %d: %w", rev.ID, err)
+			}
+			if !changed {
+				continue
+			}
+			if err := tx.Model(&models.ResourceRevision{}).Where("id = ?", rev.ID).UpdateColumn("content", rev.Content).Error; err != nil {
+				return fmt.Errorf("更新资源修订 %d 失败: %w", rev.ID, err)
+			}
+			result.Revisions++
+		}
+
+		if dryRun {
+			return errDryRun
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errDryRun) {
+		return nil, err
+	}
+
+	logger.Info("凭据重新加密完成", "clusters", result.Clusters, "argocd", result.ArgoCDConfigs,
+		"ai", result.AIConfigs, "ssh", result.SSHConfigs, "revisions", result.Revisions, "dryRun", dryRun)
+	return result, nil
+}
+
+// reencryptFields 使用当前主密钥重新加密字段，返回是否有字段发生变化
+func reencryptFields(kr *crypto.Keyring, fields ...*string) (bool, error) {
+	changed := false
+	for _, f := range fields {
+		if !kr.NeedsRotation(*f) {
+			continue
+		}
+		enc, err := kr.Reencrypt(*f)
+		if err != nil {
+			return false, err
+		}
+		*f = enc
+		changed = true
+	}
+	return changed, nil
+}
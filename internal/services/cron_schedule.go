@@ -0,0 +1,238 @@
+package services
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CronSchedule 解析后的标准 5 段 cron 表达式（分 时 日 月 周），语义与 Kubernetes CronJob 控制器一致
+type CronSchedule struct {
+	minute, hour, dom, month, dow uint64
+	Location                      *time.Location
+}
+
+// cronField cron 表达式中单个字段的取值范围
+type cronField struct {
+	min, max uint
+	names    map[string]uint
+}
+
+var (
+	cronMinute = cronField{min: 0, max: 59}
+	cronHour   = cronField{min: 0, max: 23}
+	cronDom    = cronField{min: 1, max: 31}
+	cronMonth  = cronField{min: 1, max: 12, names: map[string]uint{
+		"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+		"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+	}}
+	cronDow = cronField{min: 0, max: 7, names: map[string]uint{
+		"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+	}}
+)
+
+// cronStar 标记字段为 * 或 ?，用于判断日与周的组合方式
+const cronStar = uint64(1) << 63
+
+var cronMacros = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// ParseCronSchedule 解析 CronJob 的 schedule 与 timeZone
+// 支持 CRON_TZ=/TZ= 前缀、@daily 等宏、名称（JAN、MON）、列表、范围与步长。timeZone 为空时使用 UTC
+// （kube-controller-manager 默认以 UTC 运行）。
+func ParseCronSchedule(schedule, timeZone string) (*CronSchedule, error) {
+	spec := strings.TrimSpace(schedule)
+	zone := timeZone
+	if strings.HasPrefix(spec, "CRON_TZ=") || strings.HasPrefix(spec, "TZ=") {
+		i := strings.Index(spec, " ")
+		if i < 0 {
+			return nil, fmt.Errorf("无效的 cron 表达式: %s", schedule)
+		}
+		zone = spec[strings.Index(spec, "=")+1 : i]
+		spec = strings.TrimSpace(spec[i:])
+	}
+
+	loc := time.UTC
+	if zone != "" {
+		l, err := time.LoadLocation(zone)
+		if err != nil {
+			return nil, fmt.Errorf("无效的时区 %s: %w", zone, err)
+		}
+		loc = l
+	}
+
+	if macro, ok := cronMacros[strings.ToLower(spec)]; ok {
+		spec = macro
+	}
+	fields := strings.Fields(spec)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("无效的 cron 表达式 %q: 需要 5 个字段，实际 %d 个", schedule, len(fields))
+	}
+
+	s := &CronSchedule{Location: loc}
+	var err error
+	for i, target := range []struct {
+		bits  *uint64
+		field cronField
+	}{{&s.minute, cronMinute}, {&s.hour, cronHour}, {&s.dom, cronDom}, {&s.month, cronMonth}, {&s.dow, cronDow}} {
+		if *target.bits, err = parseCronField(fields[i], target.field); err != nil {
+			return nil, fmt.Errorf("无效的 cron 表达式 %q: %w", schedule, err)
+		}
+	}
+	// 周日可写作 0 或 7
+	if s.dow&(1<<7) != 0 {
+		s.dow = s.dow&^(1<<7) | 1
+	}
+	return s, nil
+}
+
+// Next 返回严格晚于 t 的下一次调度时间；5 年内无匹配时返回零值
+func (s *CronSchedule) Next(t time.Time) time.Time {
+	t = t.In(s.Location).Add(time.Minute - time.Duration(t.Second())*time.Second - time.Duration(t.Nanosecond()))
+	yearLimit := t.Year() + 5
+	added := false
+
+wrap:
+	for t.Year() <= yearLimit {
+		for 1<<uint(t.Month())&s.month == 0 {
+			if !added {
+				added = true
+				t = time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, s.Location)
+			}
+			t = t.AddDate(0, 1, 0)
+			if t.Month() == time.January {
+				continue wrap
+			}
+		}
+
+		for !s.dayMatches(t) {
+			if !added {
+				added = true
+				t = time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, s.Location)
+			}
+			t = t.AddDate(0, 0, 1)
+			// 夏令时切换可能使零点不存在，校正回当天的起始时间
+			if t.Hour() != 0 {
+				if t.Hour() > 12 {
+					t = t.Add(time.Duration(24-t.Hour()) * time.Hour)
+				} else {
+					t = t.Add(time.Duration(-t.Hour()) * time.Hour)
+				}
+			}
+			if t.Day() == 1 {
+				continue wrap
+			}
+		}
+
+		for 1<<uint(t.Hour())&s.hour == 0 {
+			if !added {
+				added = true
+				t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), 0, 0, 0, s.Location)
+			}
+			t = t.Add(time.Hour)
+			if t.Hour() == 0 {
+				continue wrap
+			}
+		}
+
+		for 1<<uint(t.Minute())&s.minute == 0 {
+			if !added {
+				added = true
+				t = t.Truncate(time.Minute)
+			}
+			t = t.Add(time.Minute)
+			if t.Minute() == 0 {
+				continue wrap
+			}
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+// dayMatches 日与周均被限定时满足其一即可，否则两者都需满足（与 cron 的传统语义一致）
+func (s *CronSchedule) dayMatches(t time.Time) bool {
+	domMatch := 1<<uint(t.Day())&s.dom > 0
+	dowMatch := 1<<uint(t.Weekday())&s.dow > 0
+	if s.dom&cronStar > 0 || s.dow&cronStar > 0 {
+		return domMatch && dowMatch
+	}
+	return domMatch || dowMatch
+}
+
+func parseCronField(expr string, field cronField) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(expr, ",") {
+		b, err := parseCronRange(part, field)
+		if err != nil {
+			return 0, err
+		}
+		bits |= b
+	}
+	return bits, nil
+}
+
+// parseCronRange 解析单个范围：*、?、N、N-M，均可带 /step
+func parseCronRange(expr string, field cronField) (uint64, error) {
+	rangeExpr, stepExpr, hasStep := strings.Cut(expr, "/")
+	var start, end uint
+	var extra uint64
+	switch rangeExpr {
+	case "*", "?":
+		start, end = field.min, field.max
+		if !hasStep {
+			extra = cronStar
+		}
+	default:
+		low, high, isRange := strings.Cut(rangeExpr, "-")
+		var err error
+		if start, err = parseCronValue(low, field); err != nil {
+			return 0, err
+		}
+		end = start
+		if isRange {
+			if end, err = parseCronValue(high, field); err != nil {
+				return 0, err
+			}
+		} else if hasStep {
+			end = field.max // N/step 表示从 N 到最大值
+		}
+	}
+
+	step := uint(1)
+	if hasStep {
+		n, err := strconv.ParseUint(stepExpr, 10, 32)
+		if err != nil || n == 0 {
+			return 0, fmt.Errorf("无效的步长 %q", expr)
+		}
+		step = uint(n)
+	}
+	if start < field.min || end > field.max || start > end {
+		return 0, fmt.Errorf("取值超出范围 %q（%d-%d）", expr, field.min, field.max)
+	}
+
+	var bits uint64
+	for v := start; v <= end; v += step {
+		bits |= 1 << v
+	}
+	return bits | extra, nil
+}
+
+func parseCronValue(expr string, field cronField) (uint, error) {
+	if v, ok := field.names[strings.ToLower(expr)]; ok {
+		return v, nil
+	}
+	n, err := strconv.ParseUint(expr, 10, 32)
+	if err != nil {
+		return 0, fmt.Errorf("无效的取值 %q", expr)
+	}
+	return uint(n), nil
+}
@@ -0,0 +1,108 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	batchv1 "k8s.io/api/batch/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestCronScheduleNext(t *testing.T) {
+	from := time.Date(2024, 3, 15, 10, 30, 20, 0, time.UTC) // 周五
+	tests := []struct {
+		schedule string
+		timeZone string
+		want     time.Time
+	}{
+		{"*/15 * * * *", "", time.Date(2024, 3, 15, 10, 45, 0, 0, time.UTC)},
+		{"0 2 * * *", "", time.Date(2024, 3, 16, 2, 0, 0, 0, time.UTC)},
+		{"@hourly", "", time.Date(2024, 3, 15, 11, 0, 0, 0, time.UTC)},
+		{"0 9 * * MON-FRI", "", time.Date(2024, 3, 18, 9, 0, 0, 0, time.UTC)},
+		{"0 0 1 JAN *", "", time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 0 13 * 5", "", time.Date(2024, 3, 22, 0, 0, 0, 0, time.UTC)}, // 日与周同时限定时满足其一即可
+		{"0 0 29 2 *", "", time.Date(2028, 2, 29, 0, 0, 0, 0, time.UTC)},
+		{"0 0 * * 7", "", time.Date(2024, 3, 17, 0, 0, 0, 0, time.UTC)},
+		{"0 20 * * *", "Asia/Shanghai", time.Date(2024, 3, 15, 12, 0, 0, 0, time.UTC)},
+		{"CRON_TZ=Asia/Shanghai 0 8 * * *", "", time.Date(2024, 3, 16, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		s, err := ParseCronSchedule(tt.schedule, tt.timeZone)
+		require.NoError(t, err, tt.schedule)
+		assert.True(t, tt.want.Equal(s.Next(from)), "%s: got %s", tt.schedule, s.Next(from))
+	}
+
+	for _, invalid := range []string{"* * * *", "61 * * * *", "*/0 * * * *", "0 0 * * FOO"} {
+		_, err := ParseCronSchedule(invalid, "")
+		assert.Error(t, err, invalid)
+	}
+	_, err := ParseCronSchedule("0 0 * * *", "Mars/Olympus")
+	assert.Error(t, err)
+}
+
+func TestTriggerCronJobAndHistory(t *testing.T) {
+	cronJob := &batchv1.CronJob{
+		ObjectMeta: metav1.ObjectMeta{Name: "backup", Namespace: "demo", UID: types.UID("cj-1")},
+		Spec: batchv1.CronJobSpec{
+			Schedule: "0 3 * * *",
+			JobTemplate: batchv1.JobTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"app": "backup"}},
+			},
+		},
+	}
+	clientset := fake.NewSimpleClientset(cronJob)
+	ctx := context.Background()
+
+	job, err := TriggerCronJob(ctx, clientset, false, "demo", "backup", "")
+	require.NoError(t, err)
+	assert.Equal(t, "manual", job.Annotations[CronJobInstantiateAnnotation])
+	assert.Equal(t, "backup", job.Labels["app"])
+	require.NotNil(t, metav1.GetControllerOf(job))
+
+	now := time.Date(2024, 3, 15, 10, 0, 0, 0, time.UTC)
+	history, err := GetCronJobHistory(ctx, clientset, false, "demo", "backup", now)
+	require.NoError(t, err)
+	require.Len(t, history.Runs, 1)
+	assert.True(t, history.Runs[0].Manual)
+	require.Len(t, history.NextScheduleTimes, cronJobNextRuns)
+	assert.Equal(t, time.Date(2024, 3, 16, 3, 0, 0, 0, time.UTC), history.NextScheduleTimes[0])
+
+	require.NoError(t, SetCronJobSuspend(ctx, clientset, false, "demo", "backup", true))
+	history, err = GetCronJobHistory(ctx, clientset, false, "demo", "backup", now)
+	require.NoError(t, err)
+	assert.True(t, history.Suspend)
+	assert.Empty(t, history.NextScheduleTimes)
+}
+
+func TestManualJobName(t *testing.T) {
+	now := time.Unix(1710496800, 0)
+	suffix := "-manual-1710496800"
+	maxPrefix := validation.DNS1123LabelMaxLength - len(suffix)
+
+	cases := []struct {
+		name    string
+		cronJob string
+		want    string
+	}{
+		{name: "短名称", cronJob: "backup", want: "backup" + suffix},
+		{name: "超长名称截断", cronJob: strings.Repeat("a", 60), want: strings.Repeat("a", maxPrefix) + suffix},
+		{name: "截断后末尾为连字符", cronJob: strings.Repeat("a", maxPrefix-1) + "-" + strings.Repeat("b", 10), want: strings.Repeat("a", maxPrefix-1) + suffix},
+		{name: "截断后末尾为多个连字符", cronJob: strings.Repeat("a", maxPrefix-3) + "----bbbb", want: strings.Repeat("a", maxPrefix-3) + suffix},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := manualJobName(tc.cronJob, now)
+			assert.Equal(t, tc.want, got)
+			assert.LessOrEqual(t, len(got), validation.DNS1123LabelMaxLength)
+			assert.Empty(t, validation.IsDNS1123Label(got))
+			assert.NotContains(t, got, "--")
+		})
+	}
+}
@@ -0,0 +1,243 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	batchv1 "k8s.io/api/batch/v1"
+	batchv1beta1 "k8s.io/api/batch/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/client-go/kubernetes"
+)
+
+// CronJobInstantiateAnnotation 手动触发的 Job 注解（与 kubectl create job --from=cronjob/ 一致）
+const CronJobInstantiateAnnotation = "cronjob.kubernetes.io/instantiate"
+
+// cronJobNextRuns 运行历史中展示的后续调度次数
+const cronJobNextRuns = 5
+
+// CronJobRun CronJob 的一次执行（对应一个 Job）
+type CronJobRun struct {
+	JobName         string     `json:"jobName"`
+	Status          string     `json:"status"` // Running / Succeeded / Failed
+	Manual          bool       `json:"manual"` // 是否为手动触发
+	Active          int32      `json:"active"`
+	Succeeded       int32      `json:"succeeded"`
+	Failed          int32      `json:"failed"`
+	StartTime       *time.Time `json:"startTime,omitempty"`
+	CompletionTime  *time.Time `json:"completionTime,omitempty"`
+	DurationSeconds *int64     `json:"durationSeconds,omitempty"` // 运行中的 Job 为已运行时长
+	Message         string     `json:"message,omitempty"`
+}
+
+// CronJobHistory CronJob 运行历史与后续调度时间
+type CronJobHistory struct {
+	Schedule           string       `json:"schedule"`
+	TimeZone           string       `json:"timeZone"`
+	Suspend            bool         `json:"suspend"`
+	LastScheduleTime   *time.Time   `json:"lastScheduleTime,omitempty"`
+	LastSuccessfulTime *time.Time   `json:"lastSuccessfulTime,omitempty"`
+	NextScheduleTimes  []time.Time  `json:"nextScheduleTimes"` // 挂起时为空
+	ScheduleError      string       `json:"scheduleError,omitempty"`
+	Runs               []CronJobRun `json:"runs"`
+}
+
+// GetCronJob 按集群支持的 API 版本获取 CronJob，batch/v1beta1 对象转换为 batch/v1 类型
+// v1beta1 表示集群仅提供 batch/v1beta1 CronJob，由 k8s 包探测（ClusterInformerManager.IsCronJobV1beta1）
+func GetCronJob(ctx context.Context, clientset kubernetes.Interface, v1beta1 bool, namespace, name string) (*batchv1.CronJob, error) {
+	if !v1beta1 {
+		return clientset.BatchV1().CronJobs(namespace).Get(ctx, name, metav1.GetOptions{})
+	}
+	legacy, err := clientset.BatchV1beta1().CronJobs(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	// 两个版本字段一致，通过 JSON 转换
+	data, err := json.Marshal(legacy)
+	if err != nil {
+		return nil, err
+	}
+	cronJob := &batchv1.CronJob{}
+	if err := json.Unmarshal(data, cronJob); err != nil {
+		return nil, err
+	}
+	return cronJob, nil
+}
+
+// TriggerCronJob 根据 CronJob 的 jobTemplate 立即创建一个 Job，jobName 为空时自动生成
+func TriggerCronJob(ctx context.Context, clientset kubernetes.Interface, v1beta1 bool, namespace, name, jobName string) (*batchv1.Job, error) {
+	cronJob, err := GetCronJob(ctx, clientset, v1beta1, namespace, name)
+	if err != nil {
+		return nil, err
+	}
+
+	if jobName == "" {
+		jobName = manualJobName(name, time.Now())
+	}
+	if errs := validation.IsDNS1123Subdomain(jobName); len(errs) > 0 || len(jobName) > validation.DNS1123LabelMaxLength {
+		return nil, fmt.Errorf("%w: 无效的 Job 名称 %s", ErrInvalidWorkloadOperation, jobName)
+	}
+
+	ownerAPIVersion := batchv1.SchemeGroupVersion.String()
+	if v1beta1 {
+		ownerAPIVersion = batchv1beta1.SchemeGroupVersion.String()
+	}
+	annotations := map[string]string{CronJobInstantiateAnnotation: "manual"}
+	for k, v := range cronJob.Spec.JobTemplate.Annotations {
+		annotations[k] = v
+	}
+	job := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        jobName,
+			Namespace:   namespace,
+			Labels:      cronJob.Spec.JobTemplate.Labels,
+			Annotations: annotations,
+			OwnerReferences: []metav1.OwnerReference{{
+				APIVersion: ownerAPIVersion,
+				Kind:       "CronJob",
+				Name:       cronJob.Name,
+				UID:        cronJob.UID,
+				Controller: boolPtr(true),
+			}},
+		},
+		Spec: cronJob.Spec.JobTemplate.Spec,
+	}
+	return clientset.BatchV1().Jobs(namespace).Create(ctx, job, metav1.CreateOptions{})
+}
+
+// SetCronJobSuspend 挂起或恢复 CronJob 的调度
+func SetCronJobSuspend(ctx context.Context, clientset kubernetes.Interface, v1beta1 bool, namespace, name string, suspend bool) error {
+	patch, err := json.Marshal(map[string]interface{}{
+		"spec": map[string]interface{}{"suspend": suspend},
+	})
+	if err != nil {
+		return err
+	}
+	if v1beta1 {
+		_, err = clientset.BatchV1beta1().CronJobs(namespace).Patch(ctx, name, types.MergePatchType, patch, metav1.PatchOptions{})
+	} else {
+		_, err = clientset.BatchV1().CronJobs(namespace).Patch(ctx, name, types.MergePatchType, patch, metav1.PatchOptions{})
+	}
+	return err
+}
+
+// GetCronJobHistory 获取 CronJob 的运行历史（按开始时间倒序）及后续调度时间
+func GetCronJobHistory(ctx context.Context, clientset kubernetes.Interface, v1beta1 bool, namespace, name string, now time.Time) (*CronJobHistory, error) {
+	cronJob, err := GetCronJob(ctx, clientset, v1beta1, namespace, name)
+	if err != nil {
+		return nil, err
+	}
+	jobs, err := clientset.BatchV1().Jobs(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("获取Job列表失败: %w", err)
+	}
+
+	history := &CronJobHistory{
+		Schedule:          cronJob.Spec.Schedule,
+		Suspend:           cronJob.Spec.Suspend != nil && *cronJob.Spec.Suspend,
+		NextScheduleTimes: []time.Time{},
+		Runs:              []CronJobRun{},
+	}
+	if cronJob.Spec.TimeZone != nil {
+		history.TimeZone = *cronJob.Spec.TimeZone
+	}
+	if t := cronJob.Status.LastScheduleTime; t != nil {
+		history.LastScheduleTime = &t.Time
+	}
+	if t := cronJob.Status.LastSuccessfulTime; t != nil {
+		history.LastSuccessfulTime = &t.Time
+	}
+
+	schedule, err := ParseCronSchedule(history.Schedule, history.TimeZone)
+	if err != nil {
+		history.ScheduleError = err.Error()
+	} else if !history.Suspend {
+		next := now
+		for i := 0; i < cronJobNextRuns; i++ {
+			if next = schedule.Next(next); next.IsZero() {
+				break
+			}
+			history.NextScheduleTimes = append(history.NextScheduleTimes, next)
+		}
+	}
+
+	for i := range jobs.Items {
+		job := &jobs.Items[i]
+		if ref := metav1.GetControllerOf(job); ref == nil || ref.UID != cronJob.UID {
+			continue
+		}
+		history.Runs = append(history.Runs, cronJobRun(job, now))
+	}
+	sort.Slice(history.Runs, func(i, j int) bool {
+		a, b := history.Runs[i].StartTime, history.Runs[j].StartTime
+		if a == nil || b == nil {
+			return a == nil && b != nil // 尚未开始的 Job 排在最前
+		}
+		return a.After(*b)
+	})
+	return history, nil
+}
+
+func cronJobRun(job *batchv1.Job, now time.Time) CronJobRun {
+	run := CronJobRun{
+		JobName:   job.Name,
+		Status:    "Running",
+		Manual:    job.Annotations[CronJobInstantiateAnnotation] == "manual",
+		Active:    job.Status.Active,
+		Succeeded: job.Status.Succeeded,
+		Failed:    job.Status.Failed,
+	}
+	for _, cond := range job.Status.Conditions {
+		if cond.Status != corev1.ConditionTrue {
+			continue
+		}
+		switch cond.Type {
+		case batchv1.JobComplete:
+			run.Status = "Succeeded"
+		case batchv1.JobFailed:
+			run.Status = "Failed"
+			run.Message = cond.Message
+		}
+	}
+
+	if job.Status.StartTime != nil {
+		start := job.Status.StartTime.Time
+		run.StartTime = &start
+		end := now
+		if job.Status.CompletionTime != nil {
+			end = job.Status.CompletionTime.Time
+			run.CompletionTime = &end
+		} else if run.Status == "Failed" {
+			// 失败的 Job 没有 completionTime，以失败条件的时间作为结束时间
+			for _, cond := range job.Status.Conditions {
+				if cond.Type == batchv1.JobFailed {
+					end = cond.LastTransitionTime.Time
+				}
+			}
+		}
+		duration := int64(end.Sub(start).Seconds())
+		run.DurationSeconds = &duration
+	}
+	return run
+}
+
+// manualJobName 生成手动触发的 Job 名称，超长时截断 CronJob 名称以满足 63 字符限制
+// 截断后去掉末尾的 '-'，保证名称符合 DNS-1123 规范
+func manualJobName(cronJobName string, now time.Time) string {
+	suffix := fmt.Sprintf("-manual-%d", now.Unix())
+	if max := validation.DNS1123LabelMaxLength - len(suffix); len(cronJobName) > max {
+		cronJobName = strings.TrimRight(cronJobName[:max], "-")
+	}
+	return cronJobName + suffix
+}
+
+func boolPtr(v bool) *bool {
+	return &v
+}
@@ -0,0 +1,224 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+)
+
+// Deployment 修订相关注解
+const (
+	DeploymentRevisionAnnotation = "deployment.kubernetes.io/revision"
+	ChangeCauseAnnotation        = "kubernetes.io/change-cause"
+)
+
+// rollbackSkippedAnnotations 回滚时不从 ReplicaSet 复制到 Deployment 的注解（与 kubectl rollout undo 一致）
+var rollbackSkippedAnnotations = map[string]bool{
+	corev1.LastAppliedConfigAnnotation:          true,
+	DeploymentRevisionAnnotation:                true,
+	"deployment.kubernetes.io/revision-history": true,
+	"deployment.kubernetes.io/desired-replicas": true,
+	"deployment.kubernetes.io/max-replicas":     true,
+	appsv1.DeprecatedRollbackTo:                 true,
+}
+
+// ErrRevisionNotFound Deployment 不存在指定的修订
+var ErrRevisionNotFound = errors.New("未找到指定的修订")
+
+// DeploymentRevision Deployment 的一个发布修订（对应一个 ReplicaSet）
+type DeploymentRevision struct {
+	Revision          int64         `json:"revision"`
+	ReplicaSet        string        `json:"replicaSet"`
+	ChangeCause       string        `json:"changeCause,omitempty"`
+	Images            []string      `json:"images"`
+	Replicas          int32         `json:"replicas"`
+	AvailableReplicas int32         `json:"availableReplicas"`
+	Current           bool          `json:"current"` // 当前 Deployment 的 Pod 模板与该修订一致
+	CreatedAt         time.Time     `json:"createdAt"`
+	TemplateDiff      []FieldChange `json:"templateDiff"` // 相对上一个修订的 Pod 模板变更，最早的修订为空
+}
+
+// ListDeploymentRevisions 列出 Deployment 的发布历史，按修订号倒序
+func ListDeploymentRevisions(ctx context.Context, clientset kubernetes.Interface, deployment *appsv1.Deployment) ([]DeploymentRevision, error) {
+	replicaSets, err := ownedReplicaSets(ctx, clientset, deployment)
+	if err != nil {
+		return nil, err
+	}
+
+	revisions := make([]DeploymentRevision, 0, len(replicaSets))
+	var previous map[string]interface{}
+	for i := len(replicaSets) - 1; i >= 0; i-- { // 从最早的修订开始，便于计算相邻修订的差异
+		rs := replicaSets[i]
+		template, err := comparableTemplate(&rs.Spec.Template)
+		if err != nil {
+			return nil, err
+		}
+		rev := DeploymentRevision{
+			Revision:          replicaSetRevision(rs),
+			ReplicaSet:        rs.Name,
+			ChangeCause:       rs.Annotations[ChangeCauseAnnotation],
+			Images:            containerImages(&rs.Spec.Template),
+			AvailableReplicas: rs.Status.AvailableReplicas,
+			Current:           equalIgnoreHash(&rs.Spec.Template, &deployment.Spec.Template),
+			CreatedAt:         rs.CreationTimestamp.Time,
+			TemplateDiff:      []FieldChange{},
+		}
+		if rs.Spec.Replicas != nil {
+			rev.Replicas = *rs.Spec.Replicas
+		}
+		if previous != nil {
+			diffValues("", previous, template, &rev.TemplateDiff)
+		}
+		previous = template
+		revisions = append(revisions, rev)
+	}
+
+	// 反转为倒序
+	for i, j := 0, len(revisions)-1; i < j; i, j = i+1, j-1 {
+		revisions[i], revisions[j] = revisions[j], revisions[i]
+	}
+	return revisions, nil
+}
+
+// RollbackDeployment 将 Deployment 的 Pod 模板恢复为指定修订，toRevision 为 0 时回滚到上一个修订
+// 行为与 kubectl rollout undo 一致：复制 ReplicaSet 的模板与注解，由 Deployment 控制器生成新的修订。
+// 返回目标修订号；模板已与目标修订一致时不做修改，skipped 为 true。
+func RollbackDeployment(ctx context.Context, clientset kubernetes.Interface, deployment *appsv1.Deployment, toRevision int64) (revision int64, skipped bool, err error) {
+	if deployment.Spec.Paused {
+		return 0, false, fmt.Errorf("Deployment 处于暂停状态，请先恢复后再回滚")
+	}
+
+	replicaSets, err := ownedReplicaSets(ctx, clientset, deployment)
+	if err != nil {
+		return 0, false, err
+	}
+	target, err := findRollbackTarget(replicaSets, toRevision)
+	if err != nil {
+		return 0, false, err
+	}
+	revision = replicaSetRevision(target)
+
+	if equalIgnoreHash(&target.Spec.Template, &deployment.Spec.Template) {
+		return revision, true, nil
+	}
+
+	template := target.Spec.Template.DeepCopy()
+	delete(template.Labels, appsv1.DefaultDeploymentUniqueLabelKey)
+
+	annotations := make(map[string]string)
+	for k, v := range deployment.Annotations {
+		if rollbackSkippedAnnotations[k] {
+			annotations[k] = v
+		}
+	}
+	for k, v := range target.Annotations {
+		if !rollbackSkippedAnnotations[k] {
+			annotations[k] = v
+		}
+	}
+
+	// 以 resourceVersion 作为前置条件，避免覆盖并发修改
+	patch, err := json.Marshal([]map[string]interface{}{
+		{"op": "test", "path": "/metadata/resourceVersion", "value": deployment.ResourceVersion},
+		{"op": "replace", "path": "/spec/template", "value": template},
+		{"op": "replace", "path": "/metadata/annotations", "value": annotations},
+	})
+	if err != nil {
+		return 0, false, fmt.Errorf("构造回滚补丁失败: %w", err)
+	}
+	if _, err := clientset.AppsV1().Deployments(deployment.Namespace).Patch(ctx, deployment.Name, types.JSONPatchType, patch, metav1.PatchOptions{}); err != nil {
+		return 0, false, err
+	}
+	return revision, false, nil
+}
+
+// findRollbackTarget 查找目标修订；toRevision 为 0 时返回当前修订之前的最新修订
+func findRollbackTarget(replicaSets []*appsv1.ReplicaSet, toRevision int64) (*appsv1.ReplicaSet, error) {
+	if toRevision > 0 {
+		for _, rs := range replicaSets {
+			if replicaSetRevision(rs) == toRevision {
+				return rs, nil
+			}
+		}
+		return nil, fmt.Errorf("%w: %d", ErrRevisionNotFound, toRevision)
+	}
+	// replicaSets 已按修订号倒序，第一个为当前修订
+	if len(replicaSets) < 2 {
+		return nil, fmt.Errorf("%w: 没有可回滚的历史修订", ErrRevisionNotFound)
+	}
+	return replicaSets[1], nil
+}
+
+// ownedReplicaSets 返回由 Deployment 控制的 ReplicaSet，按修订号倒序
+func ownedReplicaSets(ctx context.Context, clientset kubernetes.Interface, deployment *appsv1.Deployment) ([]*appsv1.ReplicaSet, error) {
+	selector, err := metav1.LabelSelectorAsSelector(deployment.Spec.Selector)
+	if err != nil {
+		return nil, fmt.Errorf("解析 Deployment 选择器失败: %w", err)
+	}
+	list, err := clientset.AppsV1().ReplicaSets(deployment.Namespace).List(ctx, metav1.ListOptions{LabelSelector: selector.String()})
+	if err != nil {
+		return nil, fmt.Errorf("获取ReplicaSet列表失败: %w", err)
+	}
+
+	var owned []*appsv1.ReplicaSet
+	for i := range list.Items {
+		rs := &list.Items[i]
+		if ref := metav1.GetControllerOf(rs); ref != nil && ref.UID == deployment.UID {
+			owned = append(owned, rs)
+		}
+	}
+	sort.Slice(owned, func(i, j int) bool {
+		return replicaSetRevision(owned[i]) > replicaSetRevision(owned[j])
+	})
+	return owned, nil
+}
+
+func replicaSetRevision(rs *appsv1.ReplicaSet) int64 {
+	revision, _ := strconv.ParseInt(rs.Annotations[DeploymentRevisionAnnotation], 10, 64)
+	return revision
+}
+
+func containerImages(template *corev1.PodTemplateSpec) []string {
+	images := make([]string, 0, len(template.Spec.Containers))
+	for _, c := range template.Spec.Containers {
+		images = append(images, c.Image)
+	}
+	return images
+}
+
+// equalIgnoreHash 比较两个 Pod 模板，忽略 ReplicaSet 的 pod-template-hash 标签
+func equalIgnoreHash(a, b *corev1.PodTemplateSpec) bool {
+	a1, b1 := a.DeepCopy(), b.DeepCopy()
+	delete(a1.Labels, appsv1.DefaultDeploymentUniqueLabelKey)
+	delete(b1.Labels, appsv1.DefaultDeploymentUniqueLabelKey)
+	if len(a1.Labels) == 0 {
+		a1.Labels = nil
+	}
+	if len(b1.Labels) == 0 {
+		b1.Labels = nil
+	}
+	return apiequality.Semantic.DeepEqual(a1, b1)
+}
+
+// comparableTemplate 将 Pod 模板转换为用于差异比较的 map（去除 pod-template-hash 标签）
+func comparableTemplate(template *corev1.PodTemplateSpec) (map[string]interface{}, error) {
+	t := template.DeepCopy()
+	delete(t.Labels, appsv1.DefaultDeploymentUniqueLabelKey)
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(t)
+	if err != nil {
+		return nil, fmt.Errorf("转换 Pod 模板失败: %w", err)
+	}
+	return content, nil
+}
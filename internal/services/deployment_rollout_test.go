@@ -0,0 +1,85 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func rolloutTestTemplate(image string) corev1.PodTemplateSpec {
+	return corev1.PodTemplateSpec{
+		ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"app": "web"}},
+		Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "web", Image: image}}},
+	}
+}
+
+func rolloutTestReplicaSet(deployment *appsv1.Deployment, name, revision, image, cause string) *appsv1.ReplicaSet {
+	template := rolloutTestTemplate(image)
+	template.Labels[appsv1.DefaultDeploymentUniqueLabelKey] = name
+	return &appsv1.ReplicaSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: deployment.Namespace,
+			Labels:    map[string]string{"app": "web"},
+			Annotations: map[string]string{
+				DeploymentRevisionAnnotation: revision,
+				ChangeCauseAnnotation:        cause,
+			},
+			OwnerReferences: []metav1.OwnerReference{*metav1.NewControllerRef(deployment, appsv1.SchemeGroupVersion.WithKind("Deployment"))},
+		},
+		Spec: appsv1.ReplicaSetSpec{Template: template},
+	}
+}
+
+func TestDeploymentHistoryAndRollback(t *testing.T) {
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "demo", UID: types.UID("uid-1"), ResourceVersion: "5"},
+		Spec: appsv1.DeploymentSpec{
+			Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}},
+			Template: rolloutTestTemplate("nginx:1.25"),
+		},
+	}
+	clientset := fake.NewSimpleClientset(
+		deployment,
+		rolloutTestReplicaSet(deployment, "web-a", "1", "nginx:1.24", "initial"),
+		rolloutTestReplicaSet(deployment, "web-b", "2", "nginx:1.25", "upgrade"),
+	)
+	ctx := context.Background()
+
+	revisions, err := ListDeploymentRevisions(ctx, clientset, deployment)
+	require.NoError(t, err)
+	require.Len(t, revisions, 2)
+	assert.EqualValues(t, 2, revisions[0].Revision)
+	assert.True(t, revisions[0].Current)
+	assert.Equal(t, "upgrade", revisions[0].ChangeCause)
+	assert.Equal(t, []string{"nginx:1.25"}, revisions[0].Images)
+	require.Len(t, revisions[0].TemplateDiff, 1)
+	assert.Equal(t, ".spec.containers[0].image", revisions[0].TemplateDiff[0].Path)
+	assert.Empty(t, revisions[1].TemplateDiff)
+
+	_, _, err = RollbackDeployment(ctx, clientset, deployment, 7)
+	assert.ErrorIs(t, err, ErrRevisionNotFound)
+
+	revision, skipped, err := RollbackDeployment(ctx, clientset, deployment, 2)
+	require.NoError(t, err)
+	assert.True(t, skipped)
+	assert.EqualValues(t, 2, revision)
+
+	revision, skipped, err = RollbackDeployment(ctx, clientset, deployment, 0)
+	require.NoError(t, err)
+	assert.False(t, skipped)
+	assert.EqualValues(t, 1, revision)
+
+	updated, err := clientset.AppsV1().Deployments("demo").Get(ctx, "web", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "nginx:1.24", updated.Spec.Template.Spec.Containers[0].Image)
+	assert.NotContains(t, updated.Spec.Template.Labels, appsv1.DefaultDeploymentUniqueLabelKey)
+	assert.Equal(t, "initial", updated.Annotations[ChangeCauseAnnotation])
+}
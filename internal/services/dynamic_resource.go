@@ -0,0 +1,216 @@
+package services
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/discovery/cached/memory"
+	"k8s.io/client-go/dynamic"
+)
+
+// CoreGroupPath URL 中表示核心 API 组（group 为空）的占位符
+const CoreGroupPath = "core"
+
+// APIResourceInfo 集群中可用的 API 资源
+type APIResourceInfo struct {
+	Group        string   `json:"group"`
+	Version      string   `json:"version"`
+	Resource     string   `json:"resource"` // 复数资源名，如 deployments
+	Kind         string   `json:"kind"`
+	SingularName string   `json:"singularName"`
+	Namespaced   bool     `json:"namespaced"`
+	Verbs        []string `json:"verbs"`
+	ShortNames   []string `json:"shortNames,omitempty"`
+	Categories   []string `json:"categories,omitempty"`
+	Preferred    bool     `json:"preferred"` // 是否为该组的首选版本
+}
+
+// GroupVersionResource 转换为 schema.GroupVersionResource
+func (r *APIResourceInfo) GroupVersionResource() schema.GroupVersionResource {
+	return schema.GroupVersionResource{Group: r.Group, Version: r.Version, Resource: r.Resource}
+}
+
+// APIVersion 资源的 apiVersion 字符串（核心组为 v1）
+func (r *APIResourceInfo) APIVersion() string {
+	return schema.GroupVersion{Group: r.Group, Version: r.Version}.String()
+}
+
+// SupportsVerb 资源是否支持指定动词
+func (r *APIResourceInfo) SupportsVerb(verb string) bool {
+	for _, v := range r.Verbs {
+		if v == verb {
+			return true
+		}
+	}
+	return false
+}
+
+// permissionActionAliases 内置资源单数名与类型化 API 权限动作名不一致时的映射（key 为 group/singular）
+// 保证动态 API、YAML 应用与类型化 API 受同一组权限约束（如运维权限禁止 pv:create）
+var permissionActionAliases = map[string]string{
+	"/persistentvolume":                   "pv",
+	"/resourcequota":                      "quota",
+	"autoscaling/horizontalpodautoscaler": "hpa",
+}
+
+// PermissionAction 资源操作对应的权限动作，格式与 ClusterPermission.CanPerformAction 一致（如 deployment:delete）
+func (r *APIResourceInfo) PermissionAction(verb string) string {
+	name := r.SingularName
+	if name == "" {
+		name = strings.ToLower(r.Kind)
+	}
+	if alias, ok := permissionActionAliases[r.Group+"/"+name]; ok {
+		name = alias
+	}
+	return name + ":" + verb
+}
+
+// DynamicClient 获取动态客户端（首次调用时创建并缓存）
+func (c *K8sClient) DynamicClient() (dynamic.Interface, error) {
+	c.dynamicOnce.Do(func() {
+		c.dynamicClient, c.dynamicErr = dynamic.NewForConfig(c.config)
+	})
+	return c.dynamicClient, c.dynamicErr
+}
+
+// CachedDiscovery 获取带内存缓存的发现客户端（避免每次请求都拉取全部 API 资源）
+func (c *K8sClient) CachedDiscovery() discovery.CachedDiscoveryInterface {
+	c.discoveryOnce.Do(func() {
+		c.cachedDiscovery = memory.NewMemCacheClient(c.clientset.Discovery())
+	})
+	return c.cachedDiscovery
+}
+
+// ParseGroupPath 将 URL 中的 group 转换为 API 组名（core 表示核心组）
+func ParseGroupPath(group string) string {
+	if group == CoreGroupPath {
+		return ""
+	}
+	return group
+}
+
+// ListAPIResources 列出集群中所有可用的 API 资源（不含子资源）
+// 部分聚合 API 不可用时仍返回其余资源。
+func ListAPIResources(disc discovery.DiscoveryInterface) ([]*APIResourceInfo, error) {
+	groups, resourceLists, err := disc.ServerGroupsAndResources()
+	if err != nil && !discovery.IsGroupDiscoveryFailedError(err) {
+		return nil, fmt.Errorf("获取 API 资源失败: %w", err)
+	}
+
+	preferred := make(map[string]string, len(groups))
+	for _, g := range groups {
+		preferred[g.Name] = g.PreferredVersion.Version
+	}
+
+	var result []*APIResourceInfo
+	for _, list := range resourceLists {
+		gv, parseErr := schema.ParseGroupVersion(list.GroupVersion)
+		if parseErr != nil {
+			continue
+		}
+		for i := range list.APIResources {
+			if info := newAPIResourceInfo(gv, &list.APIResources[i]); info != nil {
+				info.Preferred = preferred[gv.Group] == gv.Version
+				result = append(result, info)
+			}
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Group != result[j].Group {
+			return result[i].Group < result[j].Group
+		}
+		if result[i].Resource != result[j].Resource {
+			return result[i].Resource < result[j].Resource
+		}
+		return result[i].Version < result[j].Version
+	})
+	return result, nil
+}
+
+// ResolveAPIResource 通过发现接口解析 GVR，未命中时刷新缓存重试一次（新安装的 CRD）
+func ResolveAPIResource(disc discovery.CachedDiscoveryInterface, gvr schema.GroupVersionResource) (*APIResourceInfo, error) {
+	if strings.Contains(gvr.Resource, "/") {
+		return nil, fmt.Errorf("不支持直接操作子资源: %s", gvr.Resource)
+	}
+
+	gv := gvr.GroupVersion()
+	for attempt := 0; attempt < 2; attempt++ {
+		list, err := disc.ServerResourcesForGroupVersion(gv.String())
+		if err == nil {
+			for i := range list.APIResources {
+				if list.APIResources[i].Name == gvr.Resource {
+					return newAPIResourceInfo(gv, &list.APIResources[i]), nil
+				}
+			}
+		} else if !apierrors.IsNotFound(err) {
+			return nil, fmt.Errorf("获取 %s 的 API 资源失败: %w", gv, err)
+		}
+		disc.Invalidate()
+	}
+	return nil, fmt.Errorf("集群中不存在资源类型 %s", gvr.String())
+}
+
+func newAPIResourceInfo(gv schema.GroupVersion, r *metav1.APIResource) *APIResourceInfo {
+	if strings.Contains(r.Name, "/") {
+		return nil
+	}
+	return &APIResourceInfo{
+		Group:        gv.Group,
+		Version:      gv.Version,
+		Resource:     r.Name,
+		Kind:         r.Kind,
+		SingularName: r.SingularName,
+		Namespaced:   r.Namespaced,
+		Verbs:        r.Verbs,
+		ShortNames:   r.ShortNames,
+		Categories:   r.Categories,
+	}
+}
+
+// ResourceInterface 返回指定作用域下的动态资源接口
+func ResourceInterface(dyn dynamic.Interface, info *APIResourceInfo, namespace string) dynamic.ResourceInterface {
+	nri := dyn.Resource(info.GroupVersionResource())
+	if info.Namespaced && namespace != "" {
+		return nri.Namespace(namespace)
+	}
+	return nri
+}
+
+// DecodeResourceYAML 解析单个资源的 YAML/JSON，并校验其类型与目标资源一致
+func DecodeResourceYAML(data string, info *APIResourceInfo) (*unstructured.Unstructured, error) {
+	obj := &unstructured.Unstructured{}
+	if err := yaml.NewYAMLOrJSONDecoder(strings.NewReader(data), 4096).Decode(&obj.Object); err != nil {
+		return nil, fmt.Errorf("YAML格式错误: %w", err)
+	}
+	if len(obj.Object) == 0 {
+		return nil, fmt.Errorf("YAML内容为空")
+	}
+	if obj.GetAPIVersion() != info.APIVersion() || obj.GetKind() != info.Kind {
+		return nil, fmt.Errorf("YAML类型错误，期望 %s/%s，实际为 %s/%s",
+			info.APIVersion(), info.Kind, obj.GetAPIVersion(), obj.GetKind())
+	}
+	if obj.GetName() == "" {
+		return nil, fmt.Errorf("metadata.name 不能为空")
+	}
+	if !info.Namespaced {
+		obj.SetNamespace("")
+	} else if obj.GetNamespace() == "" {
+		obj.SetNamespace("default")
+	}
+	return obj, nil
+}
+
+// CleanDynamicObject 去除 managedFields 等对展示无意义的字段
+func CleanDynamicObject(obj *unstructured.Unstructured) *unstructured.Unstructured {
+	clean := obj.DeepCopy()
+	clean.SetManagedFields(nil)
+	return clean
+}
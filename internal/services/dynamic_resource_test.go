@@ -0,0 +1,74 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery/cached/memory"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func newFakeDiscovery() *fakediscovery.FakeDiscovery {
+	return &fakediscovery.FakeDiscovery{Fake: &k8stesting.Fake{Resources: []*metav1.APIResourceList{
+		{
+			GroupVersion: "v1",
+			APIResources: []metav1.APIResource{
+				{Name: "configmaps", SingularName: "configmap", Kind: "ConfigMap", Namespaced: true, Verbs: []string{"get", "list", "create", "update", "delete"}},
+				{Name: "namespaces", SingularName: "namespace", Kind: "Namespace", Namespaced: false, Verbs: []string{"get", "list", "create", "update", "delete"}},
+				{Name: "pods/log", Kind: "Pod", Namespaced: true, Verbs: []string{"get"}},
+			},
+		},
+		{
+			GroupVersion: "example.com/v1",
+			APIResources: []metav1.APIResource{
+				{Name: "widgets", SingularName: "widget", Kind: "Widget", Namespaced: false, Verbs: []string{"get", "list"}},
+			},
+		},
+	}}}
+}
+
+func TestResolveAPIResource(t *testing.T) {
+	disc := memory.NewMemCacheClient(newFakeDiscovery())
+
+	info, err := ResolveAPIResource(disc, schema.GroupVersionResource{Group: "example.com", Version: "v1", Resource: "widgets"})
+	require.NoError(t, err)
+	assert.Equal(t, "Widget", info.Kind)
+	assert.False(t, info.Namespaced)
+	assert.Equal(t, "example.com/v1", info.APIVersion())
+	assert.Equal(t, "widget:delete", info.PermissionAction("delete"))
+
+	// 内置资源映射为类型化 API 使用的权限动作名
+	pv := &APIResourceInfo{Version: "v1", Resource: "persistentvolumes", SingularName: "persistentvolume", Kind: "PersistentVolume"}
+	assert.Equal(t, "pv:delete", pv.PermissionAction("delete"))
+	quota := &APIResourceInfo{Version: "v1", Resource: "resourcequotas", Kind: "ResourceQuota"}
+	assert.Equal(t, "quota:create", quota.PermissionAction("create"))
+
+	_, err = ResolveAPIResource(disc, schema.GroupVersionResource{Version: "v1", Resource: "pods/log"})
+	assert.Error(t, err)
+
+	_, err = ResolveAPIResource(disc, schema.GroupVersionResource{Version: "v1", Resource: "secrets"})
+	assert.Error(t, err)
+}
+
+func TestDecodeResourceYAML(t *testing.T) {
+	configMap := &APIResourceInfo{Version: "v1", Resource: "configmaps", Kind: "ConfigMap", Namespaced: true}
+	widget := &APIResourceInfo{Group: "example.com", Version: "v1", Resource: "widgets", Kind: "Widget"}
+
+	obj, err := DecodeResourceYAML("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: demo\n", configMap)
+	require.NoError(t, err)
+	assert.Equal(t, "default", obj.GetNamespace())
+
+	obj, err = DecodeResourceYAML("apiVersion: example.com/v1\nkind: Widget\nmetadata:\n  name: w\n  namespace: ignored\n", widget)
+	require.NoError(t, err)
+	assert.Empty(t, obj.GetNamespace())
+
+	_, err = DecodeResourceYAML("apiVersion: v1\nkind: Secret\nmetadata:\n  name: demo\n", configMap)
+	assert.Error(t, err)
+
+	_, err = DecodeResourceYAML("apiVersion: v1\nkind: ConfigMap\nmetadata: {}\n", configMap)
+	assert.Error(t, err)
+}
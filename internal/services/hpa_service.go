@@ -0,0 +1,304 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/clay-wangzhi/KubePolaris/internal/models"
+
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/client-go/kubernetes"
+)
+
+// HPATargetKindRollout Argo Rollouts 的 Rollout 作为扩缩容目标
+const HPATargetKindRollout = "Rollout"
+
+// hpaTargetAPIVersions HPA 支持的扩缩容目标及其 API 版本
+var hpaTargetAPIVersions = map[string]string{
+	WorkloadKindDeployment:  "apps/v1",
+	WorkloadKindStatefulSet: "apps/v1",
+	HPATargetKindRollout:    "argoproj.io/v1alpha1",
+}
+
+// hpaRescaleMessage HPA 扩缩容事件的消息格式，如 "New size: 5; reason: cpu resource utilization (percentage of request) above target"
+var hpaRescaleMessage = regexp.MustCompile(`New size: (\d+); reason: (.*)`)
+
+// HPARequest 创建或更新 HPA 的请求（autoscaling/v2）
+type HPARequest struct {
+	Name        string                                         `json:"name"`
+	Namespace   string                                         `json:"namespace"`
+	TargetKind  string                                         `json:"targetKind"` // Deployment / StatefulSet / Rollout
+	TargetName  string                                         `json:"targetName"`
+	MinReplicas *int32                                         `json:"minReplicas"`
+	MaxReplicas int32                                          `json:"maxReplicas"`
+	Metrics     []autoscalingv2.MetricSpec                     `json:"metrics"`
+	Behavior    *autoscalingv2.HorizontalPodAutoscalerBehavior `json:"behavior,omitempty"`
+}
+
+// HPAInfo HPA 信息
+type HPAInfo struct {
+	Name            string                                           `json:"name"`
+	Namespace       string                                           `json:"namespace"`
+	TargetKind      string                                           `json:"targetKind"`
+	TargetName      string                                           `json:"targetName"`
+	MinReplicas     int32                                            `json:"minReplicas"`
+	MaxReplicas     int32                                            `json:"maxReplicas"`
+	CurrentReplicas int32                                            `json:"currentReplicas"`
+	DesiredReplicas int32                                            `json:"desiredReplicas"`
+	Metrics         []autoscalingv2.MetricSpec                       `json:"metrics"`
+	CurrentMetrics  []autoscalingv2.MetricStatus                     `json:"currentMetrics"`
+	Behavior        *autoscalingv2.HorizontalPodAutoscalerBehavior   `json:"behavior,omitempty"`
+	Conditions      []autoscalingv2.HorizontalPodAutoscalerCondition `json:"conditions"`
+	LastScaleTime   *time.Time                                       `json:"lastScaleTime,omitempty"`
+	CreatedAt       time.Time                                        `json:"createdAt"`
+}
+
+// HPATimelineEntry 扩缩容时间线中的一条记录
+type HPATimelineEntry struct {
+	Timestamp int64  `json:"timestamp"` // Unix 秒
+	Type      string `json:"type"`      // scale（扩缩容事件） / event（其它 HPA 事件）
+	Reason    string `json:"reason"`
+	Message   string `json:"message"`
+	EventType string `json:"eventType"` // Normal / Warning
+	Replicas  *int32 `json:"replicas,omitempty"`
+	Count     int32  `json:"count"`
+}
+
+// HPATimeline HPA 扩缩容时间线：事件与 Prometheus 中的副本数历史
+type HPATimeline struct {
+	HPA      *HPAInfo           `json:"hpa"`
+	Events   []HPATimelineEntry `json:"events"`
+	Replicas []models.DataPoint `json:"replicas"` // 未配置监控时为空
+	CPU      []models.DataPoint `json:"cpu"`      // CPU 使用率，便于对照阈值
+	Memory   []models.DataPoint `json:"memory"`
+}
+
+// ToHPAInfo 转换 HPA 对象
+func ToHPAInfo(hpa *autoscalingv2.HorizontalPodAutoscaler) HPAInfo {
+	info := HPAInfo{
+		Name:            hpa.Name,
+		Namespace:       hpa.Namespace,
+		TargetKind:      hpa.Spec.ScaleTargetRef.Kind,
+		TargetName:      hpa.Spec.ScaleTargetRef.Name,
+		MinReplicas:     1,
+		MaxReplicas:     hpa.Spec.MaxReplicas,
+		CurrentReplicas: hpa.Status.CurrentReplicas,
+		DesiredReplicas: hpa.Status.DesiredReplicas,
+		Metrics:         hpa.Spec.Metrics,
+		CurrentMetrics:  hpa.Status.CurrentMetrics,
+		Behavior:        hpa.Spec.Behavior,
+		Conditions:      hpa.Status.Conditions,
+		CreatedAt:       hpa.CreationTimestamp.Time,
+	}
+	if hpa.Spec.MinReplicas != nil {
+		info.MinReplicas = *hpa.Spec.MinReplicas
+	}
+	if info.Metrics == nil {
+		info.Metrics = []autoscalingv2.MetricSpec{}
+	}
+	if info.CurrentMetrics == nil {
+		info.CurrentMetrics = []autoscalingv2.MetricStatus{}
+	}
+	if info.Conditions == nil {
+		info.Conditions = []autoscalingv2.HorizontalPodAutoscalerCondition{}
+	}
+	if hpa.Status.LastScaleTime != nil {
+		t := hpa.Status.LastScaleTime.Time
+		info.LastScaleTime = &t
+	}
+	return info
+}
+
+// ListHPAs 列出 HPA，namespace 为空时跨全部命名空间，targetKind 非空时按扩缩容目标类型过滤
+func ListHPAs(ctx context.Context, clientset kubernetes.Interface, namespace, targetKind string) ([]HPAInfo, error) {
+	list, err := clientset.AutoscalingV2().HorizontalPodAutoscalers(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("获取HPA列表失败: %w", err)
+	}
+	hpas := make([]HPAInfo, 0, len(list.Items))
+	for i := range list.Items {
+		if targetKind != "" && list.Items[i].Spec.ScaleTargetRef.Kind != targetKind {
+			continue
+		}
+		hpas = append(hpas, ToHPAInfo(&list.Items[i]))
+	}
+	sort.Slice(hpas, func(i, j int) bool {
+		if hpas[i].Namespace != hpas[j].Namespace {
+			return hpas[i].Namespace < hpas[j].Namespace
+		}
+		return hpas[i].Name < hpas[j].Name
+	})
+	return hpas, nil
+}
+
+// CreateHPA 创建 HPA
+func CreateHPA(ctx context.Context, clientset kubernetes.Interface, req *HPARequest) (*autoscalingv2.HorizontalPodAutoscaler, error) {
+	if req.Name == "" || req.Namespace == "" {
+		return nil, fmt.Errorf("%w: 名称和命名空间不能为空", ErrInvalidWorkloadOperation)
+	}
+	hpa := &autoscalingv2.HorizontalPodAutoscaler{
+		ObjectMeta: metav1.ObjectMeta{Name: req.Name, Namespace: req.Namespace},
+	}
+	if err := applyHPASpec(hpa, req); err != nil {
+		return nil, err
+	}
+	return clientset.AutoscalingV2().HorizontalPodAutoscalers(req.Namespace).Create(ctx, hpa, metav1.CreateOptions{})
+}
+
+// UpdateHPA 更新 HPA 的扩缩容目标、副本范围、指标与行为，返回更新前与更新后的对象
+func UpdateHPA(ctx context.Context, clientset kubernetes.Interface, namespace, name string, req *HPARequest) (prev, updated *autoscalingv2.HorizontalPodAutoscaler, err error) {
+	prev, err = clientset.AutoscalingV2().HorizontalPodAutoscalers(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, nil, err
+	}
+	hpa := prev.DeepCopy()
+	// 未指定扩缩容目标时保留原目标
+	if req.TargetKind == "" && req.TargetName == "" {
+		req.TargetKind = hpa.Spec.ScaleTargetRef.Kind
+		req.TargetName = hpa.Spec.ScaleTargetRef.Name
+	}
+	// 未指定最小副本数与扩缩容行为时保留原配置
+	if req.MinReplicas == nil {
+		req.MinReplicas = hpa.Spec.MinReplicas
+	}
+	if req.Behavior == nil {
+		req.Behavior = hpa.Spec.Behavior
+	}
+	if err := applyHPASpec(hpa, req); err != nil {
+		return nil, nil, err
+	}
+	updated, err = clientset.AutoscalingV2().HorizontalPodAutoscalers(namespace).Update(ctx, hpa, metav1.UpdateOptions{})
+	if err != nil {
+		return nil, nil, err
+	}
+	return prev, updated, nil
+}
+
+// DeleteHPA 删除 HPA
+func DeleteHPA(ctx context.Context, clientset kubernetes.Interface, namespace, name string) error {
+	return clientset.AutoscalingV2().HorizontalPodAutoscalers(namespace).Delete(ctx, name, metav1.DeleteOptions{})
+}
+
+// applyHPASpec 校验请求并写入 HPA spec
+func applyHPASpec(hpa *autoscalingv2.HorizontalPodAutoscaler, req *HPARequest) error {
+	apiVersion, ok := hpaTargetAPIVersions[req.TargetKind]
+	if !ok {
+		return fmt.Errorf("%w: 不支持的扩缩容目标类型 %s", ErrInvalidWorkloadOperation, req.TargetKind)
+	}
+	if req.TargetName == "" {
+		return fmt.Errorf("%w: 扩缩容目标名称不能为空", ErrInvalidWorkloadOperation)
+	}
+	if req.MinReplicas != nil && *req.MinReplicas < 1 {
+		return fmt.Errorf("%w: 最小副本数必须大于 0", ErrInvalidWorkloadOperation)
+	}
+	minReplicas := int32(1)
+	if req.MinReplicas != nil {
+		minReplicas = *req.MinReplicas
+	}
+	if req.MaxReplicas < minReplicas {
+		return fmt.Errorf("%w: 最大副本数 %d 小于最小副本数 %d", ErrInvalidWorkloadOperation, req.MaxReplicas, minReplicas)
+	}
+	if len(req.Metrics) == 0 {
+		return fmt.Errorf("%w: 至少需要配置一个扩缩容指标", ErrInvalidWorkloadOperation)
+	}
+
+	hpa.Spec = autoscalingv2.HorizontalPodAutoscalerSpec{
+		ScaleTargetRef: autoscalingv2.CrossVersionObjectReference{
+			APIVersion: apiVersion,
+			Kind:       req.TargetKind,
+			Name:       req.TargetName,
+		},
+		MinReplicas: &minReplicas,
+		MaxReplicas: req.MaxReplicas,
+		Metrics:     req.Metrics,
+		Behavior:    req.Behavior,
+	}
+	return nil
+}
+
+// ListHPAEvents 获取 HPA 相关的事件
+func ListHPAEvents(ctx context.Context, clientset kubernetes.Interface, namespace, name string) ([]corev1.Event, error) {
+	selector := fields.Set{
+		"involvedObject.kind": "HorizontalPodAutoscaler",
+		"involvedObject.name": name,
+	}.AsSelector().String()
+	events, err := clientset.CoreV1().Events(namespace).List(ctx, metav1.ListOptions{FieldSelector: selector})
+	if err != nil {
+		return nil, fmt.Errorf("获取HPA事件失败: %w", err)
+	}
+	// fake clientset 等不支持字段选择器的场景下再次过滤
+	items := make([]corev1.Event, 0, len(events.Items))
+	for _, e := range events.Items {
+		if e.InvolvedObject.Kind == "HorizontalPodAutoscaler" && e.InvolvedObject.Name == name {
+			items = append(items, e)
+		}
+	}
+	return items, nil
+}
+
+// BuildHPATimeline 合并 HPA 事件与监控指标，事件按时间升序排列
+func BuildHPATimeline(hpa *autoscalingv2.HorizontalPodAutoscaler, events []corev1.Event, metrics *models.ClusterMetricsData) *HPATimeline {
+	info := ToHPAInfo(hpa)
+	timeline := &HPATimeline{
+		HPA:      &info,
+		Events:   make([]HPATimelineEntry, 0, len(events)),
+		Replicas: []models.DataPoint{},
+		CPU:      []models.DataPoint{},
+		Memory:   []models.DataPoint{},
+	}
+
+	for _, e := range events {
+		entry := HPATimelineEntry{
+			Timestamp: hpaEventTime(&e).Unix(),
+			Type:      "event",
+			Reason:    e.Reason,
+			Message:   e.Message,
+			EventType: e.Type,
+			Count:     e.Count,
+		}
+		if m := hpaRescaleMessage.FindStringSubmatch(e.Message); m != nil {
+			if n, err := strconv.ParseInt(m[1], 10, 32); err == nil {
+				replicas := int32(n)
+				entry.Type = "scale"
+				entry.Replicas = &replicas
+				entry.Message = m[2]
+			}
+		}
+		timeline.Events = append(timeline.Events, entry)
+	}
+	sort.SliceStable(timeline.Events, func(i, j int) bool {
+		return timeline.Events[i].Timestamp < timeline.Events[j].Timestamp
+	})
+
+	if metrics != nil {
+		if metrics.Replicas != nil {
+			timeline.Replicas = metrics.Replicas.Series
+		}
+		if metrics.CPU != nil {
+			timeline.CPU = metrics.CPU.Series
+		}
+		if metrics.Memory != nil {
+			timeline.Memory = metrics.Memory.Series
+		}
+	}
+	return timeline
+}
+
+// hpaEventTime 事件的最近发生时间，依次取 lastTimestamp、eventTime、firstTimestamp
+func hpaEventTime(e *corev1.Event) time.Time {
+	switch {
+	case !e.LastTimestamp.IsZero():
+		return e.LastTimestamp.Time
+	case !e.EventTime.IsZero():
+		return e.EventTime.Time
+	default:
+		return e.FirstTimestamp.Time
+	}
+}
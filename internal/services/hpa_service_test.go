@@ -0,0 +1,93 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/clay-wangzhi/KubePolaris/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func cpuMetric(utilization int32) []autoscalingv2.MetricSpec {
+	return []autoscalingv2.MetricSpec{{
+		Type: autoscalingv2.ResourceMetricSourceType,
+		Resource: &autoscalingv2.ResourceMetricSource{
+			Name:   corev1.ResourceCPU,
+			Target: autoscalingv2.MetricTarget{Type: autoscalingv2.UtilizationMetricType, AverageUtilization: &utilization},
+		},
+	}}
+}
+
+func TestHPACRUD(t *testing.T) {
+	clientset := fake.NewSimpleClientset()
+	ctx := context.Background()
+
+	_, err := CreateHPA(ctx, clientset, &HPARequest{Name: "web", Namespace: "demo", TargetKind: "DaemonSet", TargetName: "web", MaxReplicas: 3, Metrics: cpuMetric(80)})
+	assert.ErrorIs(t, err, ErrInvalidWorkloadOperation)
+	_, err = CreateHPA(ctx, clientset, &HPARequest{Name: "web", Namespace: "demo", TargetKind: "Deployment", TargetName: "web", MinReplicas: int32Ptr(4), MaxReplicas: 3, Metrics: cpuMetric(80)})
+	assert.ErrorIs(t, err, ErrInvalidWorkloadOperation)
+
+	hpa, err := CreateHPA(ctx, clientset, &HPARequest{Name: "web", Namespace: "demo", TargetKind: HPATargetKindRollout, TargetName: "web", MaxReplicas: 5, Metrics: cpuMetric(80)})
+	require.NoError(t, err)
+	assert.Equal(t, "argoproj.io/v1alpha1", hpa.Spec.ScaleTargetRef.APIVersion)
+	assert.EqualValues(t, 1, *hpa.Spec.MinReplicas)
+
+	// 未指定目标时保留原目标
+	prev, updated, err := UpdateHPA(ctx, clientset, "demo", "web", &HPARequest{MinReplicas: int32Ptr(2), MaxReplicas: 10, Metrics: cpuMetric(60)})
+	require.NoError(t, err)
+	assert.EqualValues(t, 5, prev.Spec.MaxReplicas)
+	assert.Equal(t, HPATargetKindRollout, updated.Spec.ScaleTargetRef.Kind)
+	assert.EqualValues(t, 10, updated.Spec.MaxReplicas)
+
+	// 未指定最小副本数与扩缩容行为时保留原配置
+	window := int32(120)
+	_, updated, err = UpdateHPA(ctx, clientset, "demo", "web", &HPARequest{MinReplicas: int32Ptr(2), MaxReplicas: 10, Metrics: cpuMetric(60), Behavior: &autoscalingv2.HorizontalPodAutoscalerBehavior{ScaleDown: &autoscalingv2.HPAScalingRules{StabilizationWindowSeconds: &window}}})
+	require.NoError(t, err)
+	_, updated, err = UpdateHPA(ctx, clientset, "demo", "web", &HPARequest{MaxReplicas: 8, Metrics: cpuMetric(70)})
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, *updated.Spec.MinReplicas)
+	require.NotNil(t, updated.Spec.Behavior)
+	assert.EqualValues(t, 120, *updated.Spec.Behavior.ScaleDown.StabilizationWindowSeconds)
+
+	hpas, err := ListHPAs(ctx, clientset, "", HPATargetKindRollout)
+	require.NoError(t, err)
+	require.Len(t, hpas, 1)
+	assert.EqualValues(t, 2, hpas[0].MinReplicas)
+	hpas, err = ListHPAs(ctx, clientset, "", WorkloadKindDeployment)
+	require.NoError(t, err)
+	assert.Empty(t, hpas)
+
+	require.NoError(t, DeleteHPA(ctx, clientset, "demo", "web"))
+}
+
+func TestBuildHPATimeline(t *testing.T) {
+	hpa := &autoscalingv2.HorizontalPodAutoscaler{ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "demo"}}
+	base := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	events := []corev1.Event{
+		{Reason: "SuccessfulRescale", Type: corev1.EventTypeNormal, LastTimestamp: metav1.NewTime(base.Add(5 * time.Minute)),
+			Message: "New size: 2; reason: All metrics below target"},
+		{Reason: "SuccessfulRescale", Type: corev1.EventTypeNormal, LastTimestamp: metav1.NewTime(base),
+			Message: "New size: 4; reason: cpu resource utilization (percentage of request) above target"},
+		{Reason: "FailedGetResourceMetric", Type: corev1.EventTypeWarning, EventTime: metav1.NewMicroTime(base.Add(time.Minute)),
+			Message: "failed to get cpu utilization"},
+	}
+	metrics := &models.ClusterMetricsData{Replicas: &models.MetricSeries{Series: []models.DataPoint{{Timestamp: base.Unix(), Value: 4}}}}
+
+	timeline := BuildHPATimeline(hpa, events, metrics)
+	require.Len(t, timeline.Events, 3)
+	assert.Equal(t, "scale", timeline.Events[0].Type)
+	assert.EqualValues(t, 4, *timeline.Events[0].Replicas)
+	assert.Equal(t, "cpu resource utilization (percentage of request) above target", timeline.Events[0].Message)
+	assert.Equal(t, "event", timeline.Events[1].Type)
+	assert.Nil(t, timeline.Events[1].Replicas)
+	assert.EqualValues(t, 2, *timeline.Events[2].Replicas)
+	assert.Len(t, timeline.Replicas, 1)
+	assert.Empty(t, timeline.CPU)
+}
@@ -6,12 +6,15 @@ import (
 	"fmt"
 	"math"
 	"strings"
+	"sync"
 	"time"
 
 	rolloutsclientset "github.com/argoproj/argo-rollouts/pkg/client/clientset/versioned"
 	"github.com/clay-wangzhi/KubePolaris/internal/models"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
@@ -21,6 +24,13 @@ import (
 type K8sClient struct {
 	clientset *kubernetes.Clientset
 	config    *rest.Config
+
+	// 按需创建的动态客户端与发现缓存
+	dynamicOnce     sync.Once
+	dynamicClient   dynamic.Interface
+	dynamicErr      error
+	discoveryOnce   sync.Once
+	cachedDiscovery discovery.CachedDiscoveryInterface
 }
 
 type ClusterInfo struct {
@@ -94,8 +104,25 @@ func NewK8sClientFromToken(apiServer, token, caCert string) (*K8sClient, error)
 	}, nil
 }
 
+// NewK8sClientFromAgent 创建经 Agent 反向隧道访问集群的客户端
+func NewK8sClientFromAgent(clusterID uint) (*K8sClient, error) {
+	config := NewAgentRESTConfig(clusterID)
+	clientset, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("创建kubernetes客户端失败: %v", err)
+	}
+
+	return &K8sClient{
+		clientset: clientset,
+		config:    config,
+	}, nil
+}
+
 // NewK8sClientForCluster 根据集群模型创建 K8s 客户端（统一入口，消除重复的 if/else 创建逻辑）
 func NewK8sClientForCluster(cluster *models.Cluster) (*K8sClient, error) {
+	if cluster.IsAgentMode() {
+		return NewK8sClientFromAgent(cluster.ID)
+	}
 	if cluster.KubeconfigEnc != "" {
 		return NewK8sClientFromKubeconfig(cluster.KubeconfigEnc)
 	}
@@ -325,6 +352,18 @@ func ValidateKubeconfig(kubeconfig string) error {
 	return err
 }
 
+// GetClusterUID 获取集群唯一标识（kube-system 命名空间的 UID，集群生命周期内不变）
+func (c *K8sClient) GetClusterUID() (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	ns, err := c.clientset.CoreV1().Namespaces().Get(ctx, metav1.NamespaceSystem, metav1.GetOptions{})
+	if err != nil {
+		return "", fmt.Errorf("获取 kube-system 命名空间失败: %w", err)
+	}
+	return string(ns.UID), nil
+}
+
 // GetClientset 获取kubernetes客户端
 func (c *K8sClient) GetClientset() *kubernetes.Clientset {
 	return c.clientset
@@ -858,103 +897,3 @@ func (c *K8sClient) UncordonNode(nodeName string) error {
 
 	return nil
 }
-
-// DrainNode 驱逐节点上的Pod
-func (c *K8sClient) DrainNode(nodeName string, options map[string]interface{}) error {
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute) // 驱逐操作可能需要更长时间
-	defer cancel()
-
-	// 获取节点
-	node, err := c.clientset.CoreV1().Nodes().Get(ctx, nodeName, metav1.GetOptions{})
-	if err != nil {
-		return fmt.Errorf("获取节点失败: %v", err)
-	}
-
-	// 1. 首先封锁节点，防止新的Pod调度到该节点
-	if !node.Spec.Unschedulable {
-		node.Spec.Unschedulable = true
-		_, err = c.clientset.CoreV1().Nodes().Update(ctx, node, metav1.UpdateOptions{})
-		if err != nil {
-			return fmt.Errorf("封锁节点失败: %v", err)
-		}
-	}
-
-	// 解析选项
-	ignoreDaemonSets := true
-	if val, ok := options["ignoreDaemonSets"]; ok {
-		ignoreDaemonSets = val.(bool)
-	}
-
-	deleteLocalData := false
-	if val, ok := options["deleteLocalData"]; ok {
-		deleteLocalData = val.(bool)
-	}
-
-	force := false
-	if val, ok := options["force"]; ok {
-		force = val.(bool)
-	}
-
-	gracePeriodSeconds := int64(30)
-	if val, ok := options["gracePeriodSeconds"]; ok {
-		if intVal, ok := val.(float64); ok {
-			gracePeriodSeconds = int64(intVal)
-		}
-	}
-
-	// 2. 获取节点上的所有Pod
-	fieldSelector := fmt.Sprintf("spec.nodeName=%s", nodeName)
-	pods, err := c.clientset.CoreV1().Pods("").List(ctx, metav1.ListOptions{
-		FieldSelector: fieldSelector,
-	})
-	if err != nil {
-		return fmt.Errorf("获取节点上的Pod失败: %v", err)
-	}
-
-	// 3. 驱逐Pod
-	for _, pod := range pods.Items {
-		// 如果忽略DaemonSet，检查Pod是否由DaemonSet控制
-		if ignoreDaemonSets {
-			isDaemonSet := false
-			for _, owner := range pod.OwnerReferences {
-				if owner.Kind == "DaemonSet" {
-					isDaemonSet = true
-					break
-				}
-			}
-			if isDaemonSet {
-				continue // 跳过DaemonSet管理的Pod
-			}
-		}
-
-		// 检查Pod是否使用emptyDir卷
-		if !deleteLocalData {
-			hasEmptyDir := false
-			for _, volume := range pod.Spec.Volumes {
-				if volume.EmptyDir != nil {
-					hasEmptyDir = true
-					break
-				}
-			}
-			if hasEmptyDir && !force {
-				return fmt.Errorf("pod %s/%s 使用emptyDir卷，需要设置deleteLocalData=true或force=true", pod.Namespace, pod.Name)
-			}
-		}
-
-		// 删除Pod
-		deleteOptions := metav1.DeleteOptions{}
-		if gracePeriodSeconds >= 0 {
-			deleteOptions.GracePeriodSeconds = &gracePeriodSeconds
-		}
-
-		err = c.clientset.CoreV1().Pods(pod.Namespace).Delete(ctx, pod.Name, deleteOptions)
-		if err != nil {
-			if !force {
-				return fmt.Errorf("驱逐Pod %s/%s 失败: %v", pod.Namespace, pod.Name, err)
-			}
-			// 如果设置了force，则忽略错误继续执行
-		}
-	}
-
-	return nil
-}
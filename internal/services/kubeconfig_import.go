@@ -0,0 +1,207 @@
+package services
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/tools/clientcmd/api"
+)
+
+// kubeconfigProbeConcurrency 批量导入时同时测试连接的上下文数量上限
+const kubeconfigProbeConcurrency = 10
+
+// KubeconfigContext kubeconfig 中的单个上下文
+type KubeconfigContext struct {
+	Name      string `json:"name"`
+	Cluster   string `json:"cluster"`
+	User      string `json:"user"`
+	Namespace string `json:"namespace,omitempty"`
+	APIServer string `json:"apiServer"`
+	Current   bool   `json:"current"`
+}
+
+// KubeconfigContextPreview 上下文连接测试结果（批量导入预览）
+type KubeconfigContextPreview struct {
+	KubeconfigContext
+	Reachable  bool   `json:"reachable"`
+	Version    string `json:"version,omitempty"`
+	NodeCount  int    `json:"nodeCount"`
+	ReadyNodes int    `json:"readyNodes"`
+	Status     string `json:"status,omitempty"`
+	Error      string `json:"error,omitempty"`
+	// ImportedAs 该上下文对应的集群已导入时的集群名称
+	ImportedAs   string     `json:"importedAs,omitempty"`
+	CertExpireAt *time.Time `json:"certExpireAt,omitempty"`
+
+	// 以下字段不返回给前端，导入时复用
+	Kubeconfig string `json:"-"` // 仅包含该上下文的 kubeconfig
+	ClusterUID string `json:"-"`
+}
+
+// ListKubeconfigContexts 解析 kubeconfig 中的所有上下文（按名称排序）
+func ListKubeconfigContexts(kubeconfig string) ([]KubeconfigContext, error) {
+	cfg, err := clientcmd.Load([]byte(kubeconfig))
+	if err != nil {
+		return nil, fmt.Errorf("解析kubeconfig失败: %w", err)
+	}
+	if len(cfg.Contexts) == 0 {
+		return nil, fmt.Errorf("kubeconfig 中没有任何上下文")
+	}
+
+	contexts := make([]KubeconfigContext, 0, len(cfg.Contexts))
+	for name, ctx := range cfg.Contexts {
+		item := KubeconfigContext{
+			Name:      name,
+			Cluster:   ctx.Cluster,
+			User:      ctx.AuthInfo,
+			Namespace: ctx.Namespace,
+			Current:   name == cfg.CurrentContext,
+		}
+		if cluster, ok := cfg.Clusters[ctx.Cluster]; ok {
+			item.APIServer = cluster.Server
+		}
+		contexts = append(contexts, item)
+	}
+	sort.Slice(contexts, func(i, j int) bool {
+		return contexts[i].Name < contexts[j].Name
+	})
+	return contexts, nil
+}
+
+// MinifyCurrentContext 生成只包含 current-context 的 kubeconfig，校验规则与 MinifyKubeconfig 一致
+// 用于更新集群凭据等只接受单个上下文的场景。
+func MinifyCurrentContext(kubeconfig string) (string, error) {
+	cfg, err := clientcmd.Load([]byte(kubeconfig))
+	if err != nil {
+		return "", fmt.Errorf("解析kubeconfig失败: %w", err)
+	}
+	if cfg.CurrentContext == "" {
+		return "", fmt.Errorf("kubeconfig 未设置 current-context")
+	}
+	return MinifyKubeconfig(kubeconfig, cfg.CurrentContext)
+}
+
+// MinifyKubeconfig 生成只包含指定上下文的 kubeconfig
+func MinifyKubeconfig(kubeconfig, contextName string) (string, error) {
+	cfg, err := clientcmd.Load([]byte(kubeconfig))
+	if err != nil {
+		return "", fmt.Errorf("解析kubeconfig失败: %w", err)
+	}
+	if _, ok := cfg.Contexts[contextName]; !ok {
+		return "", fmt.Errorf("上下文不存在: %s", contextName)
+	}
+
+	cfg.CurrentContext = contextName
+	if err := api.MinifyConfig(cfg); err != nil {
+		return "", fmt.Errorf("精简kubeconfig失败: %w", err)
+	}
+	// 上传的 kubeconfig 不允许引用服务端本地文件或在服务端执行凭据插件
+	for name, cluster := range cfg.Clusters {
+		if cluster.CertificateAuthority != "" {
+			return "", fmt.Errorf("集群 %s 引用了本地证书文件，请使用 certificate-authority-data", name)
+		}
+	}
+	for name, user := range cfg.AuthInfos {
+		if user.ClientCertificate != "" || user.ClientKey != "" || user.TokenFile != "" {
+			return "", fmt.Errorf("用户 %s 引用了本地凭据文件，请使用内联数据", name)
+		}
+		if user.Exec != nil || user.AuthProvider != nil {
+			return "", fmt.Errorf("用户 %s 使用了 exec/auth-provider 凭据插件，请使用 token 或客户端证书", name)
+		}
+	}
+	// 清理与集群连接无关的 preferences/extensions
+	cfg.Preferences = api.Preferences{}
+	cfg.Extensions = nil
+
+	data, err := clientcmd.Write(*cfg)
+	if err != nil {
+		return "", fmt.Errorf("序列化kubeconfig失败: %w", err)
+	}
+	return string(data), nil
+}
+
+// PreviewKubeconfigContexts 并发测试 kubeconfig 中指定上下文的连接情况
+// contextNames 为空时测试所有上下文；结果顺序与上下文名称排序一致。
+func PreviewKubeconfigContexts(kubeconfig string, contextNames []string) ([]*KubeconfigContextPreview, error) {
+	contexts, err := ListKubeconfigContexts(kubeconfig)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(contextNames) > 0 {
+		selected := make(map[string]bool, len(contextNames))
+		for _, name := range contextNames {
+			selected[name] = true
+		}
+		filtered := contexts[:0]
+		for _, ctx := range contexts {
+			if selected[ctx.Name] {
+				filtered = append(filtered, ctx)
+				delete(selected, ctx.Name)
+			}
+		}
+		for name := range selected {
+			return nil, fmt.Errorf("上下文不存在: %s", name)
+		}
+		contexts = filtered
+	}
+
+	previews := make([]*KubeconfigContextPreview, len(contexts))
+	sem := make(chan struct{}, kubeconfigProbeConcurrency)
+	var wg sync.WaitGroup
+	for i, ctx := range contexts {
+		previews[i] = &KubeconfigContextPreview{KubeconfigContext: ctx}
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(p *KubeconfigContextPreview) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			probeKubeconfigContext(kubeconfig, p)
+		}(previews[i])
+	}
+	wg.Wait()
+
+	return previews, nil
+}
+
+// probeKubeconfigContext 使用精简后的 kubeconfig 测试单个上下文
+func probeKubeconfigContext(kubeconfig string, p *KubeconfigContextPreview) {
+	minified, err := MinifyKubeconfig(kubeconfig, p.Name)
+	if err != nil {
+		p.Error = err.Error()
+		return
+	}
+	p.Kubeconfig = minified
+
+	client, err := NewK8sClientFromKubeconfig(minified)
+	if err != nil {
+		p.Error = err.Error()
+		return
+	}
+	if restConfig := client.GetRestConfig(); restConfig != nil && restConfig.Host != "" {
+		p.APIServer = restConfig.Host
+	}
+
+	info, err := client.TestConnection()
+	if err != nil {
+		p.Error = err.Error()
+		return
+	}
+	p.Reachable = true
+	p.Version = info.Version
+	p.NodeCount = info.NodeCount
+	p.ReadyNodes = info.ReadyNodes
+	p.Status = info.Status
+
+	if uid, err := client.GetClusterUID(); err == nil {
+		p.ClusterUID = uid
+	}
+	if expireAt, err := client.CertExpireAt(); err == nil {
+		p.CertExpireAt = expireAt
+	}
+}
@@ -0,0 +1,146 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+const multiContextKubeconfig = `apiVersion: v1
+kind: Config
+current-context: prod
+clusters:
+- name: prod-cluster
+  cluster:
+    server: https://prod.example.com:6443
+    insecure-skip-tls-verify: true
+- name: dev-cluster
+  cluster:
+    server: https://dev.example.com:6443
+    insecure-skip-tls-verify: true
+users:
+- name: prod-admin
+  user:
+    token: prod-token
+- name: dev-admin
+  user:
+    token: dev-token
+contexts:
+- name: prod
+  context:
+    cluster: prod-cluster
+    user: prod-admin
+- name: dev
+  context:
+    cluster: dev-cluster
+    user: dev-admin
+    namespace: apps
+`
+
+func TestListKubeconfigContexts(t *testing.T) {
+	contexts, err := ListKubeconfigContexts(multiContextKubeconfig)
+	require.NoError(t, err)
+	require.Len(t, contexts, 2)
+
+	// 按名称排序
+	assert.Equal(t, "dev", contexts[0].Name)
+	assert.Equal(t, "https://dev.example.com:6443", contexts[0].APIServer)
+	assert.Equal(t, "apps", contexts[0].Namespace)
+	assert.False(t, contexts[0].Current)
+
+	assert.Equal(t, "prod", contexts[1].Name)
+	assert.Equal(t, "prod-admin", contexts[1].User)
+	assert.True(t, contexts[1].Current)
+}
+
+func TestMinifyKubeconfig(t *testing.T) {
+	minified, err := MinifyKubeconfig(multiContextKubeconfig, "dev")
+	require.NoError(t, err)
+
+	cfg, err := clientcmd.Load([]byte(minified))
+	require.NoError(t, err)
+	assert.Equal(t, "dev", cfg.CurrentContext)
+	assert.Len(t, cfg.Contexts, 1)
+	assert.Len(t, cfg.Clusters, 1)
+	assert.Len(t, cfg.AuthInfos, 1)
+	assert.Equal(t, "https://dev.example.com:6443", cfg.Clusters["dev-cluster"].Server)
+	assert.Equal(t, "dev-token", cfg.AuthInfos["dev-admin"].Token)
+}
+
+func TestMinifyKubeconfig_UnknownContext(t *testing.T) {
+	_, err := MinifyKubeconfig(multiContextKubeconfig, "staging")
+	assert.Error(t, err)
+}
+
+func TestMinifyKubeconfig_RejectsLocalFiles(t *testing.T) {
+	kubeconfig := `apiVersion: v1
+kind: Config
+current-context: local
+clusters:
+- name: local
+  cluster:
+    server: https://127.0.0.1:6443
+    certificate-authority: /etc/kubernetes/pki/ca.crt
+users:
+- name: local
+  user:
+    token: t
+contexts:
+- name: local
+  context:
+    cluster: local
+    user: local
+`
+	_, err := MinifyKubeconfig(kubeconfig, "local")
+	assert.Error(t, err)
+}
+
+func TestMinifyKubeconfig_RejectsCredentialPlugins(t *testing.T) {
+	users := map[string]string{
+		"exec": `    exec:
+      apiVersion: client.authentication.k8s.io/v1
+      command: /bin/sh
+      args: ["-c", "id"]
+`,
+		"auth-provider": `    auth-provider:
+      name: oidc
+      config:
+        cmd-path: /bin/sh
+`,
+	}
+	for name, user := range users {
+		kubeconfig := `apiVersion: v1
+kind: Config
+current-context: remote
+clusters:
+- name: remote
+  cluster:
+    server: https://remote.example.com:6443
+    insecure-skip-tls-verify: true
+users:
+- name: remote
+  user:
+` + user + `contexts:
+- name: remote
+  context:
+    cluster: remote
+    user: remote
+`
+		_, err := MinifyKubeconfig(kubeconfig, "remote")
+		require.Error(t, err, name)
+		assert.Contains(t, err.Error(), "凭据插件", name)
+
+		// 更新集群凭据使用 current-context，校验规则相同
+		_, err = MinifyCurrentContext(kubeconfig)
+		require.Error(t, err, name)
+		assert.Contains(t, err.Error(), "凭据插件", name)
+
+		previews, err := PreviewKubeconfigContexts(kubeconfig, nil)
+		require.NoError(t, err, name)
+		require.Len(t, previews, 1)
+		assert.False(t, previews[0].Reachable, name)
+		assert.Contains(t, previews[0].Error, "凭据插件", name)
+	}
+}
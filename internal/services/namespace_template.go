@@ -0,0 +1,661 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/clay-wangzhi/KubePolaris/internal/models"
+
+	"gorm.io/gorm"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/client-go/kubernetes"
+)
+
+// 记录在命名空间上的模板信息
+const (
+	NamespaceTemplateAnnotation        = "kubepolaris.io/namespace-template"
+	NamespaceTemplateVersionAnnotation = "kubepolaris.io/namespace-template-version"
+)
+
+// 重新应用模板时清理已移除资源的结果状态
+const (
+	NamespaceTemplatePruneDeleted = "deleted"
+	NamespaceTemplatePruneAbsent  = "absent" // 资源已不存在
+	NamespaceTemplatePruneFailed  = "failed"
+)
+
+// namespaceTemplateBuiltinVariable 内置变量，渲染时替换为目标命名空间名称
+const namespaceTemplateBuiltinVariable = "namespace"
+
+var (
+	ErrNamespaceTemplateNotFound = errors.New("命名空间模板不存在")
+	ErrNamespaceTemplateExists   = errors.New("命名空间模板名称已存在")
+	ErrNamespaceTemplateInUse    = errors.New("命名空间模板正在被使用")
+
+	// ${name} 为变量占位符，$${name} 输出字面量 ${name}
+	templatePlaceholderPattern  = regexp.MustCompile(`\$(\$?)\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+	templateVariableNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+)
+
+// NamespaceTemplateRequest 创建/更新命名空间模板请求
+type NamespaceTemplateRequest struct {
+	Name        string                             `json:"name" binding:"required"`
+	Description string                             `json:"description"`
+	Content     string                             `json:"content" binding:"required"`
+	Variables   []models.NamespaceTemplateVariable `json:"variables"`
+}
+
+// NamespaceTemplateInfo 命名空间模板信息
+type NamespaceTemplateInfo struct {
+	ID          uint                               `json:"id"`
+	Name        string                             `json:"name"`
+	Description string                             `json:"description"`
+	Content     string                             `json:"content"`
+	Variables   []models.NamespaceTemplateVariable `json:"variables"`
+	Version     int                                `json:"version"`
+	Namespaces  int64                              `json:"namespaces"` // 使用该模板的命名空间数
+	CreatedBy   string                             `json:"createdBy"`
+	UpdatedBy   string                             `json:"updatedBy"`
+	CreatedAt   time.Time                          `json:"createdAt"`
+	UpdatedAt   time.Time                          `json:"updatedAt"`
+}
+
+// NamespaceTemplateBindingInfo 命名空间当前绑定的模板及是否落后于模板最新版本
+type NamespaceTemplateBindingInfo struct {
+	models.NamespaceTemplateBinding
+	Values        map[string]string `json:"values"`
+	LatestVersion int               `json:"latest_version"` // 模板已删除时为 0
+	Outdated      bool              `json:"outdated"`
+}
+
+// NamespaceProvisionRequest 按模板创建或重新应用命名空间的请求
+type NamespaceProvisionRequest struct {
+	Namespace   string
+	Labels      map[string]string
+	Annotations map[string]string
+	Values      map[string]string
+	DryRun      bool // 仅用于重新应用：命名空间不存在时无法预检其中的资源
+}
+
+// NamespaceProvisionResult 按模板下发命名空间的结果
+type NamespaceProvisionResult struct {
+	Namespace       string            `json:"namespace"`
+	Template        string            `json:"template"`
+	TemplateVersion int               `json:"templateVersion"`
+	Values          map[string]string `json:"values"`
+	Created         bool              `json:"created"` // 本次是否新建了命名空间
+	DryRun          bool              `json:"dryRun"`
+	Apply           *BatchApplyResult `json:"apply"`
+	RolledBack      bool              `json:"rolledBack"` // 应用失败后是否已回滚
+	RollbackErrors  []string          `json:"rollbackErrors,omitempty"`
+	// Pruned 模板新版本中已移除、本次从命名空间中删除（预检时为 dry-run 删除）的资源
+	Pruned []NamespaceTemplatePrunedObject `json:"pruned,omitempty"`
+}
+
+// NamespaceTemplatePrunedObject 重新应用模板时清理的资源
+type NamespaceTemplatePrunedObject struct {
+	models.NamespaceTemplateObject
+	Status string `json:"status"` // deleted / absent / failed
+	Error  string `json:"error,omitempty"`
+}
+
+// Failed 模板中是否有资源应用失败
+func (r *NamespaceProvisionResult) Failed() bool {
+	return r.Apply != nil && r.Apply.Failed > 0
+}
+
+// PruneErrors 清理已移除资源时失败的资源及原因
+func (r *NamespaceProvisionResult) PruneErrors() []string {
+	var errs []string
+	for _, p := range r.Pruned {
+		if p.Status == NamespaceTemplatePruneFailed {
+			errs = append(errs, fmt.Sprintf("%s/%s: %s", p.Kind, p.Name, p.Error))
+		}
+	}
+	return errs
+}
+
+// NamespaceTemplateService 命名空间模板服务
+type NamespaceTemplateService struct {
+	db *gorm.DB
+}
+
+// NewNamespaceTemplateService 创建命名空间模板服务
+func NewNamespaceTemplateService(db *gorm.DB) *NamespaceTemplateService {
+	return &NamespaceTemplateService{db: db}
+}
+
+// List 获取模板列表
+func (s *NamespaceTemplateService) List() ([]NamespaceTemplateInfo, error) {
+	var templates []models.NamespaceTemplate
+	if err := s.db.Order("name").Find(&templates).Error; err != nil {
+		return nil, fmt.Errorf("获取命名空间模板列表失败: %w", err)
+	}
+
+	type usage struct {
+		TemplateID uint
+		Count      int64
+	}
+	var usages []usage
+	if err := s.db.Model(&models.NamespaceTemplateBinding{}).
+		Select("template_id, count(*) as count").Group("template_id").Scan(&usages).Error; err != nil {
+		return nil, fmt.Errorf("统计模板使用情况失败: %w", err)
+	}
+	counts := make(map[uint]int64, len(usages))
+	for _, u := range usages {
+		counts[u.TemplateID] = u.Count
+	}
+
+	items := make([]NamespaceTemplateInfo, 0, len(templates))
+	for i := range templates {
+		info := ToNamespaceTemplateInfo(&templates[i])
+		info.Namespaces = counts[templates[i].ID]
+		items = append(items, info)
+	}
+	return items, nil
+}
+
+// Get 获取模板
+func (s *NamespaceTemplateService) Get(id uint) (*models.NamespaceTemplate, error) {
+	var tpl models.NamespaceTemplate
+	if err := s.db.First(&tpl, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNamespaceTemplateNotFound
+		}
+		return nil, err
+	}
+	return &tpl, nil
+}
+
+// Create 创建模板，初始版本为 1
+func (s *NamespaceTemplateService) Create(req *NamespaceTemplateRequest, operator string) (*models.NamespaceTemplate, error) {
+	if err := validateNamespaceTemplate(req); err != nil {
+		return nil, err
+	}
+	var count int64
+	s.db.Model(&models.NamespaceTemplate{}).Where("name = ?", req.Name).Count(&count)
+	if count > 0 {
+		return nil, ErrNamespaceTemplateExists
+	}
+
+	tpl := &models.NamespaceTemplate{
+		Name:        req.Name,
+		Description: req.Description,
+		Content:     req.Content,
+		Version:     1,
+		CreatedBy:   operator,
+		UpdatedBy:   operator,
+	}
+	if err := tpl.SetVariables(req.Variables); err != nil {
+		return nil, err
+	}
+	if err := s.db.Create(tpl).Error; err != nil {
+		return nil, fmt.Errorf("创建命名空间模板失败: %w", err)
+	}
+	return tpl, nil
+}
+
+// Update 更新模板，内容或变量定义变化时版本号加一
+func (s *NamespaceTemplateService) Update(id uint, req *NamespaceTemplateRequest, operator string) (*models.NamespaceTemplate, error) {
+	if err := validateNamespaceTemplate(req); err != nil {
+		return nil, err
+	}
+	tpl, err := s.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	if req.Name != tpl.Name {
+		var count int64
+		s.db.Model(&models.NamespaceTemplate{}).Where("name = ? AND id <> ?", req.Name, id).Count(&count)
+		if count > 0 {
+			return nil, ErrNamespaceTemplateExists
+		}
+	}
+
+	prevVariables := tpl.Variables
+	if err := tpl.SetVariables(req.Variables); err != nil {
+		return nil, err
+	}
+	if req.Content != tpl.Content || tpl.Variables != prevVariables {
+		tpl.Version++
+	}
+	tpl.Name = req.Name
+	tpl.Description = req.Description
+	tpl.Content = req.Content
+	tpl.UpdatedBy = operator
+	if err := s.db.Save(tpl).Error; err != nil {
+		return nil, fmt.Errorf("更新命名空间模板失败: %w", err)
+	}
+	return tpl, nil
+}
+
+// Delete 删除模板；仍有命名空间绑定该模板时拒绝删除
+func (s *NamespaceTemplateService) Delete(id uint) error {
+	if _, err := s.Get(id); err != nil {
+		return err
+	}
+	var count int64
+	s.db.Model(&models.NamespaceTemplateBinding{}).Where("template_id = ?", id).Count(&count)
+	if count > 0 {
+		return fmt.Errorf("%w: %d 个命名空间使用该模板", ErrNamespaceTemplateInUse, count)
+	}
+	return s.db.Delete(&models.NamespaceTemplate{}, id).Error
+}
+
+// GetBinding 获取命名空间绑定的模板，未绑定时返回 nil
+func (s *NamespaceTemplateService) GetBinding(clusterID uint, namespace string) (*NamespaceTemplateBindingInfo, error) {
+	var binding models.NamespaceTemplateBinding
+	err := s.db.Where("cluster_id = ? AND namespace = ?", clusterID, namespace).First(&binding).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	info := &NamespaceTemplateBindingInfo{NamespaceTemplateBinding: binding, Values: binding.GetValues()}
+	if tpl, err := s.Get(binding.TemplateID); err == nil {
+		info.LatestVersion = tpl.Version
+		info.Outdated = tpl.Version > binding.TemplateVersion
+	}
+	return info, nil
+}
+
+// DeleteBinding 删除命名空间的模板绑定（命名空间被删除时调用）
+func (s *NamespaceTemplateService) DeleteBinding(clusterID uint, namespace string) error {
+	return s.db.Where("cluster_id = ? AND namespace = ?", clusterID, namespace).Delete(&models.NamespaceTemplateBinding{}).Error
+}
+
+// saveBinding 记录命名空间应用的模板版本、变量值及下发的资源
+func (s *NamespaceTemplateService) saveBinding(clusterID uint, tpl *models.NamespaceTemplate, result *NamespaceProvisionResult,
+	objects []models.NamespaceTemplateObject, operator RevisionOperator) error {
+	var binding models.NamespaceTemplateBinding
+	err := s.db.Where("cluster_id = ? AND namespace = ?", clusterID, result.Namespace).First(&binding).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+	binding.ClusterID = clusterID
+	binding.Namespace = result.Namespace
+	binding.TemplateID = tpl.ID
+	binding.TemplateName = tpl.Name
+	binding.TemplateVersion = tpl.Version
+	binding.UserID = operator.UserID
+	binding.Username = operator.Username
+	if err := binding.SetValues(result.Values); err != nil {
+		return err
+	}
+	if err := binding.SetObjects(objects); err != nil {
+		return err
+	}
+	return s.db.Save(&binding).Error
+}
+
+// CreateNamespace 按模板创建命名空间并下发模板中的全部资源
+// 任一资源应用失败时删除新建的命名空间（其中的资源随之删除），结果中 RolledBack 为 true。
+func (s *NamespaceTemplateService) CreateNamespace(ctx context.Context, engine *ApplyEngine, clientset kubernetes.Interface,
+	clusterID uint, tpl *models.NamespaceTemplate, req *NamespaceProvisionRequest, operator RevisionOperator) (*NamespaceProvisionResult, error) {
+	if req.DryRun {
+		return nil, fmt.Errorf("%w: 命名空间尚未创建，无法预检，请使用模板预览", ErrInvalidWorkloadOperation)
+	}
+	result, nsObj, objs, err := prepareNamespaceProvision(engine, tpl, req)
+	if err != nil {
+		return nil, err
+	}
+
+	namespace := &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        req.Namespace,
+			Labels:      mergeStringMaps(nsObj.GetLabels(), req.Labels),
+			Annotations: mergeStringMaps(nsObj.GetAnnotations(), req.Annotations, namespaceTemplateAnnotations(tpl)),
+		},
+	}
+	if _, err := clientset.CoreV1().Namespaces().Create(ctx, namespace, metav1.CreateOptions{}); err != nil {
+		return nil, err
+	}
+	result.Created = true
+
+	result.Apply = engine.ApplyAll(ctx, objs, BatchApplyOptions{})
+	if result.Failed() {
+		result.RolledBack = true
+		err := clientset.CoreV1().Namespaces().Delete(ctx, req.Namespace, metav1.DeleteOptions{})
+		if err != nil && !apierrors.IsNotFound(err) {
+			result.RolledBack = false
+			result.RollbackErrors = []string{fmt.Sprintf("删除命名空间 %s 失败: %v", req.Namespace, err)}
+		}
+		return result, nil
+	}
+	if err := s.saveBinding(clusterID, tpl, result, appliedTemplateObjects(result.Apply), operator); err != nil {
+		return result, fmt.Errorf("资源已下发，但保存模板绑定失败: %w", err)
+	}
+	return result, nil
+}
+
+// ApplyToNamespace 将模板（最新版本）应用到已存在的命名空间，用于模板更新后重新应用或为存量命名空间绑定模板
+// 未指定的变量沿用上次应用时的值；任一资源应用失败时撤销本次已应用的资源。
+// 全部应用成功后，删除上次由同一模板下发、但当前版本中已移除的资源（预检时仅 dry-run 删除）。
+func (s *NamespaceTemplateService) ApplyToNamespace(ctx context.Context, engine *ApplyEngine,
+	clusterID uint, tpl *models.NamespaceTemplate, req *NamespaceProvisionRequest, operator RevisionOperator) (*NamespaceProvisionResult, error) {
+	nsInfo, err := engine.ResolveKind(corev1.SchemeGroupVersion.WithKind("Namespace"))
+	if err != nil {
+		return nil, err
+	}
+	if _, err := engine.Get(ctx, nsInfo, "", req.Namespace); err != nil {
+		return nil, err
+	}
+
+	values := map[string]string{}
+	var previous []models.NamespaceTemplateObject
+	if binding, err := s.GetBinding(clusterID, req.Namespace); err != nil {
+		return nil, err
+	} else if binding != nil && binding.TemplateID == tpl.ID {
+		values = binding.Values
+		previous = binding.GetObjects()
+	}
+	for k, v := range req.Values {
+		values[k] = v
+	}
+	provisionReq := *req
+	provisionReq.Values = values
+
+	result, nsObj, objs, err := prepareNamespaceProvision(engine, tpl, &provisionReq)
+	if err != nil {
+		return nil, err
+	}
+
+	// 通过服务端应用更新命名空间的模板标记及模板中声明的标签与注解
+	nsObj.SetAnnotations(mergeStringMaps(nsObj.GetAnnotations(), namespaceTemplateAnnotations(tpl)))
+	objs = append([]*unstructured.Unstructured{nsObj}, objs...)
+
+	result.Apply = engine.ApplyAll(ctx, objs, BatchApplyOptions{ApplyOptions: ApplyOptions{DryRun: req.DryRun}})
+	if result.Failed() {
+		if !req.DryRun {
+			result.RollbackErrors = engine.RevertApplied(ctx, result.Apply)
+			result.RolledBack = len(result.RollbackErrors) == 0
+		}
+		return result, nil
+	}
+
+	applied := appliedTemplateObjects(result.Apply)
+	result.Pruned = pruneNamespaceTemplateObjects(ctx, engine, req.Namespace, staleTemplateObjects(previous, applied), req.DryRun)
+	if req.DryRun {
+		return result, nil
+	}
+	// 删除失败的资源保留在记录中，下次应用时重试
+	for _, p := range result.Pruned {
+		if p.Status == NamespaceTemplatePruneFailed {
+			applied = append(applied, p.NamespaceTemplateObject)
+		}
+	}
+	if err := s.saveBinding(clusterID, tpl, result, applied, operator); err != nil {
+		return result, fmt.Errorf("资源已下发，但保存模板绑定失败: %w", err)
+	}
+	return result, nil
+}
+
+// appliedTemplateObjects 提取模板中已成功应用的资源（不含命名空间本身）
+func appliedTemplateObjects(result *BatchApplyResult) []models.NamespaceTemplateObject {
+	objects := make([]models.NamespaceTemplateObject, 0, len(result.Items))
+	for _, item := range result.Items {
+		if item.Status != BatchApplyStatusApplied || (item.Kind == "Namespace" && item.APIVersion == "v1") {
+			continue
+		}
+		objects = append(objects, models.NamespaceTemplateObject{APIVersion: item.APIVersion, Kind: item.Kind, Name: item.Name})
+	}
+	return objects
+}
+
+// staleTemplateObjects 返回上次应用过、本次不再包含的资源，按应用顺序的逆序排列
+// 按资源组、类型与名称比较，仅 apiVersion 升级的资源不视为移除。
+func staleTemplateObjects(previous, current []models.NamespaceTemplateObject) []models.NamespaceTemplateObject {
+	key := func(o models.NamespaceTemplateObject) string {
+		return schema.FromAPIVersionAndKind(o.APIVersion, o.Kind).GroupKind().String() + "/" + o.Name
+	}
+	keep := make(map[string]bool, len(current))
+	for _, o := range current {
+		keep[key(o)] = true
+	}
+	var stale []models.NamespaceTemplateObject
+	for _, o := range previous {
+		if !keep[key(o)] {
+			stale = append(stale, o)
+		}
+	}
+	sort.SliceStable(stale, func(i, j int) bool {
+		return ApplyKindPriority(stale[i].Kind) > ApplyKindPriority(stale[j].Kind)
+	})
+	return stale
+}
+
+// pruneNamespaceTemplateObjects 删除模板中已移除的资源；资源或资源类型已不存在时记为 absent
+func pruneNamespaceTemplateObjects(ctx context.Context, engine *ApplyEngine, namespace string,
+	stale []models.NamespaceTemplateObject, dryRun bool) []NamespaceTemplatePrunedObject {
+	opts := metav1.DeleteOptions{}
+	if dryRun {
+		opts.DryRun = []string{metav1.DryRunAll}
+	}
+	pruned := make([]NamespaceTemplatePrunedObject, 0, len(stale))
+	for _, obj := range stale {
+		item := NamespaceTemplatePrunedObject{NamespaceTemplateObject: obj, Status: NamespaceTemplatePruneDeleted}
+		info, err := engine.ResolveKind(schema.FromAPIVersionAndKind(obj.APIVersion, obj.Kind))
+		if err == nil {
+			err = ResourceInterface(engine.dynamic, info, namespace).Delete(ctx, obj.Name, opts)
+		}
+		switch {
+		case err == nil:
+		case apierrors.IsNotFound(err) || errors.Is(err, ErrUnknownResourceType):
+			item.Status = NamespaceTemplatePruneAbsent
+		default:
+			item.Status = NamespaceTemplatePruneFailed
+			item.Error = err.Error()
+		}
+		pruned = append(pruned, item)
+	}
+	return pruned
+}
+
+// prepareNamespaceProvision 渲染模板并解析资源，校验模板只包含命名空间级资源
+// 返回的 nsObj 为模板中声明的 Namespace（未声明时为空对象），objs 为其余资源。
+func prepareNamespaceProvision(engine *ApplyEngine, tpl *models.NamespaceTemplate, req *NamespaceProvisionRequest) (
+	*NamespaceProvisionResult, *unstructured.Unstructured, []*unstructured.Unstructured, error) {
+	if errs := validation.IsDNS1123Label(req.Namespace); len(errs) > 0 {
+		return nil, nil, nil, fmt.Errorf("%w: 命名空间名称无效: %s", ErrInvalidWorkloadOperation, strings.Join(errs, "; "))
+	}
+	rendered, values, err := RenderNamespaceTemplate(tpl, req.Namespace, req.Values)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	nsObj, objs, err := ParseNamespaceTemplateObjects(rendered, req.Namespace)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	for _, obj := range objs {
+		info, err := engine.ResolveKind(obj.GroupVersionKind())
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		if !info.Namespaced {
+			return nil, nil, nil, fmt.Errorf("%w: 模板只能包含命名空间级资源，%s/%s 为集群级资源",
+				ErrInvalidWorkloadOperation, obj.GetKind(), obj.GetName())
+		}
+	}
+	result := &NamespaceProvisionResult{
+		Namespace:       req.Namespace,
+		Template:        tpl.Name,
+		TemplateVersion: tpl.Version,
+		Values:          values,
+		DryRun:          req.DryRun,
+	}
+	return result, nsObj, objs, nil
+}
+
+// RenderNamespaceTemplate 将变量值代入模板内容，返回渲染结果与实际使用的变量值（含默认值）
+// 内置变量 ${namespace} 替换为目标命名空间；引用未声明的变量、缺少必填变量或变量值包含换行时返回错误。
+func RenderNamespaceTemplate(tpl *models.NamespaceTemplate, namespace string, values map[string]string) (string, map[string]string, error) {
+	effective := make(map[string]string)
+	var problems []string
+	for _, v := range tpl.GetVariables() {
+		value, ok := values[v.Name]
+		if !ok || value == "" {
+			value = v.Default
+		}
+		if value == "" && v.Required {
+			problems = append(problems, fmt.Sprintf("缺少必填变量 %s", v.Name))
+			continue
+		}
+		if strings.ContainsAny(value, "\r\n") {
+			problems = append(problems, fmt.Sprintf("变量 %s 的值不能包含换行", v.Name))
+			continue
+		}
+		effective[v.Name] = value
+	}
+
+	undefined := map[string]bool{}
+	rendered := templatePlaceholderPattern.ReplaceAllStringFunc(tpl.Content, func(match string) string {
+		sub := templatePlaceholderPattern.FindStringSubmatch(match)
+		if sub[1] != "" {
+			return match[1:]
+		}
+		name := sub[2]
+		if name == namespaceTemplateBuiltinVariable {
+			return namespace
+		}
+		value, ok := effective[name]
+		if !ok && !declaredVariable(tpl, name) {
+			undefined[name] = true
+		}
+		return value
+	})
+	for name := range undefined {
+		problems = append(problems, fmt.Sprintf("模板引用了未声明的变量 %s", name))
+	}
+	if len(problems) > 0 {
+		sort.Strings(problems)
+		return "", nil, fmt.Errorf("%w: %s", ErrInvalidWorkloadOperation, strings.Join(problems, "; "))
+	}
+	return rendered, effective, nil
+}
+
+// ParseNamespaceTemplateObjects 解析渲染后的模板，所有资源归属目标命名空间
+// 资源声明了其他命名空间时返回错误；模板中的 Namespace 对象单独返回，用于设置命名空间的标签与注解。
+func ParseNamespaceTemplateObjects(rendered, namespace string) (*unstructured.Unstructured, []*unstructured.Unstructured, error) {
+	all, err := ParseMultiDocYAML(rendered)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", ErrInvalidWorkloadOperation, err)
+	}
+	nsObj := &unstructured.Unstructured{}
+	nsObj.SetAPIVersion("v1")
+	nsObj.SetKind("Namespace")
+	nsObj.SetName(namespace)
+
+	objs := make([]*unstructured.Unstructured, 0, len(all))
+	for _, obj := range all {
+		if obj.GetKind() == "Namespace" && obj.GetAPIVersion() == "v1" {
+			if obj.GetName() != namespace {
+				return nil, nil, fmt.Errorf("%w: 模板中的 Namespace 名称应为 ${namespace}，实际为 %s", ErrInvalidWorkloadOperation, obj.GetName())
+			}
+			nsObj.SetLabels(mergeStringMaps(nsObj.GetLabels(), obj.GetLabels()))
+			nsObj.SetAnnotations(mergeStringMaps(nsObj.GetAnnotations(), obj.GetAnnotations()))
+			continue
+		}
+		if ns := obj.GetNamespace(); ns != "" && ns != namespace {
+			return nil, nil, fmt.Errorf("%w: %s/%s 声明的命名空间 %s 与目标命名空间不一致",
+				ErrInvalidWorkloadOperation, obj.GetKind(), obj.GetName(), ns)
+		}
+		obj.SetNamespace(namespace)
+		objs = append(objs, obj)
+	}
+	return nsObj, objs, nil
+}
+
+// ToNamespaceTemplateInfo 转换为模板信息
+func ToNamespaceTemplateInfo(tpl *models.NamespaceTemplate) NamespaceTemplateInfo {
+	vars := tpl.GetVariables()
+	if vars == nil {
+		vars = []models.NamespaceTemplateVariable{}
+	}
+	return NamespaceTemplateInfo{
+		ID:          tpl.ID,
+		Name:        tpl.Name,
+		Description: tpl.Description,
+		Content:     tpl.Content,
+		Variables:   vars,
+		Version:     tpl.Version,
+		CreatedBy:   tpl.CreatedBy,
+		UpdatedBy:   tpl.UpdatedBy,
+		CreatedAt:   tpl.CreatedAt,
+		UpdatedAt:   tpl.UpdatedAt,
+	}
+}
+
+// validateNamespaceTemplate 校验变量定义，并以示例值渲染、解析模板内容
+func validateNamespaceTemplate(req *NamespaceTemplateRequest) error {
+	if strings.TrimSpace(req.Name) == "" {
+		return fmt.Errorf("%w: 模板名称不能为空", ErrInvalidWorkloadOperation)
+	}
+	seen := map[string]bool{}
+	samples := map[string]string{}
+	for _, v := range req.Variables {
+		if !templateVariableNamePattern.MatchString(v.Name) {
+			return fmt.Errorf("%w: 变量名 %q 无效，只能包含字母、数字和下划线且不以数字开头", ErrInvalidWorkloadOperation, v.Name)
+		}
+		if v.Name == namespaceTemplateBuiltinVariable {
+			return fmt.Errorf("%w: 变量名 %s 为内置变量", ErrInvalidWorkloadOperation, v.Name)
+		}
+		if seen[v.Name] {
+			return fmt.Errorf("%w: 变量 %s 重复定义", ErrInvalidWorkloadOperation, v.Name)
+		}
+		seen[v.Name] = true
+		samples[v.Name] = "sample"
+	}
+
+	tpl := &models.NamespaceTemplate{Content: req.Content}
+	if err := tpl.SetVariables(req.Variables); err != nil {
+		return err
+	}
+	rendered, _, err := RenderNamespaceTemplate(tpl, "template-preview", samples)
+	if err != nil {
+		return err
+	}
+	_, _, err = ParseNamespaceTemplateObjects(rendered, "template-preview")
+	return err
+}
+
+func declaredVariable(tpl *models.NamespaceTemplate, name string) bool {
+	for _, v := range tpl.GetVariables() {
+		if v.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func namespaceTemplateAnnotations(tpl *models.NamespaceTemplate) map[string]string {
+	return map[string]string{
+		NamespaceTemplateAnnotation:        tpl.Name,
+		NamespaceTemplateVersionAnnotation: strconv.Itoa(tpl.Version),
+	}
+}
+
+// mergeStringMaps 合并多个 map，后者覆盖前者；全部为空时返回 nil
+func mergeStringMaps(maps ...map[string]string) map[string]string {
+	var result map[string]string
+	for _, m := range maps {
+		for k, v := range m {
+			if result == nil {
+				result = make(map[string]string)
+			}
+			result[k] = v
+		}
+	}
+	return result
+}
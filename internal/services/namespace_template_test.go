@@ -0,0 +1,297 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/clay-wangzhi/KubePolaris/internal/models"
+
+	"github.com/glebarez/sqlite"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/discovery/cached/memory"
+	"k8s.io/client-go/dynamic"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+const teamTemplateContent = `
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: ${namespace}
+  labels:
+    team: ${team}
+    cost-center: "${cost_center}"
+---
+apiVersion: v1
+kind: ResourceQuota
+metadata:
+  name: compute
+spec:
+  hard:
+    requests.cpu: "${cpu}"
+---
+apiVersion: networking.k8s.io/v1
+kind: NetworkPolicy
+metadata:
+  name: default-deny
+  namespace: ${namespace}
+  annotations:
+    note: "$${literal}"
+spec:
+  podSelector: {}
+  policyTypes: ["Ingress"]
+`
+
+func teamTemplate(t *testing.T) *models.NamespaceTemplate {
+	tpl := &models.NamespaceTemplate{Name: "team", Content: teamTemplateContent, Version: 2}
+	require.NoError(t, tpl.SetVariables([]models.NamespaceTemplateVariable{
+		{Name: "team", Required: true},
+		{Name: "cost_center", Required: true},
+		{Name: "cpu", Default: "4"},
+	}))
+	return tpl
+}
+
+func TestRenderNamespaceTemplate(t *testing.T) {
+	tpl := teamTemplate(t)
+
+	rendered, values, err := RenderNamespaceTemplate(tpl, "payments", map[string]string{"team": "pay", "cost_center": "cc-01"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"team": "pay", "cost_center": "cc-01", "cpu": "4"}, values)
+	assert.Contains(t, rendered, `note: "${literal}"`)
+
+	nsObj, objs, err := ParseNamespaceTemplateObjects(rendered, "payments")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"team": "pay", "cost-center": "cc-01"}, nsObj.GetLabels())
+	require.Len(t, objs, 2)
+	for _, obj := range objs {
+		assert.Equal(t, "payments", obj.GetNamespace())
+	}
+
+	_, _, err = RenderNamespaceTemplate(tpl, "payments", map[string]string{"team": "pay\nevil: true"})
+	require.Error(t, err)
+	assert.ErrorIs(t, err, ErrInvalidWorkloadOperation)
+	assert.Contains(t, err.Error(), "缺少必填变量 cost_center")
+	assert.Contains(t, err.Error(), "变量 team 的值不能包含换行")
+
+	undeclared := &models.NamespaceTemplate{Content: "metadata:\n  name: ${owner}\n"}
+	_, _, err = RenderNamespaceTemplate(undeclared, "payments", nil)
+	assert.ErrorContains(t, err, "未声明的变量 owner")
+}
+
+func TestParseNamespaceTemplateObjectsRejectsOtherNamespace(t *testing.T) {
+	_, _, err := ParseNamespaceTemplateObjects("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: a\n  namespace: kube-system\n", "payments")
+	assert.ErrorIs(t, err, ErrInvalidWorkloadOperation)
+
+	_, _, err = ParseNamespaceTemplateObjects("apiVersion: v1\nkind: Namespace\nmetadata:\n  name: other\n", "payments")
+	assert.ErrorIs(t, err, ErrInvalidWorkloadOperation)
+}
+
+func TestNamespaceTemplateServiceVersioning(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	require.NoError(t, err)
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	sqlDB.SetMaxOpenConns(1)
+	require.NoError(t, db.AutoMigrate(&models.NamespaceTemplate{}, &models.NamespaceTemplateBinding{}))
+	svc := NewNamespaceTemplateService(db)
+
+	req := &NamespaceTemplateRequest{
+		Name:      "team",
+		Content:   teamTemplateContent,
+		Variables: teamTemplate(t).GetVariables(),
+	}
+	tpl, err := svc.Create(req, "admin")
+	require.NoError(t, err)
+	assert.Equal(t, 1, tpl.Version)
+
+	_, err = svc.Create(req, "admin")
+	assert.ErrorIs(t, err, ErrNamespaceTemplateExists)
+
+	// 仅修改描述不产生新版本
+	req.Description = "团队标准命名空间"
+	tpl, err = svc.Update(tpl.ID, req, "admin")
+	require.NoError(t, err)
+	assert.Equal(t, 1, tpl.Version)
+
+	req.Variables[2].Default = "8"
+	tpl, err = svc.Update(tpl.ID, req, "ops")
+	require.NoError(t, err)
+	assert.Equal(t, 2, tpl.Version)
+	assert.Equal(t, "ops", tpl.UpdatedBy)
+
+	// 模板引用未声明的变量时拒绝保存
+	_, err = svc.Update(tpl.ID, &NamespaceTemplateRequest{Name: "team", Content: "metadata:\n  name: ${owner}\n"}, "admin")
+	assert.ErrorIs(t, err, ErrInvalidWorkloadOperation)
+
+	// 绑定记录旧版本：标记为有新版本，且模板不可删除
+	result := &NamespaceProvisionResult{Namespace: "payments", Values: map[string]string{"team": "pay"}}
+	old := *tpl
+	old.Version = 1
+	require.NoError(t, svc.saveBinding(1, &old, result, nil, RevisionOperator{UserID: 1, Username: "admin"}))
+
+	binding, err := svc.GetBinding(1, "payments")
+	require.NoError(t, err)
+	require.NotNil(t, binding)
+	assert.Equal(t, 1, binding.TemplateVersion)
+	assert.Equal(t, 2, binding.LatestVersion)
+	assert.True(t, binding.Outdated)
+	assert.Equal(t, "pay", binding.Values["team"])
+
+	items, err := svc.List()
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+	assert.Equal(t, int64(1), items[0].Namespaces)
+
+	assert.ErrorIs(t, svc.Delete(tpl.ID), ErrNamespaceTemplateInUse)
+	require.NoError(t, svc.DeleteBinding(1, "payments"))
+	require.NoError(t, svc.Delete(tpl.ID))
+	_, err = svc.Get(tpl.ID)
+	assert.ErrorIs(t, err, ErrNamespaceTemplateNotFound)
+}
+
+func TestApplyToNamespacePrunesRemovedObjects(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	require.NoError(t, err)
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	sqlDB.SetMaxOpenConns(1)
+	require.NoError(t, db.AutoMigrate(&models.NamespaceTemplate{}, &models.NamespaceTemplateBinding{}))
+	svc := NewNamespaceTemplateService(db)
+
+	namespaces := schema.GroupVersionResource{Version: "v1", Resource: "namespaces"}
+	configMaps := schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}
+	dyn := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+		namespaces: "NamespaceList",
+		configMaps: "ConfigMapList",
+	})
+	// 模拟服务端应用：写入 tracker
+	dyn.PrependReactor("patch", "*", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		patch := action.(k8stesting.PatchAction)
+		obj := &unstructured.Unstructured{}
+		if err := obj.UnmarshalJSON(patch.GetPatch()); err != nil {
+			return true, nil, err
+		}
+		if _, err := dyn.Tracker().Get(patch.GetResource(), patch.GetNamespace(), patch.GetName()); err == nil {
+			return true, obj, dyn.Tracker().Update(patch.GetResource(), obj, patch.GetNamespace())
+		}
+		return true, obj, dyn.Tracker().Create(patch.GetResource(), obj, patch.GetNamespace())
+	})
+	ns := &unstructured.Unstructured{}
+	ns.SetAPIVersion("v1")
+	ns.SetKind("Namespace")
+	ns.SetName("payments")
+	require.NoError(t, dyn.Tracker().Create(namespaces, ns, ""))
+	engine := NewApplyEngineWithClients(dryRunDynamicClient{dyn}, memory.NewMemCacheClient(newFakeDiscovery()))
+
+	configMap := func(name string) string {
+		return "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: " + name + "\ndata:\n  team: ${team}\n"
+	}
+	tplReq := &NamespaceTemplateRequest{
+		Name:      "team",
+		Content:   configMap("settings") + "---\n" + configMap("legacy"),
+		Variables: []models.NamespaceTemplateVariable{{Name: "team", Required: true}},
+	}
+	tpl, err := svc.Create(tplReq, "admin")
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	operator := RevisionOperator{UserID: 1, Username: "admin"}
+	req := &NamespaceProvisionRequest{Namespace: "payments", Values: map[string]string{"team": "pay"}}
+	result, err := svc.ApplyToNamespace(ctx, engine, 1, tpl, req, operator)
+	require.NoError(t, err)
+	require.False(t, result.Failed())
+	assert.Empty(t, result.Pruned)
+
+	// 新版本移除 legacy
+	tplReq.Content = configMap("settings")
+	tpl, err = svc.Update(tpl.ID, tplReq, "admin")
+	require.NoError(t, err)
+	require.Equal(t, 2, tpl.Version)
+
+	// 预检：报告将删除的资源，但不实际删除、不更新绑定
+	dryRun := *req
+	dryRun.DryRun = true
+	result, err = svc.ApplyToNamespace(ctx, engine, 1, tpl, &dryRun, operator)
+	require.NoError(t, err)
+	require.Len(t, result.Pruned, 1)
+	assert.Equal(t, "legacy", result.Pruned[0].Name)
+	assert.Equal(t, NamespaceTemplatePruneDeleted, result.Pruned[0].Status)
+	_, err = dyn.Tracker().Get(configMaps, "payments", "legacy")
+	require.NoError(t, err)
+	binding, err := svc.GetBinding(1, "payments")
+	require.NoError(t, err)
+	assert.Equal(t, 1, binding.TemplateVersion)
+
+	result, err = svc.ApplyToNamespace(ctx, engine, 1, tpl, req, operator)
+	require.NoError(t, err)
+	require.Len(t, result.Pruned, 1)
+	assert.Equal(t, NamespaceTemplatePruneDeleted, result.Pruned[0].Status)
+	assert.Empty(t, result.PruneErrors())
+	_, err = dyn.Tracker().Get(configMaps, "payments", "legacy")
+	assert.True(t, apierrors.IsNotFound(err))
+	_, err = dyn.Tracker().Get(configMaps, "payments", "settings")
+	require.NoError(t, err)
+
+	binding, err = svc.GetBinding(1, "payments")
+	require.NoError(t, err)
+	assert.Equal(t, 2, binding.TemplateVersion)
+	assert.Equal(t, []models.NamespaceTemplateObject{{APIVersion: "v1", Kind: "ConfigMap", Name: "settings"}}, binding.GetObjects())
+}
+
+// dryRunDynamicClient 模拟服务端 dry-run（fake 动态客户端不向 reactor 传递请求选项）：
+// dry-run 的应用直接返回提交的对象，dry-run 的删除仅校验资源存在
+type dryRunDynamicClient struct {
+	dynamic.Interface
+}
+
+func (c dryRunDynamicClient) Resource(gvr schema.GroupVersionResource) dynamic.NamespaceableResourceInterface {
+	return dryRunResourceClient{NamespaceableResourceInterface: c.Interface.Resource(gvr)}
+}
+
+type dryRunResourceClient struct {
+	dynamic.NamespaceableResourceInterface
+	namespaced dynamic.ResourceInterface
+}
+
+func (c dryRunResourceClient) Namespace(ns string) dynamic.ResourceInterface {
+	c.namespaced = c.NamespaceableResourceInterface.Namespace(ns)
+	return c
+}
+
+func (c dryRunResourceClient) target() dynamic.ResourceInterface {
+	if c.namespaced != nil {
+		return c.namespaced
+	}
+	return c.NamespaceableResourceInterface
+}
+
+func (c dryRunResourceClient) Get(ctx context.Context, name string, opts metav1.GetOptions, subresources ...string) (*unstructured.Unstructured, error) {
+	return c.target().Get(ctx, name, opts, subresources...)
+}
+
+func (c dryRunResourceClient) Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts metav1.PatchOptions, subresources ...string) (*unstructured.Unstructured, error) {
+	if len(opts.DryRun) == 0 {
+		return c.target().Patch(ctx, name, pt, data, opts, subresources...)
+	}
+	obj := &unstructured.Unstructured{}
+	return obj, obj.UnmarshalJSON(data)
+}
+
+func (c dryRunResourceClient) Delete(ctx context.Context, name string, opts metav1.DeleteOptions, subresources ...string) error {
+	if len(opts.DryRun) == 0 {
+		return c.target().Delete(ctx, name, opts, subresources...)
+	}
+	_, err := c.target().Get(ctx, name, metav1.GetOptions{})
+	return err
+}
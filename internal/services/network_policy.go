@@ -0,0 +1,518 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/kubernetes"
+)
+
+// namespaceNameLabel 命名空间自动携带的名称标签（1.21+），命名空间对象不可用时用于匹配 namespaceSelector
+const namespaceNameLabel = "kubernetes.io/metadata.name"
+
+// NetworkPolicyInfo NetworkPolicy 列表项
+type NetworkPolicyInfo struct {
+	Name        string            `json:"name"`
+	Namespace   string            `json:"namespace"`
+	PodSelector string            `json:"podSelector"` // 为空表示选中命名空间内全部 Pod
+	PolicyTypes []string          `json:"policyTypes"`
+	IngressRule int               `json:"ingressRules"`
+	EgressRule  int               `json:"egressRules"`
+	Labels      map[string]string `json:"labels"`
+	CreatedAt   time.Time         `json:"createdAt"`
+}
+
+// NetworkPolicyRequest 创建/更新 NetworkPolicy 请求
+type NetworkPolicyRequest struct {
+	Name        string                         `json:"name"`
+	Namespace   string                         `json:"namespace"`
+	Labels      map[string]string              `json:"labels"`
+	Annotations map[string]string              `json:"annotations"`
+	Spec        networkingv1.NetworkPolicySpec `json:"spec"`
+}
+
+// ToNetworkPolicyInfo 转换为列表项
+func ToNetworkPolicyInfo(np *networkingv1.NetworkPolicy) NetworkPolicyInfo {
+	types := make([]string, 0, 2)
+	for _, t := range effectivePolicyTypes(np) {
+		types = append(types, string(t))
+	}
+	return NetworkPolicyInfo{
+		Name:        np.Name,
+		Namespace:   np.Namespace,
+		PodSelector: metav1.FormatLabelSelector(&np.Spec.PodSelector),
+		PolicyTypes: types,
+		IngressRule: len(np.Spec.Ingress),
+		EgressRule:  len(np.Spec.Egress),
+		Labels:      np.Labels,
+		CreatedAt:   np.CreationTimestamp.Time,
+	}
+}
+
+// validateNetworkPolicySpec 校验策略类型、选择器与 IP 段，避免提交后才由 API Server 拒绝
+func validateNetworkPolicySpec(spec *networkingv1.NetworkPolicySpec) error {
+	var errs []string
+	for _, t := range spec.PolicyTypes {
+		if t != networkingv1.PolicyTypeIngress && t != networkingv1.PolicyTypeEgress {
+			errs = append(errs, fmt.Sprintf("无效的策略类型 %q，可选值: Ingress、Egress", t))
+		}
+	}
+	if _, err := metav1.LabelSelectorAsSelector(&spec.PodSelector); err != nil {
+		errs = append(errs, "podSelector 无效: "+err.Error())
+	}
+	checkPeers := func(direction string, i int, peers []networkingv1.NetworkPolicyPeer) {
+		for j, peer := range peers {
+			prefix := fmt.Sprintf("%s[%d].peer[%d]", direction, i, j)
+			if peer.IPBlock != nil {
+				if peer.PodSelector != nil || peer.NamespaceSelector != nil {
+					errs = append(errs, prefix+": ipBlock 不能与 podSelector/namespaceSelector 同时使用")
+				}
+				if _, _, err := net.ParseCIDR(peer.IPBlock.CIDR); err != nil {
+					errs = append(errs, fmt.Sprintf("%s: 无效的 CIDR %q", prefix, peer.IPBlock.CIDR))
+				}
+				for _, except := range peer.IPBlock.Except {
+					if _, _, err := net.ParseCIDR(except); err != nil {
+						errs = append(errs, fmt.Sprintf("%s: 无效的 except CIDR %q", prefix, except))
+					}
+				}
+			}
+			for _, sel := range []*metav1.LabelSelector{peer.PodSelector, peer.NamespaceSelector} {
+				if sel == nil {
+					continue
+				}
+				if _, err := metav1.LabelSelectorAsSelector(sel); err != nil {
+					errs = append(errs, prefix+": 选择器无效: "+err.Error())
+				}
+			}
+		}
+	}
+	checkPorts := func(direction string, i int, ports []networkingv1.NetworkPolicyPort) {
+		for j, p := range ports {
+			prefix := fmt.Sprintf("%s[%d].ports[%d]", direction, i, j)
+			if p.EndPort != nil && (p.Port == nil || p.Port.Type != intstr.Int || *p.EndPort < p.Port.IntVal) {
+				errs = append(errs, prefix+": endPort 需要配合数字端口使用且不小于 port")
+			}
+		}
+	}
+	for i, rule := range spec.Ingress {
+		checkPeers("ingress", i, rule.From)
+		checkPorts("ingress", i, rule.Ports)
+	}
+	for i, rule := range spec.Egress {
+		checkPeers("egress", i, rule.To)
+		checkPorts("egress", i, rule.Ports)
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("%w: %s", ErrInvalidWorkloadOperation, strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// CreateNetworkPolicy 创建 NetworkPolicy
+func CreateNetworkPolicy(ctx context.Context, clientset kubernetes.Interface, req *NetworkPolicyRequest) (*networkingv1.NetworkPolicy, error) {
+	if req.Name == "" || req.Namespace == "" {
+		return nil, fmt.Errorf("%w: 名称和命名空间不能为空", ErrInvalidWorkloadOperation)
+	}
+	if err := validateNetworkPolicySpec(&req.Spec); err != nil {
+		return nil, err
+	}
+	np := &networkingv1.NetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        req.Name,
+			Namespace:   req.Namespace,
+			Labels:      req.Labels,
+			Annotations: req.Annotations,
+		},
+		Spec: req.Spec,
+	}
+	return clientset.NetworkingV1().NetworkPolicies(req.Namespace).Create(ctx, np, metav1.CreateOptions{})
+}
+
+// UpdateNetworkPolicy 更新 NetworkPolicy 的标签、注解与规则，返回更新前与更新后的对象
+func UpdateNetworkPolicy(ctx context.Context, clientset kubernetes.Interface, namespace, name string, req *NetworkPolicyRequest) (*networkingv1.NetworkPolicy, *networkingv1.NetworkPolicy, error) {
+	if err := validateNetworkPolicySpec(&req.Spec); err != nil {
+		return nil, nil, err
+	}
+	np, err := clientset.NetworkingV1().NetworkPolicies(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, nil, err
+	}
+	prev := np.DeepCopy()
+	np.Labels = req.Labels
+	np.Annotations = req.Annotations
+	np.Spec = req.Spec
+	updated, err := clientset.NetworkingV1().NetworkPolicies(namespace).Update(ctx, np, metav1.UpdateOptions{})
+	if err != nil {
+		return nil, nil, err
+	}
+	return prev, updated, nil
+}
+
+// DeleteNetworkPolicy 删除 NetworkPolicy
+func DeleteNetworkPolicy(ctx context.Context, clientset kubernetes.Interface, namespace, name string) error {
+	return clientset.NetworkingV1().NetworkPolicies(namespace).Delete(ctx, name, metav1.DeleteOptions{})
+}
+
+// NetworkPolicyVerdict 选中某个 Pod 的策略及其是否放行本次访问
+type NetworkPolicyVerdict struct {
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	Allows    bool   `json:"allows"`
+	Draft     bool   `json:"draft,omitempty"` // 来自请求中的草稿策略
+}
+
+// ConnectivityDirection 单个方向（源 Pod 出站或目标 Pod 入站）的评估结果
+type ConnectivityDirection struct {
+	Isolated bool                   `json:"isolated"` // 存在选中该 Pod 且包含该方向的策略
+	Allowed  bool                   `json:"allowed"`
+	Policies []NetworkPolicyVerdict `json:"policies"`
+}
+
+// ConnectivityResult 连通性评估结果
+// 源 Pod 的出站与目标 Pod 的入站均放行时才可访问。
+type ConnectivityResult struct {
+	Allowed bool                  `json:"allowed"`
+	Reason  string                `json:"reason"`
+	Egress  ConnectivityDirection `json:"egress"`
+	Ingress ConnectivityDirection `json:"ingress"`
+}
+
+// ConnectivityInput 连通性评估输入
+type ConnectivityInput struct {
+	Source      *corev1.Pod
+	Destination *corev1.Pod
+	Port        int32
+	Protocol    corev1.Protocol // 为空时为 TCP
+	Policies    []*networkingv1.NetworkPolicy
+	Namespaces  []*corev1.Namespace
+	Drafts      map[string]bool // 草稿策略，key 为 namespace/name
+}
+
+// EvaluateConnectivity 根据所有选中源/目标 Pod 的 NetworkPolicy 计算源 Pod 能否访问目标 Pod 的指定端口
+// 评估遵循 Kubernetes NetworkPolicy 语义：未被任何策略选中的方向默认放行，被选中后仅放行规则允许的流量（规则取并集）。
+// hostNetwork Pod 与 CNI 插件的扩展语义不在评估范围内。
+func EvaluateConnectivity(in ConnectivityInput) *ConnectivityResult {
+	protocol := in.Protocol
+	if protocol == "" {
+		protocol = corev1.ProtocolTCP
+	}
+	nsLabels := namespaceLabelIndex(in.Namespaces)
+
+	result := &ConnectivityResult{
+		Egress:  ConnectivityDirection{Allowed: true, Policies: []NetworkPolicyVerdict{}},
+		Ingress: ConnectivityDirection{Allowed: true, Policies: []NetworkPolicyVerdict{}},
+	}
+
+	for _, np := range sortedPolicies(in.Policies) {
+		draft := in.Drafts[np.Namespace+"/"+np.Name]
+		// 出站：源 Pod 所在命名空间中选中源 Pod 的 Egress 策略
+		if np.Namespace == in.Source.Namespace && hasPolicyType(np, networkingv1.PolicyTypeEgress) && selectsPod(&np.Spec.PodSelector, in.Source) {
+			allows := false
+			for _, rule := range np.Spec.Egress {
+				if peersMatch(rule.To, np.Namespace, in.Destination, nsLabels) && portsMatch(rule.Ports, in.Port, protocol, in.Destination) {
+					allows = true
+					break
+				}
+			}
+			result.Egress.Isolated = true
+			result.Egress.Policies = append(result.Egress.Policies, NetworkPolicyVerdict{Namespace: np.Namespace, Name: np.Name, Allows: allows, Draft: draft})
+		}
+		// 入站：目标 Pod 所在命名空间中选中目标 Pod 的 Ingress 策略
+		if np.Namespace == in.Destination.Namespace && hasPolicyType(np, networkingv1.PolicyTypeIngress) && selectsPod(&np.Spec.PodSelector, in.Destination) {
+			allows := false
+			for _, rule := range np.Spec.Ingress {
+				if peersMatch(rule.From, np.Namespace, in.Source, nsLabels) && portsMatch(rule.Ports, in.Port, protocol, in.Destination) {
+					allows = true
+					break
+				}
+			}
+			result.Ingress.Isolated = true
+			result.Ingress.Policies = append(result.Ingress.Policies, NetworkPolicyVerdict{Namespace: np.Namespace, Name: np.Name, Allows: allows, Draft: draft})
+		}
+	}
+
+	result.Egress.Allowed = directionAllowed(&result.Egress)
+	result.Ingress.Allowed = directionAllowed(&result.Ingress)
+	result.Allowed = result.Egress.Allowed && result.Ingress.Allowed
+
+	target := fmt.Sprintf("%s/%s:%d/%s", in.Destination.Namespace, in.Destination.Name, in.Port, protocol)
+	switch {
+	case result.Allowed && !result.Egress.Isolated && !result.Ingress.Isolated:
+		result.Reason = "源 Pod 出站与目标 Pod 入站均未被策略隔离，默认放行"
+	case result.Allowed:
+		result.Reason = fmt.Sprintf("策略允许访问 %s", target)
+	case !result.Egress.Allowed && !result.Ingress.Allowed:
+		result.Reason = fmt.Sprintf("源 Pod 的出站策略与目标 Pod 的入站策略均不允许访问 %s", target)
+	case !result.Egress.Allowed:
+		result.Reason = fmt.Sprintf("源 Pod 的出站策略不允许访问 %s", target)
+	default:
+		result.Reason = fmt.Sprintf("目标 Pod 的入站策略不允许来自 %s/%s 的访问", in.Source.Namespace, in.Source.Name)
+	}
+	return result
+}
+
+// PodIsolation 单个 Pod 的隔离状态
+type PodIsolation struct {
+	Name            string            `json:"name"`
+	Labels          map[string]string `json:"labels"`
+	IngressIsolated bool              `json:"ingressIsolated"`
+	EgressIsolated  bool              `json:"egressIsolated"`
+	IngressPolicies []string          `json:"ingressPolicies"`
+	EgressPolicies  []string          `json:"egressPolicies"`
+}
+
+// NamespaceIsolation 命名空间内 Pod 的隔离情况
+type NamespaceIsolation struct {
+	Namespace       string         `json:"namespace"`
+	Policies        int            `json:"policies"`
+	TotalPods       int            `json:"totalPods"`
+	IngressIsolated int            `json:"ingressIsolated"`
+	EgressIsolated  int            `json:"egressIsolated"`
+	Pods            []PodIsolation `json:"pods"`
+}
+
+// BuildNamespaceIsolation 计算命名空间内每个 Pod 是否被入站/出站策略隔离及选中它的策略
+func BuildNamespaceIsolation(namespace string, pods []*corev1.Pod, policies []*networkingv1.NetworkPolicy) *NamespaceIsolation {
+	view := &NamespaceIsolation{Namespace: namespace, Pods: []PodIsolation{}}
+	var nsPolicies []*networkingv1.NetworkPolicy
+	for _, np := range sortedPolicies(policies) {
+		if np.Namespace == namespace {
+			nsPolicies = append(nsPolicies, np)
+		}
+	}
+	view.Policies = len(nsPolicies)
+
+	sorted := make([]*corev1.Pod, 0, len(pods))
+	for _, pod := range pods {
+		if pod.Namespace == namespace && !pod.Spec.HostNetwork {
+			sorted = append(sorted, pod)
+		}
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Name < sorted[j].Name })
+
+	for _, pod := range sorted {
+		iso := PodIsolation{Name: pod.Name, Labels: pod.Labels, IngressPolicies: []string{}, EgressPolicies: []string{}}
+		for _, np := range nsPolicies {
+			if !selectsPod(&np.Spec.PodSelector, pod) {
+				continue
+			}
+			if hasPolicyType(np, networkingv1.PolicyTypeIngress) {
+				iso.IngressPolicies = append(iso.IngressPolicies, np.Name)
+			}
+			if hasPolicyType(np, networkingv1.PolicyTypeEgress) {
+				iso.EgressPolicies = append(iso.EgressPolicies, np.Name)
+			}
+		}
+		iso.IngressIsolated = len(iso.IngressPolicies) > 0
+		iso.EgressIsolated = len(iso.EgressPolicies) > 0
+		if iso.IngressIsolated {
+			view.IngressIsolated++
+		}
+		if iso.EgressIsolated {
+			view.EgressIsolated++
+		}
+		view.Pods = append(view.Pods, iso)
+	}
+	view.TotalPods = len(view.Pods)
+	return view
+}
+
+// MergeDraftPolicies 将草稿策略合并到现有策略中（同名策略以草稿为准），返回合并结果与草稿标记
+func MergeDraftPolicies(existing []*networkingv1.NetworkPolicy, drafts []networkingv1.NetworkPolicy) ([]*networkingv1.NetworkPolicy, map[string]bool, error) {
+	marks := make(map[string]bool, len(drafts))
+	merged := make([]*networkingv1.NetworkPolicy, 0, len(existing)+len(drafts))
+	for i := range drafts {
+		d := &drafts[i]
+		if d.Name == "" || d.Namespace == "" {
+			return nil, nil, fmt.Errorf("%w: 草稿策略的名称和命名空间不能为空", ErrInvalidWorkloadOperation)
+		}
+		if err := validateNetworkPolicySpec(&d.Spec); err != nil {
+			return nil, nil, err
+		}
+		marks[d.Namespace+"/"+d.Name] = true
+		merged = append(merged, d)
+	}
+	for _, np := range existing {
+		if !marks[np.Namespace+"/"+np.Name] {
+			merged = append(merged, np)
+		}
+	}
+	return merged, marks, nil
+}
+
+// effectivePolicyTypes 未指定 policyTypes 时，始终包含 Ingress，存在出站规则时包含 Egress
+func effectivePolicyTypes(np *networkingv1.NetworkPolicy) []networkingv1.PolicyType {
+	if len(np.Spec.PolicyTypes) > 0 {
+		return np.Spec.PolicyTypes
+	}
+	types := []networkingv1.PolicyType{networkingv1.PolicyTypeIngress}
+	if len(np.Spec.Egress) > 0 {
+		types = append(types, networkingv1.PolicyTypeEgress)
+	}
+	return types
+}
+
+func hasPolicyType(np *networkingv1.NetworkPolicy, t networkingv1.PolicyType) bool {
+	for _, pt := range effectivePolicyTypes(np) {
+		if pt == t {
+			return true
+		}
+	}
+	return false
+}
+
+func directionAllowed(d *ConnectivityDirection) bool {
+	if !d.Isolated {
+		return true
+	}
+	for _, p := range d.Policies {
+		if p.Allows {
+			return true
+		}
+	}
+	return false
+}
+
+// selectsPod 选择器是否选中 Pod，选择器无效时视为不选中
+func selectsPod(selector *metav1.LabelSelector, pod *corev1.Pod) bool {
+	sel, err := metav1.LabelSelectorAsSelector(selector)
+	if err != nil {
+		return false
+	}
+	return sel.Matches(labels.Set(pod.Labels))
+}
+
+// peersMatch 规则的 from/to 是否包含对端 Pod，列表为空表示匹配所有对端
+func peersMatch(peers []networkingv1.NetworkPolicyPeer, policyNamespace string, pod *corev1.Pod, nsLabels map[string]labels.Set) bool {
+	if len(peers) == 0 {
+		return true
+	}
+	for _, peer := range peers {
+		if peerMatches(peer, policyNamespace, pod, nsLabels) {
+			return true
+		}
+	}
+	return false
+}
+
+func peerMatches(peer networkingv1.NetworkPolicyPeer, policyNamespace string, pod *corev1.Pod, nsLabels map[string]labels.Set) bool {
+	if peer.IPBlock != nil {
+		return ipBlockContains(peer.IPBlock, pod.Status.PodIP)
+	}
+	if peer.NamespaceSelector != nil {
+		sel, err := metav1.LabelSelectorAsSelector(peer.NamespaceSelector)
+		if err != nil || !sel.Matches(namespaceLabels(pod.Namespace, nsLabels)) {
+			return false
+		}
+	} else if pod.Namespace != policyNamespace {
+		// 仅指定 podSelector 时只匹配策略所在命名空间的 Pod
+		return false
+	}
+	if peer.PodSelector != nil {
+		return selectsPod(peer.PodSelector, pod)
+	}
+	return true
+}
+
+func ipBlockContains(block *networkingv1.IPBlock, ip string) bool {
+	addr := net.ParseIP(ip)
+	if addr == nil {
+		return false
+	}
+	_, cidr, err := net.ParseCIDR(block.CIDR)
+	if err != nil || !cidr.Contains(addr) {
+		return false
+	}
+	for _, except := range block.Except {
+		if _, ex, err := net.ParseCIDR(except); err == nil && ex.Contains(addr) {
+			return false
+		}
+	}
+	return true
+}
+
+// portsMatch 规则的端口是否包含目标端口，列表为空表示匹配所有端口；命名端口按目标 Pod 的容器端口解析
+func portsMatch(ports []networkingv1.NetworkPolicyPort, port int32, protocol corev1.Protocol, dst *corev1.Pod) bool {
+	if len(ports) == 0 {
+		return true
+	}
+	for _, p := range ports {
+		proto := corev1.ProtocolTCP
+		if p.Protocol != nil {
+			proto = *p.Protocol
+		}
+		if proto != protocol {
+			continue
+		}
+		if p.Port == nil {
+			return true
+		}
+		if p.Port.Type == intstr.String {
+			if namedPortMatches(dst, p.Port.StrVal, port, protocol) {
+				return true
+			}
+			continue
+		}
+		if p.EndPort != nil {
+			if port >= p.Port.IntVal && port <= *p.EndPort {
+				return true
+			}
+			continue
+		}
+		if port == p.Port.IntVal {
+			return true
+		}
+	}
+	return false
+}
+
+func namedPortMatches(pod *corev1.Pod, name string, port int32, protocol corev1.Protocol) bool {
+	for _, c := range pod.Spec.Containers {
+		for _, cp := range c.Ports {
+			proto := cp.Protocol
+			if proto == "" {
+				proto = corev1.ProtocolTCP
+			}
+			if cp.Name == name && cp.ContainerPort == port && proto == protocol {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func namespaceLabelIndex(namespaces []*corev1.Namespace) map[string]labels.Set {
+	index := make(map[string]labels.Set, len(namespaces))
+	for _, ns := range namespaces {
+		index[ns.Name] = labels.Set(ns.Labels)
+	}
+	return index
+}
+
+func namespaceLabels(name string, index map[string]labels.Set) labels.Set {
+	if set, ok := index[name]; ok {
+		return set
+	}
+	return labels.Set{namespaceNameLabel: name}
+}
+
+func sortedPolicies(policies []*networkingv1.NetworkPolicy) []*networkingv1.NetworkPolicy {
+	sorted := make([]*networkingv1.NetworkPolicy, len(policies))
+	copy(sorted, policies)
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].Namespace != sorted[j].Namespace {
+			return sorted[i].Namespace < sorted[j].Namespace
+		}
+		return sorted[i].Name < sorted[j].Name
+	})
+	return sorted
+}
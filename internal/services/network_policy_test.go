@@ -0,0 +1,148 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+func netpolTestPod(namespace, name string, labels map[string]string, ip string) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace, Labels: labels},
+		Spec: corev1.PodSpec{Containers: []corev1.Container{{
+			Name:  "app",
+			Ports: []corev1.ContainerPort{{Name: "http", ContainerPort: 8080}},
+		}}},
+		Status: corev1.PodStatus{PodIP: ip},
+	}
+}
+
+func netpolPort(port intstr.IntOrString) []networkingv1.NetworkPolicyPort {
+	return []networkingv1.NetworkPolicyPort{{Port: &port}}
+}
+
+func TestEvaluateConnectivity(t *testing.T) {
+	web := netpolTestPod("shop", "web", map[string]string{"app": "web"}, "10.0.0.10")
+	api := netpolTestPod("shop", "api", map[string]string{"app": "api"}, "10.0.0.20")
+	monitor := netpolTestPod("ops", "prometheus", map[string]string{"app": "prometheus"}, "10.0.1.5")
+	namespaces := []*corev1.Namespace{
+		{ObjectMeta: metav1.ObjectMeta{Name: "shop", Labels: map[string]string{"team": "shop"}}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "ops", Labels: map[string]string{"team": "ops"}}},
+	}
+
+	denyAll := &networkingv1.NetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "default-deny", Namespace: "shop"},
+		Spec:       networkingv1.NetworkPolicySpec{PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeIngress}},
+	}
+	allowWeb := &networkingv1.NetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "api-from-web", Namespace: "shop"},
+		Spec: networkingv1.NetworkPolicySpec{
+			PodSelector: metav1.LabelSelector{MatchLabels: map[string]string{"app": "api"}},
+			Ingress: []networkingv1.NetworkPolicyIngressRule{{
+				From:  []networkingv1.NetworkPolicyPeer{{PodSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}}}},
+				Ports: netpolPort(intstr.FromInt(8080)),
+			}},
+		},
+	}
+	allowMonitoring := &networkingv1.NetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "api-from-ops", Namespace: "shop"},
+		Spec: networkingv1.NetworkPolicySpec{
+			PodSelector: metav1.LabelSelector{MatchLabels: map[string]string{"app": "api"}},
+			Ingress: []networkingv1.NetworkPolicyIngressRule{{
+				From:  []networkingv1.NetworkPolicyPeer{{NamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"team": "ops"}}}},
+				Ports: netpolPort(intstr.FromString("http")),
+			}},
+		},
+	}
+	policies := []*networkingv1.NetworkPolicy{denyAll, allowWeb, allowMonitoring}
+
+	tests := []struct {
+		name     string
+		src, dst *corev1.Pod
+		port     int32
+		policies []*networkingv1.NetworkPolicy
+		allowed  bool
+	}{
+		{"无策略默认放行", web, api, 9090, nil, true},
+		{"默认拒绝入站", web, api, 9090, []*networkingv1.NetworkPolicy{denyAll}, false},
+		{"同命名空间按标签放行", web, api, 8080, policies, true},
+		{"端口不匹配", web, api, 9090, policies, false},
+		{"跨命名空间按命名端口放行", monitor, api, 8080, policies, true},
+		{"未被选中的来源", api, web, 8080, policies, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := EvaluateConnectivity(ConnectivityInput{
+				Source: tt.src, Destination: tt.dst, Port: tt.port,
+				Policies: tt.policies, Namespaces: namespaces,
+			})
+			assert.Equal(t, tt.allowed, result.Allowed, result.Reason)
+			assert.False(t, result.Egress.Isolated)
+		})
+	}
+
+	// 草稿策略：禁止 web 出站到 10.0.0.0/24 之外
+	draft := networkingv1.NetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "web-egress", Namespace: "shop"},
+		Spec: networkingv1.NetworkPolicySpec{
+			PodSelector: metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}},
+			PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeEgress},
+			Egress: []networkingv1.NetworkPolicyEgressRule{{
+				To: []networkingv1.NetworkPolicyPeer{{IPBlock: &networkingv1.IPBlock{CIDR: "10.0.0.0/24"}}},
+			}},
+		},
+	}
+	merged, drafts, err := MergeDraftPolicies(policies, []networkingv1.NetworkPolicy{draft})
+	require.NoError(t, err)
+	result := EvaluateConnectivity(ConnectivityInput{Source: web, Destination: monitor, Port: 9090, Policies: merged, Namespaces: namespaces, Drafts: drafts})
+	assert.False(t, result.Allowed)
+	assert.False(t, result.Egress.Allowed)
+	require.Len(t, result.Egress.Policies, 1)
+	assert.True(t, result.Egress.Policies[0].Draft)
+
+	result = EvaluateConnectivity(ConnectivityInput{Source: web, Destination: api, Port: 8080, Policies: merged, Namespaces: namespaces, Drafts: drafts})
+	assert.True(t, result.Allowed, result.Reason)
+}
+
+func TestBuildNamespaceIsolation(t *testing.T) {
+	pods := []*corev1.Pod{
+		netpolTestPod("shop", "web", map[string]string{"app": "web"}, ""),
+		netpolTestPod("shop", "api", map[string]string{"app": "api"}, ""),
+	}
+	policies := []*networkingv1.NetworkPolicy{{
+		ObjectMeta: metav1.ObjectMeta{Name: "api-ingress", Namespace: "shop"},
+		Spec:       networkingv1.NetworkPolicySpec{PodSelector: metav1.LabelSelector{MatchLabels: map[string]string{"app": "api"}}},
+	}}
+
+	view := BuildNamespaceIsolation("shop", pods, policies)
+	assert.Equal(t, 2, view.TotalPods)
+	assert.Equal(t, 1, view.IngressIsolated)
+	assert.Equal(t, 0, view.EgressIsolated)
+	require.Len(t, view.Pods, 2)
+	assert.Equal(t, "api", view.Pods[0].Name)
+	assert.Equal(t, []string{"api-ingress"}, view.Pods[0].IngressPolicies)
+	assert.False(t, view.Pods[1].IngressIsolated)
+}
+
+func TestValidateNetworkPolicySpec(t *testing.T) {
+	port := intstr.FromString("http")
+	end := int32(9000)
+	spec := networkingv1.NetworkPolicySpec{
+		PolicyTypes: []networkingv1.PolicyType{"Both"},
+		Ingress: []networkingv1.NetworkPolicyIngressRule{{
+			From:  []networkingv1.NetworkPolicyPeer{{IPBlock: &networkingv1.IPBlock{CIDR: "10.0.0.0/33"}}},
+			Ports: []networkingv1.NetworkPolicyPort{{Port: &port, EndPort: &end}},
+		}},
+	}
+	err := validateNetworkPolicySpec(&spec)
+	require.Error(t, err)
+	assert.ErrorIs(t, err, ErrInvalidWorkloadOperation)
+	assert.Contains(t, err.Error(), "Both")
+	assert.Contains(t, err.Error(), "10.0.0.0/33")
+	assert.Contains(t, err.Error(), "endPort")
+}
@@ -0,0 +1,294 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+)
+
+// 驱逐结果状态
+const (
+	DrainPodEvicted = "evicted" // 已驱逐并删除
+	DrainPodSkipped = "skipped" // 按选项跳过（DaemonSet、静态 Pod）
+	DrainPodBlocked = "blocked" // 被 PodDisruptionBudget 阻止直到超时
+	DrainPodFailed  = "failed"  // 不满足驱逐条件或驱逐出错
+)
+
+// mirrorPodAnnotation 静态 Pod 的镜像 Pod 注解，无法通过 API 驱逐
+const mirrorPodAnnotation = "kubernetes.io/config.mirror"
+
+var (
+	// drainRetryInterval 驱逐被 PDB 拒绝（429）后的重试间隔
+	drainRetryInterval = 5 * time.Second
+	// drainPollInterval 等待 Pod 删除完成的轮询间隔
+	drainPollInterval = time.Second
+)
+
+// ErrDrainIncomplete 部分 Pod 未能驱逐
+var ErrDrainIncomplete = errors.New("节点驱逐未完成")
+
+// DrainOptions 节点驱逐选项，语义与 kubectl drain 一致
+type DrainOptions struct {
+	IgnoreDaemonSets   bool   `json:"ignoreDaemonSets"`   // 跳过 DaemonSet 管理的 Pod
+	DeleteEmptyDirData bool   `json:"deleteEmptyDirData"` // 允许驱逐使用 emptyDir 的 Pod（数据将丢失）
+	Force              bool   `json:"force"`              // 允许驱逐没有控制器管理的 Pod
+	GracePeriodSeconds *int64 `json:"gracePeriodSeconds"` // 为空时使用 Pod 自身的 terminationGracePeriodSeconds
+	TimeoutSeconds     int    `json:"timeoutSeconds"`     // 整体超时，0 表示使用默认值
+
+	// OnPodResult 每个 Pod 得到结果（跳过、驱逐完成或失败）时回调，done 为已处理数量，total 为节点上的 Pod 总数
+	OnPodResult func(result DrainPodResult, done, total int) `json:"-"`
+}
+
+// DrainPodResult 单个 Pod 的驱逐结果
+type DrainPodResult struct {
+	Namespace   string `json:"namespace"`
+	Name        string `json:"name"`
+	Status      string `json:"status"`
+	Reason      string `json:"reason,omitempty"`
+	BlockingPDB string `json:"blockingPDB,omitempty"` // 阻止驱逐的 PodDisruptionBudget
+	Attempts    int    `json:"attempts"`
+}
+
+// DrainResult 节点驱逐结果
+type DrainResult struct {
+	Node      string           `json:"node"`
+	Completed bool             `json:"completed"`
+	Evicted   int              `json:"evicted"`
+	Skipped   int              `json:"skipped"`
+	Blocked   int              `json:"blocked"`
+	Failed    int              `json:"failed"`
+	Pods      []DrainPodResult `json:"pods"`
+}
+
+// DefaultDrainTimeout 未指定超时时的驱逐超时
+const DefaultDrainTimeout = 5 * time.Minute
+
+// DrainNode 封锁节点并通过 policy/v1 Eviction API 驱逐节点上的 Pod
+// 驱逐遵守 PodDisruptionBudget：被拒绝（429）时按间隔重试直到超时，并记录阻止驱逐的 PDB。
+// 与 kubectl drain 一致，存在不满足条件的 Pod（未跳过的 DaemonSet Pod、未允许的 emptyDir 或无控制器 Pod）时
+// 不驱逐任何 Pod，返回 ErrDrainIncomplete 与逐个 Pod 的原因。
+func DrainNode(ctx context.Context, clientset kubernetes.Interface, nodeName string, opts DrainOptions) (*DrainResult, error) {
+	timeout := DefaultDrainTimeout
+	if opts.TimeoutSeconds > 0 {
+		timeout = time.Duration(opts.TimeoutSeconds) * time.Second
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	// 1. 封锁节点，防止新的 Pod 调度到该节点
+	if err := SetNodeUnschedulable(ctx, clientset, nodeName, true); err != nil {
+		return nil, fmt.Errorf("封锁节点失败: %w", err)
+	}
+
+	// 2. 获取节点上的所有 Pod
+	pods, err := clientset.CoreV1().Pods("").List(ctx, metav1.ListOptions{
+		FieldSelector: fields.OneTermEqualSelector("spec.nodeName", nodeName).String(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("获取节点上的Pod失败: %w", err)
+	}
+
+	// 3. 过滤 Pod
+	result := &DrainResult{Node: nodeName, Pods: []DrainPodResult{}}
+	var toEvict []corev1.Pod
+	var mu sync.Mutex
+	done, total := 0, 0
+	report := func(r DrainPodResult) {
+		mu.Lock()
+		defer mu.Unlock()
+		done++
+		if opts.OnPodResult != nil {
+			opts.OnPodResult(r, done, total)
+		}
+	}
+	for _, pod := range pods.Items {
+		if pod.Spec.NodeName == nodeName {
+			total++
+		}
+	}
+	for _, pod := range pods.Items {
+		if pod.Spec.NodeName != nodeName {
+			continue
+		}
+		if r := filterDrainPod(&pod, opts); r != nil {
+			result.Pods = append(result.Pods, *r)
+			report(*r)
+			continue
+		}
+		toEvict = append(toEvict, pod)
+	}
+	for _, r := range result.Pods {
+		if r.Status == DrainPodFailed {
+			result.summarize()
+			return result, fmt.Errorf("%w: 存在无法驱逐的Pod", ErrDrainIncomplete)
+		}
+	}
+
+	// 4. 并发驱逐
+	evicted := make([]DrainPodResult, len(toEvict))
+	var wg sync.WaitGroup
+	for i := range toEvict {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			evicted[i] = evictPod(ctx, clientset, &toEvict[i], opts.GracePeriodSeconds)
+			report(evicted[i])
+		}(i)
+	}
+	wg.Wait()
+
+	result.Pods = append(result.Pods, evicted...)
+	result.summarize()
+	if !result.Completed {
+		return result, ErrDrainIncomplete
+	}
+	return result, nil
+}
+
+// SetNodeUnschedulable 封锁或解封节点
+func SetNodeUnschedulable(ctx context.Context, clientset kubernetes.Interface, nodeName string, unschedulable bool) error {
+	patch := fmt.Sprintf(`{"spec":{"unschedulable":%t}}`, unschedulable)
+	_, err := clientset.CoreV1().Nodes().Patch(ctx, nodeName, types.StrategicMergePatchType, []byte(patch), metav1.PatchOptions{})
+	return err
+}
+
+// filterDrainPod 检查 Pod 是否应跳过或不可驱逐，可驱逐时返回 nil
+func filterDrainPod(pod *corev1.Pod, opts DrainOptions) *DrainPodResult {
+	r := &DrainPodResult{Namespace: pod.Namespace, Name: pod.Name}
+	if _, ok := pod.Annotations[mirrorPodAnnotation]; ok {
+		r.Status, r.Reason = DrainPodSkipped, "静态Pod"
+		return r
+	}
+	// 已结束的 Pod 不受其它条件限制
+	if pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+		return nil
+	}
+
+	controller := metav1.GetControllerOf(pod)
+	if controller != nil && controller.Kind == "DaemonSet" {
+		if opts.IgnoreDaemonSets {
+			r.Status, r.Reason = DrainPodSkipped, "DaemonSet管理的Pod"
+		} else {
+			r.Status, r.Reason = DrainPodFailed, "DaemonSet管理的Pod，需要设置ignoreDaemonSets"
+		}
+		return r
+	}
+	if controller == nil && !opts.Force {
+		r.Status, r.Reason = DrainPodFailed, "Pod没有控制器管理，需要设置force"
+		return r
+	}
+	if !opts.DeleteEmptyDirData {
+		for _, volume := range pod.Spec.Volumes {
+			if volume.EmptyDir != nil {
+				r.Status, r.Reason = DrainPodFailed, fmt.Sprintf("Pod使用emptyDir卷 %s，需要设置deleteEmptyDirData", volume.Name)
+				return r
+			}
+		}
+	}
+	return nil
+}
+
+// evictPod 通过 Eviction API 驱逐 Pod，被 PDB 拒绝时重试，驱逐成功后等待 Pod 删除
+func evictPod(ctx context.Context, clientset kubernetes.Interface, pod *corev1.Pod, gracePeriodSeconds *int64) DrainPodResult {
+	r := DrainPodResult{Namespace: pod.Namespace, Name: pod.Name}
+	eviction := &policyv1.Eviction{
+		ObjectMeta:    metav1.ObjectMeta{Name: pod.Name, Namespace: pod.Namespace},
+		DeleteOptions: &metav1.DeleteOptions{GracePeriodSeconds: gracePeriodSeconds},
+	}
+
+	for {
+		r.Attempts++
+		err := clientset.PolicyV1().Evictions(pod.Namespace).Evict(ctx, eviction)
+		switch {
+		case err == nil:
+			if err := waitForPodDeleted(ctx, clientset, pod); err != nil {
+				r.Status, r.Reason = DrainPodFailed, "等待Pod删除超时"
+				return r
+			}
+			r.Status, r.Reason, r.BlockingPDB = DrainPodEvicted, "", ""
+			return r
+		case apierrors.IsNotFound(err):
+			r.Status = DrainPodEvicted
+			return r
+		case apierrors.IsTooManyRequests(err):
+			// 违反 PDB，记录阻止驱逐的 PDB 后重试
+			r.Reason = err.Error()
+			if r.BlockingPDB == "" {
+				r.BlockingPDB = matchingPDBs(ctx, clientset, pod)
+			}
+		default:
+			r.Status, r.Reason = DrainPodFailed, err.Error()
+			return r
+		}
+
+		select {
+		case <-ctx.Done():
+			r.Status = DrainPodBlocked
+			return r
+		case <-time.After(drainRetryInterval):
+		}
+	}
+}
+
+// waitForPodDeleted 等待 Pod 被删除（或被同名新 Pod 替换）
+func waitForPodDeleted(ctx context.Context, clientset kubernetes.Interface, pod *corev1.Pod) error {
+	for {
+		current, err := clientset.CoreV1().Pods(pod.Namespace).Get(ctx, pod.Name, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) || (err == nil && current.UID != pod.UID) {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(drainPollInterval):
+		}
+	}
+}
+
+// matchingPDBs 返回选中该 Pod 的 PodDisruptionBudget 名称，多个时以逗号分隔
+func matchingPDBs(ctx context.Context, clientset kubernetes.Interface, pod *corev1.Pod) string {
+	pdbs, err := clientset.PolicyV1().PodDisruptionBudgets(pod.Namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return ""
+	}
+	names := ""
+	for _, pdb := range pdbs.Items {
+		selector, err := metav1.LabelSelectorAsSelector(pdb.Spec.Selector)
+		if err != nil || !selector.Matches(labels.Set(pod.Labels)) {
+			continue
+		}
+		if names != "" {
+			names += ","
+		}
+		names += pdb.Name
+	}
+	return names
+}
+
+func (r *DrainResult) summarize() {
+	r.Evicted, r.Skipped, r.Blocked, r.Failed = 0, 0, 0, 0
+	for _, p := range r.Pods {
+		switch p.Status {
+		case DrainPodEvicted:
+			r.Evicted++
+		case DrainPodSkipped:
+			r.Skipped++
+		case DrainPodBlocked:
+			r.Blocked++
+		case DrainPodFailed:
+			r.Failed++
+		}
+	}
+	r.Completed = r.Blocked == 0 && r.Failed == 0
+}
@@ -0,0 +1,114 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func drainTestPod(name, ownerKind string, labels map[string]string) *corev1.Pod {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "demo", Labels: labels},
+		Spec:       corev1.PodSpec{NodeName: "node-1"},
+	}
+	if ownerKind != "" {
+		pod.OwnerReferences = []metav1.OwnerReference{{Kind: ownerKind, Name: "owner", Controller: boolPtr(true)}}
+	}
+	return pod
+}
+
+func newDrainClientset(objects ...runtime.Object) *fake.Clientset {
+	objects = append(objects, &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-1"}})
+	clientset := fake.NewSimpleClientset(objects...)
+	// 模拟 Eviction API：受 PDB 保护的 Pod 返回 429，其余 Pod 被删除
+	clientset.PrependReactor("create", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		if action.GetSubresource() != "eviction" {
+			return false, nil, nil
+		}
+		eviction := action.(k8stesting.CreateAction).GetObject().(*policyv1.Eviction)
+		if eviction.Name == "db-0" {
+			return true, nil, apierrors.NewTooManyRequests("Cannot evict pod as it would violate the pod's disruption budget.", 0)
+		}
+		return true, nil, clientset.Tracker().Delete(corev1.SchemeGroupVersion.WithResource("pods"), eviction.Namespace, eviction.Name)
+	})
+	return clientset
+}
+
+func TestDrainNode(t *testing.T) {
+	drainRetryInterval, drainPollInterval = 10*time.Millisecond, 10*time.Millisecond
+	defer func() { drainRetryInterval, drainPollInterval = 5*time.Second, time.Second }()
+
+	pdb := &policyv1.PodDisruptionBudget{
+		ObjectMeta: metav1.ObjectMeta{Name: "db-pdb", Namespace: "demo"},
+		Spec: policyv1.PodDisruptionBudgetSpec{
+			MinAvailable: &intstr.IntOrString{IntVal: 1},
+			Selector:     &metav1.LabelSelector{MatchLabels: map[string]string{"app": "db"}},
+		},
+	}
+	clientset := newDrainClientset(
+		drainTestPod("web-1", "ReplicaSet", nil),
+		drainTestPod("agent-1", "DaemonSet", nil),
+		drainTestPod("db-0", "StatefulSet", map[string]string{"app": "db"}),
+		pdb,
+	)
+
+	result, err := DrainNode(context.Background(), clientset, "node-1", DrainOptions{IgnoreDaemonSets: true, TimeoutSeconds: 1})
+	assert.ErrorIs(t, err, ErrDrainIncomplete)
+	require.NotNil(t, result)
+	assert.Equal(t, 1, result.Evicted)
+	assert.Equal(t, 1, result.Skipped)
+	assert.Equal(t, 1, result.Blocked)
+	for _, pod := range result.Pods {
+		if pod.Name == "db-0" {
+			assert.Equal(t, DrainPodBlocked, pod.Status)
+			assert.Equal(t, "db-pdb", pod.BlockingPDB)
+			assert.Greater(t, pod.Attempts, 1)
+		}
+	}
+
+	node, err := clientset.CoreV1().Nodes().Get(context.Background(), "node-1", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.True(t, node.Spec.Unschedulable)
+}
+
+func TestMatchingPDBs(t *testing.T) {
+	pod := drainTestPod("db-0", "StatefulSet", map[string]string{"app": "db"})
+	clientset := fake.NewSimpleClientset(
+		// 空选择器匹配命名空间内的所有 Pod
+		&policyv1.PodDisruptionBudget{ObjectMeta: metav1.ObjectMeta{Name: "all-pdb", Namespace: "demo"}, Spec: policyv1.PodDisruptionBudgetSpec{Selector: &metav1.LabelSelector{}}},
+		&policyv1.PodDisruptionBudget{ObjectMeta: metav1.ObjectMeta{Name: "web-pdb", Namespace: "demo"}, Spec: policyv1.PodDisruptionBudgetSpec{Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}}}},
+		// 未设置选择器不匹配任何 Pod
+		&policyv1.PodDisruptionBudget{ObjectMeta: metav1.ObjectMeta{Name: "nil-pdb", Namespace: "demo"}},
+	)
+	assert.Equal(t, "all-pdb", matchingPDBs(context.Background(), clientset, pod))
+}
+
+func TestDrainNodeRejectsUnsafePods(t *testing.T) {
+	withEmptyDir := drainTestPod("cache-1", "ReplicaSet", nil)
+	withEmptyDir.Spec.Volumes = []corev1.Volume{{Name: "tmp", VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}}}}
+	clientset := newDrainClientset(withEmptyDir, drainTestPod("bare", "", nil))
+
+	result, err := DrainNode(context.Background(), clientset, "node-1", DrainOptions{})
+	assert.ErrorIs(t, err, ErrDrainIncomplete)
+	assert.Equal(t, 2, result.Failed)
+	// 存在不满足条件的 Pod 时不驱逐任何 Pod
+	pods, err := clientset.CoreV1().Pods("demo").List(context.Background(), metav1.ListOptions{})
+	require.NoError(t, err)
+	assert.Len(t, pods.Items, 2)
+
+	result, err = DrainNode(context.Background(), clientset, "node-1", DrainOptions{DeleteEmptyDirData: true, Force: true})
+	require.NoError(t, err)
+	assert.True(t, result.Completed)
+	assert.Equal(t, 2, result.Evicted)
+}
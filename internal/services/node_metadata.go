@@ -0,0 +1,281 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/util/retry"
+)
+
+// maxAnnotationsSize 节点注解总大小上限（与 API Server 校验一致）
+const maxAnnotationsSize = 256 * 1024
+
+// validTaintEffects 支持的污点效果
+var validTaintEffects = map[corev1.TaintEffect]bool{
+	corev1.TaintEffectNoSchedule:       true,
+	corev1.TaintEffectPreferNoSchedule: true,
+	corev1.TaintEffectNoExecute:        true,
+}
+
+// NodeTaintKey 待删除的污点，Effect 为空时删除该 key 的全部污点
+type NodeTaintKey struct {
+	Key    string             `json:"key"`
+	Effect corev1.TaintEffect `json:"effect,omitempty"`
+}
+
+// NodeMetadataPatch 节点标签、注解与污点的变更
+// 新增与删除可同时指定，先删除后新增；新增污点时替换 key 与 effect 相同的已有污点。
+type NodeMetadataPatch struct {
+	Labels            map[string]string `json:"labels,omitempty"`
+	RemoveLabels      []string          `json:"removeLabels,omitempty"`
+	Annotations       map[string]string `json:"annotations,omitempty"`
+	RemoveAnnotations []string          `json:"removeAnnotations,omitempty"`
+	Taints            []corev1.Taint    `json:"taints,omitempty"`
+	RemoveTaints      []NodeTaintKey    `json:"removeTaints,omitempty"`
+}
+
+// NodeMetadata 节点的标签、注解与污点
+type NodeMetadata struct {
+	Labels      map[string]string `json:"labels"`
+	Annotations map[string]string `json:"annotations"`
+	Taints      []corev1.Taint    `json:"taints"`
+}
+
+// NodeMetadataResult 单个节点的变更结果，Before/After 仅包含发生变化的标签与注解，污点为完整列表
+type NodeMetadataResult struct {
+	Node    string        `json:"node"`
+	Success bool          `json:"success"`
+	Changed bool          `json:"changed"`
+	Error   string        `json:"error,omitempty"`
+	Before  *NodeMetadata `json:"before,omitempty"`
+	After   *NodeMetadata `json:"after,omitempty"`
+}
+
+// NodeMetadataBatchResult 批量变更结果
+type NodeMetadataBatchResult struct {
+	DryRun    bool                 `json:"dryRun"`
+	Total     int                  `json:"total"`
+	Succeeded int                  `json:"succeeded"`
+	Failed    int                  `json:"failed"`
+	Results   []NodeMetadataResult `json:"results"`
+}
+
+// Validate 校验标签、注解的语法与污点效果
+func (p *NodeMetadataPatch) Validate() error {
+	var errs []string
+	for k, v := range p.Labels {
+		for _, msg := range validation.IsQualifiedName(k) {
+			errs = append(errs, fmt.Sprintf("标签键 %q 无效: %s", k, msg))
+		}
+		for _, msg := range validation.IsValidLabelValue(v) {
+			errs = append(errs, fmt.Sprintf("标签 %q 的值 %q 无效: %s", k, v, msg))
+		}
+	}
+	for _, k := range p.RemoveLabels {
+		for _, msg := range validation.IsQualifiedName(k) {
+			errs = append(errs, fmt.Sprintf("标签键 %q 无效: %s", k, msg))
+		}
+	}
+	size := 0
+	for k, v := range p.Annotations {
+		for _, msg := range validation.IsQualifiedName(strings.ToLower(k)) {
+			errs = append(errs, fmt.Sprintf("注解键 %q 无效: %s", k, msg))
+		}
+		size += len(k) + len(v)
+	}
+	if size > maxAnnotationsSize {
+		errs = append(errs, fmt.Sprintf("注解总大小 %d 字节超过上限 %d 字节", size, maxAnnotationsSize))
+	}
+	seen := make(map[string]bool)
+	for _, t := range p.Taints {
+		for _, msg := range validation.IsQualifiedName(t.Key) {
+			errs = append(errs, fmt.Sprintf("污点键 %q 无效: %s", t.Key, msg))
+		}
+		if t.Value != "" {
+			for _, msg := range validation.IsValidLabelValue(t.Value) {
+				errs = append(errs, fmt.Sprintf("污点 %q 的值 %q 无效: %s", t.Key, t.Value, msg))
+			}
+		}
+		if !validTaintEffects[t.Effect] {
+			errs = append(errs, fmt.Sprintf("污点 %q 的效果 %q 无效，可选值: NoSchedule、PreferNoSchedule、NoExecute", t.Key, t.Effect))
+		}
+		id := t.Key + ":" + string(t.Effect)
+		if seen[id] {
+			errs = append(errs, fmt.Sprintf("污点 %s 重复", id))
+		}
+		seen[id] = true
+	}
+	for _, t := range p.RemoveTaints {
+		if t.Effect != "" && !validTaintEffects[t.Effect] {
+			errs = append(errs, fmt.Sprintf("污点 %q 的效果 %q 无效", t.Key, t.Effect))
+		}
+	}
+
+	if len(p.Labels)+len(p.RemoveLabels)+len(p.Annotations)+len(p.RemoveAnnotations)+len(p.Taints)+len(p.RemoveTaints) == 0 {
+		errs = append(errs, "未指定任何变更")
+	}
+	if len(errs) > 0 {
+		sort.Strings(errs)
+		return fmt.Errorf("%w: %s", ErrInvalidWorkloadOperation, strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// Apply 将变更应用到节点对象，返回是否发生变化
+func (p *NodeMetadataPatch) Apply(node *corev1.Node) bool {
+	before := node.DeepCopy()
+
+	for _, k := range p.RemoveLabels {
+		delete(node.Labels, k)
+	}
+	if len(p.Labels) > 0 && node.Labels == nil {
+		node.Labels = make(map[string]string, len(p.Labels))
+	}
+	for k, v := range p.Labels {
+		node.Labels[k] = v
+	}
+
+	for _, k := range p.RemoveAnnotations {
+		delete(node.Annotations, k)
+	}
+	if len(p.Annotations) > 0 && node.Annotations == nil {
+		node.Annotations = make(map[string]string, len(p.Annotations))
+	}
+	for k, v := range p.Annotations {
+		node.Annotations[k] = v
+	}
+
+	taints := make([]corev1.Taint, 0, len(node.Spec.Taints)+len(p.Taints))
+	for _, t := range node.Spec.Taints {
+		if p.removesTaint(t) {
+			continue
+		}
+		taints = append(taints, t)
+	}
+	for _, nt := range p.Taints {
+		replaced := false
+		for i := range taints {
+			if taints[i].Key == nt.Key && taints[i].Effect == nt.Effect {
+				// 值未变化时保留原有的 timeAdded
+				if taints[i].Value != nt.Value {
+					taints[i] = nt
+				}
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			if nt.Effect == corev1.TaintEffectNoExecute && nt.TimeAdded == nil {
+				now := metav1.Now()
+				nt.TimeAdded = &now
+			}
+			taints = append(taints, nt)
+		}
+	}
+	if len(taints) == 0 {
+		taints = nil
+	}
+	node.Spec.Taints = taints
+
+	return !reflect.DeepEqual(before.Labels, node.Labels) ||
+		!reflect.DeepEqual(before.Annotations, node.Annotations) ||
+		!reflect.DeepEqual(before.Spec.Taints, node.Spec.Taints)
+}
+
+func (p *NodeMetadataPatch) removesTaint(t corev1.Taint) bool {
+	for _, r := range p.RemoveTaints {
+		if r.Key == t.Key && (r.Effect == "" || r.Effect == t.Effect) {
+			return true
+		}
+	}
+	return false
+}
+
+// PatchNodeMetadata 修改单个节点的标签、注解与污点
+// dryRun 时仅在本地计算变更结果，不提交到集群。
+func PatchNodeMetadata(ctx context.Context, clientset kubernetes.Interface, name string, patch *NodeMetadataPatch, dryRun bool) (*NodeMetadataResult, error) {
+	if err := patch.Validate(); err != nil {
+		return nil, err
+	}
+	r, err := patchNodeMetadata(ctx, clientset, name, patch, dryRun)
+	if err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
+// PatchNodesMetadata 批量修改节点的标签、注解与污点，逐个节点返回结果，单个节点失败不影响其它节点
+func PatchNodesMetadata(ctx context.Context, clientset kubernetes.Interface, nodes []string, patch *NodeMetadataPatch, dryRun bool) (*NodeMetadataBatchResult, error) {
+	if err := patch.Validate(); err != nil {
+		return nil, err
+	}
+
+	result := &NodeMetadataBatchResult{DryRun: dryRun, Total: len(nodes), Results: make([]NodeMetadataResult, 0, len(nodes))}
+	for _, name := range nodes {
+		r, err := patchNodeMetadata(ctx, clientset, name, patch, dryRun)
+		if err != nil {
+			result.Failed++
+			result.Results = append(result.Results, NodeMetadataResult{Node: name, Error: err.Error()})
+			continue
+		}
+		result.Succeeded++
+		result.Results = append(result.Results, r)
+	}
+	return result, nil
+}
+
+func patchNodeMetadata(ctx context.Context, clientset kubernetes.Interface, name string, patch *NodeMetadataPatch, dryRun bool) (NodeMetadataResult, error) {
+	r := NodeMetadataResult{Node: name}
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		node, err := clientset.CoreV1().Nodes().Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		before := node.DeepCopy()
+		r.Changed = patch.Apply(node)
+		r.Before, r.After = diffNodeMetadata(before, node)
+		if !r.Changed || dryRun {
+			return nil
+		}
+		_, err = clientset.CoreV1().Nodes().Update(ctx, node, metav1.UpdateOptions{})
+		return err
+	})
+	if err != nil {
+		return NodeMetadataResult{Node: name}, err
+	}
+	r.Success = true
+	return r, nil
+}
+
+// diffNodeMetadata 提取变化的标签与注解（删除的键在 After 中不存在），污点变化时返回完整列表
+func diffNodeMetadata(before, after *corev1.Node) (*NodeMetadata, *NodeMetadata) {
+	b := &NodeMetadata{Labels: map[string]string{}, Annotations: map[string]string{}, Taints: []corev1.Taint{}}
+	a := &NodeMetadata{Labels: map[string]string{}, Annotations: map[string]string{}, Taints: []corev1.Taint{}}
+	diffStringMap(before.Labels, after.Labels, b.Labels, a.Labels)
+	diffStringMap(before.Annotations, after.Annotations, b.Annotations, a.Annotations)
+	if !reflect.DeepEqual(before.Spec.Taints, after.Spec.Taints) {
+		b.Taints = append(b.Taints, before.Spec.Taints...)
+		a.Taints = append(a.Taints, after.Spec.Taints...)
+	}
+	return b, a
+}
+
+func diffStringMap(before, after, outBefore, outAfter map[string]string) {
+	for k, v := range before {
+		if nv, ok := after[k]; !ok || nv != v {
+			outBefore[k] = v
+		}
+	}
+	for k, v := range after {
+		if ov, ok := before[k]; !ok || ov != v {
+			outAfter[k] = v
+		}
+	}
+}
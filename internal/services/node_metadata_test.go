@@ -0,0 +1,99 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func metadataTestNode(name string) *corev1.Node {
+	return &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        name,
+			Labels:      map[string]string{"zone": "a", "role": "worker"},
+			Annotations: map[string]string{"owner": "ops"},
+		},
+		Spec: corev1.NodeSpec{Taints: []corev1.Taint{{Key: "dedicated", Value: "db", Effect: corev1.TaintEffectNoSchedule}}},
+	}
+}
+
+func TestNodeMetadataPatchValidate(t *testing.T) {
+	tests := []struct {
+		name  string
+		patch NodeMetadataPatch
+		valid bool
+	}{
+		{"合法标签", NodeMetadataPatch{Labels: map[string]string{"example.com/tier": "gpu"}}, true},
+		{"非法标签键", NodeMetadataPatch{Labels: map[string]string{"bad key": "v"}}, false},
+		{"非法标签值", NodeMetadataPatch{Labels: map[string]string{"tier": "has space"}}, false},
+		{"非法污点效果", NodeMetadataPatch{Taints: []corev1.Taint{{Key: "gpu", Effect: "NoRun"}}}, false},
+		{"重复污点", NodeMetadataPatch{Taints: []corev1.Taint{
+			{Key: "gpu", Effect: corev1.TaintEffectNoSchedule},
+			{Key: "gpu", Value: "x", Effect: corev1.TaintEffectNoSchedule},
+		}}, false},
+		{"空变更", NodeMetadataPatch{}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.patch.Validate()
+			if tt.valid {
+				assert.NoError(t, err)
+			} else {
+				assert.True(t, errors.Is(err, ErrInvalidWorkloadOperation), "err=%v", err)
+			}
+		})
+	}
+}
+
+func TestPatchNodeMetadataDryRunDoesNotPersist(t *testing.T) {
+	clientset := fake.NewSimpleClientset(metadataTestNode("node-1"))
+	patch := &NodeMetadataPatch{Labels: map[string]string{"zone": "b"}, RemoveLabels: []string{"role"}}
+
+	result, err := PatchNodeMetadata(context.Background(), clientset, "node-1", patch, true)
+	require.NoError(t, err)
+	assert.True(t, result.Changed)
+	assert.Equal(t, map[string]string{"zone": "a", "role": "worker"}, result.Before.Labels)
+	assert.Equal(t, map[string]string{"zone": "b"}, result.After.Labels)
+
+	node, err := clientset.CoreV1().Nodes().Get(context.Background(), "node-1", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "a", node.Labels["zone"])
+	assert.Equal(t, "worker", node.Labels["role"])
+}
+
+func TestPatchNodesMetadataBatch(t *testing.T) {
+	clientset := fake.NewSimpleClientset(metadataTestNode("node-1"), metadataTestNode("node-2"))
+	patch := &NodeMetadataPatch{
+		Annotations:  map[string]string{"maintenance": "2026-10"},
+		Taints:       []corev1.Taint{{Key: "gpu", Value: "true", Effect: corev1.TaintEffectNoSchedule}},
+		RemoveTaints: []NodeTaintKey{{Key: "dedicated"}},
+	}
+
+	result, err := PatchNodesMetadata(context.Background(), clientset, []string{"node-1", "node-2", "missing"}, patch, false)
+	require.NoError(t, err)
+	assert.Equal(t, 3, result.Total)
+	assert.Equal(t, 2, result.Succeeded)
+	assert.Equal(t, 1, result.Failed)
+	assert.False(t, result.Results[2].Success)
+	assert.NotEmpty(t, result.Results[2].Error)
+
+	for _, name := range []string{"node-1", "node-2"} {
+		node, err := clientset.CoreV1().Nodes().Get(context.Background(), name, metav1.GetOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, "2026-10", node.Annotations["maintenance"])
+		assert.Equal(t, "ops", node.Annotations["owner"])
+		require.Len(t, node.Spec.Taints, 1)
+		assert.Equal(t, "gpu", node.Spec.Taints[0].Key)
+	}
+
+	// 再次应用相同变更不产生变化
+	again, err := PatchNodesMetadata(context.Background(), clientset, []string{"node-1"}, patch, false)
+	require.NoError(t, err)
+	assert.False(t, again.Results[0].Changed)
+}
@@ -0,0 +1,178 @@
+package services
+
+import (
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	sigsyaml "sigs.k8s.io/yaml"
+)
+
+// 字段差异类型
+const (
+	FieldChangeAdded    = "added"
+	FieldChangeRemoved  = "removed"
+	FieldChangeModified = "modified"
+)
+
+// maskedValue Secret 数据在差异中的展示值
+const maskedValue = "******"
+
+// FieldChange 单个字段的变更
+type FieldChange struct {
+	Path string      `json:"path"` // 字段路径，如 .spec.template.spec.containers[0].image
+	Type string      `json:"type"` // added / removed / modified
+	Old  interface{} `json:"old,omitempty"`
+	New  interface{} `json:"new,omitempty"`
+}
+
+// ObjectDiff 资源应用前后的差异
+type ObjectDiff struct {
+	Changes []FieldChange `json:"changes"`
+	Live    string        `json:"live,omitempty"` // 应用前的 YAML（新建时为空）
+	Applied string        `json:"applied"`        // 应用后（或 dry-run 预期）的 YAML
+}
+
+// DiffObjects 比较应用前后的对象，忽略 managedFields/resourceVersion 等由服务端维护的字段
+// live 为 nil 表示新建，此时仅返回应用后的 YAML。Secret 的 data/stringData 取值会被脱敏。
+func DiffObjects(live, applied *unstructured.Unstructured) *ObjectDiff {
+	diff := &ObjectDiff{Changes: []FieldChange{}}
+	appliedMap := normalizeForDiff(applied)
+	secret := isSecretObject(applied)
+
+	if live != nil {
+		liveMap := normalizeForDiff(live)
+		diffValues("", liveMap, appliedMap, &diff.Changes)
+		diff.Live = renderDiffYAML(liveMap, secret)
+	}
+	diff.Applied = renderDiffYAML(appliedMap, secret)
+
+	if secret {
+		for i := range diff.Changes {
+			if isSecretDataPath(diff.Changes[i].Path) {
+				diff.Changes[i].Old = maskIfSet(diff.Changes[i].Old)
+				diff.Changes[i].New = maskIfSet(diff.Changes[i].New)
+			}
+		}
+	}
+	return diff
+}
+
+func normalizeForDiff(obj *unstructured.Unstructured) map[string]interface{} {
+	if obj == nil {
+		return nil
+	}
+	clean := obj.DeepCopy()
+	clean.SetManagedFields(nil)
+	clean.SetResourceVersion("")
+	clean.SetGeneration(0)
+	return clean.Object
+}
+
+// diffValues 递归比较两个值，列表按下标逐项比较
+func diffValues(path string, oldVal, newVal interface{}, changes *[]FieldChange) {
+	switch o := oldVal.(type) {
+	case map[string]interface{}:
+		n, ok := newVal.(map[string]interface{})
+		if !ok {
+			break
+		}
+		keys := make([]string, 0, len(o)+len(n))
+		for k := range o {
+			keys = append(keys, k)
+		}
+		for k := range n {
+			if _, exists := o[k]; !exists {
+				keys = append(keys, k)
+			}
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			ov, inOld := o[k]
+			nv, inNew := n[k]
+			childPath := joinFieldPath(path, k)
+			switch {
+			case !inNew:
+				*changes = append(*changes, FieldChange{Path: childPath, Type: FieldChangeRemoved, Old: ov})
+			case !inOld:
+				*changes = append(*changes, FieldChange{Path: childPath, Type: FieldChangeAdded, New: nv})
+			default:
+				diffValues(childPath, ov, nv, changes)
+			}
+		}
+		return
+	case []interface{}:
+		n, ok := newVal.([]interface{})
+		if !ok {
+			break
+		}
+		for i := 0; i < len(o) || i < len(n); i++ {
+			childPath := path + "[" + strconv.Itoa(i) + "]"
+			switch {
+			case i >= len(n):
+				*changes = append(*changes, FieldChange{Path: childPath, Type: FieldChangeRemoved, Old: o[i]})
+			case i >= len(o):
+				*changes = append(*changes, FieldChange{Path: childPath, Type: FieldChangeAdded, New: n[i]})
+			default:
+				diffValues(childPath, o[i], n[i], changes)
+			}
+		}
+		return
+	}
+	if !reflect.DeepEqual(oldVal, newVal) {
+		*changes = append(*changes, FieldChange{Path: path, Type: FieldChangeModified, Old: oldVal, New: newVal})
+	}
+}
+
+// joinFieldPath 拼接字段路径，包含 . 或 / 的键（如注解）使用 ["key"] 形式
+func joinFieldPath(path, key string) string {
+	if strings.ContainsAny(key, "./") {
+		return path + "[" + strconv.Quote(key) + "]"
+	}
+	return path + "." + key
+}
+
+func isSecretDataPath(path string) bool {
+	for _, prefix := range []string{".data", ".stringData"} {
+		if path == prefix || strings.HasPrefix(path, prefix+".") || strings.HasPrefix(path, prefix+"[") {
+			return true
+		}
+	}
+	return false
+}
+
+func maskIfSet(v interface{}) interface{} {
+	if v == nil {
+		return nil
+	}
+	if m, ok := v.(map[string]interface{}); ok {
+		masked := make(map[string]interface{}, len(m))
+		for k := range m {
+			masked[k] = maskedValue
+		}
+		return masked
+	}
+	return maskedValue
+}
+
+func renderDiffYAML(obj map[string]interface{}, secret bool) string {
+	if obj == nil {
+		return ""
+	}
+	if secret {
+		masked := (&unstructured.Unstructured{Object: obj}).DeepCopy().Object
+		for _, field := range []string{"data", "stringData"} {
+			if v, ok := masked[field]; ok {
+				masked[field] = maskIfSet(v)
+			}
+		}
+		obj = masked
+	}
+	data, err := sigsyaml.Marshal(obj)
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
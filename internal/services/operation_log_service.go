@@ -37,6 +37,7 @@ type LogEntry struct {
 	ResourceType string
 	ResourceName string
 	RequestBody  interface{}
+	Changes      interface{} // 变更前后的值
 	StatusCode   int
 	Success      bool
 	ErrorMessage string
@@ -61,6 +62,7 @@ func (s *OperationLogService) Record(entry *LogEntry) error {
 		ResourceType: entry.ResourceType,
 		ResourceName: entry.ResourceName,
 		RequestBody:  sanitizeAndMarshal(entry.RequestBody),
+		Changes:      marshalChanges(entry.Changes),
 		StatusCode:   entry.StatusCode,
 		Success:      entry.Success,
 		ErrorMessage: entry.ErrorMessage,
@@ -104,6 +106,22 @@ var sensitiveKeys = map[string]bool{
 	"salt":          true,
 }
 
+// marshalChanges 序列化变更前后的值
+// 变更内容由 handler 构造（如节点标签、污点），不做脱敏，以免污点的 key 等字段被误判为敏感信息。
+func marshalChanges(changes interface{}) string {
+	if changes == nil {
+		return ""
+	}
+	result, err := json.Marshal(changes)
+	if err != nil {
+		return ""
+	}
+	if len(result) > 16000 {
+		return string(result[:16000]) + "...(truncated)"
+	}
+	return string(result)
+}
+
 // sanitizeAndMarshal 脱敏并序列化请求体
 func sanitizeAndMarshal(body interface{}) string {
 	if body == nil {
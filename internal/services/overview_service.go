@@ -14,9 +14,11 @@ import (
 	rolloutslisters "github.com/argoproj/argo-rollouts/pkg/client/listers/rollouts/v1alpha1"
 	"gorm.io/gorm"
 	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	appsv1listers "k8s.io/client-go/listers/apps/v1"
+	batchv1listers "k8s.io/client-go/listers/batch/v1"
 	corev1listers "k8s.io/client-go/listers/core/v1"
 )
 
@@ -27,6 +29,8 @@ type InformerListerProvider interface {
 	DeploymentsLister(clusterID uint) appsv1listers.DeploymentLister
 	StatefulSetsLister(clusterID uint) appsv1listers.StatefulSetLister
 	RolloutsLister(clusterID uint) rolloutslisters.RolloutLister
+	CronJobsLister(clusterID uint) batchv1listers.CronJobLister
+	IsCronJobV1beta1(clusterID uint) bool
 }
 
 // OverviewService 总览服务
@@ -187,8 +191,8 @@ type ClusterAlertCount struct {
 // ========== 服务方法 ==========
 
 // GetOverviewStats 获取总览统计数据
-func (s *OverviewService) GetOverviewStats(ctx context.Context) (*OverviewStatsResponse, error) {
-	clusters, err := s.clusterService.GetAllClusters()
+func (s *OverviewService) GetOverviewStats(ctx context.Context, selector labels.Selector) (*OverviewStatsResponse, error) {
+	clusters, err := s.clusterService.GetClustersBySelector(selector)
 	if err != nil {
 		return nil, fmt.Errorf("获取集群列表失败: %w", err)
 	}
@@ -279,8 +283,8 @@ func (s *OverviewService) GetOverviewStats(ctx context.Context) (*OverviewStatsR
 }
 
 // GetResourceDistribution 获取资源分布
-func (s *OverviewService) GetResourceDistribution(ctx context.Context) (*ResourceDistributionResponse, error) {
-	clusters, err := s.clusterService.GetAllClusters()
+func (s *OverviewService) GetResourceDistribution(ctx context.Context, selector labels.Selector) (*ResourceDistributionResponse, error) {
+	clusters, err := s.clusterService.GetClustersBySelector(selector)
 	if err != nil {
 		return nil, fmt.Errorf("获取集群列表失败: %w", err)
 	}
@@ -351,8 +355,8 @@ func (s *OverviewService) GetResourceDistribution(ctx context.Context) (*Resourc
 }
 
 // GetResourceUsage 获取资源使用率
-func (s *OverviewService) GetResourceUsage(ctx context.Context) (*ResourceUsageResponse, error) {
-	clusters, err := s.clusterService.GetAllClusters()
+func (s *OverviewService) GetResourceUsage(ctx context.Context, selector labels.Selector) (*ResourceUsageResponse, error) {
+	clusters, err := s.clusterService.GetClustersBySelector(selector)
 	if err != nil {
 		return nil, fmt.Errorf("获取集群列表失败: %w", err)
 	}
@@ -500,8 +504,8 @@ func (s *OverviewService) GetResourceUsage(ctx context.Context) (*ResourceUsageR
 }
 
 // GetTrends 获取趋势数据（并发查询优化性能）
-func (s *OverviewService) GetTrends(ctx context.Context, timeRange string, step string) (*TrendResponse, error) {
-	clusters, err := s.clusterService.GetAllClusters()
+func (s *OverviewService) GetTrends(ctx context.Context, timeRange string, step string, selector labels.Selector) (*TrendResponse, error) {
+	clusters, err := s.clusterService.GetClustersBySelector(selector)
 	if err != nil {
 		return nil, fmt.Errorf("获取集群列表失败: %w", err)
 	}
@@ -602,8 +606,8 @@ func (s *OverviewService) GetTrends(ctx context.Context, timeRange string, step
 }
 
 // GetAbnormalWorkloads 获取异常工作负载
-func (s *OverviewService) GetAbnormalWorkloads(ctx context.Context, limit int) ([]AbnormalWorkload, error) {
-	clusters, err := s.clusterService.GetAllClusters()
+func (s *OverviewService) GetAbnormalWorkloads(ctx context.Context, limit int, selector labels.Selector) ([]AbnormalWorkload, error) {
+	clusters, err := s.clusterService.GetClustersBySelector(selector)
 	if err != nil {
 		return nil, fmt.Errorf("获取集群列表失败: %w", err)
 	}
@@ -689,6 +693,30 @@ func (s *OverviewService) GetAbnormalWorkloads(ctx context.Context, limit int) (
 			}
 		}
 
+		// 检查 CronJob 最近一次执行失败
+		if cronJobLister := s.listerProvider.CronJobsLister(cluster.ID); cronJobLister != nil {
+			cronJobs, err := cronJobLister.List(labels.Everything())
+			if err == nil {
+				v1beta1 := s.listerProvider.IsCronJobV1beta1(cluster.ID)
+				for _, cj := range cronJobs {
+					if reason, msg := detectCronJobIssue(cj, v1beta1); reason != "" {
+						duration := formatDuration(cj.Status.LastScheduleTime.Time)
+						workloads = append(workloads, AbnormalWorkload{
+							Name:        cj.Name,
+							Namespace:   cj.Namespace,
+							ClusterID:   cluster.ID,
+							ClusterName: cluster.Name,
+							Type:        "CronJob",
+							Reason:      reason,
+							Message:     msg,
+							Duration:    duration,
+							Severity:    "warning",
+						})
+					}
+				}
+			}
+		}
+
 		// 检查异常 Pod
 		if podLister := s.listerProvider.PodsLister(cluster.ID); podLister != nil {
 			pods, err := podLister.List(labels.Everything())
@@ -726,8 +754,8 @@ func (s *OverviewService) GetAbnormalWorkloads(ctx context.Context, limit int) (
 }
 
 // GetGlobalAlertStats 获取全局告警统计（聚合所有集群的告警数据）
-func (s *OverviewService) GetGlobalAlertStats(ctx context.Context) (*GlobalAlertStats, error) {
-	clusters, err := s.clusterService.GetAllClusters()
+func (s *OverviewService) GetGlobalAlertStats(ctx context.Context, selector labels.Selector) (*GlobalAlertStats, error) {
+	clusters, err := s.clusterService.GetClustersBySelector(selector)
 	if err != nil {
 		return nil, fmt.Errorf("获取集群列表失败: %w", err)
 	}
@@ -871,6 +899,26 @@ func detectPodIssue(pod *corev1.Pod) (string, string) {
 	return "", ""
 }
 
+// detectCronJobIssue 检测 CronJob 异常：最近一次调度已结束但未成功
+// 仅提供 batch/v1beta1 CronJob 的集群（1.21 之前）不维护 status.lastSuccessfulTime，无法据此判断，跳过检测
+func detectCronJobIssue(cj *batchv1.CronJob, v1beta1 bool) (string, string) {
+	if v1beta1 || (cj.Spec.Suspend != nil && *cj.Spec.Suspend) {
+		return "", ""
+	}
+	last := cj.Status.LastScheduleTime
+	if last == nil || len(cj.Status.Active) > 0 {
+		return "", ""
+	}
+	if cj.Status.LastSuccessfulTime == nil {
+		return "执行失败", fmt.Sprintf("最近一次调度于 %s，尚无成功记录", last.Format("2006-01-02 15:04:05"))
+	}
+	if cj.Status.LastSuccessfulTime.Before(last) {
+		return "执行失败", fmt.Sprintf("最近一次调度于 %s，最近成功于 %s",
+			last.Format("2006-01-02 15:04:05"), cj.Status.LastSuccessfulTime.Format("2006-01-02 15:04:05"))
+	}
+	return "", ""
+}
+
 // detectRolloutIssue 检测 Argo Rollout 异常
 func detectRolloutIssue(rollout *rolloutsv1alpha1.Rollout) (string, string, string) {
 	// 检查副本不一致
@@ -0,0 +1,40 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestDetectCronJobIssue(t *testing.T) {
+	scheduled := metav1.NewTime(time.Date(2024, 3, 15, 3, 0, 0, 0, time.UTC))
+	earlier := metav1.NewTime(scheduled.Add(-24 * time.Hour))
+	suspend := true
+
+	cases := []struct {
+		name    string
+		status  batchv1.CronJobStatus
+		suspend *bool
+		v1beta1 bool
+		issue   bool
+	}{
+		{name: "从未调度", status: batchv1.CronJobStatus{}},
+		{name: "最近一次成功", status: batchv1.CronJobStatus{LastScheduleTime: &scheduled, LastSuccessfulTime: &scheduled}},
+		{name: "尚无成功记录", status: batchv1.CronJobStatus{LastScheduleTime: &scheduled}, issue: true},
+		{name: "最近一次失败", status: batchv1.CronJobStatus{LastScheduleTime: &scheduled, LastSuccessfulTime: &earlier}, issue: true},
+		{name: "运行中", status: batchv1.CronJobStatus{LastScheduleTime: &scheduled, Active: []corev1.ObjectReference{{Name: "job-1"}}}},
+		{name: "已挂起", status: batchv1.CronJobStatus{LastScheduleTime: &scheduled}, suspend: &suspend},
+		{name: "v1beta1 集群不维护成功时间", status: batchv1.CronJobStatus{LastScheduleTime: &scheduled}, v1beta1: true},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			cj := &batchv1.CronJob{Spec: batchv1.CronJobSpec{Suspend: tc.suspend}, Status: tc.status}
+			reason, _ := detectCronJobIssue(cj, tc.v1beta1)
+			assert.Equal(t, tc.issue, reason != "")
+		})
+	}
+}
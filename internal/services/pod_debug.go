@@ -0,0 +1,189 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilrand "k8s.io/apimachinery/pkg/util/rand"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes"
+)
+
+// debugContainerStartTimeout 等待临时调试容器启动的最长时间（含拉取镜像）
+const debugContainerStartTimeout = 2 * time.Minute
+
+// DebugContainerRequest 添加临时调试容器请求
+type DebugContainerRequest struct {
+	Name            string   `json:"name"`            // 为空时自动生成 debugger-xxxxx
+	Image           string   `json:"image"`           // 为空时使用平台配置的默认调试镜像
+	TargetContainer string   `json:"targetContainer"` // 共享进程命名空间的目标容器，为空时不共享
+	Command         []string `json:"command"`         // 为空时使用镜像默认命令
+}
+
+// DebugContainerInfo 临时调试容器信息
+type DebugContainerInfo struct {
+	Name            string     `json:"name"`
+	Image           string     `json:"image"`
+	TargetContainer string     `json:"targetContainer,omitempty"`
+	State           string     `json:"state"` // waiting / running / terminated
+	Reason          string     `json:"reason,omitempty"`
+	Message         string     `json:"message,omitempty"`
+	StartedAt       *time.Time `json:"startedAt,omitempty"`
+}
+
+// ListDebugContainers 获取 Pod 中的临时调试容器及其状态
+func ListDebugContainers(pod *corev1.Pod) []DebugContainerInfo {
+	statuses := make(map[string]corev1.ContainerStatus, len(pod.Status.EphemeralContainerStatuses))
+	for _, s := range pod.Status.EphemeralContainerStatuses {
+		statuses[s.Name] = s
+	}
+	items := make([]DebugContainerInfo, 0, len(pod.Spec.EphemeralContainers))
+	for _, ec := range pod.Spec.EphemeralContainers {
+		info := DebugContainerInfo{
+			Name:            ec.Name,
+			Image:           ec.Image,
+			TargetContainer: ec.TargetContainerName,
+			State:           "waiting",
+		}
+		if s, ok := statuses[ec.Name]; ok {
+			switch {
+			case s.State.Running != nil:
+				info.State = "running"
+				started := s.State.Running.StartedAt.Time
+				info.StartedAt = &started
+			case s.State.Terminated != nil:
+				info.State = "terminated"
+				info.Reason = s.State.Terminated.Reason
+				info.Message = s.State.Terminated.Message
+			case s.State.Waiting != nil:
+				info.Reason = s.State.Waiting.Reason
+				info.Message = s.State.Waiting.Message
+			}
+		}
+		items = append(items, info)
+	}
+	return items
+}
+
+// AddDebugContainer 通过 pods/ephemeralcontainers 子资源为运行中的 Pod 添加临时调试容器，并等待其启动
+// 调试容器开启 stdin/tty，终端通过 attach 连接；指定目标容器时共享其进程命名空间，可查看目标容器的进程与文件系统（/proc/<pid>/root）。
+func AddDebugContainer(ctx context.Context, clientset kubernetes.Interface, namespace, podName string, req *DebugContainerRequest) (*DebugContainerInfo, error) {
+	pod, err := clientset.CoreV1().Pods(namespace).Get(ctx, podName, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	ec, err := buildDebugContainer(pod, req)
+	if err != nil {
+		return nil, err
+	}
+
+	pod.Spec.EphemeralContainers = append(pod.Spec.EphemeralContainers, *ec)
+	if _, err := clientset.CoreV1().Pods(namespace).UpdateEphemeralContainers(ctx, podName, pod, metav1.UpdateOptions{}); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, fmt.Errorf("%w: 集群不支持临时容器（需要 Kubernetes 1.23 及以上版本）: %v", ErrInvalidWorkloadOperation, err)
+		}
+		return nil, err
+	}
+
+	return waitForDebugContainer(ctx, clientset, namespace, podName, ec.Name)
+}
+
+// buildDebugContainer 校验请求并构建临时容器定义
+func buildDebugContainer(pod *corev1.Pod, req *DebugContainerRequest) (*corev1.EphemeralContainer, error) {
+	if pod.Status.Phase != corev1.PodRunning {
+		return nil, fmt.Errorf("%w: Pod 当前状态为 %s，只能调试运行中的 Pod", ErrInvalidWorkloadOperation, pod.Status.Phase)
+	}
+	if req.Image == "" {
+		return nil, fmt.Errorf("%w: 未指定调试镜像", ErrInvalidWorkloadOperation)
+	}
+
+	existing := make(map[string]bool)
+	for _, c := range pod.Spec.Containers {
+		existing[c.Name] = true
+	}
+	for _, c := range pod.Spec.InitContainers {
+		existing[c.Name] = true
+	}
+	for _, c := range pod.Spec.EphemeralContainers {
+		existing[c.Name] = true
+	}
+
+	if req.TargetContainer != "" {
+		found := false
+		for _, c := range pod.Spec.Containers {
+			if c.Name == req.TargetContainer {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("%w: 目标容器 %s 不存在", ErrInvalidWorkloadOperation, req.TargetContainer)
+		}
+	}
+
+	name := req.Name
+	if name == "" {
+		for name == "" || existing[name] {
+			name = "debugger-" + utilrand.String(5)
+		}
+	} else {
+		if errs := validation.IsDNS1123Label(name); len(errs) > 0 {
+			return nil, fmt.Errorf("%w: 容器名称无效: %v", ErrInvalidWorkloadOperation, errs)
+		}
+		if existing[name] {
+			return nil, fmt.Errorf("%w: 容器 %s 已存在", ErrInvalidWorkloadOperation, name)
+		}
+	}
+
+	return &corev1.EphemeralContainer{
+		EphemeralContainerCommon: corev1.EphemeralContainerCommon{
+			Name:                     name,
+			Image:                    req.Image,
+			Command:                  req.Command,
+			ImagePullPolicy:          corev1.PullIfNotPresent,
+			Stdin:                    true,
+			TTY:                      true,
+			TerminationMessagePolicy: corev1.TerminationMessageReadFile,
+		},
+		TargetContainerName: req.TargetContainer,
+	}, nil
+}
+
+// waitForDebugContainer 等待临时容器进入运行状态；容器退出或镜像拉取失败时返回错误
+func waitForDebugContainer(ctx context.Context, clientset kubernetes.Interface, namespace, podName, name string) (*DebugContainerInfo, error) {
+	var info *DebugContainerInfo
+	err := wait.PollUntilContextTimeout(ctx, time.Second, debugContainerStartTimeout, true, func(ctx context.Context) (bool, error) {
+		pod, err := clientset.CoreV1().Pods(namespace).Get(ctx, podName, metav1.GetOptions{})
+		if err != nil {
+			return false, err
+		}
+		for _, c := range ListDebugContainers(pod) {
+			if c.Name != name {
+				continue
+			}
+			c := c
+			info = &c
+			switch {
+			case c.State == "running":
+				return true, nil
+			case c.State == "terminated":
+				return false, fmt.Errorf("调试容器已退出: %s %s", c.Reason, c.Message)
+			case c.Reason == "ErrImagePull" || c.Reason == "ImagePullBackOff" || c.Reason == "InvalidImageName":
+				return false, fmt.Errorf("拉取调试镜像失败: %s %s", c.Reason, c.Message)
+			}
+		}
+		return false, nil
+	})
+	if err != nil {
+		if wait.Interrupted(err) {
+			return info, fmt.Errorf("等待调试容器 %s 启动超时", name)
+		}
+		return info, err
+	}
+	return info, nil
+}
@@ -0,0 +1,73 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func debugTestPod(phase corev1.PodPhase) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "api-0", Namespace: "default"},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{{Name: "app", Image: "gcr.io/distroless/static"}},
+			EphemeralContainers: []corev1.EphemeralContainer{{
+				EphemeralContainerCommon: corev1.EphemeralContainerCommon{Name: "debugger-old", Image: "busybox:1.36"},
+				TargetContainerName:      "app",
+			}},
+		},
+		Status: corev1.PodStatus{
+			Phase: phase,
+			EphemeralContainerStatuses: []corev1.ContainerStatus{{
+				Name:  "debugger-old",
+				State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{Reason: "Completed"}},
+			}},
+		},
+	}
+}
+
+func TestBuildDebugContainer(t *testing.T) {
+	pod := debugTestPod(corev1.PodRunning)
+
+	ec, err := buildDebugContainer(pod, &DebugContainerRequest{Image: "busybox:1.36", TargetContainer: "app"})
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(ec.Name, "debugger-"))
+	assert.NotEqual(t, "debugger-old", ec.Name)
+	assert.Equal(t, "app", ec.TargetContainerName)
+	assert.True(t, ec.Stdin)
+	assert.True(t, ec.TTY)
+
+	cases := []*DebugContainerRequest{
+		{Image: ""},
+		{Image: "busybox:1.36", TargetContainer: "sidecar"},
+		{Image: "busybox:1.36", Name: "app"},
+		{Image: "busybox:1.36", Name: "debugger-old"},
+		{Image: "busybox:1.36", Name: "Invalid_Name"},
+	}
+	for _, req := range cases {
+		_, err := buildDebugContainer(pod, req)
+		assert.ErrorIs(t, err, ErrInvalidWorkloadOperation, "%+v", req)
+	}
+}
+
+func TestAddDebugContainerRequiresRunningPod(t *testing.T) {
+	clientset := fake.NewSimpleClientset(debugTestPod(corev1.PodPending))
+
+	_, err := AddDebugContainer(context.Background(), clientset, "default", "api-0", &DebugContainerRequest{Image: "busybox:1.36"})
+	assert.ErrorIs(t, err, ErrInvalidWorkloadOperation)
+}
+
+func TestListDebugContainers(t *testing.T) {
+	items := ListDebugContainers(debugTestPod(corev1.PodRunning))
+	require.Len(t, items, 1)
+	assert.Equal(t, "debugger-old", items[0].Name)
+	assert.Equal(t, "app", items[0].TargetContainer)
+	assert.Equal(t, "terminated", items[0].State)
+	assert.Equal(t, "Completed", items[0].Reason)
+}
@@ -436,6 +436,11 @@ func (s *PrometheusService) QueryWorkloadMetrics(ctx context.Context, config *mo
 		metrics.OOMKills = oomKills
 	}
 
+	// 查询运行中的副本数（用于观察扩缩容历史）
+	if replicas, err := s.queryMetricSeries(ctx, config, fmt.Sprintf("sum(kube_pod_status_phase{phase=\"Running\",%s})", workloadSelector), start, end, step); err == nil {
+		metrics.Replicas = replicas
+	}
+
 	// 查询多Pod时间序列数据（用于展示多条曲线）
 	// CPU使用率（每个Pod独立）
 	if cpuMulti, err := s.queryMultiSeriesMetric(ctx, config, fmt.Sprintf("sum (rate(container_cpu_usage_seconds_total{container!=\"\",%s}[1m])) by(pod) /( sum (kube_pod_container_resource_limits{container!=\"\",resource=\"cpu\",%s}) by(pod) ) * 100", workloadSelector, workloadSelector), start, end, step); err == nil {
@@ -0,0 +1,393 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// QuotaWarning 应用后将超出 ResourceQuota 的资源项
+type QuotaWarning struct {
+	Namespace string   `json:"namespace"`
+	Quota     string   `json:"quota"`
+	Resource  string   `json:"resource"`
+	Hard      string   `json:"hard"`
+	Used      string   `json:"used"`
+	Requested string   `json:"requested"` // 本次应用新增的用量
+	Objects   []string `json:"objects"`   // 产生新增用量的资源（Kind/名称）
+	Message   string   `json:"message"`
+}
+
+// quotaDemand 单个资源应用前后的配额用量增量
+type quotaDemand struct {
+	Object string
+	Delta  corev1.ResourceList
+}
+
+var (
+	resourceQuotaGVR = schema.GroupVersionResource{Version: "v1", Resource: "resourcequotas"}
+	limitRangeGVR    = schema.GroupVersionResource{Version: "v1", Resource: "limitranges"}
+
+	// 计入 requests/limits 配额的计算资源
+	quotaComputeResources = []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory, corev1.ResourceEphemeralStorage}
+
+	// 核心组中使用旧式名称（不带 count/ 前缀）计数的对象
+	legacyCountedResources = map[string]bool{
+		"services": true, "secrets": true, "configmaps": true,
+		"persistentvolumeclaims": true, "replicationcontrollers": true,
+	}
+)
+
+// CheckQuota 预估应用资源后命名空间的配额用量，返回将超出 ResourceQuota 的资源项
+// 仅作提示：按副本数 × Pod 有效请求量估算（考虑 LimitRange 默认值），不考虑滚动更新期间的额外 Pod；
+// 带作用域的配额无法在应用前准确判断，会被跳过。查询失败时静默跳过，不影响应用。
+func (e *ApplyEngine) CheckQuota(ctx context.Context, objs []*unstructured.Unstructured) []QuotaWarning {
+	type namespaceQuota struct {
+		quotas      []corev1.ResourceQuota
+		limitRanges []corev1.LimitRange
+		demands     []quotaDemand
+	}
+	namespaces := make(map[string]*namespaceQuota)
+
+	for _, obj := range objs {
+		info, err := e.ResolveKind(obj.GroupVersionKind())
+		if err != nil || !info.Namespaced {
+			continue
+		}
+		obj = prepareApplyObject(obj, info)
+		namespace := obj.GetNamespace()
+
+		nq, ok := namespaces[namespace]
+		if !ok {
+			nq = &namespaceQuota{}
+			nq.quotas, nq.limitRanges = e.loadQuotaPolicies(ctx, namespace)
+			namespaces[namespace] = nq
+		}
+		if len(nq.quotas) == 0 {
+			continue
+		}
+
+		live, err := e.Get(ctx, info, namespace, obj.GetName())
+		if err != nil {
+			if !apierrors.IsNotFound(err) {
+				continue
+			}
+			live = nil
+		}
+
+		delta := workloadQuotaDemand(obj, nq.limitRanges)
+		if live != nil {
+			subtractResourceList(delta, workloadQuotaDemand(live, nq.limitRanges))
+		} else {
+			for _, name := range objectCountResources(info) {
+				delta[name] = *resource.NewQuantity(1, resource.DecimalSI)
+			}
+		}
+		if len(delta) > 0 {
+			nq.demands = append(nq.demands, quotaDemand{Object: obj.GetKind() + "/" + obj.GetName(), Delta: delta})
+		}
+	}
+
+	var warnings []QuotaWarning
+	names := make([]string, 0, len(namespaces))
+	for ns := range namespaces {
+		names = append(names, ns)
+	}
+	sort.Strings(names)
+	for _, ns := range names {
+		nq := namespaces[ns]
+		warnings = append(warnings, evaluateQuotaWarnings(ns, nq.demands, nq.quotas)...)
+	}
+	return warnings
+}
+
+// loadQuotaPolicies 获取命名空间的 ResourceQuota 与 LimitRange，失败时返回空
+func (e *ApplyEngine) loadQuotaPolicies(ctx context.Context, namespace string) ([]corev1.ResourceQuota, []corev1.LimitRange) {
+	quotaList, err := e.dynamic.Resource(resourceQuotaGVR).Namespace(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil || len(quotaList.Items) == 0 {
+		return nil, nil
+	}
+	quotas := make([]corev1.ResourceQuota, 0, len(quotaList.Items))
+	for i := range quotaList.Items {
+		var q corev1.ResourceQuota
+		if runtime.DefaultUnstructuredConverter.FromUnstructured(quotaList.Items[i].Object, &q) == nil {
+			quotas = append(quotas, q)
+		}
+	}
+
+	var limitRanges []corev1.LimitRange
+	if lrList, err := e.dynamic.Resource(limitRangeGVR).Namespace(namespace).List(ctx, metav1.ListOptions{}); err == nil {
+		for i := range lrList.Items {
+			var lr corev1.LimitRange
+			if runtime.DefaultUnstructuredConverter.FromUnstructured(lrList.Items[i].Object, &lr) == nil {
+				limitRanges = append(limitRanges, lr)
+			}
+		}
+	}
+	return quotas, limitRanges
+}
+
+// evaluateQuotaWarnings 将各资源的用量增量与配额比较，返回超限项
+func evaluateQuotaWarnings(namespace string, demands []quotaDemand, quotas []corev1.ResourceQuota) []QuotaWarning {
+	total := corev1.ResourceList{}
+	objects := make(map[corev1.ResourceName][]string)
+	for _, d := range demands {
+		for name, q := range d.Delta {
+			if q.Sign() <= 0 {
+				continue
+			}
+			sum := total[name]
+			sum.Add(q)
+			total[name] = sum
+			objects[name] = append(objects[name], d.Object)
+		}
+	}
+
+	var warnings []QuotaWarning
+	for _, quota := range quotas {
+		if len(quota.Spec.Scopes) > 0 || quota.Spec.ScopeSelector != nil {
+			continue
+		}
+		hardList := quota.Status.Hard
+		if len(hardList) == 0 {
+			hardList = quota.Spec.Hard
+		}
+		for name, hard := range hardList {
+			requested, ok := total[name]
+			if !ok {
+				continue
+			}
+			used := quota.Status.Used[name]
+			after := used.DeepCopy()
+			after.Add(requested)
+			if after.Cmp(hard) <= 0 {
+				continue
+			}
+			warnings = append(warnings, QuotaWarning{
+				Namespace: namespace,
+				Quota:     quota.Name,
+				Resource:  string(name),
+				Hard:      hard.String(),
+				Used:      used.String(),
+				Requested: requested.String(),
+				Objects:   objects[name],
+				Message: fmt.Sprintf("命名空间 %s 的配额 %s 中 %s 将超限：已用 %s + 新增 %s > 上限 %s",
+					namespace, quota.Name, name, used.String(), requested.String(), hard.String()),
+			})
+		}
+	}
+	sort.Slice(warnings, func(i, j int) bool {
+		if warnings[i].Quota != warnings[j].Quota {
+			return warnings[i].Quota < warnings[j].Quota
+		}
+		return warnings[i].Resource < warnings[j].Resource
+	})
+	return warnings
+}
+
+// workloadQuotaDemand 计算资源对配额的占用：工作负载按副本数 × Pod 有效用量计算，PVC 按存储请求计算
+func workloadQuotaDemand(obj *unstructured.Unstructured, limitRanges []corev1.LimitRange) corev1.ResourceList {
+	demand := corev1.ResourceList{}
+	if obj.GetKind() == "PersistentVolumeClaim" {
+		if s, found, _ := unstructured.NestedString(obj.Object, "spec", "resources", "requests", "storage"); found {
+			if q, err := resource.ParseQuantity(s); err == nil {
+				demand[corev1.ResourceRequestsStorage] = q
+			}
+		}
+		return demand
+	}
+
+	var podSpecPath []string
+	replicasField := "replicas"
+	switch obj.GetKind() {
+	case "Pod":
+		podSpecPath = []string{"spec"}
+		replicasField = ""
+	case "Deployment", "StatefulSet", "ReplicaSet", "Rollout":
+		podSpecPath = []string{"spec", "template", "spec"}
+	case "Job":
+		podSpecPath = []string{"spec", "template", "spec"}
+		replicasField = "parallelism"
+	default:
+		return demand
+	}
+
+	raw, found, _ := unstructured.NestedMap(obj.Object, podSpecPath...)
+	if !found {
+		return demand
+	}
+	var spec corev1.PodSpec
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(raw, &spec); err != nil {
+		return demand
+	}
+
+	replicas := int64(1)
+	if replicasField != "" {
+		if v, found, _ := unstructured.NestedInt64(obj.Object, "spec", replicasField); found {
+			replicas = v
+		}
+	}
+	if replicas <= 0 {
+		return demand
+	}
+
+	requests, limits := podEffectiveResources(&spec, limitRanges)
+	for _, name := range quotaComputeResources {
+		if q, ok := requests[name]; ok {
+			demand[corev1.ResourceName("requests."+string(name))] = multiplyQuantity(q, replicas)
+			demand[name] = multiplyQuantity(q, replicas)
+		}
+		if q, ok := limits[name]; ok {
+			demand[corev1.ResourceName("limits."+string(name))] = multiplyQuantity(q, replicas)
+		}
+	}
+	demand[corev1.ResourcePods] = *resource.NewQuantity(replicas, resource.DecimalSI)
+	return demand
+}
+
+// podEffectiveResources 计算 Pod 的有效 requests/limits：
+// 取普通容器之和与单个 init 容器最大值中的较大者，加上 overhead；容器未设置时按 LimitRange 默认值补齐。
+func podEffectiveResources(spec *corev1.PodSpec, limitRanges []corev1.LimitRange) (corev1.ResourceList, corev1.ResourceList) {
+	defaultLimits, defaultRequests := containerDefaults(limitRanges)
+
+	requests, limits := corev1.ResourceList{}, corev1.ResourceList{}
+	for i := range spec.Containers {
+		req, lim := containerResources(&spec.Containers[i], defaultLimits, defaultRequests)
+		addResourceList(requests, req)
+		addResourceList(limits, lim)
+	}
+	for i := range spec.InitContainers {
+		req, lim := containerResources(&spec.InitContainers[i], defaultLimits, defaultRequests)
+		maxResourceList(requests, req)
+		maxResourceList(limits, lim)
+	}
+	addResourceList(requests, spec.Overhead)
+	addResourceList(limits, spec.Overhead)
+	return requests, limits
+}
+
+// containerResources 返回容器补齐默认值后的 requests 与 limits
+// 与 API Server 的行为一致：未设置 requests 但设置了 limits 时，requests 等于 limits。
+func containerResources(c *corev1.Container, defaultLimits, defaultRequests corev1.ResourceList) (corev1.ResourceList, corev1.ResourceList) {
+	requests, limits := corev1.ResourceList{}, corev1.ResourceList{}
+	for name, q := range c.Resources.Limits {
+		limits[name] = q
+	}
+	for name, q := range c.Resources.Requests {
+		requests[name] = q
+	}
+	for name, q := range limits {
+		if _, ok := requests[name]; !ok {
+			requests[name] = q
+		}
+	}
+	for name, q := range defaultLimits {
+		if _, ok := limits[name]; !ok {
+			limits[name] = q
+		}
+	}
+	for name, q := range defaultRequests {
+		if _, ok := requests[name]; !ok {
+			requests[name] = q
+		}
+	}
+	return requests, limits
+}
+
+// containerDefaults 汇总 LimitRange 中 Container 类型的默认 limits 与 requests
+// default 未设置时取 max，defaultRequest 未设置时取 default，与 LimitRange 的默认化规则一致。
+func containerDefaults(limitRanges []corev1.LimitRange) (corev1.ResourceList, corev1.ResourceList) {
+	defaultLimits, defaultRequests := corev1.ResourceList{}, corev1.ResourceList{}
+	for _, lr := range limitRanges {
+		for _, item := range lr.Spec.Limits {
+			if item.Type != corev1.LimitTypeContainer {
+				continue
+			}
+			for name, q := range item.Max {
+				if _, ok := item.Default[name]; !ok {
+					defaultLimits[name] = q
+				}
+			}
+			for name, q := range item.Default {
+				defaultLimits[name] = q
+				if _, ok := item.DefaultRequest[name]; !ok {
+					defaultRequests[name] = q
+				}
+			}
+			for name, q := range item.DefaultRequest {
+				defaultRequests[name] = q
+			}
+		}
+	}
+	return defaultLimits, defaultRequests
+}
+
+// objectCountResources 新建资源时计入的对象数量配额名称，如 count/deployments.apps
+func objectCountResources(info *APIResourceInfo) []corev1.ResourceName {
+	name := "count/" + info.Resource
+	if info.Group != "" {
+		name += "." + info.Group
+	}
+	names := []corev1.ResourceName{corev1.ResourceName(name)}
+	if info.Group == "" && legacyCountedResources[info.Resource] {
+		names = append(names, corev1.ResourceName(info.Resource))
+	}
+	return names
+}
+
+func multiplyQuantity(q resource.Quantity, n int64) resource.Quantity {
+	if n == 1 {
+		return q.DeepCopy()
+	}
+	return *resource.NewMilliQuantity(q.MilliValue()*n, q.Format)
+}
+
+func addResourceList(dst, src corev1.ResourceList) {
+	for name, q := range src {
+		sum := dst[name]
+		sum.Add(q)
+		dst[name] = sum
+	}
+}
+
+func maxResourceList(dst, src corev1.ResourceList) {
+	for name, q := range src {
+		if cur, ok := dst[name]; !ok || q.Cmp(cur) > 0 {
+			dst[name] = q.DeepCopy()
+		}
+	}
+}
+
+// subtractResourceList 从 dst 中减去 src，结果非正的资源项被移除
+func subtractResourceList(dst, src corev1.ResourceList) {
+	for name, q := range src {
+		cur, ok := dst[name]
+		if !ok {
+			continue
+		}
+		cur.Sub(q)
+		if cur.Sign() <= 0 {
+			delete(dst, name)
+			continue
+		}
+		dst[name] = cur
+	}
+}
+
+// FormatQuotaWarnings 将配额预警拼接为一行文本
+func FormatQuotaWarnings(warnings []QuotaWarning) string {
+	messages := make([]string, 0, len(warnings))
+	for _, w := range warnings {
+		messages = append(messages, w.Message)
+	}
+	return strings.Join(messages, "; ")
+}
@@ -0,0 +1,157 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func quotaTestDeployment(replicas int64, containers ...interface{}) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "apps/v1",
+		"kind":       "Deployment",
+		"metadata":   map[string]interface{}{"name": "api", "namespace": "shop"},
+		"spec": map[string]interface{}{
+			"replicas": replicas,
+			"template": map[string]interface{}{
+				"spec": map[string]interface{}{"containers": containers},
+			},
+		},
+	}}
+}
+
+func quotaTestContainer(name string, requests, limits map[string]interface{}) map[string]interface{} {
+	res := map[string]interface{}{}
+	if requests != nil {
+		res["requests"] = requests
+	}
+	if limits != nil {
+		res["limits"] = limits
+	}
+	return map[string]interface{}{"name": name, "image": "nginx", "resources": res}
+}
+
+func TestWorkloadQuotaDemand(t *testing.T) {
+	limitRanges := []corev1.LimitRange{{
+		Spec: corev1.LimitRangeSpec{Limits: []corev1.LimitRangeItem{{
+			Type:           corev1.LimitTypeContainer,
+			Default:        corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("512Mi")},
+			DefaultRequest: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("100m")},
+		}}},
+	}}
+
+	deploy := quotaTestDeployment(3,
+		quotaTestContainer("app", map[string]interface{}{"cpu": "500m"}, map[string]interface{}{"cpu": "1"}),
+		// 未设置的资源按 LimitRange 补齐：cpu 取 defaultRequest，memory 的 requests/limits 均取 default
+		quotaTestContainer("sidecar", nil, nil),
+	)
+	demand := workloadQuotaDemand(deploy, limitRanges)
+
+	assertQuantity(t, "1800m", demand["requests.cpu"])
+	assertQuantity(t, "1800m", demand["cpu"])
+	assertQuantity(t, "3", demand["limits.cpu"])
+	assertQuantity(t, "3Gi", demand["requests.memory"])
+	assertQuantity(t, "3Gi", demand["limits.memory"])
+	assertQuantity(t, "3", demand["pods"])
+
+	// 仅设置 limits 时 requests 等于 limits
+	pod := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "Pod",
+		"metadata":   map[string]interface{}{"name": "debug"},
+		"spec": map[string]interface{}{
+			"containers": []interface{}{quotaTestContainer("app", nil, map[string]interface{}{"cpu": "200m"})},
+			"initContainers": []interface{}{
+				quotaTestContainer("init", map[string]interface{}{"cpu": "1"}, nil),
+			},
+		},
+	}}
+	demand = workloadQuotaDemand(pod, nil)
+	assertQuantity(t, "1", demand["requests.cpu"])
+	assertQuantity(t, "200m", demand["limits.cpu"])
+	assertQuantity(t, "1", demand["pods"])
+
+	configMap := &unstructured.Unstructured{Object: map[string]interface{}{"apiVersion": "v1", "kind": "ConfigMap"}}
+	assert.Empty(t, workloadQuotaDemand(configMap, nil))
+}
+
+func TestEvaluateQuotaWarnings(t *testing.T) {
+	quotas := []corev1.ResourceQuota{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "compute"},
+			Spec:       corev1.ResourceQuotaSpec{Hard: corev1.ResourceList{"requests.cpu": resource.MustParse("4"), "pods": resource.MustParse("10")}},
+			Status: corev1.ResourceQuotaStatus{
+				Hard: corev1.ResourceList{"requests.cpu": resource.MustParse("4"), "pods": resource.MustParse("10")},
+				Used: corev1.ResourceList{"requests.cpu": resource.MustParse("3500m"), "pods": resource.MustParse("4")},
+			},
+		},
+		{
+			// 带作用域的配额无法预判，跳过
+			ObjectMeta: metav1.ObjectMeta{Name: "best-effort"},
+			Spec: corev1.ResourceQuotaSpec{
+				Hard:   corev1.ResourceList{"pods": resource.MustParse("1")},
+				Scopes: []corev1.ResourceQuotaScope{corev1.ResourceQuotaScopeBestEffort},
+			},
+		},
+	}
+
+	// 已有 1 副本（500m）扩容到 3 副本：新增 1 CPU，已用 3.5 时超出 4 的上限
+	live := quotaTestDeployment(1, quotaTestContainer("app", map[string]interface{}{"cpu": "500m"}, nil))
+	desired := quotaTestDeployment(3, quotaTestContainer("app", map[string]interface{}{"cpu": "500m"}, nil))
+	delta := workloadQuotaDemand(desired, nil)
+	subtractResourceList(delta, workloadQuotaDemand(live, nil))
+
+	warnings := evaluateQuotaWarnings("shop", []quotaDemand{{Object: "Deployment/api", Delta: delta}}, quotas)
+	require.Len(t, warnings, 1)
+	assert.Equal(t, "compute", warnings[0].Quota)
+	assert.Equal(t, "requests.cpu", warnings[0].Resource)
+	assert.Equal(t, "1", warnings[0].Requested)
+	assert.Equal(t, []string{"Deployment/api"}, warnings[0].Objects)
+	assert.Contains(t, warnings[0].Message, "requests.cpu")
+
+	// 缩容不产生预警
+	delta = workloadQuotaDemand(live, nil)
+	subtractResourceList(delta, workloadQuotaDemand(desired, nil))
+	assert.Empty(t, evaluateQuotaWarnings("shop", []quotaDemand{{Object: "Deployment/api", Delta: delta}}, quotas))
+}
+
+func TestBuildNamespaceQuotaUsage(t *testing.T) {
+	quotas := []corev1.ResourceQuota{{
+		ObjectMeta: metav1.ObjectMeta{Name: "compute"},
+		Spec:       corev1.ResourceQuotaSpec{Hard: corev1.ResourceList{"requests.memory": resource.MustParse("8Gi"), "pods": resource.MustParse("10")}},
+		Status: corev1.ResourceQuotaStatus{
+			Used: corev1.ResourceList{"requests.memory": resource.MustParse("2Gi")},
+		},
+	}}
+
+	usage := BuildNamespaceQuotaUsage("shop", quotas)
+	require.Len(t, usage.Items, 2)
+	assert.Equal(t, QuotaUsageItem{Quota: "compute", Resource: "pods", Hard: "10", Used: "0", Percent: 0}, usage.Items[0])
+	assert.Equal(t, "requests.memory", usage.Items[1].Resource)
+	assert.Equal(t, 25.0, usage.Items[1].Percent)
+}
+
+func TestBuildLimitRangeSpecValidation(t *testing.T) {
+	_, err := buildLimitRangeSpec([]LimitRangeItem{
+		{Type: "Node"},
+		{Type: corev1.LimitTypeContainer, Max: map[string]string{"cpu": "two"}},
+	})
+	require.Error(t, err)
+	assert.ErrorIs(t, err, ErrInvalidWorkloadOperation)
+	assert.Contains(t, err.Error(), "Node")
+
+	_, err = buildLimitRangeSpec([]LimitRangeItem{{Type: corev1.LimitTypeContainer, Max: map[string]string{"cpu": "two"}}})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "limits[0].max.cpu")
+}
+
+func assertQuantity(t *testing.T, expected string, actual resource.Quantity) {
+	t.Helper()
+	want := resource.MustParse(expected)
+	assert.Zero(t, want.Cmp(actual), "expected %s, got %s", expected, actual.String())
+}
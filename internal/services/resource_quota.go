@@ -0,0 +1,337 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// ResourceQuotaRequest 创建/更新 ResourceQuota 请求，hard 的值使用 Kubernetes 数量格式（如 4、500m、8Gi）
+type ResourceQuotaRequest struct {
+	Name   string                      `json:"name"`
+	Labels map[string]string           `json:"labels"`
+	Hard   map[string]string           `json:"hard"`
+	Scopes []corev1.ResourceQuotaScope `json:"scopes,omitempty"`
+}
+
+// ResourceQuotaDetail ResourceQuota 详情
+type ResourceQuotaDetail struct {
+	Name      string            `json:"name"`
+	Namespace string            `json:"namespace"`
+	Labels    map[string]string `json:"labels"`
+	Hard      map[string]string `json:"hard"`
+	Used      map[string]string `json:"used"`
+	Scopes    []string          `json:"scopes"`
+	CreatedAt time.Time         `json:"createdAt"`
+}
+
+// LimitRangeItem LimitRange 中单类对象（Container/Pod/PersistentVolumeClaim）的限制
+type LimitRangeItem struct {
+	Type                 corev1.LimitType  `json:"type"`
+	Max                  map[string]string `json:"max,omitempty"`
+	Min                  map[string]string `json:"min,omitempty"`
+	Default              map[string]string `json:"default,omitempty"`        // 默认 limits
+	DefaultRequest       map[string]string `json:"defaultRequest,omitempty"` // 默认 requests
+	MaxLimitRequestRatio map[string]string `json:"maxLimitRequestRatio,omitempty"`
+}
+
+// LimitRangeRequest 创建/更新 LimitRange 请求
+type LimitRangeRequest struct {
+	Name   string            `json:"name"`
+	Labels map[string]string `json:"labels"`
+	Limits []LimitRangeItem  `json:"limits"`
+}
+
+// LimitRangeDetail LimitRange 详情
+type LimitRangeDetail struct {
+	Name      string            `json:"name"`
+	Namespace string            `json:"namespace"`
+	Labels    map[string]string `json:"labels"`
+	Limits    []LimitRangeItem  `json:"limits"`
+	CreatedAt time.Time         `json:"createdAt"`
+}
+
+// QuotaUsageItem 单个配额资源的使用情况
+type QuotaUsageItem struct {
+	Quota    string  `json:"quota"`
+	Resource string  `json:"resource"`
+	Hard     string  `json:"hard"`
+	Used     string  `json:"used"`
+	Percent  float64 `json:"percent"` // 使用率（0-100，可能超过 100）
+}
+
+// NamespaceQuotaUsage 命名空间配额使用情况
+type NamespaceQuotaUsage struct {
+	Namespace string           `json:"namespace"`
+	Items     []QuotaUsageItem `json:"items"`
+}
+
+var validLimitTypes = map[corev1.LimitType]bool{
+	corev1.LimitTypeContainer:             true,
+	corev1.LimitTypePod:                   true,
+	corev1.LimitTypePersistentVolumeClaim: true,
+}
+
+// ToResourceQuotaDetail 转换为 ResourceQuota 详情
+func ToResourceQuotaDetail(q *corev1.ResourceQuota) ResourceQuotaDetail {
+	scopes := make([]string, 0, len(q.Spec.Scopes))
+	for _, s := range q.Spec.Scopes {
+		scopes = append(scopes, string(s))
+	}
+	return ResourceQuotaDetail{
+		Name:      q.Name,
+		Namespace: q.Namespace,
+		Labels:    q.Labels,
+		Hard:      convertQuantities(q.Spec.Hard),
+		Used:      convertQuantities(q.Status.Used),
+		Scopes:    scopes,
+		CreatedAt: q.CreationTimestamp.Time,
+	}
+}
+
+// ToLimitRangeDetail 转换为 LimitRange 详情
+func ToLimitRangeDetail(lr *corev1.LimitRange) LimitRangeDetail {
+	limits := make([]LimitRangeItem, 0, len(lr.Spec.Limits))
+	for _, item := range lr.Spec.Limits {
+		limits = append(limits, LimitRangeItem{
+			Type:                 item.Type,
+			Max:                  convertQuantities(item.Max),
+			Min:                  convertQuantities(item.Min),
+			Default:              convertQuantities(item.Default),
+			DefaultRequest:       convertQuantities(item.DefaultRequest),
+			MaxLimitRequestRatio: convertQuantities(item.MaxLimitRequestRatio),
+		})
+	}
+	return LimitRangeDetail{
+		Name:      lr.Name,
+		Namespace: lr.Namespace,
+		Labels:    lr.Labels,
+		Limits:    limits,
+		CreatedAt: lr.CreationTimestamp.Time,
+	}
+}
+
+// ListResourceQuotas 获取命名空间的 ResourceQuota 列表
+func ListResourceQuotas(ctx context.Context, clientset kubernetes.Interface, namespace string) ([]ResourceQuotaDetail, error) {
+	list, err := clientset.CoreV1().ResourceQuotas(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("获取ResourceQuota列表失败: %w", err)
+	}
+	items := make([]ResourceQuotaDetail, 0, len(list.Items))
+	for i := range list.Items {
+		items = append(items, ToResourceQuotaDetail(&list.Items[i]))
+	}
+	sort.Slice(items, func(i, j int) bool { return items[i].Name < items[j].Name })
+	return items, nil
+}
+
+// CreateResourceQuota 创建 ResourceQuota
+func CreateResourceQuota(ctx context.Context, clientset kubernetes.Interface, namespace string, req *ResourceQuotaRequest) (*corev1.ResourceQuota, error) {
+	if req.Name == "" {
+		return nil, fmt.Errorf("%w: 名称不能为空", ErrInvalidWorkloadOperation)
+	}
+	hard, err := parseQuantities("hard", req.Hard)
+	if err != nil {
+		return nil, err
+	}
+	quota := &corev1.ResourceQuota{
+		ObjectMeta: metav1.ObjectMeta{Name: req.Name, Namespace: namespace, Labels: req.Labels},
+		Spec:       corev1.ResourceQuotaSpec{Hard: hard, Scopes: req.Scopes},
+	}
+	return clientset.CoreV1().ResourceQuotas(namespace).Create(ctx, quota, metav1.CreateOptions{})
+}
+
+// UpdateResourceQuota 更新 ResourceQuota 的标签、配额与作用域，返回更新前与更新后的对象
+func UpdateResourceQuota(ctx context.Context, clientset kubernetes.Interface, namespace, name string, req *ResourceQuotaRequest) (*corev1.ResourceQuota, *corev1.ResourceQuota, error) {
+	hard, err := parseQuantities("hard", req.Hard)
+	if err != nil {
+		return nil, nil, err
+	}
+	quota, err := clientset.CoreV1().ResourceQuotas(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, nil, err
+	}
+	prev := quota.DeepCopy()
+	quota.Labels = req.Labels
+	quota.Spec.Hard = hard
+	quota.Spec.Scopes = req.Scopes
+	updated, err := clientset.CoreV1().ResourceQuotas(namespace).Update(ctx, quota, metav1.UpdateOptions{})
+	if err != nil {
+		return nil, nil, err
+	}
+	return prev, updated, nil
+}
+
+// DeleteResourceQuota 删除 ResourceQuota
+func DeleteResourceQuota(ctx context.Context, clientset kubernetes.Interface, namespace, name string) error {
+	return clientset.CoreV1().ResourceQuotas(namespace).Delete(ctx, name, metav1.DeleteOptions{})
+}
+
+// ListLimitRanges 获取命名空间的 LimitRange 列表
+func ListLimitRanges(ctx context.Context, clientset kubernetes.Interface, namespace string) ([]LimitRangeDetail, error) {
+	list, err := clientset.CoreV1().LimitRanges(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("获取LimitRange列表失败: %w", err)
+	}
+	items := make([]LimitRangeDetail, 0, len(list.Items))
+	for i := range list.Items {
+		items = append(items, ToLimitRangeDetail(&list.Items[i]))
+	}
+	sort.Slice(items, func(i, j int) bool { return items[i].Name < items[j].Name })
+	return items, nil
+}
+
+// CreateLimitRange 创建 LimitRange
+func CreateLimitRange(ctx context.Context, clientset kubernetes.Interface, namespace string, req *LimitRangeRequest) (*corev1.LimitRange, error) {
+	if req.Name == "" {
+		return nil, fmt.Errorf("%w: 名称不能为空", ErrInvalidWorkloadOperation)
+	}
+	spec, err := buildLimitRangeSpec(req.Limits)
+	if err != nil {
+		return nil, err
+	}
+	lr := &corev1.LimitRange{
+		ObjectMeta: metav1.ObjectMeta{Name: req.Name, Namespace: namespace, Labels: req.Labels},
+		Spec:       spec,
+	}
+	return clientset.CoreV1().LimitRanges(namespace).Create(ctx, lr, metav1.CreateOptions{})
+}
+
+// UpdateLimitRange 更新 LimitRange 的标签与限制，返回更新前与更新后的对象
+func UpdateLimitRange(ctx context.Context, clientset kubernetes.Interface, namespace, name string, req *LimitRangeRequest) (*corev1.LimitRange, *corev1.LimitRange, error) {
+	spec, err := buildLimitRangeSpec(req.Limits)
+	if err != nil {
+		return nil, nil, err
+	}
+	lr, err := clientset.CoreV1().LimitRanges(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, nil, err
+	}
+	prev := lr.DeepCopy()
+	lr.Labels = req.Labels
+	lr.Spec = spec
+	updated, err := clientset.CoreV1().LimitRanges(namespace).Update(ctx, lr, metav1.UpdateOptions{})
+	if err != nil {
+		return nil, nil, err
+	}
+	return prev, updated, nil
+}
+
+// DeleteLimitRange 删除 LimitRange
+func DeleteLimitRange(ctx context.Context, clientset kubernetes.Interface, namespace, name string) error {
+	return clientset.CoreV1().LimitRanges(namespace).Delete(ctx, name, metav1.DeleteOptions{})
+}
+
+// BuildNamespaceQuotaUsage 汇总命名空间内所有 ResourceQuota 每项资源的已用量与上限
+func BuildNamespaceQuotaUsage(namespace string, quotas []corev1.ResourceQuota) *NamespaceQuotaUsage {
+	usage := &NamespaceQuotaUsage{Namespace: namespace, Items: []QuotaUsageItem{}}
+	for _, q := range quotas {
+		// 优先使用 status.hard（已被配额控制器确认），尚未同步时使用 spec.hard
+		hard := q.Status.Hard
+		if len(hard) == 0 {
+			hard = q.Spec.Hard
+		}
+		for name, h := range hard {
+			used := q.Status.Used[name]
+			usage.Items = append(usage.Items, QuotaUsageItem{
+				Quota:    q.Name,
+				Resource: string(name),
+				Hard:     h.String(),
+				Used:     used.String(),
+				Percent:  quantityPercent(used, h),
+			})
+		}
+	}
+	sort.Slice(usage.Items, func(i, j int) bool {
+		if usage.Items[i].Quota != usage.Items[j].Quota {
+			return usage.Items[i].Quota < usage.Items[j].Quota
+		}
+		return usage.Items[i].Resource < usage.Items[j].Resource
+	})
+	return usage
+}
+
+func buildLimitRangeSpec(items []LimitRangeItem) (corev1.LimitRangeSpec, error) {
+	spec := corev1.LimitRangeSpec{Limits: make([]corev1.LimitRangeItem, 0, len(items))}
+	if len(items) == 0 {
+		return spec, fmt.Errorf("%w: limits 不能为空", ErrInvalidWorkloadOperation)
+	}
+	for i, item := range items {
+		if !validLimitTypes[item.Type] {
+			return spec, fmt.Errorf("%w: limits[%d] 的类型 %q 无效，可选值: Container、Pod、PersistentVolumeClaim", ErrInvalidWorkloadOperation, i, item.Type)
+		}
+		prefix := fmt.Sprintf("limits[%d].", i)
+		out := corev1.LimitRangeItem{Type: item.Type}
+		var err error
+		if out.Max, err = parseQuantities(prefix+"max", item.Max); err != nil {
+			return spec, err
+		}
+		if out.Min, err = parseQuantities(prefix+"min", item.Min); err != nil {
+			return spec, err
+		}
+		if out.Default, err = parseQuantities(prefix+"default", item.Default); err != nil {
+			return spec, err
+		}
+		if out.DefaultRequest, err = parseQuantities(prefix+"defaultRequest", item.DefaultRequest); err != nil {
+			return spec, err
+		}
+		if out.MaxLimitRequestRatio, err = parseQuantities(prefix+"maxLimitRequestRatio", item.MaxLimitRequestRatio); err != nil {
+			return spec, err
+		}
+		spec.Limits = append(spec.Limits, out)
+	}
+	return spec, nil
+}
+
+// parseQuantities 解析资源数量，任一值格式错误时返回 ErrInvalidWorkloadOperation
+func parseQuantities(field string, values map[string]string) (corev1.ResourceList, error) {
+	if len(values) == 0 {
+		return nil, nil
+	}
+	list := make(corev1.ResourceList, len(values))
+	var errs []string
+	for name, value := range values {
+		q, err := resource.ParseQuantity(strings.TrimSpace(value))
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("%s.%s 的值 %q 无效", field, name, value))
+			continue
+		}
+		list[corev1.ResourceName(name)] = q
+	}
+	if len(errs) > 0 {
+		sort.Strings(errs)
+		return nil, fmt.Errorf("%w: %s", ErrInvalidWorkloadOperation, strings.Join(errs, "; "))
+	}
+	return list, nil
+}
+
+func convertQuantities(list corev1.ResourceList) map[string]string {
+	if len(list) == 0 {
+		return nil
+	}
+	result := make(map[string]string, len(list))
+	for name, q := range list {
+		result[string(name)] = q.String()
+	}
+	return result
+}
+
+func quantityPercent(used, hard resource.Quantity) float64 {
+	h := hard.AsApproximateFloat64()
+	u := used.AsApproximateFloat64()
+	if h <= 0 {
+		if u > 0 {
+			return 100
+		}
+		return 0
+	}
+	return math.Round(u/h*1000) / 10
+}
@@ -0,0 +1,202 @@
+package services
+
+import (
+	"fmt"
+
+	"github.com/clay-wangzhi/KubePolaris/internal/models"
+	"github.com/clay-wangzhi/KubePolaris/pkg/crypto"
+
+	"gorm.io/gorm"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	sigsyaml "sigs.k8s.io/yaml"
+)
+
+// 触发资源快照的操作
+const (
+	RevisionActionApply    = "apply"
+	RevisionActionUpdate   = "update"
+	RevisionActionRevert   = "revert"
+	RevisionActionRollback = "rollback"
+)
+
+// maxRevisionsPerObject 每个资源保留的最大修订数，超出后删除最旧的记录
+const maxRevisionsPerObject = 50
+
+// ResourceRevisionService 资源修订历史服务
+type ResourceRevisionService struct {
+	db *gorm.DB
+}
+
+// NewResourceRevisionService 创建资源修订历史服务
+func NewResourceRevisionService(db *gorm.DB) *ResourceRevisionService {
+	return &ResourceRevisionService{db: db}
+}
+
+// RevisionOperator 修改资源的操作者
+type RevisionOperator struct {
+	UserID   uint
+	Username string
+}
+
+// ResourceRevisionListRequest 修订列表查询条件
+type ResourceRevisionListRequest struct {
+	ClusterID uint
+	Kind      string
+	Namespace string
+	Name      string
+	Page      int
+	PageSize  int
+}
+
+// ResourceRevisionListResponse 修订列表
+type ResourceRevisionListResponse struct {
+	Items    []models.ResourceRevision `json:"items"`
+	Total    int64                     `json:"total"`
+	Page     int                       `json:"page"`
+	PageSize int                       `json:"pageSize"`
+}
+
+// Record 保存资源变更前的快照
+// 快照去除 managedFields 与 status；Secret 的内容整体加密存储。
+func (s *ResourceRevisionService) Record(clusterID uint, obj *unstructured.Unstructured, action string, operator RevisionOperator) (*models.ResourceRevision, error) {
+	snapshot := obj.DeepCopy()
+	snapshot.SetManagedFields(nil)
+	unstructured.RemoveNestedField(snapshot.Object, "status")
+
+	data, err := sigsyaml.Marshal(snapshot.Object)
+	if err != nil {
+		return nil, fmt.Errorf("序列化资源快照失败: %w", err)
+	}
+
+	rev := &models.ResourceRevision{
+		ClusterID:       clusterID,
+		APIVersion:      snapshot.GetAPIVersion(),
+		Kind:            snapshot.GetKind(),
+		Namespace:       snapshot.GetNamespace(),
+		Name:            snapshot.GetName(),
+		ResourceVersion: snapshot.GetResourceVersion(),
+		Action:          action,
+		Content:         string(data),
+		Username:        operator.Username,
+	}
+	if operator.UserID > 0 {
+		uid := operator.UserID
+		rev.UserID = &uid
+	}
+	if isSecretObject(snapshot) {
+		enc, err := crypto.Encrypt(rev.Content)
+		if err != nil {
+			return nil, fmt.Errorf("加密 Secret 快照失败: %w", err)
+		}
+		rev.Content = enc
+		rev.Encrypted = true
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		object := tx.Model(&models.ResourceRevision{}).Where("cluster_id = ? AND kind = ? AND namespace = ? AND name = ?",
+			clusterID, rev.Kind, rev.Namespace, rev.Name)
+
+		var latest int
+		if err := object.Session(&gorm.Session{}).Select("COALESCE(MAX(revision), 0)").Scan(&latest).Error; err != nil {
+			return err
+		}
+		rev.Revision = latest + 1
+		if err := tx.Create(rev).Error; err != nil {
+			return err
+		}
+
+		// 清理超出保留数量的旧修订
+		return object.Session(&gorm.Session{}).Where("revision <= ?", rev.Revision-maxRevisionsPerObject).
+			Delete(&models.ResourceRevision{}).Error
+	})
+	if err != nil {
+		return nil, fmt.Errorf("保存资源修订失败: %w", err)
+	}
+	return rev, nil
+}
+
+// RecordTyped 保存 typed 对象（如 *corev1.ConfigMap）的快照，gvk 用于补全对象中缺失的 apiVersion/kind
+func (s *ResourceRevisionService) RecordTyped(clusterID uint, obj runtime.Object, gvk schema.GroupVersionKind, action string, operator RevisionOperator) (*models.ResourceRevision, error) {
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		return nil, fmt.Errorf("转换资源失败: %w", err)
+	}
+	u := &unstructured.Unstructured{Object: content}
+	u.SetGroupVersionKind(gvk)
+	return s.Record(clusterID, u, action, operator)
+}
+
+// List 分页查询修订（不含快照内容），按创建时间倒序
+func (s *ResourceRevisionService) List(req *ResourceRevisionListRequest) (*ResourceRevisionListResponse, error) {
+	query := s.db.Model(&models.ResourceRevision{}).Where("cluster_id = ?", req.ClusterID)
+	if req.Kind != "" {
+		query = query.Where("kind = ?", req.Kind)
+	}
+	if req.Namespace != "" {
+		query = query.Where("namespace = ?", req.Namespace)
+	}
+	if req.Name != "" {
+		query = query.Where("name = ?", req.Name)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, err
+	}
+
+	if req.Page <= 0 {
+		req.Page = 1
+	}
+	if req.PageSize <= 0 {
+		req.PageSize = 20
+	}
+
+	var items []models.ResourceRevision
+	if err := query.Omit("content").Order("created_at DESC, id DESC").
+		Offset((req.Page - 1) * req.PageSize).Limit(req.PageSize).Find(&items).Error; err != nil {
+		return nil, err
+	}
+	return &ResourceRevisionListResponse{
+		Items:    items,
+		Total:    total,
+		Page:     req.Page,
+		PageSize: req.PageSize,
+	}, nil
+}
+
+// Get 获取修订记录
+func (s *ResourceRevisionService) Get(clusterID, id uint) (*models.ResourceRevision, error) {
+	var rev models.ResourceRevision
+	if err := s.db.Where("cluster_id = ?", clusterID).First(&rev, id).Error; err != nil {
+		return nil, err
+	}
+	return &rev, nil
+}
+
+// Object 解密并解析修订中保存的资源对象
+func (s *ResourceRevisionService) Object(rev *models.ResourceRevision) (*unstructured.Unstructured, error) {
+	content := rev.Content
+	if rev.Encrypted {
+		plain, err := crypto.Decrypt(content)
+		if err != nil {
+			return nil, fmt.Errorf("解密资源快照失败: %w", err)
+		}
+		content = plain
+	}
+	obj := &unstructured.Unstructured{}
+	if err := sigsyaml.Unmarshal([]byte(content), &obj.Object); err != nil {
+		return nil, fmt.Errorf("解析资源快照失败: %w", err)
+	}
+	return obj, nil
+}
+
+// MaskedYAML 返回用于展示的快照 YAML，Secret 的 data/stringData 取值被脱敏
+func MaskedYAML(obj *unstructured.Unstructured) string {
+	return renderDiffYAML(obj.Object, isSecretObject(obj))
+}
+
+func isSecretObject(obj *unstructured.Unstructured) bool {
+	return obj != nil && obj.GetKind() == "Secret" && obj.GetAPIVersion() == "v1"
+}
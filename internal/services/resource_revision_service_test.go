@@ -0,0 +1,84 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/clay-wangzhi/KubePolaris/internal/models"
+	"github.com/clay-wangzhi/KubePolaris/pkg/crypto"
+
+	"github.com/glebarez/sqlite"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func newRevisionTestService(t *testing.T) *ResourceRevisionService {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&models.ResourceRevision{}))
+	return NewResourceRevisionService(db)
+}
+
+func TestResourceRevisionRecordAndList(t *testing.T) {
+	svc := newRevisionTestService(t)
+	operator := RevisionOperator{UserID: 1, Username: "admin"}
+
+	cm := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "ConfigMap",
+		"metadata":   map[string]interface{}{"name": "app", "namespace": "demo", "resourceVersion": "10"},
+		"data":       map[string]interface{}{"key": "v1"},
+	}}
+	first, err := svc.Record(1, cm, RevisionActionApply, operator)
+	require.NoError(t, err)
+	assert.Equal(t, 1, first.Revision)
+	assert.False(t, first.Encrypted)
+
+	cm.Object["data"] = map[string]interface{}{"key": "v2"}
+	second, err := svc.Record(1, cm, RevisionActionUpdate, operator)
+	require.NoError(t, err)
+	assert.Equal(t, 2, second.Revision)
+
+	list, err := svc.List(&ResourceRevisionListRequest{ClusterID: 1, Kind: "ConfigMap", Namespace: "demo", Name: "app"})
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, list.Total)
+	require.Len(t, list.Items, 2)
+	assert.Empty(t, list.Items[0].Content)
+
+	rev, err := svc.Get(1, first.ID)
+	require.NoError(t, err)
+	obj, err := svc.Object(rev)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"key": "v1"}, obj.Object["data"])
+
+	_, err = svc.Get(2, first.ID)
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+}
+
+func TestResourceRevisionEncryptsSecret(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	kr, err := crypto.NewKeyring(key)
+	require.NoError(t, err)
+	crypto.SetDefault(kr)
+	defer crypto.SetDefault(nil)
+
+	svc := newRevisionTestService(t)
+	secret := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "Secret",
+		"metadata":   map[string]interface{}{"name": "db", "namespace": "demo"},
+		"data":       map[string]interface{}{"password": "c2VjcmV0"},
+	}}
+	rev, err := svc.Record(1, secret, RevisionActionUpdate, RevisionOperator{})
+	require.NoError(t, err)
+	assert.True(t, rev.Encrypted)
+	assert.NotContains(t, rev.Content, "c2VjcmV0")
+
+	obj, err := svc.Object(rev)
+	require.NoError(t, err)
+	assert.Equal(t, "c2VjcmV0", obj.Object["data"].(map[string]interface{})["password"])
+	assert.NotContains(t, MaskedYAML(obj), "c2VjcmV0")
+}
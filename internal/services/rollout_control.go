@@ -0,0 +1,491 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	rollouts "github.com/argoproj/argo-rollouts/pkg/apis/rollouts/v1alpha1"
+	rolloutsclientset "github.com/argoproj/argo-rollouts/pkg/client/clientset/versioned"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+)
+
+// RolloutStepInfo Rollout 金丝雀步骤
+type RolloutStepInfo struct {
+	Index       int32  `json:"index"`
+	Type        string `json:"type"` // setWeight / pause / analysis / experiment / setCanaryScale / setHeaderRoute / setMirrorRoute
+	Description string `json:"description"`
+}
+
+// RolloutAnalysisMetric AnalysisRun 中单个指标的结果
+type RolloutAnalysisMetric struct {
+	Name         string `json:"name"`
+	Phase        string `json:"phase"`
+	Message      string `json:"message,omitempty"`
+	Count        int32  `json:"count"`
+	Successful   int32  `json:"successful"`
+	Failed       int32  `json:"failed"`
+	Inconclusive int32  `json:"inconclusive"`
+	Error        int32  `json:"error"`
+}
+
+// RolloutAnalysisRunInfo Rollout 关联的 AnalysisRun
+type RolloutAnalysisRunInfo struct {
+	Name        string                  `json:"name"`
+	Phase       string                  `json:"phase"`
+	Message     string                  `json:"message,omitempty"`
+	Metrics     []RolloutAnalysisMetric `json:"metrics"`
+	StartedAt   *time.Time              `json:"startedAt,omitempty"`
+	CompletedAt *time.Time              `json:"completedAt,omitempty"`
+	CreatedAt   time.Time               `json:"createdAt"`
+}
+
+// RolloutExperimentTemplate Experiment 中单个模板的状态
+type RolloutExperimentTemplate struct {
+	Name              string `json:"name"`
+	Status            string `json:"status"`
+	Message           string `json:"message,omitempty"`
+	Replicas          int32  `json:"replicas"`
+	ReadyReplicas     int32  `json:"readyReplicas"`
+	AvailableReplicas int32  `json:"availableReplicas"`
+}
+
+// RolloutExperimentInfo Rollout 关联的 Experiment
+type RolloutExperimentInfo struct {
+	Name         string                      `json:"name"`
+	Phase        string                      `json:"phase"`
+	Message      string                      `json:"message,omitempty"`
+	Templates    []RolloutExperimentTemplate `json:"templates"`
+	AnalysisRuns []string                    `json:"analysisRuns"`
+	CreatedAt    time.Time                   `json:"createdAt"`
+}
+
+// RolloutControlStatus Rollout 的发布状态：当前步骤、权重与关联的分析/实验
+type RolloutControlStatus struct {
+	Name              string                   `json:"name"`
+	Namespace         string                   `json:"namespace"`
+	Strategy          string                   `json:"strategy"` // canary / blueGreen
+	Phase             string                   `json:"phase"`
+	Message           string                   `json:"message,omitempty"`
+	Paused            bool                     `json:"paused"`
+	PauseReasons      []string                 `json:"pauseReasons"`
+	Aborted           bool                     `json:"aborted"`
+	StableRS          string                   `json:"stableRS"`
+	CurrentPodHash    string                   `json:"currentPodHash"`
+	Replicas          int32                    `json:"replicas"`
+	UpdatedReplicas   int32                    `json:"updatedReplicas"`
+	ReadyReplicas     int32                    `json:"readyReplicas"`
+	AvailableReplicas int32                    `json:"availableReplicas"`
+	CurrentStepIndex  *int32                   `json:"currentStepIndex,omitempty"`
+	CurrentStep       *RolloutStepInfo         `json:"currentStep,omitempty"`
+	Steps             []RolloutStepInfo        `json:"steps"`
+	SetWeight         *int32                   `json:"setWeight,omitempty"`    // 当前步骤期望的金丝雀权重
+	ActualWeight      *int32                   `json:"actualWeight,omitempty"` // 流量管理器上报的实际金丝雀权重
+	AnalysisRuns      []RolloutAnalysisRunInfo `json:"analysisRuns"`
+	Experiments       []RolloutExperimentInfo  `json:"experiments"`
+}
+
+// GetRolloutControlStatus 获取 Rollout 的发布状态及关联的 AnalysisRun/Experiment
+func GetRolloutControlStatus(ctx context.Context, client rolloutsclientset.Interface, namespace, name string) (*RolloutControlStatus, error) {
+	ro, err := client.ArgoprojV1alpha1().Rollouts(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	status := BuildRolloutControlStatus(ro)
+
+	runs, err := client.ArgoprojV1alpha1().AnalysisRuns(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("获取AnalysisRun列表失败: %w", err)
+	}
+	for i := range runs.Items {
+		run := &runs.Items[i]
+		if ref := metav1.GetControllerOf(run); ref == nil || ref.UID != ro.UID {
+			continue
+		}
+		status.AnalysisRuns = append(status.AnalysisRuns, analysisRunInfo(run))
+	}
+	sort.Slice(status.AnalysisRuns, func(i, j int) bool {
+		return status.AnalysisRuns[i].CreatedAt.After(status.AnalysisRuns[j].CreatedAt)
+	})
+
+	experiments, err := client.ArgoprojV1alpha1().Experiments(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("获取Experiment列表失败: %w", err)
+	}
+	for i := range experiments.Items {
+		ex := &experiments.Items[i]
+		if ref := metav1.GetControllerOf(ex); ref == nil || ref.UID != ro.UID {
+			continue
+		}
+		status.Experiments = append(status.Experiments, experimentInfo(ex))
+	}
+	sort.Slice(status.Experiments, func(i, j int) bool {
+		return status.Experiments[i].CreatedAt.After(status.Experiments[j].CreatedAt)
+	})
+	return status, nil
+}
+
+// BuildRolloutControlStatus 根据 Rollout 对象计算当前步骤与权重（不含关联的分析/实验）
+func BuildRolloutControlStatus(ro *rollouts.Rollout) *RolloutControlStatus {
+	status := &RolloutControlStatus{
+		Name:              ro.Name,
+		Namespace:         ro.Namespace,
+		Phase:             string(ro.Status.Phase),
+		Message:           ro.Status.Message,
+		Paused:            ro.Spec.Paused,
+		PauseReasons:      []string{},
+		Aborted:           ro.Status.Abort,
+		StableRS:          ro.Status.StableRS,
+		CurrentPodHash:    ro.Status.CurrentPodHash,
+		Replicas:          ro.Status.Replicas,
+		UpdatedReplicas:   ro.Status.UpdatedReplicas,
+		ReadyReplicas:     ro.Status.ReadyReplicas,
+		AvailableReplicas: ro.Status.AvailableReplicas,
+		Steps:             []RolloutStepInfo{},
+		AnalysisRuns:      []RolloutAnalysisRunInfo{},
+		Experiments:       []RolloutExperimentInfo{},
+	}
+	for _, cond := range ro.Status.PauseConditions {
+		status.PauseReasons = append(status.PauseReasons, string(cond.Reason))
+	}
+
+	canary := ro.Spec.Strategy.Canary
+	if canary == nil {
+		if ro.Spec.Strategy.BlueGreen != nil {
+			status.Strategy = "blueGreen"
+		}
+		return status
+	}
+	status.Strategy = "canary"
+	for i, step := range canary.Steps {
+		status.Steps = append(status.Steps, describeCanaryStep(int32(i), step))
+	}
+	if ro.Status.Canary.Weights != nil {
+		actual := ro.Status.Canary.Weights.Canary.Weight
+		status.ActualWeight = &actual
+	}
+
+	if len(canary.Steps) == 0 {
+		weight := int32(100)
+		if ro.Status.Abort {
+			weight = 0
+		}
+		status.SetWeight = &weight
+		return status
+	}
+
+	index := int32(0)
+	if ro.Status.CurrentStepIndex != nil {
+		index = *ro.Status.CurrentStepIndex
+	}
+	status.CurrentStepIndex = &index
+	weight := int32(100) // 全部步骤已完成
+	if int(index) < len(canary.Steps) {
+		current := status.Steps[index]
+		status.CurrentStep = &current
+		weight = 0
+		for i := index; i >= 0; i-- {
+			if w := canary.Steps[i].SetWeight; w != nil {
+				weight = *w
+				break
+			}
+		}
+	}
+	if ro.Status.Abort {
+		weight = 0
+	}
+	status.SetWeight = &weight
+	return status
+}
+
+// PromoteRollout 推进 Rollout：full 为 false 时进入下一步（与 kubectl argo rollouts promote 一致），
+// full 为 true 时跳过剩余步骤、分析与暂停直接全量发布
+func PromoteRollout(ctx context.Context, client rolloutsclientset.Interface, namespace, name string, full bool) (*RolloutControlStatus, error) {
+	ro, err := client.ArgoprojV1alpha1().Rollouts(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	// 用户手动暂停（spec.paused）时先恢复，全量发布同样需要（与 kubectl argo rollouts promote --full 一致）
+	if ro.Spec.Paused {
+		if ro, err = patchRolloutSpec(ctx, client, namespace, name, map[string]interface{}{"paused": false}); err != nil {
+			return nil, err
+		}
+	}
+
+	if full {
+		if ro.Status.CurrentPodHash == ro.Status.StableRS {
+			return BuildRolloutControlStatus(ro), nil
+		}
+		return patchRolloutStatus(ctx, client, namespace, name, map[string]interface{}{"promoteFull": true})
+	}
+
+	canary := ro.Spec.Strategy.Canary
+	inconclusive := canary != nil && ro.Status.Canary.CurrentStepAnalysisRunStatus != nil &&
+		ro.Status.Canary.CurrentStepAnalysisRunStatus.Status == rollouts.AnalysisPhaseInconclusive
+	switch {
+	case inconclusive && len(ro.Status.PauseConditions) > 0 && ro.Status.ControllerPause:
+		// 分析结果不确定时需要同时清除控制器暂停并进入下一步，否则会卡在下一个暂停步骤
+		return patchRolloutStatus(ctx, client, namespace, name, map[string]interface{}{
+			"pauseConditions":  nil,
+			"controllerPause":  false,
+			"currentStepIndex": nextCanaryStepIndex(ro),
+		})
+	case len(ro.Status.PauseConditions) > 0:
+		return patchRolloutStatus(ctx, client, namespace, name, map[string]interface{}{"pauseConditions": nil})
+	case canary != nil && len(canary.Steps) > 0:
+		return patchRolloutStatus(ctx, client, namespace, name, map[string]interface{}{
+			"pauseConditions":  nil,
+			"currentStepIndex": nextCanaryStepIndex(ro),
+		})
+	default:
+		return BuildRolloutControlStatus(ro), nil
+	}
+}
+
+// AbortRollout 中止 Rollout，流量与副本回到稳定版本
+func AbortRollout(ctx context.Context, client rolloutsclientset.Interface, namespace, name string) (*RolloutControlStatus, error) {
+	return patchRolloutStatus(ctx, client, namespace, name, map[string]interface{}{"abort": true})
+}
+
+// RetryRollout 重试已中止的 Rollout
+func RetryRollout(ctx context.Context, client rolloutsclientset.Interface, namespace, name string) (*RolloutControlStatus, error) {
+	return patchRolloutStatus(ctx, client, namespace, name, map[string]interface{}{"abort": false})
+}
+
+// SetRolloutPaused 暂停或恢复 Rollout
+func SetRolloutPaused(ctx context.Context, client rolloutsclientset.Interface, namespace, name string, paused bool) (*RolloutControlStatus, error) {
+	ro, err := patchRolloutSpec(ctx, client, namespace, name, map[string]interface{}{"paused": paused})
+	if err != nil {
+		return nil, err
+	}
+	return BuildRolloutControlStatus(ro), nil
+}
+
+// RestartRollout 滚动重启 Rollout 的全部 Pod
+func RestartRollout(ctx context.Context, client rolloutsclientset.Interface, namespace, name string) (*RolloutControlStatus, error) {
+	ro, err := patchRolloutSpec(ctx, client, namespace, name, map[string]interface{}{
+		"restartAt": time.Now().UTC().Format(time.RFC3339),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return BuildRolloutControlStatus(ro), nil
+}
+
+// SetRolloutCanaryWeight 将金丝雀发布跳转到 setWeight 等于 weight 的第一个步骤
+// Argo Rollouts 的权重只能由步骤声明，因此通过调整当前步骤实现权重设置。
+func SetRolloutCanaryWeight(ctx context.Context, client rolloutsclientset.Interface, namespace, name string, weight int32) (*RolloutControlStatus, error) {
+	ro, err := client.ArgoprojV1alpha1().Rollouts(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	canary := ro.Spec.Strategy.Canary
+	if canary == nil || len(canary.Steps) == 0 {
+		return nil, fmt.Errorf("%w: 只有配置了步骤的金丝雀发布才能设置权重", ErrInvalidWorkloadOperation)
+	}
+
+	weights := make([]string, 0)
+	for i, step := range canary.Steps {
+		if step.SetWeight == nil {
+			continue
+		}
+		if *step.SetWeight == weight {
+			return patchRolloutStatus(ctx, client, namespace, name, map[string]interface{}{
+				"pauseConditions":  nil,
+				"currentStepIndex": i,
+			})
+		}
+		weights = append(weights, fmt.Sprintf("%d", *step.SetWeight))
+	}
+	return nil, fmt.Errorf("%w: 步骤中没有 setWeight 为 %d 的步骤，可选权重: %s", ErrInvalidWorkloadOperation, weight, strings.Join(weights, ", "))
+}
+
+// SetRolloutImages 更新 Rollout 容器镜像；使用 workloadRef 引用 Deployment 时更新被引用的 Deployment
+func SetRolloutImages(ctx context.Context, client rolloutsclientset.Interface, clientset kubernetes.Interface, namespace, name string, images map[string]string) (*RolloutControlStatus, error) {
+	if len(images) == 0 {
+		return nil, fmt.Errorf("%w: 未指定需要更新的容器镜像", ErrInvalidWorkloadOperation)
+	}
+	ro, err := client.ArgoprojV1alpha1().Rollouts(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	if ref := ro.Spec.WorkloadRef; ref != nil {
+		if ref.Kind != WorkloadKindDeployment {
+			return nil, fmt.Errorf("%w: 不支持更新 workloadRef 类型 %s 的镜像", ErrInvalidWorkloadOperation, ref.Kind)
+		}
+		if _, err := SetWorkloadImages(ctx, clientset, WorkloadKindDeployment, namespace, ref.Name, images); err != nil {
+			return nil, err
+		}
+		return BuildRolloutControlStatus(ro), nil
+	}
+
+	matched := make(map[string]bool, len(images))
+	podSpec := &ro.Spec.Template.Spec
+	for _, containers := range [][]corev1.Container{podSpec.InitContainers, podSpec.Containers} {
+		for i := range containers {
+			if image, ok := images[containers[i].Name]; ok {
+				containers[i].Image = image
+				matched[containers[i].Name] = true
+			}
+		}
+	}
+	var missing []string
+	for container, image := range images {
+		if strings.TrimSpace(image) == "" {
+			return nil, fmt.Errorf("%w: 容器 %s 的镜像不能为空", ErrInvalidWorkloadOperation, container)
+		}
+		if !matched[container] {
+			missing = append(missing, container)
+		}
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return nil, fmt.Errorf("%w: 容器不存在: %s", ErrInvalidWorkloadOperation, strings.Join(missing, ", "))
+	}
+
+	// Rollout 为 CRD 不支持策略合并补丁，使用带 resourceVersion 的 Update 防止覆盖并发修改
+	updated, err := client.ArgoprojV1alpha1().Rollouts(namespace).Update(ctx, ro, metav1.UpdateOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return BuildRolloutControlStatus(updated), nil
+}
+
+// patchRolloutStatus 以合并补丁修改 Rollout 的 status 子资源；
+// 旧版本 Rollouts CRD 未启用 status 子资源时回退为直接修改对象（与 kubectl argo rollouts 插件一致）
+func patchRolloutStatus(ctx context.Context, client rolloutsclientset.Interface, namespace, name string, status map[string]interface{}) (*RolloutControlStatus, error) {
+	data, err := json.Marshal(map[string]interface{}{"status": status})
+	if err != nil {
+		return nil, fmt.Errorf("构造补丁失败: %w", err)
+	}
+	rolloutIf := client.ArgoprojV1alpha1().Rollouts(namespace)
+	ro, err := rolloutIf.Patch(ctx, name, types.MergePatchType, data, metav1.PatchOptions{}, "status")
+	if apierrors.IsNotFound(err) {
+		ro, err = rolloutIf.Patch(ctx, name, types.MergePatchType, data, metav1.PatchOptions{})
+	}
+	if err != nil {
+		return nil, err
+	}
+	return BuildRolloutControlStatus(ro), nil
+}
+
+func patchRolloutSpec(ctx context.Context, client rolloutsclientset.Interface, namespace, name string, spec map[string]interface{}) (*rollouts.Rollout, error) {
+	data, err := json.Marshal(map[string]interface{}{"spec": spec})
+	if err != nil {
+		return nil, fmt.Errorf("构造补丁失败: %w", err)
+	}
+	return client.ArgoprojV1alpha1().Rollouts(namespace).Patch(ctx, name, types.MergePatchType, data, metav1.PatchOptions{})
+}
+
+// nextCanaryStepIndex 返回下一步的索引，已是最后一步时保持为步骤总数
+func nextCanaryStepIndex(ro *rollouts.Rollout) int32 {
+	index := int32(0)
+	if ro.Status.CurrentStepIndex != nil {
+		index = *ro.Status.CurrentStepIndex
+	}
+	if ro.Spec.Strategy.Canary != nil && index < int32(len(ro.Spec.Strategy.Canary.Steps)) {
+		index++
+	}
+	return index
+}
+
+func describeCanaryStep(index int32, step rollouts.CanaryStep) RolloutStepInfo {
+	info := RolloutStepInfo{Index: index}
+	switch {
+	case step.SetWeight != nil:
+		info.Type = "setWeight"
+		info.Description = fmt.Sprintf("设置金丝雀权重 %d%%", *step.SetWeight)
+	case step.Pause != nil:
+		info.Type = "pause"
+		if step.Pause.Duration != nil {
+			info.Description = "暂停 " + step.Pause.Duration.String()
+		} else {
+			info.Description = "暂停，等待手动推进"
+		}
+	case step.Analysis != nil:
+		info.Type = "analysis"
+		names := make([]string, 0, len(step.Analysis.Templates))
+		for _, t := range step.Analysis.Templates {
+			names = append(names, t.TemplateName)
+		}
+		info.Description = "运行分析 " + strings.Join(names, ", ")
+	case step.Experiment != nil:
+		info.Type = "experiment"
+		info.Description = fmt.Sprintf("运行实验（%d 个模板）", len(step.Experiment.Templates))
+	case step.SetCanaryScale != nil:
+		info.Type = "setCanaryScale"
+		info.Description = "调整金丝雀副本规模"
+	case step.SetHeaderRoute != nil:
+		info.Type = "setHeaderRoute"
+		info.Description = "设置请求头路由 " + step.SetHeaderRoute.Name
+	case step.SetMirrorRoute != nil:
+		info.Type = "setMirrorRoute"
+		info.Description = "设置流量镜像 " + step.SetMirrorRoute.Name
+	}
+	return info
+}
+
+func analysisRunInfo(run *rollouts.AnalysisRun) RolloutAnalysisRunInfo {
+	info := RolloutAnalysisRunInfo{
+		Name:      run.Name,
+		Phase:     string(run.Status.Phase),
+		Message:   run.Status.Message,
+		Metrics:   []RolloutAnalysisMetric{},
+		CreatedAt: run.CreationTimestamp.Time,
+	}
+	if run.Status.StartedAt != nil {
+		t := run.Status.StartedAt.Time
+		info.StartedAt = &t
+	}
+	if run.Status.CompletedAt != nil {
+		t := run.Status.CompletedAt.Time
+		info.CompletedAt = &t
+	}
+	for _, m := range run.Status.MetricResults {
+		info.Metrics = append(info.Metrics, RolloutAnalysisMetric{
+			Name:         m.Name,
+			Phase:        string(m.Phase),
+			Message:      m.Message,
+			Count:        m.Count,
+			Successful:   m.Successful,
+			Failed:       m.Failed,
+			Inconclusive: m.Inconclusive,
+			Error:        m.Error,
+		})
+	}
+	return info
+}
+
+func experimentInfo(ex *rollouts.Experiment) RolloutExperimentInfo {
+	info := RolloutExperimentInfo{
+		Name:         ex.Name,
+		Phase:        string(ex.Status.Phase),
+		Message:      ex.Status.Message,
+		Templates:    []RolloutExperimentTemplate{},
+		AnalysisRuns: []string{},
+		CreatedAt:    ex.CreationTimestamp.Time,
+	}
+	for _, t := range ex.Status.TemplateStatuses {
+		info.Templates = append(info.Templates, RolloutExperimentTemplate{
+			Name:              t.Name,
+			Status:            string(t.Status),
+			Message:           t.Message,
+			Replicas:          t.Replicas,
+			ReadyReplicas:     t.ReadyReplicas,
+			AvailableReplicas: t.AvailableReplicas,
+		})
+	}
+	for _, a := range ex.Status.AnalysisRuns {
+		info.AnalysisRuns = append(info.AnalysisRuns, a.AnalysisRun)
+	}
+	return info
+}
@@ -0,0 +1,102 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	rollouts "github.com/argoproj/argo-rollouts/pkg/apis/rollouts/v1alpha1"
+	rolloutsfake "github.com/argoproj/argo-rollouts/pkg/client/clientset/versioned/fake"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func int32Ptr(v int32) *int32 { return &v }
+
+func newCanaryRollout() *rollouts.Rollout {
+	return &rollouts.Rollout{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "demo", UID: types.UID("ro-1")},
+		Spec: rollouts.RolloutSpec{
+			Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+				Containers: []corev1.Container{{Name: "web", Image: "nginx:1.24"}},
+			}},
+			Strategy: rollouts.RolloutStrategy{Canary: &rollouts.CanaryStrategy{Steps: []rollouts.CanaryStep{
+				{SetWeight: int32Ptr(20)},
+				{Pause: &rollouts.RolloutPause{}},
+				{SetWeight: int32Ptr(50)},
+				{Analysis: &rollouts.RolloutAnalysis{Templates: []rollouts.AnalysisTemplateRef{{TemplateName: "success-rate"}}}},
+			}}},
+		},
+		Status: rollouts.RolloutStatus{
+			CurrentStepIndex: int32Ptr(1),
+			PauseConditions:  []rollouts.PauseCondition{{Reason: rollouts.PauseReasonCanaryPauseStep}},
+			StableRS:         "abc",
+			CurrentPodHash:   "def",
+		},
+	}
+}
+
+func TestBuildRolloutControlStatus(t *testing.T) {
+	status := BuildRolloutControlStatus(newCanaryRollout())
+	assert.Equal(t, "canary", status.Strategy)
+	require.Len(t, status.Steps, 4)
+	require.NotNil(t, status.CurrentStep)
+	assert.Equal(t, "pause", status.CurrentStep.Type)
+	assert.EqualValues(t, 20, *status.SetWeight)
+	assert.Equal(t, []string{string(rollouts.PauseReasonCanaryPauseStep)}, status.PauseReasons)
+}
+
+func TestRolloutControlActions(t *testing.T) {
+	ro := newCanaryRollout()
+	run := &rollouts.AnalysisRun{ObjectMeta: metav1.ObjectMeta{
+		Name: "web-run", Namespace: "demo",
+		OwnerReferences: []metav1.OwnerReference{*metav1.NewControllerRef(ro, rollouts.SchemeGroupVersion.WithKind("Rollout"))},
+	}, Status: rollouts.AnalysisRunStatus{Phase: rollouts.AnalysisPhaseRunning}}
+	other := &rollouts.AnalysisRun{ObjectMeta: metav1.ObjectMeta{Name: "other-run", Namespace: "demo"}}
+	client := rolloutsfake.NewSimpleClientset(ro, run, other)
+	ctx := context.Background()
+
+	status, err := GetRolloutControlStatus(ctx, client, "demo", "web")
+	require.NoError(t, err)
+	require.Len(t, status.AnalysisRuns, 1)
+	assert.Equal(t, "web-run", status.AnalysisRuns[0].Name)
+
+	status, err = PromoteRollout(ctx, client, "demo", "web", false)
+	require.NoError(t, err)
+	assert.Empty(t, status.PauseReasons)
+
+	_, err = SetRolloutCanaryWeight(ctx, client, "demo", "web", 30)
+	assert.ErrorIs(t, err, ErrInvalidWorkloadOperation)
+	status, err = SetRolloutCanaryWeight(ctx, client, "demo", "web", 50)
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, *status.CurrentStepIndex)
+	assert.EqualValues(t, 50, *status.SetWeight)
+
+	status, err = AbortRollout(ctx, client, "demo", "web")
+	require.NoError(t, err)
+	assert.True(t, status.Aborted)
+	assert.EqualValues(t, 0, *status.SetWeight)
+
+	_, err = SetRolloutImages(ctx, client, fake.NewSimpleClientset(), "demo", "web", map[string]string{"web": "nginx:1.25"})
+	require.NoError(t, err)
+	updated, err := client.ArgoprojV1alpha1().Rollouts("demo").Get(ctx, "web", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "nginx:1.25", updated.Spec.Template.Spec.Containers[0].Image)
+}
+
+func TestPromoteRolloutFullUnpauses(t *testing.T) {
+	ro := newCanaryRollout()
+	ro.Spec.Paused = true
+	client := rolloutsfake.NewSimpleClientset(ro)
+	ctx := context.Background()
+
+	_, err := PromoteRollout(ctx, client, "demo", "web", true)
+	require.NoError(t, err)
+	updated, err := client.ArgoprojV1alpha1().Rollouts("demo").Get(ctx, "web", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.False(t, updated.Spec.Paused, "全量发布应同时恢复手动暂停")
+	assert.True(t, updated.Status.PromoteFull)
+}
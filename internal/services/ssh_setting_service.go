@@ -10,6 +10,9 @@ import (
 	"gorm.io/gorm"
 )
 
+// sshConfigKey SSH 配置在 system_settings 表中的键
+const sshConfigKey = "ssh_config"
+
 // SSHSettingService SSH配置服务
 type SSHSettingService struct {
 	db *gorm.DB
@@ -23,7 +26,7 @@ func NewSSHSettingService(db *gorm.DB) *SSHSettingService {
 // GetSSHConfig 从数据库获取SSH配置
 func (s *SSHSettingService) GetSSHConfig() (*models.SSHConfig, error) {
 	var setting models.SystemSetting
-	if err := s.db.Where("config_key = ?", "ssh_config").First(&setting).Error; err != nil {
+	if err := s.db.Where("config_key = ?", sshConfigKey).First(&setting).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			// 返回默认配置
 			defaultConfig := models.GetDefaultSSHConfig()
@@ -36,24 +39,31 @@ func (s *SSHSettingService) GetSSHConfig() (*models.SSHConfig, error) {
 	if err := json.Unmarshal([]byte(setting.Value), &config); err != nil {
 		return nil, fmt.Errorf("解析SSH配置失败: %w", err)
 	}
+	if err := decryptFields(sshCredentialFields(&config)...); err != nil {
+		return nil, err
+	}
 
 	return &config, nil
 }
 
-// SaveSSHConfig 保存SSH配置到数据库
+// SaveSSHConfig 保存SSH配置到数据库（密码与私钥加密后落库）
 func (s *SSHSettingService) SaveSSHConfig(config *models.SSHConfig) error {
-	configJSON, err := json.Marshal(config)
+	stored := *config
+	if err := encryptFields(sshCredentialFields(&stored)...); err != nil {
+		return err
+	}
+	configJSON, err := json.Marshal(stored)
 	if err != nil {
 		return fmt.Errorf("序列化SSH配置失败: %w", err)
 	}
 
 	var setting models.SystemSetting
-	result := s.db.Where("config_key = ?", "ssh_config").First(&setting)
+	result := s.db.Where("config_key = ?", sshConfigKey).First(&setting)
 
 	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
 		// 创建新配置
 		setting = models.SystemSetting{
-			ConfigKey: "ssh_config",
+			ConfigKey: sshConfigKey,
 			Value:     string(configJSON),
 			Type:      "ssh",
 		}
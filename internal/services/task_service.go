@@ -0,0 +1,471 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/clay-wangzhi/KubePolaris/internal/models"
+	"github.com/clay-wangzhi/KubePolaris/pkg/logger"
+
+	"gorm.io/gorm"
+)
+
+// 任务日志级别
+const (
+	TaskLogInfo  = "info"
+	TaskLogWarn  = "warn"
+	TaskLogError = "error"
+)
+
+// 任务事件类型
+const (
+	TaskEventStatus   = "status"
+	TaskEventProgress = "progress"
+	TaskEventStep     = "step"
+	TaskEventLog      = "log"
+)
+
+// taskSubscriberBuffer 订阅通道缓冲，消费过慢时丢弃事件（客户端可重新拉取任务详情）
+const taskSubscriberBuffer = 256
+
+var (
+	// ErrTaskNotFound 任务不存在
+	ErrTaskNotFound = errors.New("任务不存在")
+	// ErrTaskFinished 任务已结束，无法取消
+	ErrTaskFinished = errors.New("任务已结束")
+)
+
+// TaskRunner 任务执行函数，通过 reporter 上报步骤、日志与进度，返回值序列化后保存为任务结果
+// ctx 在任务被取消时结束；执行函数应尽量幂等，服务重启后未完成的任务会重新执行。
+type TaskRunner func(ctx context.Context, task *models.Task, reporter *TaskReporter) (interface{}, error)
+
+// TaskEvent 任务进度事件
+type TaskEvent struct {
+	Type     string           `json:"type"`
+	TaskID   uint             `json:"taskId"`
+	Status   string           `json:"status,omitempty"`
+	Progress int              `json:"progress"`
+	Error    string           `json:"error,omitempty"`
+	Step     *models.TaskStep `json:"step,omitempty"`
+	Log      *models.TaskLog  `json:"log,omitempty"`
+}
+
+// TaskSubmitRequest 提交任务请求
+type TaskSubmitRequest struct {
+	ClusterID uint
+	Type      string
+	Target    string
+	Params    interface{}
+	UserID    uint
+	Username  string
+}
+
+// TaskListRequest 任务列表查询条件
+type TaskListRequest struct {
+	ClusterID uint
+	Type      string
+	Status    string
+	Page      int
+	PageSize  int
+}
+
+// TaskListResponse 任务列表
+type TaskListResponse struct {
+	Items    []models.Task `json:"items"`
+	Total    int64         `json:"total"`
+	Page     int           `json:"page"`
+	PageSize int           `json:"pageSize"`
+}
+
+// TaskService 后台任务服务：任务状态持久化到数据库，执行进度通过订阅实时推送
+type TaskService struct {
+	db *gorm.DB
+
+	mu          sync.Mutex
+	runners     map[string]TaskRunner
+	cancels     map[uint]context.CancelFunc
+	subscribers map[uint]map[chan TaskEvent]struct{}
+	wg          sync.WaitGroup
+}
+
+// NewTaskService 创建任务服务
+func NewTaskService(db *gorm.DB) *TaskService {
+	return &TaskService{
+		db:          db,
+		runners:     make(map[string]TaskRunner),
+		cancels:     make(map[uint]context.CancelFunc),
+		subscribers: make(map[uint]map[chan TaskEvent]struct{}),
+	}
+}
+
+// RegisterRunner 注册任务类型的执行函数，需在 Submit 与 Recover 之前调用
+func (s *TaskService) RegisterRunner(taskType string, runner TaskRunner) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.runners[taskType] = runner
+}
+
+// Submit 创建任务并在后台执行
+func (s *TaskService) Submit(req TaskSubmitRequest) (*models.Task, error) {
+	s.mu.Lock()
+	runner, ok := s.runners[req.Type]
+	s.mu.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("未知的任务类型: %s", req.Type)
+	}
+
+	params, err := json.Marshal(req.Params)
+	if err != nil {
+		return nil, fmt.Errorf("序列化任务参数失败: %w", err)
+	}
+	task := &models.Task{
+		ClusterID: req.ClusterID,
+		Type:      req.Type,
+		Target:    req.Target,
+		Status:    models.TaskStatusPending,
+		Params:    string(params),
+		UserID:    req.UserID,
+		Username:  req.Username,
+	}
+	if err := s.db.Create(task).Error; err != nil {
+		return nil, fmt.Errorf("保存任务失败: %w", err)
+	}
+
+	// 执行协程使用副本，避免与调用方读取返回值产生竞争
+	running := *task
+	s.start(&running, runner)
+	return task, nil
+}
+
+// Recover 处理服务重启前未完成的任务：已注册执行函数的任务重新执行，其余标记为中断
+func (s *TaskService) Recover() {
+	var tasks []models.Task
+	if err := s.db.Where("status IN ?", []string{models.TaskStatusPending, models.TaskStatusRunning}).
+		Order("id").Find(&tasks).Error; err != nil {
+		logger.Error("加载未完成任务失败", "error", err)
+		return
+	}
+
+	for i := range tasks {
+		task := &tasks[i]
+		s.mu.Lock()
+		runner, ok := s.runners[task.Type]
+		s.mu.Unlock()
+		if !ok {
+			now := time.Now()
+			s.db.Model(task).Updates(map[string]interface{}{
+				"status":      models.TaskStatusInterrupted,
+				"error":       "服务重启，任务中断",
+				"finished_at": &now,
+			})
+			continue
+		}
+		logger.Info("恢复执行未完成任务", "id", task.ID, "type", task.Type, "target", task.Target)
+		s.appendLog(task.ID, TaskLogWarn, "服务重启后恢复执行")
+		s.start(task, runner)
+	}
+}
+
+// Get 获取任务详情（含步骤与日志）
+func (s *TaskService) Get(id uint) (*models.Task, error) {
+	var task models.Task
+	err := s.db.Preload("Steps", func(db *gorm.DB) *gorm.DB { return db.Order("id") }).
+		Preload("Logs", func(db *gorm.DB) *gorm.DB { return db.Order("id") }).
+		First(&task, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrTaskNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &task, nil
+}
+
+// List 分页查询任务（不含步骤与日志），按创建时间倒序
+func (s *TaskService) List(req TaskListRequest) (*TaskListResponse, error) {
+	if req.Page <= 0 {
+		req.Page = 1
+	}
+	if req.PageSize <= 0 || req.PageSize > 100 {
+		req.PageSize = 20
+	}
+
+	query := s.db.Model(&models.Task{})
+	if req.ClusterID > 0 {
+		query = query.Where("cluster_id = ?", req.ClusterID)
+	}
+	if req.Type != "" {
+		query = query.Where("type = ?", req.Type)
+	}
+	if req.Status != "" {
+		query = query.Where("status = ?", req.Status)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, err
+	}
+	items := make([]models.Task, 0)
+	if err := query.Order("id DESC").Offset((req.Page - 1) * req.PageSize).Limit(req.PageSize).Find(&items).Error; err != nil {
+		return nil, err
+	}
+	return &TaskListResponse{Items: items, Total: total, Page: req.Page, PageSize: req.PageSize}, nil
+}
+
+// Cancel 取消任务：执行中的任务通过 context 取消，由执行函数尽快退出
+func (s *TaskService) Cancel(id uint) error {
+	var task models.Task
+	if err := s.db.First(&task, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrTaskNotFound
+		}
+		return err
+	}
+	if task.IsFinished() {
+		return ErrTaskFinished
+	}
+
+	s.mu.Lock()
+	cancel, running := s.cancels[id]
+	s.mu.Unlock()
+	if running {
+		cancel()
+		return nil
+	}
+
+	// 不在本实例执行（例如执行函数未注册），直接标记为已取消
+	s.finish(&task, nil, context.Canceled)
+	return nil
+}
+
+// Subscribe 订阅任务事件，返回的取消函数需在不再接收时调用
+func (s *TaskService) Subscribe(id uint) (<-chan TaskEvent, func()) {
+	ch := make(chan TaskEvent, taskSubscriberBuffer)
+	s.mu.Lock()
+	if s.subscribers[id] == nil {
+		s.subscribers[id] = make(map[chan TaskEvent]struct{})
+	}
+	s.subscribers[id][ch] = struct{}{}
+	s.mu.Unlock()
+
+	return ch, func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if subs, ok := s.subscribers[id]; ok {
+			delete(subs, ch)
+			if len(subs) == 0 {
+				delete(s.subscribers, id)
+			}
+		}
+	}
+}
+
+// Wait 等待所有执行中的任务结束
+func (s *TaskService) Wait() {
+	s.wg.Wait()
+}
+
+// DecodeTaskParams 解析任务参数
+func DecodeTaskParams(task *models.Task, v interface{}) error {
+	if err := json.Unmarshal([]byte(task.Params), v); err != nil {
+		return fmt.Errorf("解析任务参数失败: %w", err)
+	}
+	return nil
+}
+
+func (s *TaskService) start(task *models.Task, runner TaskRunner) {
+	ctx, cancel := context.WithCancel(context.Background())
+	s.mu.Lock()
+	s.cancels[task.ID] = cancel
+	s.mu.Unlock()
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		defer func() {
+			cancel()
+			s.mu.Lock()
+			delete(s.cancels, task.ID)
+			s.mu.Unlock()
+		}()
+		s.run(ctx, task, runner)
+	}()
+}
+
+func (s *TaskService) run(ctx context.Context, task *models.Task, runner TaskRunner) {
+	now := time.Now()
+	task.Status = models.TaskStatusRunning
+	task.Attempts++
+	if task.StartedAt == nil {
+		task.StartedAt = &now
+	}
+	s.db.Model(task).Updates(map[string]interface{}{
+		"status":     task.Status,
+		"attempts":   task.Attempts,
+		"started_at": task.StartedAt,
+	})
+	s.publish(TaskEvent{Type: TaskEventStatus, TaskID: task.ID, Status: task.Status, Progress: task.Progress})
+
+	reporter := &TaskReporter{svc: s, taskID: task.ID, progress: task.Progress}
+	result, err := safeRunTask(ctx, task, runner, reporter)
+	if ctx.Err() != nil {
+		// 被取消的任务以取消状态结束，不论执行函数返回何种错误
+		err = ctx.Err()
+	}
+	reporter.closeStep(err)
+	task.Progress = reporter.Progress()
+	s.finish(task, result, err)
+}
+
+// safeRunTask 执行任务并将 panic 转换为错误
+func safeRunTask(ctx context.Context, task *models.Task, runner TaskRunner, reporter *TaskReporter) (result interface{}, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			logger.Error("任务执行异常", "id", task.ID, "type", task.Type, "panic", r)
+			err = fmt.Errorf("任务执行异常: %v", r)
+		}
+	}()
+	return runner(ctx, task, reporter)
+}
+
+// finish 保存任务的最终状态与结果
+func (s *TaskService) finish(task *models.Task, result interface{}, err error) {
+	now := time.Now()
+	updates := map[string]interface{}{"finished_at": &now}
+	switch {
+	case err == nil:
+		task.Status = models.TaskStatusSucceeded
+		task.Progress = 100
+	case errors.Is(err, context.Canceled):
+		task.Status = models.TaskStatusCancelled
+		task.Error = "任务已取消"
+	default:
+		task.Status = models.TaskStatusFailed
+		task.Error = err.Error()
+	}
+	updates["status"] = task.Status
+	updates["progress"] = task.Progress
+	updates["error"] = task.Error
+	if result != nil {
+		if data, mErr := json.Marshal(result); mErr == nil {
+			task.Result = string(data)
+			updates["result"] = task.Result
+		}
+	}
+	task.FinishedAt = &now
+	if dbErr := s.db.Model(task).Updates(updates).Error; dbErr != nil {
+		logger.Error("保存任务状态失败", "id", task.ID, "error", dbErr)
+	}
+	s.publish(TaskEvent{Type: TaskEventStatus, TaskID: task.ID, Status: task.Status, Progress: task.Progress, Error: task.Error})
+}
+
+func (s *TaskService) appendLog(taskID uint, level, message string) {
+	entry := &models.TaskLog{TaskID: taskID, Level: level, Message: message}
+	if err := s.db.Create(entry).Error; err != nil {
+		logger.Error("保存任务日志失败", "id", taskID, "error", err)
+	}
+	s.publish(TaskEvent{Type: TaskEventLog, TaskID: taskID, Log: entry})
+}
+
+// publish 向订阅者推送事件，通道已满时丢弃
+func (s *TaskService) publish(event TaskEvent) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for ch := range s.subscribers[event.TaskID] {
+		select {
+		case ch <- event:
+		default:
+		}
+	}
+}
+
+// TaskReporter 任务执行过程中上报步骤、日志与进度
+type TaskReporter struct {
+	svc    *TaskService
+	taskID uint
+
+	mu       sync.Mutex
+	progress int
+	step     *models.TaskStep
+}
+
+// Logf 记录任务日志
+func (r *TaskReporter) Logf(level, format string, args ...interface{}) {
+	r.svc.appendLog(r.taskID, level, fmt.Sprintf(format, args...))
+}
+
+// StartStep 开始新步骤，上一个未结束的步骤视为成功
+func (r *TaskReporter) StartStep(name string) {
+	r.closeStep(nil)
+	step := &models.TaskStep{TaskID: r.taskID, Name: name, Status: models.TaskStatusRunning, StartedAt: time.Now()}
+	if err := r.svc.db.Create(step).Error; err != nil {
+		logger.Error("保存任务步骤失败", "id", r.taskID, "error", err)
+	}
+	r.mu.Lock()
+	r.step = step
+	r.mu.Unlock()
+	r.svc.publish(TaskEvent{Type: TaskEventStep, TaskID: r.taskID, Step: step, Progress: r.Progress()})
+}
+
+// FinishStep 结束当前步骤，err 非空时步骤标记为失败
+func (r *TaskReporter) FinishStep(err error) {
+	r.closeStep(err)
+}
+
+// SetProgress 更新任务进度（0-100）
+func (r *TaskReporter) SetProgress(progress int) {
+	if progress < 0 {
+		progress = 0
+	} else if progress > 100 {
+		progress = 100
+	}
+	r.mu.Lock()
+	changed := progress != r.progress
+	r.progress = progress
+	r.mu.Unlock()
+	if !changed {
+		return
+	}
+	r.svc.db.Model(&models.Task{}).Where("id = ?", r.taskID).Update("progress", progress)
+	r.svc.publish(TaskEvent{Type: TaskEventProgress, TaskID: r.taskID, Progress: progress})
+}
+
+// Progress 当前进度
+func (r *TaskReporter) Progress() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.progress
+}
+
+func (r *TaskReporter) closeStep(err error) {
+	r.mu.Lock()
+	step := r.step
+	r.step = nil
+	r.mu.Unlock()
+	if step == nil {
+		return
+	}
+
+	now := time.Now()
+	step.FinishedAt = &now
+	switch {
+	case err == nil:
+		step.Status = models.TaskStatusSucceeded
+	case errors.Is(err, context.Canceled):
+		step.Status = models.TaskStatusCancelled
+		step.Message = "任务已取消"
+	default:
+		step.Status = models.TaskStatusFailed
+		step.Message = err.Error()
+	}
+	r.svc.db.Model(step).Updates(map[string]interface{}{
+		"status":      step.Status,
+		"message":     step.Message,
+		"finished_at": step.FinishedAt,
+	})
+	r.svc.publish(TaskEvent{Type: TaskEventStep, TaskID: r.taskID, Step: step, Progress: r.Progress()})
+}
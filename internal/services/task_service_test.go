@@ -0,0 +1,128 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/clay-wangzhi/KubePolaris/internal/models"
+
+	"github.com/glebarez/sqlite"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func newTaskTestService(t *testing.T) *TaskService {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	require.NoError(t, err)
+	// 内存数据库仅在单个连接内可见
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	sqlDB.SetMaxOpenConns(1)
+	require.NoError(t, db.AutoMigrate(&models.Task{}, &models.TaskStep{}, &models.TaskLog{}))
+	return NewTaskService(db)
+}
+
+func TestTaskServiceRun(t *testing.T) {
+	svc := newTaskTestService(t)
+	release := make(chan struct{})
+	svc.RegisterRunner("demo", func(ctx context.Context, task *models.Task, r *TaskReporter) (interface{}, error) {
+		var params struct{ Nodes []string }
+		if err := DecodeTaskParams(task, &params); err != nil {
+			return nil, err
+		}
+		<-release
+		for i, node := range params.Nodes {
+			r.StartStep("处理 " + node)
+			r.Logf(TaskLogInfo, "节点 %s 完成", node)
+			r.SetProgress((i + 1) * 50)
+		}
+		return map[string]int{"count": len(params.Nodes)}, nil
+	})
+
+	_, err := svc.Submit(TaskSubmitRequest{Type: "unknown"})
+	assert.Error(t, err)
+
+	task, err := svc.Submit(TaskSubmitRequest{ClusterID: 1, Type: "demo", Target: "node-a", Params: map[string][]string{"Nodes": {"node-a", "node-b"}}})
+	require.NoError(t, err)
+	events, unsubscribe := svc.Subscribe(task.ID)
+	defer unsubscribe()
+	close(release)
+
+	var last TaskEvent
+	timeout := time.After(5 * time.Second)
+	for last.Type != TaskEventStatus || last.Status == models.TaskStatusRunning {
+		select {
+		case last = <-events:
+		case <-timeout:
+			t.Fatal("等待任务事件超时")
+		}
+	}
+	assert.Equal(t, models.TaskStatusSucceeded, last.Status)
+	svc.Wait()
+
+	got, err := svc.Get(task.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.TaskStatusSucceeded, got.Status)
+	assert.Equal(t, 100, got.Progress)
+	assert.JSONEq(t, `{"count":2}`, got.Result)
+	require.Len(t, got.Steps, 2)
+	assert.Equal(t, models.TaskStatusSucceeded, got.Steps[1].Status)
+	assert.Len(t, got.Logs, 2)
+	assert.ErrorIs(t, svc.Cancel(task.ID), ErrTaskFinished)
+}
+
+func TestTaskServiceCancel(t *testing.T) {
+	svc := newTaskTestService(t)
+	started := make(chan struct{})
+	svc.RegisterRunner("wait", func(ctx context.Context, task *models.Task, r *TaskReporter) (interface{}, error) {
+		r.StartStep("等待")
+		close(started)
+		<-ctx.Done()
+		return nil, errors.New("interrupted")
+	})
+
+	task, err := svc.Submit(TaskSubmitRequest{ClusterID: 1, Type: "wait"})
+	require.NoError(t, err)
+	<-started
+	require.NoError(t, svc.Cancel(task.ID))
+	svc.Wait()
+
+	got, err := svc.Get(task.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.TaskStatusCancelled, got.Status)
+	require.Len(t, got.Steps, 1)
+	assert.Equal(t, models.TaskStatusCancelled, got.Steps[0].Status)
+}
+
+func TestTaskServiceRecover(t *testing.T) {
+	svc := newTaskTestService(t)
+	svc.RegisterRunner("resumable", func(ctx context.Context, task *models.Task, r *TaskReporter) (interface{}, error) {
+		return nil, nil
+	})
+	resumable := &models.Task{ClusterID: 1, Type: "resumable", Status: models.TaskStatusRunning, Attempts: 1, Params: "{}"}
+	orphan := &models.Task{ClusterID: 1, Type: "removed", Status: models.TaskStatusPending, Params: "{}"}
+	require.NoError(t, svc.db.Create(resumable).Error)
+	require.NoError(t, svc.db.Create(orphan).Error)
+
+	svc.Recover()
+	svc.Wait()
+
+	got, err := svc.Get(resumable.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.TaskStatusSucceeded, got.Status)
+	assert.Equal(t, 2, got.Attempts)
+	require.Len(t, got.Logs, 1)
+	assert.Equal(t, TaskLogWarn, got.Logs[0].Level)
+
+	got, err = svc.Get(orphan.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.TaskStatusInterrupted, got.Status)
+
+	list, err := svc.List(TaskListRequest{ClusterID: 1, Status: models.TaskStatusInterrupted})
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, list.Total)
+}
@@ -0,0 +1,368 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+)
+
+// 支持生命周期操作的原生工作负载类型
+const (
+	WorkloadKindDeployment  = "Deployment"
+	WorkloadKindStatefulSet = "StatefulSet"
+	WorkloadKindDaemonSet   = "DaemonSet"
+)
+
+// RestartedAtAnnotation 滚动重启时写入 Pod 模板的注解（与 kubectl rollout restart 一致）
+const RestartedAtAnnotation = "kubectl.kubernetes.io/restartedAt"
+
+// ErrInvalidWorkloadOperation 当前工作负载状态或参数不允许执行该操作
+var ErrInvalidWorkloadOperation = errors.New("无效的工作负载操作")
+
+// WorkloadRolloutStatus 工作负载的发布进度，判断逻辑与 kubectl rollout status 一致
+type WorkloadRolloutStatus struct {
+	Kind               string `json:"kind"`
+	Namespace          string `json:"namespace"`
+	Name               string `json:"name"`
+	Generation         int64  `json:"generation"`
+	ObservedGeneration int64  `json:"observedGeneration"`
+	Replicas           int32  `json:"replicas"` // 期望副本数（DaemonSet 为期望调度的节点数）
+	UpdatedReplicas    int32  `json:"updatedReplicas"`
+	ReadyReplicas      int32  `json:"readyReplicas"`
+	AvailableReplicas  int32  `json:"availableReplicas"`
+	Paused             bool   `json:"paused,omitempty"`
+	Partition          *int32 `json:"partition,omitempty"` // StatefulSet 滚动更新分区
+	Complete           bool   `json:"complete"`
+	Message            string `json:"message"`
+}
+
+// GetWorkloadRolloutStatus 获取工作负载当前的发布进度
+func GetWorkloadRolloutStatus(ctx context.Context, clientset kubernetes.Interface, kind, namespace, name string) (*WorkloadRolloutStatus, error) {
+	switch kind {
+	case WorkloadKindDeployment:
+		d, err := clientset.AppsV1().Deployments(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		return DeploymentRolloutStatus(d), nil
+	case WorkloadKindStatefulSet:
+		s, err := clientset.AppsV1().StatefulSets(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		return StatefulSetRolloutStatus(s), nil
+	case WorkloadKindDaemonSet:
+		ds, err := clientset.AppsV1().DaemonSets(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		return DaemonSetRolloutStatus(ds), nil
+	default:
+		return nil, fmt.Errorf("%w: 不支持的工作负载类型 %s", ErrInvalidWorkloadOperation, kind)
+	}
+}
+
+// RestartWorkload 滚动重启工作负载：更新 Pod 模板的 restartedAt 注解
+func RestartWorkload(ctx context.Context, clientset kubernetes.Interface, kind, namespace, name string) (*WorkloadRolloutStatus, error) {
+	if kind == WorkloadKindDeployment {
+		d, err := clientset.AppsV1().Deployments(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		if d.Spec.Paused {
+			return nil, fmt.Errorf("%w: Deployment 处于暂停状态，请先恢复后再重启", ErrInvalidWorkloadOperation)
+		}
+	}
+
+	patch := map[string]interface{}{
+		"spec": map[string]interface{}{
+			"template": map[string]interface{}{
+				"metadata": map[string]interface{}{
+					"annotations": map[string]string{RestartedAtAnnotation: time.Now().Format(time.RFC3339)},
+				},
+			},
+		},
+	}
+	return patchWorkload(ctx, clientset, kind, namespace, name, patch)
+}
+
+// SetDeploymentPaused 暂停或恢复 Deployment 的发布
+func SetDeploymentPaused(ctx context.Context, clientset kubernetes.Interface, namespace, name string, paused bool) (*WorkloadRolloutStatus, error) {
+	patch := map[string]interface{}{
+		"spec": map[string]interface{}{"paused": paused},
+	}
+	return patchWorkload(ctx, clientset, WorkloadKindDeployment, namespace, name, patch)
+}
+
+// SetWorkloadImages 更新工作负载容器镜像，images 为容器名到镜像的映射，同时支持 initContainers
+func SetWorkloadImages(ctx context.Context, clientset kubernetes.Interface, kind, namespace, name string, images map[string]string) (*WorkloadRolloutStatus, error) {
+	if len(images) == 0 {
+		return nil, fmt.Errorf("%w: 未指定需要更新的容器镜像", ErrInvalidWorkloadOperation)
+	}
+	template, err := getWorkloadTemplate(ctx, clientset, kind, namespace, name)
+	if err != nil {
+		return nil, err
+	}
+
+	containers := make([]map[string]string, 0)
+	initContainers := make([]map[string]string, 0)
+	matched := make(map[string]bool, len(images))
+	for _, c := range template.Spec.Containers {
+		if image, ok := images[c.Name]; ok {
+			containers = append(containers, map[string]string{"name": c.Name, "image": image})
+			matched[c.Name] = true
+		}
+	}
+	for _, c := range template.Spec.InitContainers {
+		if image, ok := images[c.Name]; ok {
+			initContainers = append(initContainers, map[string]string{"name": c.Name, "image": image})
+			matched[c.Name] = true
+		}
+	}
+
+	var missing []string
+	for container, image := range images {
+		if strings.TrimSpace(image) == "" {
+			return nil, fmt.Errorf("%w: 容器 %s 的镜像不能为空", ErrInvalidWorkloadOperation, container)
+		}
+		if !matched[container] {
+			missing = append(missing, container)
+		}
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return nil, fmt.Errorf("%w: 容器不存在: %s", ErrInvalidWorkloadOperation, strings.Join(missing, ", "))
+	}
+
+	// 策略合并补丁按容器名合并，只修改指定容器的镜像
+	podSpec := map[string]interface{}{}
+	if len(containers) > 0 {
+		podSpec["containers"] = containers
+	}
+	if len(initContainers) > 0 {
+		podSpec["initContainers"] = initContainers
+	}
+	patch := map[string]interface{}{
+		"spec": map[string]interface{}{
+			"template": map[string]interface{}{"spec": podSpec},
+		},
+	}
+	return patchWorkload(ctx, clientset, kind, namespace, name, patch)
+}
+
+// SetStatefulSetPartition 设置 StatefulSet 滚动更新分区，序号大于等于 partition 的 Pod 才会被更新
+func SetStatefulSetPartition(ctx context.Context, clientset kubernetes.Interface, namespace, name string, partition int32) (*WorkloadRolloutStatus, error) {
+	if partition < 0 {
+		return nil, fmt.Errorf("%w: partition 不能小于 0", ErrInvalidWorkloadOperation)
+	}
+	s, err := clientset.AppsV1().StatefulSets(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	if s.Spec.UpdateStrategy.Type == appsv1.OnDeleteStatefulSetStrategyType {
+		return nil, fmt.Errorf("%w: 更新策略为 OnDelete 的 StatefulSet 不支持设置分区", ErrInvalidWorkloadOperation)
+	}
+
+	patch := map[string]interface{}{
+		"spec": map[string]interface{}{
+			"updateStrategy": map[string]interface{}{
+				"type":          appsv1.RollingUpdateStatefulSetStrategyType,
+				"rollingUpdate": map[string]interface{}{"partition": partition},
+			},
+		},
+	}
+	return patchWorkload(ctx, clientset, WorkloadKindStatefulSet, namespace, name, patch)
+}
+
+// patchWorkload 对工作负载执行策略合并补丁，返回更新后的发布进度
+func patchWorkload(ctx context.Context, clientset kubernetes.Interface, kind, namespace, name string, patch map[string]interface{}) (*WorkloadRolloutStatus, error) {
+	data, err := json.Marshal(patch)
+	if err != nil {
+		return nil, fmt.Errorf("构造补丁失败: %w", err)
+	}
+
+	apps := clientset.AppsV1()
+	switch kind {
+	case WorkloadKindDeployment:
+		d, err := apps.Deployments(namespace).Patch(ctx, name, types.StrategicMergePatchType, data, metav1.PatchOptions{})
+		if err != nil {
+			return nil, err
+		}
+		return DeploymentRolloutStatus(d), nil
+	case WorkloadKindStatefulSet:
+		s, err := apps.StatefulSets(namespace).Patch(ctx, name, types.StrategicMergePatchType, data, metav1.PatchOptions{})
+		if err != nil {
+			return nil, err
+		}
+		return StatefulSetRolloutStatus(s), nil
+	case WorkloadKindDaemonSet:
+		ds, err := apps.DaemonSets(namespace).Patch(ctx, name, types.StrategicMergePatchType, data, metav1.PatchOptions{})
+		if err != nil {
+			return nil, err
+		}
+		return DaemonSetRolloutStatus(ds), nil
+	default:
+		return nil, fmt.Errorf("%w: 不支持的工作负载类型 %s", ErrInvalidWorkloadOperation, kind)
+	}
+}
+
+func getWorkloadTemplate(ctx context.Context, clientset kubernetes.Interface, kind, namespace, name string) (*corev1.PodTemplateSpec, error) {
+	apps := clientset.AppsV1()
+	switch kind {
+	case WorkloadKindDeployment:
+		d, err := apps.Deployments(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		return &d.Spec.Template, nil
+	case WorkloadKindStatefulSet:
+		s, err := apps.StatefulSets(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		return &s.Spec.Template, nil
+	case WorkloadKindDaemonSet:
+		ds, err := apps.DaemonSets(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		return &ds.Spec.Template, nil
+	default:
+		return nil, fmt.Errorf("%w: 不支持的工作负载类型 %s", ErrInvalidWorkloadOperation, kind)
+	}
+}
+
+// DeploymentRolloutStatus 计算 Deployment 的发布进度
+func DeploymentRolloutStatus(d *appsv1.Deployment) *WorkloadRolloutStatus {
+	status := &WorkloadRolloutStatus{
+		Kind:               WorkloadKindDeployment,
+		Namespace:          d.Namespace,
+		Name:               d.Name,
+		Generation:         d.Generation,
+		ObservedGeneration: d.Status.ObservedGeneration,
+		UpdatedReplicas:    d.Status.UpdatedReplicas,
+		ReadyReplicas:      d.Status.ReadyReplicas,
+		AvailableReplicas:  d.Status.AvailableReplicas,
+		Paused:             d.Spec.Paused,
+	}
+	status.Replicas = 1
+	if d.Spec.Replicas != nil {
+		status.Replicas = *d.Spec.Replicas
+	}
+
+	switch {
+	case d.Generation > d.Status.ObservedGeneration:
+		status.Message = "等待控制器处理最新的 Deployment 配置"
+	case d.Spec.Paused:
+		status.Message = "发布已暂停"
+	case deploymentProgressDeadlineExceeded(d):
+		status.Message = "发布超时：超过 progressDeadlineSeconds 仍未完成"
+	case d.Status.UpdatedReplicas < status.Replicas:
+		status.Message = fmt.Sprintf("发布中：%d/%d 个副本已更新", d.Status.UpdatedReplicas, status.Replicas)
+	case d.Status.Replicas > d.Status.UpdatedReplicas:
+		status.Message = fmt.Sprintf("发布中：%d 个旧副本等待终止", d.Status.Replicas-d.Status.UpdatedReplicas)
+	case d.Status.AvailableReplicas < d.Status.UpdatedReplicas:
+		status.Message = fmt.Sprintf("发布中：%d/%d 个已更新副本可用", d.Status.AvailableReplicas, d.Status.UpdatedReplicas)
+	default:
+		status.Complete = true
+		status.Message = "发布完成"
+	}
+	return status
+}
+
+func deploymentProgressDeadlineExceeded(d *appsv1.Deployment) bool {
+	for _, cond := range d.Status.Conditions {
+		if cond.Type == appsv1.DeploymentProgressing {
+			return cond.Reason == "ProgressDeadlineExceeded"
+		}
+	}
+	return false
+}
+
+// StatefulSetRolloutStatus 计算 StatefulSet 的发布进度，设置分区时只要求分区内的副本完成更新
+func StatefulSetRolloutStatus(s *appsv1.StatefulSet) *WorkloadRolloutStatus {
+	status := &WorkloadRolloutStatus{
+		Kind:               WorkloadKindStatefulSet,
+		Namespace:          s.Namespace,
+		Name:               s.Name,
+		Generation:         s.Generation,
+		ObservedGeneration: s.Status.ObservedGeneration,
+		UpdatedReplicas:    s.Status.UpdatedReplicas,
+		ReadyReplicas:      s.Status.ReadyReplicas,
+		AvailableReplicas:  s.Status.AvailableReplicas,
+	}
+	status.Replicas = 1
+	if s.Spec.Replicas != nil {
+		status.Replicas = *s.Spec.Replicas
+	}
+	if ru := s.Spec.UpdateStrategy.RollingUpdate; ru != nil && ru.Partition != nil {
+		partition := *ru.Partition
+		status.Partition = &partition
+	}
+
+	switch {
+	case s.Spec.UpdateStrategy.Type == appsv1.OnDeleteStatefulSetStrategyType:
+		status.Message = "更新策略为 OnDelete，需要手动删除 Pod 触发更新"
+	case s.Status.ObservedGeneration == 0 || s.Generation > s.Status.ObservedGeneration:
+		status.Message = "等待控制器处理最新的 StatefulSet 配置"
+	case s.Status.ReadyReplicas < status.Replicas:
+		status.Message = fmt.Sprintf("发布中：%d/%d 个副本就绪", s.Status.ReadyReplicas, status.Replicas)
+	case status.Partition != nil && *status.Partition > 0:
+		expected := status.Replicas - *status.Partition
+		if expected < 0 {
+			expected = 0
+		}
+		if s.Status.UpdatedReplicas < expected {
+			status.Message = fmt.Sprintf("分区发布中：%d/%d 个分区内副本已更新", s.Status.UpdatedReplicas, expected)
+		} else {
+			status.Complete = true
+			status.Message = fmt.Sprintf("分区发布完成：%d 个副本已更新，序号小于 %d 的副本保持旧版本", s.Status.UpdatedReplicas, *status.Partition)
+		}
+	case s.Status.UpdateRevision != s.Status.CurrentRevision:
+		status.Message = fmt.Sprintf("发布中：%d/%d 个副本已更新", s.Status.UpdatedReplicas, status.Replicas)
+	default:
+		status.Complete = true
+		status.Message = "发布完成"
+	}
+	return status
+}
+
+// DaemonSetRolloutStatus 计算 DaemonSet 的发布进度
+func DaemonSetRolloutStatus(ds *appsv1.DaemonSet) *WorkloadRolloutStatus {
+	status := &WorkloadRolloutStatus{
+		Kind:               WorkloadKindDaemonSet,
+		Namespace:          ds.Namespace,
+		Name:               ds.Name,
+		Generation:         ds.Generation,
+		ObservedGeneration: ds.Status.ObservedGeneration,
+		Replicas:           ds.Status.DesiredNumberScheduled,
+		UpdatedReplicas:    ds.Status.UpdatedNumberScheduled,
+		ReadyReplicas:      ds.Status.NumberReady,
+		AvailableReplicas:  ds.Status.NumberAvailable,
+	}
+
+	switch {
+	case ds.Spec.UpdateStrategy.Type == appsv1.OnDeleteDaemonSetStrategyType:
+		status.Message = "更新策略为 OnDelete，需要手动删除 Pod 触发更新"
+	case ds.Generation > ds.Status.ObservedGeneration:
+		status.Message = "等待控制器处理最新的 DaemonSet 配置"
+	case ds.Status.UpdatedNumberScheduled < ds.Status.DesiredNumberScheduled:
+		status.Message = fmt.Sprintf("发布中：%d/%d 个节点上的 Pod 已更新", ds.Status.UpdatedNumberScheduled, ds.Status.DesiredNumberScheduled)
+	case ds.Status.NumberAvailable < ds.Status.DesiredNumberScheduled:
+		status.Message = fmt.Sprintf("发布中：%d/%d 个已更新 Pod 可用", ds.Status.NumberAvailable, ds.Status.DesiredNumberScheduled)
+	default:
+		status.Complete = true
+		status.Message = "发布完成"
+	}
+	return status
+}
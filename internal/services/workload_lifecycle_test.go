@@ -0,0 +1,86 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestWorkloadLifecycleActions(t *testing.T) {
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "demo"},
+		Spec: appsv1.DeploymentSpec{
+			Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+				InitContainers: []corev1.Container{{Name: "init", Image: "busybox:1.35"}},
+				Containers:     []corev1.Container{{Name: "web", Image: "nginx:1.24"}, {Name: "sidecar", Image: "envoy:1.28"}},
+			}},
+		},
+	}
+	clientset := fake.NewSimpleClientset(deployment)
+	ctx := context.Background()
+
+	_, err := SetWorkloadImages(ctx, clientset, WorkloadKindDeployment, "demo", "web", map[string]string{"missing": "x:1"})
+	assert.ErrorIs(t, err, ErrInvalidWorkloadOperation)
+
+	_, err = SetWorkloadImages(ctx, clientset, WorkloadKindDeployment, "demo", "web", map[string]string{"web": "nginx:1.25", "init": "busybox:1.36"})
+	require.NoError(t, err)
+	updated, err := clientset.AppsV1().Deployments("demo").Get(ctx, "web", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "nginx:1.25", updated.Spec.Template.Spec.Containers[0].Image)
+	assert.Equal(t, "envoy:1.28", updated.Spec.Template.Spec.Containers[1].Image)
+	assert.Equal(t, "busybox:1.36", updated.Spec.Template.Spec.InitContainers[0].Image)
+
+	status, err := SetDeploymentPaused(ctx, clientset, "demo", "web", true)
+	require.NoError(t, err)
+	assert.True(t, status.Paused)
+	_, err = RestartWorkload(ctx, clientset, WorkloadKindDeployment, "demo", "web")
+	assert.ErrorIs(t, err, ErrInvalidWorkloadOperation)
+
+	_, err = SetDeploymentPaused(ctx, clientset, "demo", "web", false)
+	require.NoError(t, err)
+	_, err = RestartWorkload(ctx, clientset, WorkloadKindDeployment, "demo", "web")
+	require.NoError(t, err)
+	updated, err = clientset.AppsV1().Deployments("demo").Get(ctx, "web", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.NotEmpty(t, updated.Spec.Template.Annotations[RestartedAtAnnotation])
+}
+
+func TestStatefulSetRolloutStatusWithPartition(t *testing.T) {
+	replicas, partition := int32(5), int32(3)
+	s := &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "demo", Generation: 2},
+		Spec: appsv1.StatefulSetSpec{
+			Replicas: &replicas,
+			UpdateStrategy: appsv1.StatefulSetUpdateStrategy{
+				Type:          appsv1.RollingUpdateStatefulSetStrategyType,
+				RollingUpdate: &appsv1.RollingUpdateStatefulSetStrategy{Partition: &partition},
+			},
+		},
+		Status: appsv1.StatefulSetStatus{
+			ObservedGeneration: 2,
+			ReadyReplicas:      5,
+			UpdatedReplicas:    1,
+			CurrentRevision:    "db-1",
+			UpdateRevision:     "db-2",
+		},
+	}
+	status := StatefulSetRolloutStatus(s)
+	assert.False(t, status.Complete)
+
+	s.Status.UpdatedReplicas = 2
+	status = StatefulSetRolloutStatus(s)
+	assert.True(t, status.Complete)
+	require.NotNil(t, status.Partition)
+	assert.EqualValues(t, 3, *status.Partition)
+
+	s.Spec.UpdateStrategy.Type = appsv1.OnDeleteStatefulSetStrategyType
+	clientset := fake.NewSimpleClientset(s)
+	_, err := SetStatefulSetPartition(context.Background(), clientset, "demo", "db", 0)
+	assert.ErrorIs(t, err, ErrInvalidWorkloadOperation)
+}
@@ -13,6 +13,7 @@ import (
 	"github.com/clay-wangzhi/KubePolaris/internal/config"
 	"github.com/clay-wangzhi/KubePolaris/internal/database"
 	"github.com/clay-wangzhi/KubePolaris/internal/router"
+	"github.com/clay-wangzhi/KubePolaris/pkg/crypto"
 	"github.com/clay-wangzhi/KubePolaris/pkg/logger"
 
 	"github.com/gin-gonic/gin"
@@ -28,6 +29,11 @@ func main() {
 	// 初始化日志
 	logger.Init(cfg.Log.Level)
 
+	// 初始化凭据加密（集群 kubeconfig/Token、集成密钥等落库前加密）
+	if err := crypto.Init(cfg.Security.EncryptionOptions()); err != nil {
+		logger.Fatal("凭据加密初始化失败: %v", err)
+	}
+
 	// 初始化数据库连接
 	db, err := database.Init(cfg.Database)
 	if err != nil {
@@ -68,6 +74,8 @@ func main() {
 	if err := srv.Shutdown(ctx); err != nil {
 		logger.Fatal("服务器强制关闭: %v", err)
 	}
+	// 停止集群健康探测等后台组件
+	router.Shutdown()
 
 	logger.Info("服务器已退出")
 }
@@ -0,0 +1,196 @@
+// Package crypto 提供基于主密钥的信封加密（Envelope Encryption）
+//
+// 每次加密都会生成一个随机的 AES-256 数据密钥（DEK），用 DEK 以 AES-GCM 加密明文，
+// 再用主密钥（KEK）以 AES-GCM 包裹 DEK。密文格式：
+//
+//	enc:v1:<keyID>:<base64(wrappedDEK)>:<base64(nonce+ciphertext)>
+//
+// keyID 为主密钥 SHA-256 指纹的前 16 位十六进制，用于密钥轮换时识别旧密钥。
+// 不带 enc:v1: 前缀的值视为历史明文数据，解密时原样返回。
+package crypto
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+const (
+	// envelopePrefix 密文前缀（含格式版本号）
+	envelopePrefix = "enc:v1:"
+	// KeySize 主密钥/数据密钥长度（AES-256）
+	KeySize = 32
+)
+
+// ErrNotInitialized 默认密钥环尚未初始化
+var ErrNotInitialized = errors.New("加密模块未初始化，请先配置主密钥")
+
+// Keyring 密钥环：一个用于加密的主密钥 + 若干仅用于解密的历史密钥
+type Keyring struct {
+	primaryID string
+	keys      map[string][]byte
+}
+
+// NewKeyring 创建密钥环，primary 用于加密，previous 为轮换前的旧密钥（仅用于解密）
+func NewKeyring(primary []byte, previous ...[]byte) (*Keyring, error) {
+	if len(primary) != KeySize {
+		return nil, fmt.Errorf("主密钥长度必须为 %d 字节，当前为 %d 字节", KeySize, len(primary))
+	}
+	kr := &Keyring{
+		primaryID: KeyID(primary),
+		keys:      map[string][]byte{KeyID(primary): primary},
+	}
+	for _, key := range previous {
+		if len(key) != KeySize {
+			return nil, fmt.Errorf("历史密钥长度必须为 %d 字节，当前为 %d 字节", KeySize, len(key))
+		}
+		if _, ok := kr.keys[KeyID(key)]; !ok {
+			kr.keys[KeyID(key)] = key
+		}
+	}
+	return kr, nil
+}
+
+// KeyID 计算密钥指纹
+func KeyID(key []byte) string {
+	sum := sha256.Sum256(key)
+	return hex.EncodeToString(sum[:])[:16]
+}
+
+// PrimaryKeyID 返回当前主密钥的指纹
+func (k *Keyring) PrimaryKeyID() string {
+	return k.primaryID
+}
+
+// IsEncrypted 判断值是否为信封加密格式
+func IsEncrypted(value string) bool {
+	return strings.HasPrefix(value, envelopePrefix)
+}
+
+// Encrypt 使用主密钥加密，空字符串原样返回
+func (k *Keyring) Encrypt(plaintext string) (string, error) {
+	if plaintext == "" {
+		return "", nil
+	}
+
+	dek := make([]byte, KeySize)
+	if _, err := io.ReadFull(rand.Reader, dek); err != nil {
+		return "", fmt.Errorf("生成数据密钥失败: %w", err)
+	}
+
+	// keyID 作为附加认证数据，防止密文被挪用到其他密钥下
+	aad := []byte(k.primaryID)
+	wrapped, err := seal(k.keys[k.primaryID], dek, aad)
+	if err != nil {
+		return "", fmt.Errorf("包裹数据密钥失败: %w", err)
+	}
+	ciphertext, err := seal(dek, []byte(plaintext), aad)
+	if err != nil {
+		return "", fmt.Errorf("加密数据失败: %w", err)
+	}
+
+	return envelopePrefix + k.primaryID + ":" +
+		base64.StdEncoding.EncodeToString(wrapped) + ":" +
+		base64.StdEncoding.EncodeToString(ciphertext), nil
+}
+
+// Decrypt 解密信封密文；非加密格式（历史明文）原样返回
+func (k *Keyring) Decrypt(value string) (string, error) {
+	if !IsEncrypted(value) {
+		return value, nil
+	}
+
+	parts := strings.Split(strings.TrimPrefix(value, envelopePrefix), ":")
+	if len(parts) != 3 {
+		return "", errors.New("密文格式错误")
+	}
+	keyID := parts[0]
+	kek, ok := k.keys[keyID]
+	if !ok {
+		return "", fmt.Errorf("未找到密钥 %s，请检查主密钥或历史密钥配置", keyID)
+	}
+
+	wrapped, err := base64.StdEncoding.DecodeString(parts[1])
+	if err != nil {
+		return "", fmt.Errorf("解析数据密钥失败: %w", err)
+	}
+	ciphertext, err := base64.StdEncoding.DecodeString(parts[2])
+	if err != nil {
+		return "", fmt.Errorf("解析密文失败: %w", err)
+	}
+
+	aad := []byte(keyID)
+	dek, err := open(kek, wrapped, aad)
+	if err != nil {
+		return "", fmt.Errorf("解包数据密钥失败: %w", err)
+	}
+	plaintext, err := open(dek, ciphertext, aad)
+	if err != nil {
+		return "", fmt.Errorf("解密数据失败: %w", err)
+	}
+	return string(plaintext), nil
+}
+
+// NeedsRotation 判断值是否需要用当前主密钥重新加密（明文或由旧密钥加密）
+func (k *Keyring) NeedsRotation(value string) bool {
+	if value == "" {
+		return false
+	}
+	if !IsEncrypted(value) {
+		return true
+	}
+	return !strings.HasPrefix(value, envelopePrefix+k.primaryID+":")
+}
+
+// Reencrypt 使用当前主密钥重新加密（支持明文和旧密钥密文）
+func (k *Keyring) Reencrypt(value string) (string, error) {
+	if !k.NeedsRotation(value) {
+		return value, nil
+	}
+	plaintext, err := k.Decrypt(value)
+	if err != nil {
+		return "", err
+	}
+	return k.Encrypt(plaintext)
+}
+
+// seal AES-GCM 加密，输出 nonce+ciphertext
+func seal(key, plaintext, aad []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+	return gcm.Seal(nonce, nonce, plaintext, aad), nil
+}
+
+// open AES-GCM 解密，输入 nonce+ciphertext
+func open(key, data, aad []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < gcm.NonceSize() {
+		return nil, errors.New("密文长度不足")
+	}
+	nonce, ciphertext := data[:gcm.NonceSize()], data[gcm.NonceSize():]
+	return gcm.Open(nil, nonce, ciphertext, aad)
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,105 @@
+package crypto
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func mustKey(t *testing.T) []byte {
+	key, err := GenerateKey()
+	require.NoError(t, err)
+	return key
+}
+
+// TestEncryptDecrypt 测试加解密往返
+func TestEncryptDecrypt(t *testing.T) {
+	kr, err := NewKeyring(mustKey(t))
+	require.NoError(t, err)
+
+	enc, err := kr.Encrypt("apiVersion: v1\nkind: Config")
+	require.NoError(t, err)
+	assert.True(t, IsEncrypted(enc))
+	assert.NotContains(t, enc, "kind: Config")
+
+	plain, err := kr.Decrypt(enc)
+	require.NoError(t, err)
+	assert.Equal(t, "apiVersion: v1\nkind: Config", plain)
+
+	// 相同明文每次加密结果不同（随机数据密钥）
+	enc2, err := kr.Encrypt("apiVersion: v1\nkind: Config")
+	require.NoError(t, err)
+	assert.NotEqual(t, enc, enc2)
+}
+
+// TestEmptyAndLegacyPlaintext 测试空值与历史明文
+func TestEmptyAndLegacyPlaintext(t *testing.T) {
+	kr, err := NewKeyring(mustKey(t))
+	require.NoError(t, err)
+
+	enc, err := kr.Encrypt("")
+	require.NoError(t, err)
+	assert.Equal(t, "", enc)
+
+	plain, err := kr.Decrypt("legacy-token")
+	require.NoError(t, err)
+	assert.Equal(t, "legacy-token", plain)
+	assert.True(t, kr.NeedsRotation("legacy-token"))
+	assert.False(t, kr.NeedsRotation(""))
+}
+
+// TestRotation 测试密钥轮换
+func TestRotation(t *testing.T) {
+	oldKey, newKey := mustKey(t), mustKey(t)
+
+	oldRing, err := NewKeyring(oldKey)
+	require.NoError(t, err)
+	enc, err := oldRing.Encrypt("secret")
+	require.NoError(t, err)
+
+	// 仅有新密钥时无法解密
+	newOnly, err := NewKeyring(newKey)
+	require.NoError(t, err)
+	_, err = newOnly.Decrypt(enc)
+	assert.Error(t, err)
+
+	rotating, err := NewKeyring(newKey, oldKey)
+	require.NoError(t, err)
+	assert.True(t, rotating.NeedsRotation(enc))
+
+	rotated, err := rotating.Reencrypt(enc)
+	require.NoError(t, err)
+	assert.False(t, rotating.NeedsRotation(rotated))
+
+	plain, err := newOnly.Decrypt(rotated)
+	require.NoError(t, err)
+	assert.Equal(t, "secret", plain)
+}
+
+// TestTamperedCiphertext 测试密文被篡改
+func TestTamperedCiphertext(t *testing.T) {
+	kr, err := NewKeyring(mustKey(t))
+	require.NoError(t, err)
+	enc, err := kr.Encrypt("secret")
+	require.NoError(t, err)
+
+	tampered := enc[:len(enc)-4] + "AAAA"
+	_, err = kr.Decrypt(tampered)
+	assert.Error(t, err)
+}
+
+// TestLoadKeyringAutoGenerate 测试自动生成主密钥文件
+func TestLoadKeyringAutoGenerate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "keys", "master.key")
+
+	_, err := LoadKeyring(Options{MasterKeyFile: path})
+	assert.Error(t, err)
+
+	kr1, err := LoadKeyring(Options{MasterKeyFile: path, AutoGenerate: true})
+	require.NoError(t, err)
+	kr2, err := LoadKeyring(Options{MasterKeyFile: path})
+	require.NoError(t, err)
+	assert.Equal(t, kr1.PrimaryKeyID(), kr2.PrimaryKeyID())
+}
@@ -0,0 +1,177 @@
+package crypto
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/clay-wangzhi/KubePolaris/pkg/logger"
+)
+
+// Options 主密钥加载选项
+type Options struct {
+	MasterKey        string   // base64 编码的主密钥（优先级最高）
+	MasterKeyFile    string   // 主密钥文件路径（文件内容为 base64 编码的密钥）
+	PreviousKeys     []string // 轮换前的旧密钥（base64），仅用于解密
+	PreviousKeyFiles []string // 轮换前的旧密钥文件
+	AutoGenerate     bool     // 主密钥文件不存在时是否自动生成
+}
+
+var (
+	defaultMu      sync.RWMutex
+	defaultKeyring *Keyring
+)
+
+// Init 根据配置加载主密钥并初始化默认密钥环
+func Init(opts Options) error {
+	kr, err := LoadKeyring(opts)
+	if err != nil {
+		return err
+	}
+	SetDefault(kr)
+	logger.Info("凭据加密已启用，主密钥指纹: %s", kr.PrimaryKeyID())
+	return nil
+}
+
+// LoadKeyring 根据配置加载密钥环
+func LoadKeyring(opts Options) (*Keyring, error) {
+	primary, err := loadPrimaryKey(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var previous [][]byte
+	for _, encoded := range opts.PreviousKeys {
+		if strings.TrimSpace(encoded) == "" {
+			continue
+		}
+		key, err := DecodeKey(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("解析历史密钥失败: %w", err)
+		}
+		previous = append(previous, key)
+	}
+	for _, path := range opts.PreviousKeyFiles {
+		if strings.TrimSpace(path) == "" {
+			continue
+		}
+		key, err := readKeyFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("读取历史密钥文件失败: %w", err)
+		}
+		previous = append(previous, key)
+	}
+
+	return NewKeyring(primary, previous...)
+}
+
+// loadPrimaryKey 加载主密钥：环境变量 > 密钥文件 > 自动生成
+func loadPrimaryKey(opts Options) ([]byte, error) {
+	if opts.MasterKey != "" {
+		return DecodeKey(opts.MasterKey)
+	}
+	if opts.MasterKeyFile == "" {
+		return nil, errors.New("未配置主密钥，请设置 ENCRYPTION_MASTER_KEY 或 ENCRYPTION_MASTER_KEY_FILE")
+	}
+
+	key, err := readKeyFile(opts.MasterKeyFile)
+	if err == nil {
+		return key, nil
+	}
+	if !errors.Is(err, os.ErrNotExist) || !opts.AutoGenerate {
+		return nil, fmt.Errorf("读取主密钥文件失败: %w", err)
+	}
+
+	key, err = GenerateKey()
+	if err != nil {
+		return nil, err
+	}
+	if dir := filepath.Dir(opts.MasterKeyFile); dir != "" && dir != "." {
+		if err := os.MkdirAll(dir, 0700); err != nil {
+			return nil, fmt.Errorf("创建主密钥目录失败: %w", err)
+		}
+	}
+	if err := os.WriteFile(opts.MasterKeyFile, []byte(EncodeKey(key)+"\n"), 0600); err != nil {
+		return nil, fmt.Errorf("写入主密钥文件失败: %w", err)
+	}
+	logger.Warn("未找到主密钥，已自动生成: %s（请妥善备份，丢失后已加密的凭据将无法恢复）", opts.MasterKeyFile)
+	return key, nil
+}
+
+func readKeyFile(path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return DecodeKey(string(data))
+}
+
+// GenerateKey 生成随机主密钥
+func GenerateKey() ([]byte, error) {
+	key := make([]byte, KeySize)
+	if _, err := io.ReadFull(rand.Reader, key); err != nil {
+		return nil, fmt.Errorf("生成密钥失败: %w", err)
+	}
+	return key, nil
+}
+
+// EncodeKey 将密钥编码为 base64 字符串
+func EncodeKey(key []byte) string {
+	return base64.StdEncoding.EncodeToString(key)
+}
+
+// DecodeKey 解析 base64 编码的密钥
+func DecodeKey(encoded string) ([]byte, error) {
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+	if err != nil {
+		return nil, fmt.Errorf("密钥必须为 base64 编码: %w", err)
+	}
+	if len(key) != KeySize {
+		return nil, fmt.Errorf("密钥长度必须为 %d 字节，当前为 %d 字节", KeySize, len(key))
+	}
+	return key, nil
+}
+
+// SetDefault 设置默认密钥环
+func SetDefault(kr *Keyring) {
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+	defaultKeyring = kr
+}
+
+// Default 返回默认密钥环（未初始化时为 nil）
+func Default() *Keyring {
+	defaultMu.RLock()
+	defer defaultMu.RUnlock()
+	return defaultKeyring
+}
+
+// Encrypt 使用默认密钥环加密，空字符串无需初始化即可原样返回
+func Encrypt(plaintext string) (string, error) {
+	if plaintext == "" {
+		return "", nil
+	}
+	kr := Default()
+	if kr == nil {
+		return "", ErrNotInitialized
+	}
+	return kr.Encrypt(plaintext)
+}
+
+// Decrypt 使用默认密钥环解密，历史明文无需初始化即可原样返回
+func Decrypt(value string) (string, error) {
+	if !IsEncrypted(value) {
+		return value, nil
+	}
+	kr := Default()
+	if kr == nil {
+		return "", ErrNotInitialized
+	}
+	return kr.Decrypt(value)
+}
@@ -0,0 +1,277 @@
+// Package tunnel 在一条 WebSocket 连接上复用多个双向字节流
+//
+// KubePolaris 与集群 Agent 之间的反向隧道基于该包实现：Agent 主动连接服务端，
+// 服务端通过 Session.Open 打开新的流（对 Agent 而言是 Accept），流实现了 net.Conn，
+// 可以直接作为 HTTP 连接使用。
+//
+// 帧格式（WebSocket 二进制消息）：1 字节类型 + 4 字节流 ID（大端）+ 负载。
+//
+// 流控：每个流的发送方最多有 streamReadBuffer 个未被对端读取的数据帧，接收方每读取
+// 一定数量的帧后通过窗口帧归还额度。因此读循环投递数据时不会阻塞，单个流读取缓慢
+// 不会影响同一会话上的其他流；对端超出窗口时该流被重置。
+// 同理，对端打开流的速度超过本端 Accept 时，超出 acceptBacklog 的流被直接重置。
+package tunnel
+
+import (
+	"encoding/binary"
+	"errors"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+const (
+	frameOpen      byte = 1 // 打开流
+	frameData      byte = 2 // 流数据
+	frameClose     byte = 3 // 关闭流
+	frameHeartbeat byte = 4 // 心跳（负载由上层定义）
+	frameWindow    byte = 5 // 归还发送额度（负载为 4 字节帧数，大端）
+
+	headerSize      = 5
+	acceptBacklog   = 16 // 等待 Accept 的流数量上限
+	maxFramePayload = 32 * 1024
+	writeTimeout    = 30 * time.Second
+
+	// DefaultPingInterval WebSocket ping 间隔，超过 3 个间隔未收到任何消息视为连接断开
+	DefaultPingInterval = 20 * time.Second
+)
+
+var (
+	// ErrSessionClosed 会话已关闭
+	ErrSessionClosed = errors.New("tunnel: session closed")
+	// ErrStreamReset 对端发送的数据超出流控窗口，流被重置
+	ErrStreamReset = errors.New("tunnel: stream reset")
+)
+
+// Config 会话配置
+type Config struct {
+	// PingInterval WebSocket ping 间隔，默认 DefaultPingInterval
+	PingInterval time.Duration
+	// OnHeartbeat 收到对端心跳帧时回调（在读循环中同步执行，不应阻塞）
+	OnHeartbeat func(s *Session, payload []byte)
+}
+
+// Session 一条 WebSocket 连接上的多路复用会话
+// 同时实现 net.Listener，可直接交给 http.Server.Serve 处理对端打开的流。
+type Session struct {
+	conn *websocket.Conn
+	cfg  Config
+
+	writeMu sync.Mutex
+
+	mu       sync.Mutex
+	streams  map[uint32]*stream
+	nextID   uint32
+	acceptCh chan *stream
+
+	done      chan struct{}
+	closeOnce sync.Once
+	err       error
+}
+
+// NewSession 基于已建立的 WebSocket 连接创建会话并启动读循环
+func NewSession(conn *websocket.Conn, cfg Config) *Session {
+	if cfg.PingInterval <= 0 {
+		cfg.PingInterval = DefaultPingInterval
+	}
+	s := &Session{
+		conn:     conn,
+		cfg:      cfg,
+		streams:  make(map[uint32]*stream),
+		acceptCh: make(chan *stream, acceptBacklog),
+		done:     make(chan struct{}),
+	}
+
+	deadline := 3 * cfg.PingInterval
+	_ = conn.SetReadDeadline(time.Now().Add(deadline))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(deadline))
+	})
+
+	go s.readLoop(deadline)
+	go s.pingLoop()
+	return s
+}
+
+// Open 打开一个新的流
+func (s *Session) Open() (net.Conn, error) {
+	select {
+	case <-s.done:
+		return nil, ErrSessionClosed
+	default:
+	}
+
+	id := atomic.AddUint32(&s.nextID, 1)
+	st := newStream(s, id)
+	s.mu.Lock()
+	s.streams[id] = st
+	s.mu.Unlock()
+
+	if err := s.writeFrame(frameOpen, id, nil); err != nil {
+		s.removeStream(id)
+		return nil, err
+	}
+	return st, nil
+}
+
+// Accept 等待对端打开的流（实现 net.Listener）
+func (s *Session) Accept() (net.Conn, error) {
+	select {
+	case st := <-s.acceptCh:
+		return st, nil
+	case <-s.done:
+		return nil, ErrSessionClosed
+	}
+}
+
+// Addr 实现 net.Listener
+func (s *Session) Addr() net.Addr {
+	return s.conn.LocalAddr()
+}
+
+// RemoteAddr 对端地址
+func (s *Session) RemoteAddr() net.Addr {
+	return s.conn.RemoteAddr()
+}
+
+// SendHeartbeat 发送心跳帧
+func (s *Session) SendHeartbeat(payload []byte) error {
+	return s.writeFrame(frameHeartbeat, 0, payload)
+}
+
+// Done 会话关闭时关闭的 channel
+func (s *Session) Done() <-chan struct{} {
+	return s.done
+}
+
+// Err 会话关闭的原因
+func (s *Session) Err() error {
+	select {
+	case <-s.done:
+		return s.err
+	default:
+		return nil
+	}
+}
+
+// Close 关闭会话及其所有流
+func (s *Session) Close() error {
+	s.closeWithError(ErrSessionClosed)
+	return nil
+}
+
+func (s *Session) closeWithError(err error) {
+	s.closeOnce.Do(func() {
+		s.err = err
+		close(s.done)
+		_ = s.conn.WriteControl(websocket.CloseMessage,
+			websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""), time.Now().Add(time.Second))
+		_ = s.conn.Close()
+	})
+}
+
+func (s *Session) readLoop(deadline time.Duration) {
+	for {
+		msgType, data, err := s.conn.ReadMessage()
+		if err != nil {
+			s.closeWithError(err)
+			return
+		}
+		_ = s.conn.SetReadDeadline(time.Now().Add(deadline))
+		if msgType != websocket.BinaryMessage || len(data) < headerSize {
+			continue
+		}
+
+		typ, id, payload := data[0], binary.BigEndian.Uint32(data[1:headerSize]), data[headerSize:]
+		switch typ {
+		case frameOpen:
+			st := newStream(s, id)
+			s.mu.Lock()
+			s.streams[id] = st
+			s.mu.Unlock()
+			select {
+			case s.acceptCh <- st:
+			default:
+				// Accept 队列已满：拒绝该流，不能阻塞读循环而影响其他流的数据投递
+				st.reset()
+			}
+		case frameData:
+			if st := s.getStream(id); st != nil && !st.deliver(payload) {
+				// 对端未遵守流控窗口：重置该流而不是阻塞整个会话
+				st.reset()
+			}
+		case frameWindow:
+			if st := s.getStream(id); st != nil && len(payload) >= 4 {
+				st.addCredits(binary.BigEndian.Uint32(payload))
+			}
+		case frameClose:
+			if st := s.getStream(id); st != nil {
+				st.closeRemote()
+				s.removeStream(id)
+			}
+		case frameHeartbeat:
+			if s.cfg.OnHeartbeat != nil {
+				s.cfg.OnHeartbeat(s, payload)
+			}
+		}
+	}
+}
+
+func (s *Session) pingLoop() {
+	ticker := time.NewTicker(s.cfg.PingInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.done:
+			return
+		case <-ticker.C:
+			if err := s.conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(writeTimeout)); err != nil {
+				s.closeWithError(err)
+				return
+			}
+		}
+	}
+}
+
+func (s *Session) writeFrame(typ byte, id uint32, payload []byte) error {
+	buf := make([]byte, headerSize+len(payload))
+	buf[0] = typ
+	binary.BigEndian.PutUint32(buf[1:headerSize], id)
+	copy(buf[headerSize:], payload)
+
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+	select {
+	case <-s.done:
+		return ErrSessionClosed
+	default:
+	}
+	_ = s.conn.SetWriteDeadline(time.Now().Add(writeTimeout))
+	if err := s.conn.WriteMessage(websocket.BinaryMessage, buf); err != nil {
+		s.closeWithError(err)
+		return err
+	}
+	return nil
+}
+
+func (s *Session) getStream(id uint32) *stream {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.streams[id]
+}
+
+func (s *Session) removeStream(id uint32) {
+	s.mu.Lock()
+	delete(s.streams, id)
+	s.mu.Unlock()
+}
+
+// NumStreams 当前打开的流数量
+func (s *Session) NumStreams() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.streams)
+}
@@ -0,0 +1,314 @@
+package tunnel
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newSessionPair 建立一对通过 WebSocket 连接的会话：server 端负责 Open，agent 端负责 Accept
+func newSessionPair(t *testing.T, serverCfg, agentCfg Config) (*Session, *Session) {
+	t.Helper()
+
+	serverCh := make(chan *Session, 1)
+	upgrader := websocket.Upgrader{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			t.Errorf("upgrade: %v", err)
+			return
+		}
+		serverCh <- NewSession(conn, serverCfg)
+	}))
+	t.Cleanup(srv.Close)
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(srv.URL, "http"), nil)
+	require.NoError(t, err)
+	agent := NewSession(conn, agentCfg)
+	server := <-serverCh
+	t.Cleanup(func() {
+		_ = server.Close()
+		_ = agent.Close()
+	})
+	return server, agent
+}
+
+func TestSession_HTTPOverStream(t *testing.T) {
+	server, agent := newSessionPair(t, Config{}, Config{})
+
+	// agent 端在会话上提供 HTTP 服务
+	go func() {
+		_ = http.Serve(agent, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ := io.ReadAll(r.Body)
+			_, _ = w.Write([]byte(r.URL.Path + ":" + string(body)))
+		}))
+	}()
+
+	client := &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			return server.Open()
+		},
+	}}
+
+	for _, path := range []string{"/api", "/apis/apps/v1"} {
+		resp, err := client.Post("http://agent"+path, "text/plain", strings.NewReader("hello"))
+		require.NoError(t, err)
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		require.NoError(t, err)
+		assert.Equal(t, path+":hello", string(body))
+	}
+}
+
+func TestSession_LargePayload(t *testing.T) {
+	server, agent := newSessionPair(t, Config{}, Config{})
+
+	payload := bytes.Repeat([]byte("kubepolaris"), 20000) // 超过单帧上限，会被拆分
+	go func() {
+		conn, err := agent.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		_, _ = io.Copy(conn, conn)
+	}()
+
+	conn, err := server.Open()
+	require.NoError(t, err)
+	go func() {
+		_, _ = conn.Write(payload)
+	}()
+
+	got := make([]byte, len(payload))
+	_, err = io.ReadFull(conn, got)
+	require.NoError(t, err)
+	assert.Equal(t, payload, got)
+	require.NoError(t, conn.Close())
+}
+
+func TestSession_RemoteCloseReturnsEOF(t *testing.T) {
+	server, agent := newSessionPair(t, Config{}, Config{})
+
+	go func() {
+		conn, err := agent.Accept()
+		if err != nil {
+			return
+		}
+		_, _ = conn.Write([]byte("bye"))
+		_ = conn.Close()
+	}()
+
+	conn, err := server.Open()
+	require.NoError(t, err)
+	data, err := io.ReadAll(conn)
+	require.NoError(t, err)
+	assert.Equal(t, "bye", string(data))
+}
+
+func TestSession_Heartbeat(t *testing.T) {
+	received := make(chan string, 1)
+	_, agent := newSessionPair(t, Config{OnHeartbeat: func(_ *Session, p []byte) { received <- string(p) }}, Config{})
+
+	require.NoError(t, agent.SendHeartbeat([]byte(`{"version":"v1"}`)))
+	select {
+	case got := <-received:
+		assert.Equal(t, `{"version":"v1"}`, got)
+	case <-time.After(5 * time.Second):
+		t.Fatal("未收到心跳")
+	}
+}
+
+func TestSession_CloseUnblocksStreams(t *testing.T) {
+	server, agent := newSessionPair(t, Config{}, Config{})
+
+	conn, err := server.Open()
+	require.NoError(t, err)
+	_, err = agent.Accept()
+	require.NoError(t, err)
+
+	_ = agent.Close()
+	select {
+	case <-server.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("对端关闭后会话未结束")
+	}
+	_, err = conn.Read(make([]byte, 1))
+	assert.Error(t, err)
+	_, err = server.Open()
+	assert.ErrorIs(t, err, ErrSessionClosed)
+}
+
+func TestSession_StalledStreamDoesNotBlockOthers(t *testing.T) {
+	server, agent := newSessionPair(t, Config{}, Config{})
+
+	// agent 端：第一个流持续写入大量数据，第二个流回显
+	total := 4 * streamReadBuffer * maxFramePayload
+	writeDone := make(chan error, 1)
+	go func() {
+		stalled, err := agent.Accept()
+		if err != nil {
+			writeDone <- err
+			return
+		}
+		go func() {
+			_, err := stalled.Write(bytes.Repeat([]byte("x"), total))
+			writeDone <- err
+		}()
+		echo, err := agent.Accept()
+		if err != nil {
+			return
+		}
+		defer echo.Close()
+		_, _ = io.Copy(echo, echo)
+	}()
+
+	// 第一个流始终不读取
+	stalled, err := server.Open()
+	require.NoError(t, err)
+	echo, err := server.Open()
+	require.NoError(t, err)
+
+	for i := 0; i < 3; i++ {
+		_, err = echo.Write([]byte("ping"))
+		require.NoError(t, err)
+		require.NoError(t, echo.SetReadDeadline(time.Now().Add(5*time.Second)))
+		got := make([]byte, 4)
+		_, err = io.ReadFull(echo, got)
+		require.NoError(t, err, "未读取的流不应阻塞其他流")
+		assert.Equal(t, "ping", string(got))
+	}
+
+	// 写入方受流控窗口限制而阻塞，未出现超出窗口的重置
+	select {
+	case err := <-writeDone:
+		t.Fatalf("写入应等待对端读取, err=%v", err)
+	default:
+	}
+
+	// 开始读取后额度归还，写入完成且数据完整
+	require.NoError(t, stalled.SetReadDeadline(time.Now().Add(10*time.Second)))
+	got := make([]byte, total)
+	_, err = io.ReadFull(stalled, got)
+	require.NoError(t, err)
+	assert.Equal(t, bytes.Repeat([]byte("x"), total), got)
+	select {
+	case err := <-writeDone:
+		require.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("读取完成后写入仍未结束")
+	}
+}
+
+func TestStream_WriteDeadline(t *testing.T) {
+	server, agent := newSessionPair(t, Config{}, Config{})
+
+	accepted := make(chan net.Conn, 1)
+	go func() {
+		if st, err := agent.Accept(); err == nil {
+			accepted <- st
+		}
+	}()
+	st, err := server.Open()
+	require.NoError(t, err)
+	// 对端接受但从不读取：写满流控窗口后 Write 应在截止时间返回
+	peer := <-accepted
+	defer peer.Close()
+
+	require.NoError(t, st.SetWriteDeadline(time.Now().Add(200*time.Millisecond)))
+	start := time.Now()
+	n, err := st.Write(bytes.Repeat([]byte("x"), 2*streamReadBuffer*maxFramePayload))
+	require.ErrorIs(t, err, os.ErrDeadlineExceeded)
+	var netErr net.Error
+	require.ErrorAs(t, err, &netErr)
+	assert.True(t, netErr.Timeout())
+	assert.Equal(t, streamReadBuffer*maxFramePayload, n)
+	assert.Less(t, time.Since(start), 5*time.Second)
+
+	// 清除截止时间后，对端读取归还额度即可继续写入
+	require.NoError(t, st.SetWriteDeadline(time.Time{}))
+	go func() { _, _ = io.Copy(io.Discard, peer) }()
+	_, err = st.Write([]byte("more"))
+	require.NoError(t, err)
+}
+
+func TestStream_DeadlineChangeWakesBlockedCalls(t *testing.T) {
+	server, agent := newSessionPair(t, Config{}, Config{})
+	go func() {
+		if st, err := agent.Accept(); err == nil {
+			defer st.Close()
+			<-server.Done()
+		}
+	}()
+	st, err := server.Open()
+	require.NoError(t, err)
+
+	readErr := make(chan error, 1)
+	go func() {
+		_, err := st.Read(make([]byte, 1))
+		readErr <- err
+	}()
+	time.Sleep(50 * time.Millisecond)
+	// 阻塞中的 Read 没有截止时间，设置一个已过去的截止时间应立即唤醒它
+	require.NoError(t, st.SetDeadline(time.Now().Add(-time.Second)))
+	select {
+	case err := <-readErr:
+		assert.ErrorIs(t, err, os.ErrDeadlineExceeded)
+	case <-time.After(5 * time.Second):
+		t.Fatal("修改截止时间未唤醒阻塞的 Read")
+	}
+	_, err = st.Write([]byte("x"))
+	assert.ErrorIs(t, err, os.ErrDeadlineExceeded)
+}
+
+func TestSession_AcceptBacklogDoesNotBlockReadLoop(t *testing.T) {
+	server, agent := newSessionPair(t, Config{}, Config{})
+
+	// 先建立一条正常的回显流
+	go func() {
+		echo, err := agent.Accept()
+		if err != nil {
+			return
+		}
+		defer echo.Close()
+		_, _ = io.Copy(echo, echo)
+	}()
+	echo, err := server.Open()
+	require.NoError(t, err)
+	_, err = echo.Write([]byte("ping"))
+	require.NoError(t, err)
+	got := make([]byte, 4)
+	require.NoError(t, echo.SetReadDeadline(time.Now().Add(5*time.Second)))
+	_, err = io.ReadFull(echo, got)
+	require.NoError(t, err)
+
+	// agent 不再 Accept：超出 backlog 的流被重置
+	var burst []net.Conn
+	for i := 0; i < acceptBacklog+4; i++ {
+		st, err := server.Open()
+		require.NoError(t, err)
+		burst = append(burst, st)
+	}
+	last := burst[len(burst)-1]
+	require.NoError(t, last.SetReadDeadline(time.Now().Add(5*time.Second)))
+	_, err = last.Read(make([]byte, 1))
+	assert.ErrorIs(t, err, io.EOF, "超出 Accept 队列的流应被对端关闭")
+
+	// 读循环未被阻塞，已建立的流仍可正常收发
+	_, err = echo.Write([]byte("pong"))
+	require.NoError(t, err)
+	require.NoError(t, echo.SetReadDeadline(time.Now().Add(5*time.Second)))
+	_, err = io.ReadFull(echo, got)
+	require.NoError(t, err)
+	assert.Equal(t, "pong", string(got))
+}
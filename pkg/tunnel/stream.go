@@ -0,0 +1,288 @@
+package tunnel
+
+import (
+	"encoding/binary"
+	"io"
+	"net"
+	"os"
+	"sync"
+	"time"
+)
+
+const (
+	// streamReadBuffer 每个流缓存的未读帧数量，同时也是发送方的初始流控窗口
+	streamReadBuffer = 64
+	// windowUpdateThreshold 接收方累计读取该数量的帧后归还额度
+	windowUpdateThreshold = streamReadBuffer / 2
+)
+
+// stream 会话中的单个双向字节流，实现 net.Conn
+type stream struct {
+	s  *Session
+	id uint32
+
+	readCh  chan []byte
+	pending []byte
+
+	remoteClosed chan struct{}
+	remoteOnce   sync.Once
+	remoteErr    error // 流结束时读取返回的错误，正常关闭为 io.EOF
+	localClosed  chan struct{}
+	localOnce    sync.Once
+
+	mu            sync.Mutex
+	readDeadline  time.Time
+	writeDeadline time.Time
+	deadlineCh    chan struct{} // 截止时间变更时关闭并替换，唤醒阻塞的读写
+	sendCredits   int           // 剩余可发送的数据帧数
+	creditCh      chan struct{} // 额度增加时通知阻塞的 Write
+	unacked       int           // 已读取但尚未归还额度的帧数
+}
+
+func newStream(s *Session, id uint32) *stream {
+	return &stream{
+		s:            s,
+		id:           id,
+		readCh:       make(chan []byte, streamReadBuffer),
+		remoteClosed: make(chan struct{}),
+		remoteErr:    io.EOF,
+		localClosed:  make(chan struct{}),
+		deadlineCh:   make(chan struct{}),
+		sendCredits:  streamReadBuffer,
+		creditCh:     make(chan struct{}, 1),
+	}
+}
+
+// deliver 由会话读循环调用，投递对端发送的数据，不会阻塞
+// 返回 false 表示读缓冲已满，即对端超出了流控窗口。
+func (st *stream) deliver(p []byte) bool {
+	select {
+	case st.readCh <- p:
+		return true
+	case <-st.localClosed:
+		return true
+	default:
+		return false
+	}
+}
+
+func (st *stream) closeRemote() {
+	st.closeRemoteWithError(io.EOF)
+}
+
+func (st *stream) closeRemoteWithError(err error) {
+	st.remoteOnce.Do(func() {
+		st.remoteErr = err
+		close(st.remoteClosed)
+	})
+}
+
+// reset 重置流：本端读取返回 ErrStreamReset，并通知对端关闭
+func (st *stream) reset() {
+	st.closeRemoteWithError(ErrStreamReset)
+	st.s.removeStream(st.id)
+	_ = st.s.writeFrame(frameClose, st.id, nil)
+}
+
+// addCredits 对端归还发送额度
+func (st *stream) addCredits(n uint32) {
+	st.mu.Lock()
+	st.sendCredits += int(n)
+	st.mu.Unlock()
+	select {
+	case st.creditCh <- struct{}{}:
+	default:
+	}
+}
+
+// acquireCredit 获取一个数据帧的发送额度，额度用尽时等待对端读取，直到写截止时间
+func (st *stream) acquireCredit() error {
+	for {
+		st.mu.Lock()
+		deadline, changed := st.writeDeadline, st.deadlineCh
+		if !deadline.IsZero() && !time.Now().Before(deadline) {
+			st.mu.Unlock()
+			return os.ErrDeadlineExceeded
+		}
+		if st.sendCredits > 0 {
+			st.sendCredits--
+			st.mu.Unlock()
+			return nil
+		}
+		st.mu.Unlock()
+
+		timeout, stop := deadlineTimer(deadline)
+		select {
+		case <-st.creditCh:
+		case <-changed:
+		case <-timeout:
+		case <-st.localClosed:
+			stop()
+			return net.ErrClosed
+		case <-st.remoteClosed:
+			stop()
+			return io.ErrClosedPipe
+		case <-st.s.done:
+			stop()
+			return ErrSessionClosed
+		}
+		stop()
+	}
+}
+
+// deadlineTimer 返回在截止时间触发的 channel；截止时间为零值时返回 nil（永不触发）
+func deadlineTimer(deadline time.Time) (<-chan time.Time, func()) {
+	if deadline.IsZero() {
+		return nil, func() {}
+	}
+	timer := time.NewTimer(time.Until(deadline))
+	return timer.C, func() { timer.Stop() }
+}
+
+// consumed 记录已读取一个数据帧，累计到阈值后向对端归还额度
+func (st *stream) consumed() {
+	st.mu.Lock()
+	st.unacked++
+	n := st.unacked
+	if n < windowUpdateThreshold {
+		st.mu.Unlock()
+		return
+	}
+	st.unacked = 0
+	st.mu.Unlock()
+
+	select {
+	case <-st.remoteClosed:
+		return
+	default:
+	}
+	var payload [4]byte
+	binary.BigEndian.PutUint32(payload[:], uint32(n))
+	_ = st.s.writeFrame(frameWindow, st.id, payload[:])
+}
+
+func (st *stream) Read(b []byte) (int, error) {
+	if len(st.pending) > 0 {
+		n := copy(b, st.pending)
+		st.pending = st.pending[n:]
+		return n, nil
+	}
+
+	for {
+		st.mu.Lock()
+		deadline, changed := st.readDeadline, st.deadlineCh
+		st.mu.Unlock()
+		if !deadline.IsZero() && !time.Now().Before(deadline) {
+			return 0, os.ErrDeadlineExceeded
+		}
+
+		timeout, stop := deadlineTimer(deadline)
+		select {
+		case p := <-st.readCh:
+			stop()
+			return st.consume(b, p), nil
+		case <-st.remoteClosed:
+			stop()
+			return st.drain(b, st.remoteErr)
+		case <-st.s.done:
+			stop()
+			return st.drain(b, io.ErrUnexpectedEOF)
+		case <-st.localClosed:
+			stop()
+			return 0, net.ErrClosed
+		case <-timeout:
+			return 0, os.ErrDeadlineExceeded
+		case <-changed:
+			// 截止时间已变更，按新的截止时间重新等待
+			stop()
+		}
+	}
+}
+
+// drain 流已结束时先返回缓冲中剩余的数据
+func (st *stream) drain(b []byte, err error) (int, error) {
+	select {
+	case p := <-st.readCh:
+		return st.consume(b, p), nil
+	default:
+		return 0, err
+	}
+}
+
+func (st *stream) consume(b, p []byte) int {
+	st.consumed()
+	n := copy(b, p)
+	st.pending = p[n:]
+	return n
+}
+
+func (st *stream) Write(b []byte) (int, error) {
+	select {
+	case <-st.localClosed:
+		return 0, net.ErrClosed
+	case <-st.remoteClosed:
+		return 0, io.ErrClosedPipe
+	default:
+	}
+
+	written := 0
+	for len(b) > 0 {
+		chunk := b
+		if len(chunk) > maxFramePayload {
+			chunk = chunk[:maxFramePayload]
+		}
+		if err := st.acquireCredit(); err != nil {
+			return written, err
+		}
+		if err := st.s.writeFrame(frameData, st.id, chunk); err != nil {
+			return written, err
+		}
+		written += len(chunk)
+		b = b[len(chunk):]
+	}
+	return written, nil
+}
+
+func (st *stream) Close() error {
+	st.localOnce.Do(func() {
+		close(st.localClosed)
+		st.s.removeStream(st.id)
+		select {
+		case <-st.remoteClosed:
+		default:
+			_ = st.s.writeFrame(frameClose, st.id, nil)
+		}
+	})
+	return nil
+}
+
+func (st *stream) LocalAddr() net.Addr  { return st.s.conn.LocalAddr() }
+func (st *stream) RemoteAddr() net.Addr { return st.s.conn.RemoteAddr() }
+
+func (st *stream) SetDeadline(t time.Time) error {
+	st.setDeadline(func() {
+		st.readDeadline = t
+		st.writeDeadline = t
+	})
+	return nil
+}
+
+func (st *stream) SetReadDeadline(t time.Time) error {
+	st.setDeadline(func() { st.readDeadline = t })
+	return nil
+}
+
+// SetWriteDeadline 限制 Write 等待流控额度的时间；单帧写入 WebSocket 另受会话级 writeTimeout 限制
+func (st *stream) SetWriteDeadline(t time.Time) error {
+	st.setDeadline(func() { st.writeDeadline = t })
+	return nil
+}
+
+// setDeadline 修改截止时间并唤醒正在等待的读写，使其按新的截止时间重新计时
+func (st *stream) setDeadline(update func()) {
+	st.mu.Lock()
+	update()
+	close(st.deadlineCh)
+	st.deadlineCh = make(chan struct{})
+	st.mu.Unlock()
+}
@@ -31,6 +31,7 @@ import {
   DesktopOutlined,
   CloudServerOutlined,
   ClockCircleOutlined,
+  BugOutlined,
   ExportOutlined,
 } from '@ant-design/icons';
 import type { ColumnsType } from 'antd/es/table';
@@ -53,6 +54,7 @@ const terminalTypeConfig: Record<string, { label: string; color: string; icon: R
   kubectl: { label: 'Kubectl', color: 'blue', icon: <CodeOutlined /> },
   pod: { label: 'Pod', color: 'green', icon: <CloudServerOutlined /> },
   node: { label: 'Node SSH', color: 'orange', icon: <DesktopOutlined /> },
+  pod_debug: { label: 'Pod Debug', color: 'purple', icon: <BugOutlined /> },
 };
 
 // 状态配置
@@ -109,7 +111,7 @@ const [sessions, setSessions] = useState<TerminalSessionItem[]>([]);
         page: currentPage,
         pageSize,
       };
-      if (targetType) params.targetType = targetType as 'kubectl' | 'pod' | 'node';
+      if (targetType) params.targetType = targetType as 'kubectl' | 'pod' | 'node' | 'pod_debug';
       if (status) params.status = status as 'active' | 'closed' | 'error';
       if (keyword) params.keyword = keyword;
       if (dateRange) {
@@ -184,7 +186,7 @@ const [sessions, setSessions] = useState<TerminalSessionItem[]>([]);
 
   // 获取目标显示
   const getTargetDisplay = (record: TerminalSessionItem) => {
-    if (record.target_type === 'pod') {
+    if (record.target_type === 'pod' || record.target_type === 'pod_debug') {
       return `${record.namespace}/${record.pod}${record.container ? ` (${record.container})` : ''}`;
     }
     if (record.target_type === 'node') {
@@ -378,6 +380,7 @@ const [sessions, setSessions] = useState<TerminalSessionItem[]>([]);
             <Select.Option value="kubectl">Kubectl</Select.Option>
             <Select.Option value="pod">Pod</Select.Option>
             <Select.Option value="node">Node SSH</Select.Option>
+            <Select.Option value="pod_debug">Pod Debug</Select.Option>
           </Select>
           <Select
             placeholder={t('audit:commands.status')}
@@ -487,7 +490,7 @@ const [sessions, setSessions] = useState<TerminalSessionItem[]>([]);
                   />
                 </Descriptions.Item>
                 <Descriptions.Item label={t('audit:commands.target')} span={2}>
-                  {selectedSession.target_type === 'pod'
+                  {selectedSession.target_type === 'pod' || selectedSession.target_type === 'pod_debug'
                     ? `${selectedSession.namespace}/${selectedSession.pod}`
                     : selectedSession.node || selectedSession.namespace}
                 </Descriptions.Item>
@@ -627,6 +627,39 @@ const [logs, setLogs] = useState<OperationLogItem[]>([]);
                 </Card>
               )}
 
+              {/* 变更内容 */}
+              {selectedLog.changes && (
+                <Card
+                  title={t('operations.changes')}
+                  size="small"
+                  style={{ marginBottom: 24 }}
+                >
+                  <Paragraph
+                    copyable
+                    style={{
+                      background: '#1e1e1e',
+                      color: '#d4d4d4',
+                      padding: 12,
+                      borderRadius: 4,
+                      maxHeight: 300,
+                      overflow: 'auto',
+                      fontFamily: "'Fira Code', monospace",
+                      fontSize: 12,
+                      whiteSpace: 'pre-wrap',
+                      margin: 0,
+                    }}
+                  >
+                    {(() => {
+                      try {
+                        return JSON.stringify(JSON.parse(selectedLog.changes), null, 2);
+                      } catch {
+                        return selectedLog.changes;
+                      }
+                    })()}
+                  </Paragraph>
+                </Card>
+              )}
+
               {/* 错误信息 */}
               {!selectedLog.success && selectedLog.error_message && (
                 <Card
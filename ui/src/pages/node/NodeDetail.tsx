@@ -44,6 +44,7 @@ import {
   ArrowLeftOutlined,
 } from '@ant-design/icons';
 import { nodeService } from '../../services/nodeService';
+import { taskService } from '../../services/taskService';
 import { PodService } from '../../services/podService';
 import type { Node, NodeTaint, Pod, NodeCondition } from '../../types';
 import type { ColumnsType } from 'antd/es/table';
@@ -277,9 +278,14 @@ const NodeDetail: React.FC = () => {
 
   const handleDrain = async () => {
     try {
-      await nodeService.drainNode(clusterId || '', nodeName || '', drainOptions);
-      message.success(t('messages.drainSuccess'));
+      const submitted = await nodeService.drainNode(clusterId || '', nodeName || '', drainOptions);
       setDrainModalVisible(false);
+      // 驱逐以后台任务执行，等待任务结束
+      const task = await taskService.waitForTask(clusterId || '', submitted.data.id);
+      if (task.status !== 'succeeded') {
+        throw new Error(task.error || task.status);
+      }
+      message.success(t('messages.drainSuccess'));
       fetchNodeDetail();
     } catch (error) {
       console.error('Failed to drain node:', error);
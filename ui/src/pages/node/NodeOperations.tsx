@@ -33,6 +33,7 @@ import {
   InfoCircleOutlined,
 } from '@ant-design/icons';
 import { nodeService } from '../../services/nodeService';
+import { taskService } from '../../services/taskService';
 import type { Node } from '../../types';
 import { useTranslation } from 'react-i18next';
 
@@ -343,12 +344,24 @@ const [currentStep, setCurrentStep] = useState(0);
         break;
       case 'drain':
         updateNodeStatus(index, 'running', t('nodeOps:execution.draining'), 30);
-        await nodeService.drainNode(clusterId, nodeName, {
-          ignoreDaemonSets: drainOptions.ignoreDaemonSets,
-          deleteLocalData: drainOptions.deleteLocalData,
-          force: drainOptions.force,
-          gracePeriodSeconds: drainOptions.gracePeriodSeconds,
-        });
+        {
+          const submitted = await nodeService.drainNode(clusterId, nodeName, {
+            ignoreDaemonSets: drainOptions.ignoreDaemonSets,
+            deleteLocalData: drainOptions.deleteLocalData,
+            force: drainOptions.force,
+            gracePeriodSeconds: drainOptions.gracePeriodSeconds,
+            timeoutSeconds: drainOptions.timeoutSeconds,
+          });
+          // 驱逐以后台任务执行，等待任务结束
+          const task = await taskService.waitForTask(clusterId, submitted.data.id, (event) => {
+            if (event.type === 'progress' && event.progress !== undefined) {
+              updateNodeStatus(index, 'running', t('nodeOps:execution.draining'), 30 + Math.round(event.progress * 0.6));
+            }
+          });
+          if (task.status !== 'succeeded') {
+            throw new Error(task.error || task.status);
+          }
+        }
         updateNodeStatus(index, 'running', t('nodeOps:execution.drainSuccess'), 90);
         break;
       default:
@@ -8,7 +8,7 @@ export interface TerminalSessionItem {
   display_name: string;
   cluster_id: number;
   cluster_name: string;
-  target_type: 'kubectl' | 'pod' | 'node';
+  target_type: 'kubectl' | 'pod' | 'node' | 'pod_debug';
   target_ref: string;
   namespace: string;
   pod: string;
@@ -77,6 +77,7 @@ export interface SessionStats {
   kubectl_sessions: number;
   pod_sessions: number;
   node_sessions: number;
+  pod_debug_sessions: number;
 }
 
 // 会话列表查询参数
@@ -85,7 +86,7 @@ export interface SessionListParams {
   pageSize?: number;
   userId?: number;
   clusterId?: number;
-  targetType?: 'kubectl' | 'pod' | 'node';
+  targetType?: 'kubectl' | 'pod' | 'node' | 'pod_debug';
   status?: 'active' | 'closed' | 'error';
   startTime?: string;
   endTime?: string;
@@ -130,6 +131,7 @@ export interface OperationLogListResponse {
 export interface OperationLogDetail extends OperationLogItem {
   query: string;
   request_body: string;
+  changes: string; // 变更前后的值（JSON）
   user_agent: string;
 }
 
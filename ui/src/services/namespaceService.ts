@@ -21,6 +21,71 @@ export interface NamespaceDetailData extends NamespaceData {
   };
 }
 
+export interface ResourceQuotaRequest {
+  name: string;
+  labels?: Record<string, string>;
+  hard: Record<string, string>; // Kubernetes 数量格式，如 4、500m、8Gi
+  scopes?: string[];
+}
+
+export interface ResourceQuotaDetail {
+  name: string;
+  namespace: string;
+  labels: Record<string, string>;
+  hard: Record<string, string>;
+  used: Record<string, string>;
+  scopes: string[];
+  createdAt: string;
+}
+
+export interface LimitRangeItem {
+  type: 'Container' | 'Pod' | 'PersistentVolumeClaim';
+  max?: Record<string, string>;
+  min?: Record<string, string>;
+  default?: Record<string, string>;
+  defaultRequest?: Record<string, string>;
+  maxLimitRequestRatio?: Record<string, string>;
+}
+
+export interface LimitRangeRequest {
+  name: string;
+  labels?: Record<string, string>;
+  limits: LimitRangeItem[];
+}
+
+export interface LimitRangeDetail {
+  name: string;
+  namespace: string;
+  labels: Record<string, string>;
+  limits: LimitRangeItem[];
+  createdAt: string;
+}
+
+export interface QuotaUsageItem {
+  quota: string;
+  resource: string;
+  hard: string;
+  used: string;
+  percent: number;
+}
+
+export interface NamespaceQuotaUsage {
+  namespace: string;
+  items: QuotaUsageItem[];
+}
+
+// 应用 YAML 时预估将超出的配额
+export interface QuotaWarning {
+  namespace: string;
+  quota: string;
+  resource: string;
+  hard: string;
+  used: string;
+  requested: string;
+  objects: string[];
+  message: string;
+}
+
 export interface CreateNamespaceRequest {
   name: string;
   labels?: Record<string, string>;
@@ -67,6 +132,110 @@ export const deleteNamespace = async (
   await request.delete<void>(`/clusters/${clusterId}/namespaces/${namespace}`);
 };
 
+/**
+ * 获取命名空间配额使用情况
+ */
+export const getQuotaUsage = async (clusterId: number | string, namespace: string): Promise<NamespaceQuotaUsage> => {
+  const response = await request.get<NamespaceQuotaUsage>(`/clusters/${clusterId}/namespaces/${namespace}/quota-usage`);
+  return response.data;
+};
+
+/**
+ * 获取 ResourceQuota 列表
+ */
+export const getResourceQuotas = async (
+  clusterId: number | string,
+  namespace: string
+): Promise<{ items: ResourceQuotaDetail[]; total: number }> => {
+  const response = await request.get<{ items: ResourceQuotaDetail[]; total: number }>(
+    `/clusters/${clusterId}/namespaces/${namespace}/quotas`
+  );
+  return response.data;
+};
+
+/**
+ * 创建 ResourceQuota
+ */
+export const createResourceQuota = async (
+  clusterId: number | string,
+  namespace: string,
+  data: ResourceQuotaRequest
+): Promise<ResourceQuotaDetail> => {
+  const response = await request.post<ResourceQuotaDetail>(`/clusters/${clusterId}/namespaces/${namespace}/quotas`, data);
+  return response.data;
+};
+
+/**
+ * 更新 ResourceQuota
+ */
+export const updateResourceQuota = async (
+  clusterId: number | string,
+  namespace: string,
+  name: string,
+  data: ResourceQuotaRequest
+): Promise<ResourceQuotaDetail> => {
+  const response = await request.put<ResourceQuotaDetail>(
+    `/clusters/${clusterId}/namespaces/${namespace}/quotas/${name}`,
+    data
+  );
+  return response.data;
+};
+
+/**
+ * 删除 ResourceQuota
+ */
+export const deleteResourceQuota = async (clusterId: number | string, namespace: string, name: string): Promise<void> => {
+  await request.delete<void>(`/clusters/${clusterId}/namespaces/${namespace}/quotas/${name}`);
+};
+
+/**
+ * 获取 LimitRange 列表
+ */
+export const getLimitRanges = async (
+  clusterId: number | string,
+  namespace: string
+): Promise<{ items: LimitRangeDetail[]; total: number }> => {
+  const response = await request.get<{ items: LimitRangeDetail[]; total: number }>(
+    `/clusters/${clusterId}/namespaces/${namespace}/limitranges`
+  );
+  return response.data;
+};
+
+/**
+ * 创建 LimitRange
+ */
+export const createLimitRange = async (
+  clusterId: number | string,
+  namespace: string,
+  data: LimitRangeRequest
+): Promise<LimitRangeDetail> => {
+  const response = await request.post<LimitRangeDetail>(`/clusters/${clusterId}/namespaces/${namespace}/limitranges`, data);
+  return response.data;
+};
+
+/**
+ * 更新 LimitRange
+ */
+export const updateLimitRange = async (
+  clusterId: number | string,
+  namespace: string,
+  name: string,
+  data: LimitRangeRequest
+): Promise<LimitRangeDetail> => {
+  const response = await request.put<LimitRangeDetail>(
+    `/clusters/${clusterId}/namespaces/${namespace}/limitranges/${name}`,
+    data
+  );
+  return response.data;
+};
+
+/**
+ * 删除 LimitRange
+ */
+export const deleteLimitRange = async (clusterId: number | string, namespace: string, name: string): Promise<void> => {
+  await request.delete<void>(`/clusters/${clusterId}/namespaces/${namespace}/limitranges/${name}`);
+};
+
 /**
  * 命名空间服务对象 - 兼容旧的调用方式
  */
@@ -78,5 +247,14 @@ export const namespaceService = {
   getNamespaceDetail,
   createNamespace,
   deleteNamespace,
+  getQuotaUsage,
+  getResourceQuotas,
+  createResourceQuota,
+  updateResourceQuota,
+  deleteResourceQuota,
+  getLimitRanges,
+  createLimitRange,
+  updateLimitRange,
+  deleteLimitRange,
 };
 
@@ -0,0 +1,142 @@
+import { request } from '../utils/api';
+import type { ApiResponse } from '../types';
+
+export interface NamespaceTemplateVariable {
+  name: string;
+  description?: string;
+  required?: boolean;
+  default?: string;
+}
+
+export interface NamespaceTemplate {
+  id: number;
+  name: string;
+  description: string;
+  content: string; // 多文档 YAML，${变量} 为占位符，${namespace} 为内置变量
+  variables: NamespaceTemplateVariable[];
+  version: number;
+  namespaces: number;
+  createdBy: string;
+  updatedBy: string;
+  createdAt: string;
+  updatedAt: string;
+}
+
+export interface NamespaceTemplateRequest {
+  name: string;
+  description?: string;
+  content: string;
+  variables?: NamespaceTemplateVariable[];
+}
+
+export interface NamespaceTemplateBinding {
+  id: number;
+  cluster_id: number;
+  namespace: string;
+  template_id: number;
+  template_name: string;
+  template_version: number;
+  latest_version: number;
+  outdated: boolean;
+  values: Record<string, string>;
+  username: string;
+  created_at: string;
+  updated_at: string;
+}
+
+export interface BatchApplyItem {
+  index: number;
+  kind: string;
+  apiVersion: string;
+  name: string;
+  namespace?: string;
+  status: 'applied' | 'failed' | 'skipped';
+  error?: string;
+}
+
+export interface NamespaceProvisionResult {
+  namespace: string;
+  template: string;
+  templateVersion: number;
+  values: Record<string, string>;
+  created: boolean;
+  dryRun: boolean;
+  apply: {
+    items: BatchApplyItem[];
+    total: number;
+    succeeded: number;
+    failed: number;
+    skipped: number;
+  };
+  rolledBack: boolean;
+  rollbackErrors?: string[];
+}
+
+export interface CreateNamespaceFromTemplateRequest {
+  name: string;
+  templateId: number;
+  values?: Record<string, string>;
+  labels?: Record<string, string>;
+  annotations?: Record<string, string>;
+}
+
+export class NamespaceTemplateService {
+  // 获取模板列表
+  static async getTemplates(): Promise<ApiResponse<{ items: NamespaceTemplate[]; total: number }>> {
+    return request.get('/namespace-templates');
+  }
+
+  // 获取模板详情
+  static async getTemplate(id: number): Promise<ApiResponse<NamespaceTemplate>> {
+    return request.get(`/namespace-templates/${id}`);
+  }
+
+  // 创建模板（平台管理员）
+  static async createTemplate(data: NamespaceTemplateRequest): Promise<ApiResponse<NamespaceTemplate>> {
+    return request.post('/namespace-templates', data);
+  }
+
+  // 更新模板，内容或变量变化时版本号加一（平台管理员）
+  static async updateTemplate(id: number, data: NamespaceTemplateRequest): Promise<ApiResponse<NamespaceTemplate>> {
+    return request.put(`/namespace-templates/${id}`, data);
+  }
+
+  // 删除模板，仍有命名空间使用时返回 409（平台管理员）
+  static async deleteTemplate(id: number): Promise<ApiResponse<null>> {
+    return request.delete(`/namespace-templates/${id}`);
+  }
+
+  // 预览渲染结果
+  static async previewTemplate(
+    id: number,
+    namespace: string,
+    values?: Record<string, string>
+  ): Promise<ApiResponse<{ yaml: string; values: Record<string, string>; version: number }>> {
+    return request.post(`/namespace-templates/${id}/preview`, { namespace, values });
+  }
+
+  // 按模板创建命名空间，任一资源失败时整体回滚
+  static async createNamespace(
+    clusterId: string | number,
+    data: CreateNamespaceFromTemplateRequest
+  ): Promise<ApiResponse<NamespaceProvisionResult>> {
+    return request.post(`/clusters/${clusterId}/namespaces/from-template`, data);
+  }
+
+  // 获取命名空间绑定的模板及是否有新版本
+  static async getNamespaceBinding(
+    clusterId: string | number,
+    namespace: string
+  ): Promise<ApiResponse<NamespaceTemplateBinding | null>> {
+    return request.get(`/clusters/${clusterId}/namespaces/${namespace}/template`);
+  }
+
+  // 重新应用模板最新版本（未指定 templateId 时使用当前绑定的模板），未传入的变量沿用上次的值
+  static async applyToNamespace(
+    clusterId: string | number,
+    namespace: string,
+    data: { templateId?: number; values?: Record<string, string>; dryRun?: boolean }
+  ): Promise<ApiResponse<NamespaceProvisionResult>> {
+    return request.post(`/clusters/${clusterId}/namespaces/${namespace}/template`, data);
+  }
+}
@@ -0,0 +1,136 @@
+import { request } from '../utils/api';
+import type { ApiResponse } from '../types';
+
+export interface NetworkPolicyInfo {
+  name: string;
+  namespace: string;
+  podSelector: string;
+  policyTypes: string[];
+  ingressRules: number;
+  egressRules: number;
+  labels: Record<string, string>;
+  createdAt: string;
+}
+
+export interface NetworkPolicyRequest {
+  name: string;
+  namespace: string;
+  labels?: Record<string, string>;
+  annotations?: Record<string, string>;
+  spec: Record<string, unknown>; // networking.k8s.io/v1 NetworkPolicySpec
+}
+
+export interface NetworkPolicyVerdict {
+  namespace: string;
+  name: string;
+  allows: boolean;
+  draft?: boolean;
+}
+
+export interface ConnectivityDirection {
+  isolated: boolean;
+  allowed: boolean;
+  policies: NetworkPolicyVerdict[];
+}
+
+export interface ConnectivityResult {
+  allowed: boolean;
+  reason: string;
+  egress: ConnectivityDirection;
+  ingress: ConnectivityDirection;
+}
+
+export interface ConnectivityRequest {
+  srcNamespace: string;
+  srcPod: string;
+  dstNamespace: string;
+  dstPod: string;
+  port: number;
+  protocol?: 'TCP' | 'UDP' | 'SCTP';
+  // 草稿策略（完整的 NetworkPolicy 对象），与现有策略合并后评估，同名策略以草稿为准
+  draftPolicies?: Record<string, unknown>[];
+}
+
+export interface PodIsolation {
+  name: string;
+  labels: Record<string, string>;
+  ingressIsolated: boolean;
+  egressIsolated: boolean;
+  ingressPolicies: string[];
+  egressPolicies: string[];
+}
+
+export interface NamespaceIsolation {
+  namespace: string;
+  policies: number;
+  totalPods: number;
+  ingressIsolated: number;
+  egressIsolated: number;
+  pods: PodIsolation[];
+}
+
+export class NetworkPolicyService {
+  // 获取NetworkPolicy列表
+  static async getNetworkPolicies(
+    clusterId: string,
+    namespace?: string,
+    search?: string
+  ): Promise<ApiResponse<{ items: NetworkPolicyInfo[]; total: number }>> {
+    const params = new URLSearchParams();
+    if (namespace && namespace !== '_all_') {
+      params.append('namespace', namespace);
+    }
+    if (search) {
+      params.append('search', search);
+    }
+    return request.get(`/clusters/${clusterId}/networkpolicies?${params}`);
+  }
+
+  // 获取NetworkPolicy详情（完整对象）
+  static async getNetworkPolicy(clusterId: string, namespace: string, name: string): Promise<ApiResponse<Record<string, unknown>>> {
+    return request.get(`/clusters/${clusterId}/networkpolicies/${namespace}/${name}`);
+  }
+
+  // 创建NetworkPolicy
+  static async createNetworkPolicy(clusterId: string, data: NetworkPolicyRequest): Promise<ApiResponse<NetworkPolicyInfo>> {
+    return request.post(`/clusters/${clusterId}/networkpolicies`, data);
+  }
+
+  // 更新NetworkPolicy
+  static async updateNetworkPolicy(
+    clusterId: string,
+    namespace: string,
+    name: string,
+    data: NetworkPolicyRequest
+  ): Promise<ApiResponse<NetworkPolicyInfo>> {
+    return request.put(`/clusters/${clusterId}/networkpolicies/${namespace}/${name}`, data);
+  }
+
+  // 删除NetworkPolicy
+  static async deleteNetworkPolicy(clusterId: string, namespace: string, name: string): Promise<ApiResponse<null>> {
+    return request.delete(`/clusters/${clusterId}/networkpolicies/${namespace}/${name}`);
+  }
+
+  // 评估源 Pod 能否访问目标 Pod 的端口，携带草稿策略时使用 POST
+  static async simulate(clusterId: string, req: ConnectivityRequest): Promise<ApiResponse<ConnectivityResult>> {
+    if (req.draftPolicies && req.draftPolicies.length > 0) {
+      return request.post(`/clusters/${clusterId}/networkpolicies/simulate`, req);
+    }
+    const params = new URLSearchParams({
+      srcNamespace: req.srcNamespace,
+      srcPod: req.srcPod,
+      dstNamespace: req.dstNamespace,
+      dstPod: req.dstPod,
+      port: req.port.toString(),
+    });
+    if (req.protocol) {
+      params.append('protocol', req.protocol);
+    }
+    return request.get(`/clusters/${clusterId}/networkpolicies/simulate?${params}`);
+  }
+
+  // 获取命名空间内各 Pod 的隔离情况
+  static async getNamespaceIsolation(clusterId: string, namespace: string): Promise<ApiResponse<NamespaceIsolation>> {
+    return request.get(`/clusters/${clusterId}/networkpolicies/isolation?namespace=${encodeURIComponent(namespace)}`);
+  }
+}
@@ -1,5 +1,6 @@
 import type { ApiResponse, Node, PaginatedResponse } from '../types';
 import { request } from '../utils/api';
+import type { Task } from './taskService';
 
 export interface NodeListParams {
   clusterId: string;
@@ -19,6 +20,65 @@ export interface NodeOverview {
   storageUsage: number;
 }
 
+export interface DrainPodResult {
+  namespace: string;
+  name: string;
+  status: 'evicted' | 'skipped' | 'blocked' | 'failed';
+  reason?: string;
+  blockingPDB?: string;
+  attempts: number;
+}
+
+export interface DrainResult {
+  node: string;
+  completed: boolean;
+  evicted: number;
+  skipped: number;
+  blocked: number;
+  failed: number;
+  pods: DrainPodResult[];
+}
+
+export interface NodeTaint {
+  key: string;
+  value?: string;
+  effect: 'NoSchedule' | 'PreferNoSchedule' | 'NoExecute';
+  timeAdded?: string;
+}
+
+// 节点标签、注解与污点变更，先删除后新增
+export interface NodeMetadataPatch {
+  labels?: Record<string, string>;
+  removeLabels?: string[];
+  annotations?: Record<string, string>;
+  removeAnnotations?: string[];
+  taints?: NodeTaint[];
+  removeTaints?: { key: string; effect?: NodeTaint['effect'] }[];
+}
+
+export interface NodeMetadata {
+  labels: Record<string, string>;
+  annotations: Record<string, string>;
+  taints: NodeTaint[];
+}
+
+export interface NodeMetadataResult {
+  node: string;
+  success: boolean;
+  changed: boolean;
+  error?: string;
+  before?: NodeMetadata;
+  after?: NodeMetadata;
+}
+
+export interface NodeMetadataBatchResult {
+  dryRun: boolean;
+  total: number;
+  succeeded: number;
+  failed: number;
+  results: NodeMetadataResult[];
+}
+
 export const nodeService = {
   // 获取节点列表
   getNodes: async (params: NodeListParams): Promise<ApiResponse<PaginatedResponse<Node>>> => {
@@ -62,8 +122,34 @@ export const nodeService = {
       deleteLocalData?: boolean;
       force?: boolean;
       gracePeriodSeconds?: number;
+      timeoutSeconds?: number;
     } = {}
-  ): Promise<ApiResponse<null>> => {
+  ): Promise<ApiResponse<Task>> => {
     return request.post(`/clusters/${clusterId}/nodes/${name}/drain`, options);
   },
+
+  // 批量封锁/解封节点（后台任务）
+  batchCordonNodes: async (clusterId: string, nodes: string[], cordon = true): Promise<ApiResponse<Task>> => {
+    return request.post(`/clusters/${clusterId}/nodes/batch/${cordon ? 'cordon' : 'uncordon'}`, { nodes });
+  },
+
+  // 修改节点标签、注解与污点，dryRun 时仅预览
+  updateNodeMetadata: async (
+    clusterId: string,
+    name: string,
+    patch: NodeMetadataPatch,
+    dryRun = false
+  ): Promise<ApiResponse<NodeMetadataResult>> => {
+    return request.put(`/clusters/${clusterId}/nodes/${name}/metadata`, { ...patch, dryRun });
+  },
+
+  // 批量修改多个节点的标签、注解与污点
+  batchUpdateNodeMetadata: async (
+    clusterId: string,
+    nodes: string[],
+    patch: NodeMetadataPatch,
+    dryRun = false
+  ): Promise<ApiResponse<NodeMetadataBatchResult>> => {
+    return request.post(`/clusters/${clusterId}/nodes/batch/metadata`, { nodes, ...patch, dryRun });
+  },
 };
\ No newline at end of file
@@ -87,6 +87,23 @@ export interface PodLogsResponse {
   };
 }
 
+export interface DebugContainerInfo {
+  name: string;
+  image: string;
+  targetContainer?: string;
+  state: 'waiting' | 'running' | 'terminated';
+  reason?: string;
+  message?: string;
+  startedAt?: string;
+}
+
+export interface DebugContainerRequest {
+  name?: string;
+  image?: string; // 为空时使用平台默认调试镜像
+  targetContainer?: string; // 共享进程命名空间的目标容器
+  command?: string[];
+}
+
 export class PodService {
   // 获取Pod列表
   static async getPods(
@@ -295,6 +312,37 @@ export class PodService {
     return request.get(`/clusters/${clusterId}/pods/nodes`);
   }
 
+  // 获取Pod中的临时调试容器及平台默认调试镜像
+  static async getDebugContainers(
+    clusterId: string,
+    namespace: string,
+    name: string
+  ): Promise<ApiResponse<{ items: DebugContainerInfo[]; defaultImage: string }>> {
+    return request.get(`/clusters/${clusterId}/pods/${namespace}/${name}/debug`);
+  }
+
+  // 添加临时调试容器，容器启动后返回；终端通过 getDebugTerminalUrl 接入
+  static async createDebugContainer(
+    clusterId: string,
+    namespace: string,
+    name: string,
+    data: DebugContainerRequest
+  ): Promise<ApiResponse<DebugContainerInfo>> {
+    return request.post(`/clusters/${clusterId}/pods/${namespace}/${name}/debug`, data);
+  }
+
+  // 临时调试容器终端 WebSocket 地址
+  static getDebugTerminalUrl(
+    clusterId: string,
+    namespace: string,
+    name: string,
+    container: string,
+    token: string
+  ): string {
+    const protocol = window.location.protocol === 'https:' ? 'wss:' : 'ws:';
+    return `${protocol}//${window.location.hostname}:8080/ws/clusters/${clusterId}/pods/${namespace}/${name}/debug/${container}/terminal?token=${encodeURIComponent(token)}`;
+  }
+
   // 创建WebSocket连接获取实时日志流
   static createLogStream(
     clusterId: string,
@@ -0,0 +1,111 @@
+import type { ApiResponse } from '../types';
+import { request } from '../utils/api';
+
+export type TaskStatus = 'pending' | 'running' | 'succeeded' | 'failed' | 'cancelled' | 'interrupted';
+
+export interface TaskStep {
+  id: number;
+  task_id: number;
+  name: string;
+  status: string;
+  message: string;
+  started_at: string;
+  finished_at?: string;
+}
+
+export interface TaskLog {
+  id: number;
+  task_id: number;
+  level: 'info' | 'warn' | 'error';
+  message: string;
+  created_at: string;
+}
+
+export interface Task {
+  id: number;
+  cluster_id: number;
+  type: string;
+  target: string;
+  status: TaskStatus;
+  progress: number;
+  params: string;
+  result: string;
+  error: string;
+  attempts: number;
+  user_id: number;
+  username: string;
+  started_at?: string;
+  finished_at?: string;
+  created_at: string;
+  updated_at: string;
+  steps?: TaskStep[];
+  logs?: TaskLog[];
+}
+
+export interface TaskEvent {
+  type: 'snapshot' | 'status' | 'progress' | 'step' | 'log';
+  taskId?: number;
+  status?: TaskStatus;
+  progress?: number;
+  error?: string;
+  step?: TaskStep;
+  log?: TaskLog;
+  task?: Task;
+}
+
+const finishedStatuses: TaskStatus[] = ['succeeded', 'failed', 'cancelled', 'interrupted'];
+
+export const isTaskFinished = (status: TaskStatus): boolean => finishedStatuses.includes(status);
+
+export const taskService = {
+  // 获取任务列表
+  getTasks: async (
+    clusterId: string,
+    params: { type?: string; status?: string; page?: number; pageSize?: number } = {}
+  ): Promise<ApiResponse<{ items: Task[]; total: number; page: number; pageSize: number }>> => {
+    return request.get(`/clusters/${clusterId}/tasks`, { params });
+  },
+
+  // 获取任务详情（含步骤与日志）
+  getTask: async (clusterId: string, taskId: number): Promise<ApiResponse<Task>> => {
+    return request.get(`/clusters/${clusterId}/tasks/${taskId}`);
+  },
+
+  // 取消任务
+  cancelTask: async (clusterId: string, taskId: number): Promise<ApiResponse<null>> => {
+    return request.post(`/clusters/${clusterId}/tasks/${taskId}/cancel`);
+  },
+
+  // 订阅任务进度 WebSocket
+  createTaskStream: (taskId: number): WebSocket => {
+    const token = localStorage.getItem('token');
+    const protocol = window.location.protocol === 'https:' ? 'wss:' : 'ws:';
+    const host = window.location.host;
+
+    // 开发环境使用后端端口
+    const wsHost = import.meta.env.DEV ? 'localhost:8080' : host;
+    return new WebSocket(`${protocol}//${wsHost}/ws/tasks/${taskId}?token=${token}`);
+  },
+
+  // 等待任务结束，onEvent 接收进度事件；任务最终状态由 REST 接口确认
+  waitForTask: (clusterId: string, taskId: number, onEvent?: (event: TaskEvent) => void): Promise<Task> => {
+    return new Promise((resolve, reject) => {
+      const ws = taskService.createTaskStream(taskId);
+      const finish = () => {
+        taskService.getTask(clusterId, taskId).then(res => resolve(res.data)).catch(reject);
+      };
+      ws.onmessage = (msg) => {
+        const event = JSON.parse(msg.data) as TaskEvent;
+        onEvent?.(event);
+        const status = event.type === 'snapshot' ? event.task?.status : event.status;
+        if (event.type !== 'progress' && status && isTaskFinished(status)) {
+          ws.close();
+        }
+      };
+      ws.onclose = finish;
+      ws.onerror = () => ws.close();
+    });
+  },
+};
+
+export default taskService;